@@ -1,56 +1,195 @@
+// Command main is the NullTask server, along with the commands operators maintain it with.
+//
+// Usage:
+//
+//	main [command] [flags] [arguments]
+//
+// Without a command it serves the API, as "main serve" does. Every command reads the configuration from the
+// environment and the .env file of the working directory, and those working on the data connect to the same database.
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	// Embed the time zone database, so the time zones of the users load even without one installed
+	_ "time/tzdata"
 
+	"github.com/charmbracelet/log"
 	"github.com/devgugga/NullTask/internal/config"
-	"github.com/devgugga/NullTask/internal/models"
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
+// env is what the commands share: the settings read from the environment, the logger built from them,
+// the database connection once connect opened it, and where they read and write.
+type env struct {
+	settings *settings
+	log      *log.Logger
+	db       *gorm.DB
+	out      io.Writer
+	in       io.Reader
+}
+
+// command is a subcommand of the server. define registers its flags and returns the function running it
+// with the arguments left after them.
+type command struct {
+	name    string
+	args    string
+	summary string
+	define  func(flags *flag.FlagSet, e *env) func(ctx context.Context, args []string) error
+}
+
+// errUsage is returned by commands called with the wrong arguments, for their usage to be printed.
+var errUsage = errors.New("invalid arguments")
+
+var commands []command
+
+func init() {
+	// Set up in init, since the help lists them
+	commands = []command{
+		{"serve", "", "Migrate the database and serve the API (the default)", defineServe},
+		{"migrate", "", "Create or update the tables of the database", defineMigrate},
+		{"create-admin", "", "Create an admin user", defineCreateAdmin},
+		{"reset-password", "<email|id>", "Set a new password for a user", defineResetPassword},
+		{"deactivate-user", "<email|id>", "Stop a user from logging in and using their tokens", defineDeactivateUser},
+		{"reactivate-user", "<email|id>", "Let a deactivated user log in again", defineReactivateUser},
+		{"purge-trash", "", "Permanently remove the deleted tasks", definePurgeTrash},
+		{"reindex-search", "", "Rebuild the task search index", defineReindexSearch},
+		{"print-config", "", "Print the configuration read from the environment", definePrintConfig},
+	}
+}
+
 // main function is the entry point of the application.
 func main() {
-	// Initializing logger using the config package's Logger function.
-	logger := config.Logger()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	// Loading.env file using godotenv's Load function.
-	// If.env file is not found, it will return an error.
-	err := godotenv.Load(".env")
-	if err != nil {
-		logger.Error("Error loading.env file", "error", err)
+// run runs the command named by the first argument, or serve when there's none, and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	program := filepath.Base(os.Args[0])
+	name, named := "serve", false
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args, named = args[0], args[1:], true
+	}
+	if name == "help" || !named && len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		usage(stderr, program)
+		return 0
 	}
 
-	// Getting the environment variables HOST, PORT, USER, PASSWORD, DBNAME using os.Getenv function.
-	host := os.Getenv("HOST")
-	dbPort := os.Getenv("PORT")
-	user := os.Getenv("USER")
-	password := os.Getenv("PASSWORD")
-	dbname := os.Getenv("DBNAME")
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		e := &env{out: stdout, in: stdin}
+		flags := flag.NewFlagSet(program+" "+cmd.name, flag.ContinueOnError)
+		flags.SetOutput(stderr)
+		flags.Usage = func() {
+			fmt.Fprintf(stderr, "Usage: %s %s [flags] %s\n\n%s.\n", program, cmd.name, cmd.args, cmd.summary)
+			if hasFlags(flags) {
+				fmt.Fprintln(stderr, "\nFlags:")
+				flags.PrintDefaults()
+			}
+		}
+		runCommand := cmd.define(flags, e)
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			return 2
+		}
+
+		if err := e.load(); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", program, err)
+			return 1
+		}
+		defer e.close()
+
+		// The server shuts down gracefully on an interrupt, and the other commands stop where they are
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		switch err := runCommand(ctx, flags.Args()); {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage):
+			flags.Usage()
+			return 2
+		default:
+			fmt.Fprintf(stderr, "%s: %v\n", program, err)
+			return 1
+		}
+	}
+	fmt.Fprintf(stderr, "%s: unknown command %q\n", program, name)
+	usage(stderr, program)
+	return 2
+}
 
-	// Creating a DBConfig struct with the obtained environment variables.
-	dbConfig := &config.DBConfig{
-		Host:     host,
-		Port:     dbPort,
-		User:     user,
-		Password: password,
-		DBName:   dbname,
+// usage prints how to use the server and its commands.
+func usage(w io.Writer, program string) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags] [arguments]\n", program)
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.summary)
 	}
+	fmt.Fprintf(w, "\nRun \"%s <command> -h\" for the flags of a command.\n", program)
+	fmt.Fprintln(w, "The configuration is read from the environment and the .env file of the working directory.")
+}
+
+// load reads the settings from the environment and builds the logger they ask for.
+func (e *env) load() error {
+	// Loading.env file using godotenv's Load function.
+	// The file is optional, since the variables can be set in the environment, but the other errors are logged once the logger is ready.
+	envErr := godotenv.Load(".env")
 
-	// Connecting to the database using the config package's ConnectDB function.
-	// If there is an error during the connection, it will return an error.
-	db, err := config.ConnectDB(dbConfig)
+	// Initializing logger using the config package's Logger function.
+	// The format, level and output are read from the LOG_FORMAT, LOG_LEVEL and LOG_OUTPUT variables.
+	logger, err := config.Logger(config.LoggerConfigFromEnv())
 	if err != nil {
-		logger.Fatal("Error connecting to the database", "error", err)
+		return fmt.Errorf("configuring the logger: %w", err)
+	}
+	e.log = logger
+
+	if envErr != nil && !errors.Is(envErr, os.ErrNotExist) {
+		logger.Error("Error loading.env file", "error", envErr)
 	}
 
-	// Migrating the User and Tasks models to the database using GORM's AutoMigrate function.
-	// If there is an error during the migration, it will return an error.
-	err = db.AutoMigrate(&models.User{}, &models.Tasks{})
+	e.settings, err = loadSettings()
+	return err
+}
+
+// connect opens the connection to the database the settings select, once, and returns it.
+func (e *env) connect() (*gorm.DB, error) {
+	if e.db != nil {
+		return e.db, nil
+	}
+	db, err := config.ConnectDB(&e.settings.db)
 	if err != nil {
-		logger.Error("Failed to creating tables in the database", "error", err)
+		return nil, fmt.Errorf("connecting to the database: %w", err)
+	}
+	e.db = db
+	return db, nil
+}
+
+// close closes the connection to the database, if connect opened it.
+func (e *env) close() {
+	if e.db == nil {
+		return
 	}
+	if sqlDB, err := e.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
 
-	// Starting the server on port "1323" using the config package's StartServer function.
-	// It takes the port number, database connection, and logger as parameters.
-	config.StartServer("1323", db, logger)
+// hasFlags reports whether any flag is defined in the set.
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/config"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+)
+
+// defineMigrate defines the migrate command, which creates or updates the tables of the database,
+// as the server does when it starts.
+func defineMigrate(_ *flag.FlagSet, e *env) func(context.Context, []string) error {
+	return func(_ context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		db, err := e.connect()
+		if err != nil {
+			return err
+		}
+		if err := config.Migrate(db); err != nil {
+			return fmt.Errorf("migrating the database: %w", err)
+		}
+		fmt.Fprintln(e.out, "Migrated the database")
+		return nil
+	}
+}
+
+// definePurgeTrash defines the purge-trash command, which permanently removes the deleted tasks, along with
+// everything attached to them, and the files of their attachments from the storage.
+func definePurgeTrash(flags *flag.FlagSet, e *env) func(context.Context, []string) error {
+	olderThan := flags.Duration("older-than", 0, "only purge the tasks deleted at least this long ago, such as 720h")
+	dryRun := flags.Bool("dry-run", false, "count the tasks that would be purged without removing them")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 || *olderThan < 0 {
+			return errUsage
+		}
+		db, err := e.connect()
+		if err != nil {
+			return err
+		}
+		tasks := repository.NewGormTaskRepository(db)
+		attachments := repository.NewGormAttachmentRepository(db)
+
+		deleted, err := tasks.FindDeleted(ctx, time.Now().Add(-*olderThan))
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			fmt.Fprintf(e.out, "%d deleted tasks would be purged\n", len(deleted))
+			return nil
+		}
+		if len(deleted) == 0 {
+			fmt.Fprintln(e.out, "The trash is empty")
+			return nil
+		}
+
+		blobs, err := config.BlobStore(ctx, &e.settings.storage)
+		if err != nil {
+			return fmt.Errorf("connecting to the attachment storage: %w", err)
+		}
+		thumbs := thumbnails.NewWorker(attachments, blobs, e.log, e.settings.storage.ThumbnailSizes)
+
+		purged, files := 0, 0
+		for _, task := range deleted {
+			// Interrupting stops between tasks, leaving the others in the trash
+			if ctx.Err() != nil {
+				break
+			}
+			attached, err := attachments.FindByTask(ctx, task.ID)
+			if err != nil {
+				return err
+			}
+			if err := tasks.Purge(ctx, task.ID); err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					// Purged meanwhile by another run
+					continue
+				}
+				return err
+			}
+			purged++
+
+			// The files go once their attachments are gone, as when they're deleted through the API
+			for _, attachment := range attached {
+				if err := blobs.Delete(ctx, attachment.StorageKey); err != nil {
+					e.log.Error("Error removing the stored attachment", "key", attachment.StorageKey, "error", err)
+					continue
+				}
+				if attachment.ThumbnailStatus != models.ThumbnailNone {
+					if err := thumbs.Delete(ctx, attachment); err != nil {
+						e.log.Error("Error removing the thumbnails", "key", attachment.StorageKey, "error", err)
+					}
+				}
+				files++
+			}
+		}
+
+		fmt.Fprintf(e.out, "Purged %d deleted tasks and %d attachments\n", purged, files)
+		return ctx.Err()
+	}
+}
+
+// defineReindexSearch defines the reindex-search command, which rebuilds the task search index from the tasks.
+func defineReindexSearch(_ *flag.FlagSet, e *env) func(context.Context, []string) error {
+	return func(_ context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		db, err := e.connect()
+		if err != nil {
+			return err
+		}
+		if err := config.ReindexSearch(db); err != nil {
+			return fmt.Errorf("reindexing the search: %w", err)
+		}
+		fmt.Fprintln(e.out, "Reindexed the task search")
+		return nil
+	}
+}
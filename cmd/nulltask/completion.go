@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defineCompletion defines the completion command, which prints the script completing the commands
+// and flags of nulltask for a shell. Load it with, for instance:
+//
+//	source <(nulltask completion bash)
+//	nulltask completion fish > ~/.config/fish/completions/nulltask.fish
+func defineCompletion(_ *flag.FlagSet, a *app) func(context.Context, []string) error {
+	return func(_ context.Context, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		switch args[0] {
+		case "bash":
+			writeBashCompletion(a.out)
+		case "zsh":
+			// zsh runs the bash completion through its compatibility layer
+			fmt.Fprintln(a.out, "#compdef nulltask")
+			fmt.Fprintln(a.out, "autoload -U +X bashcompinit && bashcompinit")
+			writeBashCompletion(a.out)
+		case "fish":
+			writeFishCompletion(a.out)
+		default:
+			return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", args[0])
+		}
+		return nil
+	}
+}
+
+// completedFlag is a flag of a command, as completions offer it.
+type completedFlag struct {
+	name, usage string
+	takesValue  bool
+}
+
+// commandFlags returns the flags the command defines.
+func commandFlags(cmd command) []completedFlag {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	cmd.define(flags, &app{})
+	var completed []completedFlag
+	flags.VisitAll(func(f *flag.Flag) {
+		boolean, ok := f.Value.(interface{ IsBoolFlag() bool })
+		completed = append(completed, completedFlag{f.Name, f.Usage, !ok || !boolean.IsBoolFlag()})
+	})
+	return completed
+}
+
+// completionArgs returns the words completing the arguments of the command,
+// "<files>" standing for the files in the directory and an empty string for nothing.
+func completionArgs(name string) string {
+	switch name {
+	case "import":
+		return "<files>"
+	case "completion":
+		return "bash zsh fish"
+	}
+	return ""
+}
+
+func writeBashCompletion(w io.Writer) {
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+
+	fmt.Fprint(w, `_nulltask() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} cmd="" i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case ${COMP_WORDS[i]} in
+		-server | -token) ((i++)) ;;
+		-*) ;;
+		*)
+			cmd=${COMP_WORDS[i]}
+			break
+			;;
+		esac
+	done
+
+	local flags="" values="" args=""
+	case $cmd in
+	"")
+		flags="-server -token"
+		values="-server -token"
+		args="`+strings.Join(names, " ")+`"
+		;;
+`)
+	for _, cmd := range commands {
+		var flags, values []string
+		for _, f := range commandFlags(cmd) {
+			flags = append(flags, "-"+f.name)
+			if f.takesValue {
+				values = append(values, "-"+f.name)
+			}
+		}
+		fmt.Fprintf(w, "\t%s)\n\t\tflags=%q\n\t\tvalues=%q\n\t\targs=%q\n\t\t;;\n", cmd.name, strings.Join(flags, " "), strings.Join(values, " "), completionArgs(cmd.name))
+	}
+	fmt.Fprint(w, `	esac
+
+	if [[ " $values " == *" $prev "* ]]; then
+		COMPREPLY=()
+	elif [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$flags" -- "$cur"))
+	elif [[ $args == "<files>" ]]; then
+		COMPREPLY=($(compgen -f -- "$cur"))
+	else
+		COMPREPLY=($(compgen -W "$args" -- "$cur"))
+	fi
+}
+complete -o default -F _nulltask nulltask
+`)
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintln(w, "complete -c nulltask -f")
+	fmt.Fprintln(w, "complete -c nulltask -n __fish_use_subcommand -o server -r -d 'address of the NullTask API'")
+	fmt.Fprintln(w, "complete -c nulltask -n __fish_use_subcommand -o token -r -d 'access token of the user'")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c nulltask -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(cmd.summary))
+	}
+	for _, cmd := range commands {
+		seen := "'__fish_seen_subcommand_from " + cmd.name + "'"
+		for _, f := range commandFlags(cmd) {
+			value := ""
+			if f.takesValue {
+				value = " -r"
+			}
+			fmt.Fprintf(w, "complete -c nulltask -n %s -o %s%s -d %s\n", seen, f.name, value, fishQuote(f.usage))
+		}
+		switch args := completionArgs(cmd.name); args {
+		case "":
+		case "<files>":
+			fmt.Fprintf(w, "complete -c nulltask -n %s -F\n", seen)
+		default:
+			fmt.Fprintf(w, "complete -c nulltask -n %s -a %s\n", seen, fishQuote(args))
+		}
+	}
+}
+
+// fishQuote quotes the text as a single-quoted string of fish.
+func fishQuote(text string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(text) + "'"
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// config is the configuration file of nulltask, where login keeps the session of the user.
+type config struct {
+	Server    string     `json:"server,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	UserID    string     `json:"user_id,omitempty"`
+	Email     string     `json:"email,omitempty"`
+}
+
+// configPath returns where the configuration file is: the NULLTASK_CONFIG environment variable,
+// or nulltask/config.json in the configuration directory of the user.
+func configPath() (string, error) {
+	if path := os.Getenv("NULLTASK_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nulltask", "config.json"), nil
+}
+
+// loadConfig reads the configuration file, which is empty when it doesn't exist yet.
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, errors.New("invalid configuration file " + path + ": " + err.Error())
+	}
+	return cfg, nil
+}
+
+// save writes the configuration file, readable only by the user since it holds their token.
+func (c *config) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defineLogin defines the login command, which asks for the e-mail and password of the user
+// and keeps the token the API returns, with the server, in the configuration file.
+func defineLogin(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	email := flags.String("email", "", "e-mail of the user (default: asked)")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the standard input")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		input := bufio.NewReader(a.in)
+		if *email == "" {
+			fmt.Fprint(a.out, "E-mail: ")
+			line, err := readLine(input)
+			if err != nil {
+				return err
+			}
+			*email = line
+		}
+
+		var password string
+		var err error
+		if *passwordStdin {
+			password, err = readLine(input)
+		} else {
+			fmt.Fprint(a.out, "Password: ")
+			password, err = readPassword(a.in, input)
+			fmt.Fprintln(a.out)
+		}
+		if err != nil {
+			return err
+		}
+		if *email == "" || password == "" {
+			return errors.New("the e-mail and the password are required")
+		}
+
+		session, err := a.api.Login(ctx, *email, password)
+		if err != nil {
+			return err
+		}
+		*a.config = config{
+			Server:    a.api.BaseURL,
+			Token:     session.Token,
+			ExpiresAt: &session.ExpiresAt,
+			UserID:    session.UserID,
+			Email:     session.Email,
+		}
+		if err := a.config.save(a.configPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "Logged in as %s until %s\n", session.Email, session.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		return nil
+	}
+}
+
+// defineLogout defines the logout command, which removes the token from the configuration file.
+// The token stays valid on the server until it expires.
+func defineLogout(_ *flag.FlagSet, a *app) func(context.Context, []string) error {
+	return func(_ context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		*a.config = config{Server: a.config.Server, Email: a.config.Email}
+		if err := a.config.save(a.configPath); err != nil {
+			return err
+		}
+		fmt.Fprintln(a.out, "Logged out")
+		return nil
+	}
+}
+
+// userID returns the ID of the user logged in.
+func (a *app) userID() (string, error) {
+	if a.config.UserID == "" {
+		return "", errors.New("not logged in, run \"nulltask login\"")
+	}
+	return a.config.UserID, nil
+}
+
+// readLine reads a line of the input, without the line break.
+func readLine(input *bufio.Reader) (string, error) {
+	line, err := input.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.New("unexpected end of the input")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readPassword reads the password from the terminal without echoing it,
+// or a line of the input when it isn't a terminal.
+func readPassword(in io.Reader, input *bufio.Reader) (string, error) {
+	if file, ok := in.(*os.File); ok && input.Buffered() == 0 && term.IsTerminal(int(file.Fd())) {
+		password, err := term.ReadPassword(int(file.Fd()))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(password)), nil
+	}
+	return readLine(input)
+}
@@ -0,0 +1,181 @@
+// Command nulltask is the command line client of the NullTask API.
+//
+// Usage:
+//
+//	nulltask [-server URL] [-token TOKEN] <command> [flags] [arguments]
+//
+// Run "nulltask login" first: the token it gets is kept in the configuration file of the user, along with the server.
+// The NULLTASK_SERVER and NULLTASK_TOKEN environment variables, and the -server and -token flags, take precedence over it.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/devgugga/NullTask/internal/client"
+)
+
+// app is what the commands share: the client of the API, the configuration it was built from and where they write.
+type app struct {
+	api        *client.Client
+	config     *config
+	configPath string
+	out        io.Writer
+	in         io.Reader
+}
+
+// command is a subcommand of nulltask. define registers its flags and returns the function running it
+// with the arguments left after them, so the flags can be listed without running it, as completions do.
+type command struct {
+	name    string
+	args    string
+	summary string
+	define  func(flags *flag.FlagSet, a *app) func(ctx context.Context, args []string) error
+}
+
+// errUsage is returned by commands called with the wrong arguments, for their usage to be printed.
+var errUsage = errors.New("invalid arguments")
+
+var commands []command
+
+func init() {
+	// Set up in init, since the completion command reads the list
+	commands = []command{
+		{"login", "", "Log in and keep the token in the configuration file", defineLogin},
+		{"logout", "", "Forget the token kept in the configuration file", defineLogout},
+		{"add", "<title>...", "Create a task", defineAdd},
+		{"list", "", "List your tasks", defineList},
+		{"search", "<text>...", "Search the text of your tasks", defineSearch},
+		{"done", "<id>...", "Complete tasks", defineDone},
+		{"edit", "<id>", "Change a task", defineEdit},
+		{"rm", "<id>...", "Delete tasks", defineRemove},
+		{"tui", "", "Browse and edit your tasks in a full-screen interface", defineTUI},
+		{"import", "<file>", "Import a file of tasks", defineImport},
+		{"export", "", "Export your tasks", defineExport},
+		{"completion", "bash|zsh|fish", "Print the shell completion script", defineCompletion},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs nulltask with the given arguments and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{out: stdout, in: stdin}
+	var err error
+	if a.configPath, err = configPath(); err == nil {
+		a.config, err = loadConfig(a.configPath)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "nulltask:", err)
+		return 1
+	}
+
+	flags := flag.NewFlagSet("nulltask", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	server := flags.String("server", firstOf(os.Getenv("NULLTASK_SERVER"), a.config.Server, client.DefaultServer), "address of the NullTask API")
+	token := flags.String("token", firstOf(os.Getenv("NULLTASK_TOKEN"), a.config.Token), "access token of the user")
+	flags.Usage = func() { usage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	a.api = client.New(*server, *token)
+
+	for _, cmd := range commands {
+		if cmd.name != flags.Arg(0) {
+			continue
+		}
+		cmdFlags := flag.NewFlagSet("nulltask "+cmd.name, flag.ContinueOnError)
+		cmdFlags.SetOutput(stderr)
+		cmdFlags.Usage = func() {
+			fmt.Fprintf(stderr, "Usage: nulltask %s [flags] %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+			if hasFlags(cmdFlags) {
+				fmt.Fprintln(stderr, "\nFlags:")
+				cmdFlags.PrintDefaults()
+			}
+		}
+		runCommand := cmd.define(cmdFlags, a)
+		cmdArgs, err := parseInterspersed(cmdFlags, flags.Args()[1:])
+		if err != nil {
+			return 2
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		switch err := runCommand(ctx, cmdArgs); {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage):
+			cmdFlags.Usage()
+			return 2
+		case client.IsStatus(err, 401):
+			fmt.Fprintln(stderr, "nulltask: not logged in, run \"nulltask login\"")
+			return 1
+		default:
+			fmt.Fprintln(stderr, "nulltask:", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(stderr, "nulltask: unknown command %q\n", flags.Arg(0))
+	flags.Usage()
+	return 2
+}
+
+// usage prints how to use nulltask and its commands.
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: nulltask [flags] <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nRun \"nulltask <command> -h\" for the flags of a command.")
+}
+
+// parseInterspersed parses the flags of a command wherever they are among its arguments, as in "nulltask edit 12 -due today",
+// and returns the arguments. Everything after "--" is an argument.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// hasFlags reports whether any flag is defined in the set.
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// firstOf returns the first of the values that isn't empty.
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
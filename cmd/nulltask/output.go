@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// Columns of the table of tasks.
+const (
+	columnID = iota
+	columnStatus
+	columnPriority
+	columnDue
+	columnTitle
+)
+
+// printJSON prints the value as indented JSON.
+func (a *app) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(a.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printTasks prints the tasks as JSON, or as a table colored when the output is a terminal.
+func (a *app) printTasks(tasks []models.Tasks, asJSON bool) error {
+	if asJSON {
+		return a.printJSON(tasks)
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(a.out, "No tasks")
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([][]string, len(tasks))
+	for i, task := range tasks {
+		due := ""
+		if task.DueDate != nil {
+			due = formatDue(*task.DueDate)
+		}
+		rows[i] = []string{strconv.FormatUint(uint64(task.ID), 10), task.Status, priorityNames[clampPriority(task.Priority)], due, task.Title}
+	}
+
+	renderer := lipgloss.NewRenderer(a.out)
+	cell := renderer.NewStyle().Padding(0, 1)
+	header := cell.Bold(true)
+	faint := cell.Faint(true)
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderTop(false).BorderBottom(false).BorderLeft(false).BorderRight(false).BorderColumn(false).
+		BorderStyle(renderer.NewStyle().Faint(true)).
+		Headers("ID", "STATUS", "PRIORITY", "DUE", "TITLE").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == 0 {
+				return header
+			}
+			task := tasks[row-1]
+			if closed(task) && col != columnStatus {
+				return faint
+			}
+			switch col {
+			case columnID:
+				return faint
+			case columnStatus:
+				if color, ok := statusColors[task.Status]; ok {
+					return cell.Foreground(color)
+				}
+			case columnPriority:
+				return cell.Foreground(priorityColors[clampPriority(task.Priority)])
+			case columnDue:
+				if task.DueDate != nil && task.DueDate.Before(now) {
+					return cell.Foreground(lipgloss.Color("1")).Bold(true)
+				}
+			}
+			return cell
+		})
+	fmt.Fprintln(a.out, t.Render())
+	return nil
+}
+
+// statusColors are the ANSI colors of the statuses of the default workflow; other statuses aren't colored.
+var statusColors = map[string]lipgloss.TerminalColor{
+	models.TaskInProgress: lipgloss.Color("3"),
+	models.TaskDone:       lipgloss.Color("2"),
+	models.TaskCancelled:  lipgloss.Color("8"),
+}
+
+// priorityColors are the ANSI colors of the priorities, indexed by their level.
+var priorityColors = []lipgloss.TerminalColor{lipgloss.Color("8"), lipgloss.Color("4"), lipgloss.Color("3"), lipgloss.Color("1")}
+
+// clampPriority keeps the priority within the levels there are names for.
+func clampPriority(priority int) int {
+	return max(0, min(priority, len(priorityNames)-1))
+}
+
+// formatDue formats a due date in the local time zone, leaving out the time of those due at the end of the day.
+func formatDue(due time.Time) string {
+	due = due.Local()
+	if due.Hour() == 23 && due.Minute() == 59 {
+		return due.Format("2006-01-02")
+	}
+	return due.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/client"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// defineAdd defines the add command, which creates a task titled with its arguments.
+// With -quick the arguments are parsed by the API as in quick add, so "Pay rent tomorrow #finance !high" works.
+func defineAdd(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	quick := flags.Bool("quick", false, "parse the due date, labels and priority out of the title")
+	due := flags.String("due", "", "due date, as YYYY-MM-DD, YYYY-MM-DD HH:MM, today or tomorrow")
+	priority := flags.String("priority", "", "priority: none, low, medium or high")
+	description := flags.String("description", "", "description of the task")
+	parent := flags.Uint("parent", 0, "ID of the parent task")
+	asJSON := flags.Bool("json", false, "print the task as JSON")
+	return func(ctx context.Context, args []string) error {
+		title := strings.TrimSpace(strings.Join(args, " "))
+		if title == "" {
+			return errUsage
+		}
+
+		var task *models.Tasks
+		var err error
+		if *quick {
+			if task, err = a.api.QuickAdd(ctx, title); err != nil {
+				return err
+			}
+			fields, err := taskFields(flags, *due, *priority, *description, *parent)
+			if err != nil || len(fields) == 0 {
+				return a.printCreated(task, *asJSON, err)
+			}
+			task, err = a.api.UpdateTask(ctx, task.ID, fields)
+			return a.printCreated(task, *asJSON, err)
+		}
+
+		fields, err := taskFields(flags, *due, *priority, *description, *parent)
+		if err != nil {
+			return err
+		}
+		fields["title"] = title
+		task, err = a.api.CreateTask(ctx, fields)
+		return a.printCreated(task, *asJSON, err)
+	}
+}
+
+// printCreated prints the task add created.
+func (a *app) printCreated(task *models.Tasks, asJSON bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return a.printJSON(task)
+	}
+	fmt.Fprintf(a.out, "Created task %d: %s\n", task.ID, task.Title)
+	return nil
+}
+
+// defineList defines the list command, which prints the open tasks of the user, or all of them with -all.
+func defineList(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	filter := defineFilter(flags)
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		userID, err := a.userID()
+		if err != nil {
+			return err
+		}
+		taskFilter, err := filter.resolve(ctx, a.api)
+		if err != nil {
+			return err
+		}
+		tasks, err := a.api.Tasks(ctx, userID, taskFilter)
+		if err != nil {
+			return err
+		}
+		return a.printTasks(filter.apply(tasks), filter.json)
+	}
+}
+
+// defineSearch defines the search command, which prints the tasks whose text contains every word of its arguments.
+func defineSearch(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	filter := defineFilter(flags)
+	return func(ctx context.Context, args []string) error {
+		query := strings.TrimSpace(strings.Join(args, " "))
+		if query == "" {
+			return errUsage
+		}
+		userID, err := a.userID()
+		if err != nil {
+			return err
+		}
+		taskFilter, err := filter.resolve(ctx, a.api)
+		if err != nil {
+			return err
+		}
+		tasks, err := a.api.SearchTasks(ctx, userID, query, taskFilter)
+		if err != nil {
+			return err
+		}
+		return a.printTasks(filter.apply(tasks), filter.json)
+	}
+}
+
+// listFilter holds the flags list and search share.
+type listFilter struct {
+	all    bool
+	status string
+	labels string
+	any    bool
+	json   bool
+}
+
+func defineFilter(flags *flag.FlagSet) *listFilter {
+	f := &listFilter{}
+	flags.BoolVar(&f.all, "all", false, "include the done and cancelled tasks")
+	flags.StringVar(&f.status, "status", "", "only the tasks with the status: pending, in_progress, done or cancelled")
+	flags.StringVar(&f.labels, "labels", "", "only the tasks with all of the comma-separated labels")
+	flags.BoolVar(&f.any, "any", false, "with -labels, the tasks with any of the labels")
+	flags.BoolVar(&f.json, "json", false, "print the tasks as JSON")
+	return f
+}
+
+// resolve returns the part of the filter the API applies, looking up the labels by name among the user's.
+func (f *listFilter) resolve(ctx context.Context, api *client.Client) (client.TaskFilter, error) {
+	filter := client.TaskFilter{MatchAny: f.any}
+	if strings.TrimSpace(f.labels) == "" {
+		return filter, nil
+	}
+	labels, err := api.Labels(ctx)
+	if err != nil {
+		return filter, err
+	}
+	for _, name := range strings.Split(f.labels, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		found := false
+		for _, label := range labels {
+			if strings.EqualFold(label.Name, name) {
+				filter.LabelIDs = append(filter.LabelIDs, label.ID)
+				found = true
+			}
+		}
+		if !found {
+			return filter, fmt.Errorf("no label named %q", name)
+		}
+	}
+	return filter, nil
+}
+
+// apply keeps the tasks with the status asked for, or the open ones unless -all is given.
+func (f *listFilter) apply(tasks []models.Tasks) []models.Tasks {
+	kept := tasks[:0]
+	for _, task := range tasks {
+		switch {
+		case f.status != "":
+			if task.Status != f.status {
+				continue
+			}
+		case !f.all && closed(task):
+			continue
+		}
+		kept = append(kept, task)
+	}
+	return kept
+}
+
+// closed reports whether the task is in a terminal status of its workflow, such as done or cancelled,
+// which is when the API sets when it was completed.
+func closed(task models.Tasks) bool {
+	return task.CompletedAt != nil
+}
+
+// defineDone defines the done command, which moves tasks to the done status.
+func defineDone(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	asJSON := flags.Bool("json", false, "print the tasks as JSON")
+	return func(ctx context.Context, args []string) error {
+		ids, err := taskIDs(args)
+		if err != nil {
+			return err
+		}
+		tasks := make([]models.Tasks, 0, len(ids))
+		for _, id := range ids {
+			task, err := a.api.UpdateTask(ctx, id, map[string]interface{}{"status": models.TaskDone})
+			if err != nil {
+				return fmt.Errorf("task %d: %w", id, err)
+			}
+			if !*asJSON {
+				fmt.Fprintf(a.out, "Completed task %d: %s\n", task.ID, task.Title)
+			}
+			tasks = append(tasks, *task)
+		}
+		if *asJSON {
+			return a.printJSON(tasks)
+		}
+		return nil
+	}
+}
+
+// defineEdit defines the edit command, which changes only the fields of a task given as flags.
+func defineEdit(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	title := flags.String("title", "", "new title")
+	due := flags.String("due", "", "due date, as YYYY-MM-DD, YYYY-MM-DD HH:MM, today or tomorrow, or none to remove it")
+	priority := flags.String("priority", "", "priority: none, low, medium or high")
+	description := flags.String("description", "", "description of the task")
+	parent := flags.Uint("parent", 0, "ID of the parent task, or 0 to make it a top-level task")
+	status := flags.String("status", "", "status: pending, in_progress, done or cancelled")
+	asJSON := flags.Bool("json", false, "print the task as JSON")
+	return func(ctx context.Context, args []string) error {
+		ids, err := taskIDs(args)
+		if err != nil || len(ids) != 1 {
+			return errUsage
+		}
+		fields, err := taskFields(flags, *due, *priority, *description, *parent)
+		if err != nil {
+			return err
+		}
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "title":
+				fields["title"] = *title
+			case "status":
+				fields["status"] = *status
+			}
+		})
+		if len(fields) == 0 {
+			return errors.New("nothing to change, see \"nulltask edit -h\"")
+		}
+
+		task, err := a.api.UpdateTask(ctx, ids[0], fields)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return a.printJSON(task)
+		}
+		fmt.Fprintf(a.out, "Updated task %d: %s\n", task.ID, task.Title)
+		return nil
+	}
+}
+
+// defineRemove defines the rm command, which deletes tasks.
+func defineRemove(_ *flag.FlagSet, a *app) func(context.Context, []string) error {
+	return func(ctx context.Context, args []string) error {
+		ids, err := taskIDs(args)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := a.api.DeleteTask(ctx, id); err != nil {
+				return fmt.Errorf("task %d: %w", id, err)
+			}
+			fmt.Fprintf(a.out, "Deleted task %d\n", id)
+		}
+		return nil
+	}
+}
+
+// taskIDs parses the IDs of tasks given as arguments, of which there must be at least one.
+func taskIDs(args []string) ([]uint, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	ids := make([]uint, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseUint(strings.TrimPrefix(arg, "#"), 10, 0)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid task ID %q", arg)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// taskFields returns the fields of a task set by the flags add and edit share, keyed as in the JSON of models.Tasks.
+// Only the flags given on the command line are included.
+func taskFields(flags *flag.FlagSet, due, priority, description string, parent uint) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch f.Name {
+		case "due":
+			if strings.EqualFold(due, "none") {
+				fields["due_date"] = nil
+				return
+			}
+			var at time.Time
+			if at, err = parseDue(due, time.Now()); err == nil {
+				fields["due_date"] = at
+			}
+		case "priority":
+			var level int
+			if level, err = parsePriority(priority); err == nil {
+				fields["priority"] = level
+			}
+		case "description":
+			fields["description"] = description
+		case "parent":
+			if parent == 0 {
+				fields["parent_id"] = nil
+			} else {
+				fields["parent_id"] = parent
+			}
+		}
+	})
+	return fields, err
+}
+
+// parseDue parses a due date given on the command line, in the local time zone.
+// A day without a time is due at its end, 23:59, as in the rest of NullTask.
+func parseDue(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, time.Local)
+	switch strings.ToLower(value) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if at, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return at, nil
+		}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return day.Add(23*time.Hour + 59*time.Minute), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q, expected YYYY-MM-DD, YYYY-MM-DD HH:MM, today or tomorrow", value)
+}
+
+// priorityNames are the names of the priorities, indexed by their level.
+var priorityNames = []string{"none", "low", "medium", "high"}
+
+// parsePriority parses a priority given by name or by level.
+func parsePriority(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for level, name := range priorityNames {
+		if value == name || value == strconv.Itoa(level) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid priority %q, expected none, low, medium or high", value)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/client"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// mappingFlag collects the field=column pairs of repeated -map flags.
+type mappingFlag map[string]string
+
+func (m mappingFlag) String() string {
+	pairs := make([]string, 0, len(m))
+	for field, column := range m {
+		pairs = append(pairs, field+"="+column)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m mappingFlag) Set(value string) error {
+	field, column, ok := strings.Cut(value, "=")
+	if !ok || field == "" || column == "" {
+		return errors.New("expected field=column")
+	}
+	m[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	return nil
+}
+
+// defineImport defines the import command, which uploads a file of tasks and, unless -no-wait is given,
+// waits for the import to finish and prints its outcome.
+func defineImport(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	format := flags.String("format", "", "format of the file: nulltask, csv, todoist, trello, todotxt or markdown (default: from the file extension)")
+	mapping := mappingFlag{}
+	flags.Var(mapping, "map", "map a field of the tasks onto a CSV column, as field=column (repeatable)")
+	dryRun := flags.Bool("dry-run", false, "only report what the import would do")
+	noWait := flags.Bool("no-wait", false, "return as soon as the file is uploaded")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		return a.runImport(ctx, args[0], *format, mapping, *dryRun, *noWait)
+	}
+}
+
+// runImport uploads the file and waits for the import, as defineImport describes.
+func (a *app) runImport(ctx context.Context, path, format string, mapping mappingFlag, dryRun, noWait bool) error {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = "csv"
+		case ".json":
+			format = "nulltask"
+		case ".txt":
+			format = "todotxt"
+		case ".md", ".markdown":
+			format = "markdown"
+		default:
+			return errors.New("can't tell the format of the file, use -format")
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	job, err := a.api.Import(ctx, client.ImportOptions{Format: format, Mapping: mapping, DryRun: dryRun}, filepath.Base(path), file)
+	if err != nil {
+		return err
+	}
+	if noWait {
+		fmt.Fprintf(a.out, "Import %d queued\n", job.ID)
+		return nil
+	}
+	if job, err = a.api.WaitImport(ctx, job.ID, time.Second); err != nil {
+		return err
+	}
+	a.printImport(job)
+	if job.Status == models.ImportFailed {
+		return errors.New(job.Error)
+	}
+	return nil
+}
+
+// printImport prints the counts of an import job and the rows that failed or were skipped.
+func (a *app) printImport(job *models.ImportJob) {
+	verb := "Imported"
+	if job.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(a.out, "%s %d rows: %d created, %d updated, %d skipped, %d failed\n", verb, job.Total, job.Created, job.Updated, job.Skipped, job.Failed)
+	for _, row := range job.Rows {
+		switch {
+		case row.Error != "":
+			fmt.Fprintf(a.out, "  row %d (%s): %s\n", row.Row, row.Ref, row.Error)
+		case row.Note != "":
+			fmt.Fprintf(a.out, "  row %d (%s): %s\n", row.Row, row.Ref, row.Note)
+		}
+	}
+}
+
+// defineExport defines the export command, which writes the personal tasks of the user to a file, or to the standard output.
+func defineExport(flags *flag.FlagSet, a *app) func(context.Context, []string) error {
+	format := flags.String("format", "nulltask", "format of the file: nulltask, csv, todotxt or markdown")
+	output := flags.String("o", "", "file to write, instead of the standard output")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		if *output == "" {
+			return a.api.Export(ctx, *format, a.out)
+		}
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		if err := a.api.Export(ctx, *format, file); err != nil {
+			file.Close()
+			os.Remove(*output)
+			return err
+		}
+		return file.Close()
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/devgugga/NullTask/internal/tui"
+)
+
+// defineTUI defines the tui command, which browses and edits the tasks in a full-screen interface
+// kept up to date by the event stream of the API.
+func defineTUI(_ *flag.FlagSet, a *app) func(context.Context, []string) error {
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		userID, err := a.userID()
+		if err != nil {
+			return err
+		}
+		return tui.Run(ctx, tui.ClientSource(a.api, userID))
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/config"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/mail"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/router"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+	"github.com/devgugga/NullTask/internal/transfer"
+)
+
+// defineServe defines the serve command, which migrates the database, starts the background jobs
+// and serves the API until interrupted.
+func defineServe(flags *flag.FlagSet, e *env) func(context.Context, []string) error {
+	port := flags.String("port", "1323", "port the API listens on")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		logger := e.log
+
+		// Connecting to the database selected by the DB_* environment variables.
+		db, err := e.connect()
+		if err != nil {
+			return err
+		}
+
+		// Migrating the models to the database using the config package's Migrate function.
+		// A failed migration is logged, and the server starts anyway with the tables it has.
+		if err := config.Migrate(db); err != nil {
+			logger.Error("Failed to creating tables in the database", "error", err)
+		}
+
+		// Without JWT_SECRET a random secret is generated, which invalidates every token when the server restarts.
+		secret := e.settings.jwtSecret
+		if secret == "" {
+			logger.Warn("JWT_SECRET is not set, using a random secret")
+			random := make([]byte, 32)
+			if _, err := rand.Read(random); err != nil {
+				return fmt.Errorf("generating the JWT secret: %w", err)
+			}
+			secret = hex.EncodeToString(random)
+		}
+
+		storageConfig := &e.settings.storage
+		blobs, err := config.BlobStore(ctx, storageConfig)
+		if err != nil {
+			return fmt.Errorf("connecting to the attachment storage: %w", err)
+		}
+
+		// Starting the background job generating the thumbnails of image attachments, at the THUMBNAIL_SIZES sizes.
+		thumbs := thumbnails.NewWorker(repository.NewGormAttachmentRepository(db), blobs, logger, storageConfig.ThumbnailSizes)
+		thumbs.Start(ctx, 2)
+
+		// Starting the background job importing the files of tasks uploaded to the import routes,
+		// which also resumes the imports a previous run left unfinished.
+		importer := transfer.NewImporter(repository.NewGormImportRepository(db), repository.NewGormTaskRepository(db),
+			repository.NewGormLabelRepository(db), repository.NewGormUserRepository(db), logger)
+		importer.Start(ctx, 1)
+
+		// Creating the bus the task events are published to.
+		// Until a notification channel subscribes to it, the events are only logged and streamed to the clients following them.
+		bus := events.NewBus()
+		bus.Subscribe(events.LogSubscriber(logger))
+		stream := events.NewStream()
+		bus.Subscribe(stream.Subscriber())
+
+		// The e-mails, such as the workspace invitations, are written to the log until an e-mail provider is supported.
+		mailer := mail.LogMailer(logger)
+
+		// Starting the server using the config package's StartServer function, until interrupted.
+		// It takes the port number and the dependencies shared by the routes as parameters.
+		config.StartServer(ctx, *port, &router.Dependencies{
+			DB:              db,
+			Log:             logger,
+			Tokens:          auth.NewTokenManager(secret, e.settings.tokenTTL),
+			Events:          bus,
+			Stream:          stream,
+			Mailer:          mailer,
+			Blobs:           blobs,
+			AttachmentQuota: storageConfig.Quota,
+			Thumbnails:      thumbs,
+			Importer:        importer,
+		})
+		return nil
+	}
+}
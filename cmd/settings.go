@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/config"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+)
+
+// defaultTokenTTL is how long the access tokens last when JWT_TTL isn't set.
+const defaultTokenTTL = 24 * time.Hour
+
+// settings is the configuration of the server, read from the environment by loadSettings.
+type settings struct {
+	db      config.DBConfig
+	logger  config.LoggerConfig
+	storage config.StorageConfig
+
+	// jwtSecret signs the access tokens. When empty, the server generates a random one on every start.
+	jwtSecret string
+	tokenTTL  time.Duration
+}
+
+// loadSettings reads the settings from the environment variables, failing on the values it can't parse.
+func loadSettings() (*settings, error) {
+	// Getting the environment variables DB_DRIVER, DB_PATH, HOST, PORT, USER, PASSWORD, DBNAME using os.Getenv function.
+	// DB_DRIVER selects "postgres" (the default) or "sqlite", in which case only DB_PATH is used.
+	s := &settings{
+		db: config.DBConfig{
+			Driver:   os.Getenv("DB_DRIVER"),
+			Path:     os.Getenv("DB_PATH"),
+			Host:     os.Getenv("HOST"),
+			Port:     os.Getenv("PORT"),
+			User:     os.Getenv("USER"),
+			Password: os.Getenv("PASSWORD"),
+			DBName:   os.Getenv("DBNAME"),
+		},
+		logger:    *config.LoggerConfigFromEnv(),
+		jwtSecret: os.Getenv("JWT_SECRET"),
+		tokenTTL:  defaultTokenTTL,
+	}
+
+	// Getting the JWT_TTL environment variable, how long the access tokens last
+	if ttl := os.Getenv("JWT_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_TTL: %w", err)
+		}
+		s.tokenTTL = parsed
+	}
+
+	// Getting the STORAGE_* and S3_* environment variables, which select where the attachments are kept,
+	// and ATTACHMENT_QUOTA, how many bytes of attachments each user can upload.
+	storage, err := config.StorageConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("configuring the attachment storage: %w", err)
+	}
+	s.storage = *storage
+
+	return s, nil
+}
+
+// definePrintConfig defines the print-config command, which prints the settings in the format of the .env file,
+// with the defaults filled in and the secrets hidden.
+func definePrintConfig(flags *flag.FlagSet, e *env) func(context.Context, []string) error {
+	showSecrets := flags.Bool("show-secrets", false, "print the passwords and keys rather than hiding them")
+	return func(_ context.Context, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+		s := e.settings
+		secret := func(value string) string {
+			if value == "" || *showSecrets {
+				return value
+			}
+			return "********"
+		}
+		line := func(name, value string) {
+			fmt.Fprintf(e.out, "%s=%s\n", name, value)
+		}
+
+		driver := firstOf(s.db.Driver, config.DriverPostgres)
+		line("DB_DRIVER", driver)
+		if driver == config.DriverSQLite {
+			line("DB_PATH", firstOf(s.db.Path, config.DefaultSQLitePath))
+		} else {
+			line("HOST", s.db.Host)
+			line("PORT", s.db.Port)
+			line("USER", s.db.User)
+			line("PASSWORD", secret(s.db.Password))
+			line("DBNAME", s.db.DBName)
+		}
+
+		line("LOG_FORMAT", firstOf(strings.ToLower(s.logger.Format), "text"))
+		line("LOG_LEVEL", firstOf(strings.ToLower(s.logger.Level), "info"))
+		line("LOG_OUTPUT", firstOf(s.logger.Output, "stderr"))
+
+		if s.jwtSecret == "" {
+			fmt.Fprintln(e.out, "# JWT_SECRET isn't set: the server signs the tokens with a random secret, which changes on every start")
+		}
+		line("JWT_SECRET", secret(s.jwtSecret))
+		line("JWT_TTL", s.tokenTTL.String())
+
+		storage := firstOf(s.storage.Driver, config.StorageLocal)
+		line("STORAGE_DRIVER", storage)
+		if storage == config.StorageS3 {
+			line("S3_ENDPOINT", s.storage.S3.Endpoint)
+			line("S3_ACCESS_KEY", s.storage.S3.AccessKey)
+			line("S3_SECRET_KEY", secret(s.storage.S3.SecretKey))
+			line("S3_BUCKET", s.storage.S3.Bucket)
+			line("S3_REGION", s.storage.S3.Region)
+			line("S3_USE_SSL", strconv.FormatBool(s.storage.S3.UseSSL))
+		} else {
+			line("STORAGE_PATH", firstOf(s.storage.Path, config.DefaultStoragePath))
+		}
+		line("ATTACHMENT_QUOTA", strconv.FormatInt(s.storage.Quota, 10))
+
+		sizes := s.storage.ThumbnailSizes
+		if len(sizes) == 0 {
+			sizes = thumbnails.DefaultSizes
+		}
+		formatted := make([]string, len(sizes))
+		for i, size := range sizes {
+			formatted[i] = strconv.Itoa(size)
+		}
+		line("THUMBNAIL_SIZES", strings.Join(formatted, ","))
+		return nil
+	}
+}
+
+// firstOf returns the first of the values that isn't empty.
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
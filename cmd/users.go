@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is the length of the shortest password the API accepts.
+const minPasswordLength = 6
+
+// minAge is the youngest age the API accepts for a user.
+const minAge = 18
+
+// defineCreateAdmin defines the create-admin command, which creates an admin user with a password
+// read from the standard input or, by default, generated and printed.
+func defineCreateAdmin(flags *flag.FlagSet, e *env) func(context.Context, []string) error {
+	name := flags.String("name", "", "name of the admin (required)")
+	email := flags.String("email", "", "e-mail the admin logs in with (required)")
+	age := flags.Uint("age", minAge, "age of the admin")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the standard input rather than generating one")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 0 || *name == "" || *email == "" {
+			return errUsage
+		}
+		if err := validator.New().Var(*email, "email"); err != nil {
+			return fmt.Errorf("invalid e-mail %q", *email)
+		}
+		if *age < minAge || *age > math.MaxUint8 {
+			return fmt.Errorf("the age must be between %d and %d", minAge, math.MaxUint8)
+		}
+		password, generated, err := newPassword(e.in, *passwordStdin)
+		if err != nil {
+			return err
+		}
+
+		users, err := e.users()
+		if err != nil {
+			return err
+		}
+		if _, err := users.FindByEmail(ctx, *email); err == nil {
+			return fmt.Errorf("the e-mail %s is already registered", *email)
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		user := &models.User{Name: *name, Email: *email, Age: uint8(*age), Admin: true}
+		if user.Password, err = hashPassword(password); err != nil {
+			return err
+		}
+		if err := users.Create(ctx, user); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return fmt.Errorf("the e-mail %s is already registered", *email)
+			}
+			return err
+		}
+
+		fmt.Fprintf(e.out, "Created the admin %s with the ID %s\n", user.Email, user.ID)
+		if generated {
+			fmt.Fprintf(e.out, "Password: %s\n", password)
+		}
+		return nil
+	}
+}
+
+// defineResetPassword defines the reset-password command, which replaces the password of a user with one
+// read from the standard input or, by default, generated and printed.
+// The tokens the user already holds stay valid until they expire.
+func defineResetPassword(flags *flag.FlagSet, e *env) func(context.Context, []string) error {
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the standard input rather than generating one")
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		password, generated, err := newPassword(e.in, *passwordStdin)
+		if err != nil {
+			return err
+		}
+
+		users, user, err := e.findUser(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if user.Password, err = hashPassword(password); err != nil {
+			return err
+		}
+		if err := users.Update(ctx, user); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(e.out, "Reset the password of %s\n", user.Email)
+		if generated {
+			fmt.Fprintf(e.out, "Password: %s\n", password)
+		}
+		return nil
+	}
+}
+
+// defineDeactivateUser defines the deactivate-user command, which stops a user from logging in and from using
+// the tokens they hold, while keeping their data.
+func defineDeactivateUser(_ *flag.FlagSet, e *env) func(context.Context, []string) error {
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		users, user, err := e.findUser(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if user.DeactivatedAt != nil {
+			return fmt.Errorf("%s is already deactivated, since %s", user.Email, user.DeactivatedAt.Local().Format("2006-01-02 15:04"))
+		}
+
+		now := time.Now()
+		user.DeactivatedAt = &now
+		if err := users.Update(ctx, user); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.out, "Deactivated %s\n", user.Email)
+		return nil
+	}
+}
+
+// defineReactivateUser defines the reactivate-user command, which undoes deactivate-user.
+func defineReactivateUser(_ *flag.FlagSet, e *env) func(context.Context, []string) error {
+	return func(ctx context.Context, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		users, user, err := e.findUser(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if user.DeactivatedAt == nil {
+			return fmt.Errorf("%s isn't deactivated", user.Email)
+		}
+
+		user.DeactivatedAt = nil
+		if err := users.Update(ctx, user); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.out, "Reactivated %s\n", user.Email)
+		return nil
+	}
+}
+
+// users returns the repository of the users of the database.
+func (e *env) users() (repository.UserRepository, error) {
+	db, err := e.connect()
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewGormUserRepository(db), nil
+}
+
+// findUser returns the repository of the users and the user with the given e-mail or, without an @, ID.
+func (e *env) findUser(ctx context.Context, emailOrID string) (repository.UserRepository, *models.User, error) {
+	users, err := e.users()
+	if err != nil {
+		return nil, nil, err
+	}
+	var user *models.User
+	if strings.Contains(emailOrID, "@") {
+		user, err = users.FindByEmail(ctx, emailOrID)
+	} else {
+		user, err = users.FindByID(ctx, emailOrID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, fmt.Errorf("no user %s", emailOrID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, user, nil
+}
+
+// newPassword returns the password read from the first line of the input when fromInput is set,
+// or a random one otherwise, along with whether it was generated.
+func newPassword(in io.Reader, fromInput bool) (string, bool, error) {
+	if !fromInput {
+		random := make([]byte, 12)
+		if _, err := rand.Read(random); err != nil {
+			return "", false, fmt.Errorf("generating the password: %w", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(random), true, nil
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", false, fmt.Errorf("reading the password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < minPasswordLength {
+		return "", false, fmt.Errorf("the password must have at least %d characters", minPasswordLength)
+	}
+	return password, false, nil
+}
+
+// hashPassword hashes the password the way the API does before storing it.
+func hashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hashing the password: %w", err)
+	}
+	return string(hashed), nil
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// NewOpaqueToken generates a random, URL-safe secret token, such as the ones in invitation links.
+// It returns the token, to be handed to the user once, and its hash, which is what should be stored.
+func NewOpaqueToken() (token, hash string, err error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(random)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 hash of an opaque token, used to look it up without storing it.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
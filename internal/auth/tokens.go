@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned when a token is malformed, expired or wasn't signed by this server.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenManager issues and verifies the HS256-signed JWTs that authenticate API requests.
+type TokenManager struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewTokenManager returns a TokenManager that signs tokens with the given secret.
+// Issued tokens expire after ttl.
+func NewTokenManager(secret string, ttl time.Duration) *TokenManager {
+	return &TokenManager{secret: []byte(secret), ttl: ttl}
+}
+
+// Issue creates a signed token identifying the given user.
+//
+// Parameters:
+// - userID: The ID of the authenticated user, stored in the token's subject.
+//
+// Returns:
+// - string: The signed token.
+// - time.Time: The moment the token expires.
+// - error: An error if the token couldn't be signed.
+func (m *TokenManager) Issue(userID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   userID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	})
+
+	signed, err := token.SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// Parse verifies the token's signature and expiration and returns the ID of the user it identifies.
+// Any verification failure is reported as ErrInvalidToken.
+func (m *TokenManager) Parse(token string) (string, error) {
+	claims := new(jwt.RegisteredClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Subject == "" {
+		return "", ErrInvalidToken
+	}
+	return claims.Subject, nil
+}
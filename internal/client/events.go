@@ -0,0 +1,64 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/devgugga/NullTask/internal/events"
+)
+
+// EventStream follows the events of the user logged in, as they happen.
+type EventStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Subscribe connects to the event stream of the user logged in. It returns once connected,
+// so whatever is fetched afterwards is up to date with the events Next returns.
+func (c *Client) Subscribe(ctx context.Context) (*EventStream, error) {
+	// The stream lasts for as long as the client follows it, beyond any timeout of the other requests
+	streaming := *c
+	streaming.HTTP = &http.Client{Transport: c.HTTP.Transport, Jar: c.HTTP.Jar}
+	res, err := streaming.send(ctx, http.MethodGet, "/events/stream", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &EventStream{body: res.Body, scanner: bufio.NewScanner(res.Body)}, nil
+}
+
+// Next waits for the next event. It returns io.EOF when the server ends the stream, which happens
+// when the client falls behind: fetch what was missed and subscribe again.
+func (s *EventStream) Next() (events.Event, error) {
+	var data strings.Builder
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var event events.Event
+			err := json.Unmarshal([]byte(data.String()), &event)
+			return event, err
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Comments, such as heartbeats, and the event names, repeated in the data, are skipped
+	}
+	if err := s.scanner.Err(); err != nil {
+		return events.Event{}, err
+	}
+	return events.Event{}, io.EOF
+}
+
+// Close disconnects from the stream.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
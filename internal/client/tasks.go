@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// Session is what Login returns: the bearer token of the user, when it expires, and who the user is.
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+}
+
+// Login exchanges the e-mail and password of a user for a bearer token, which the Client uses from then on.
+func (c *Client) Login(ctx context.Context, email, password string) (*Session, error) {
+	session := &Session{Email: email}
+	body := map[string]string{"email": email, "password": password}
+	if err := c.doJSON(ctx, http.MethodPost, "/users/login", body, session); err != nil {
+		return nil, err
+	}
+	c.Token = session.Token
+
+	var user models.User
+	if err := c.do(ctx, http.MethodGet, "/users/get/email/"+url.PathEscape(email), "", nil, &user); err != nil {
+		return nil, err
+	}
+	session.UserID = user.ID
+	return session, nil
+}
+
+// TaskFilter narrows the tasks returned by Tasks and SearchTasks to the ones tagged with the labels
+// with the IDs in LabelIDs, with all of them, or any of them when MatchAny is set.
+type TaskFilter struct {
+	LabelIDs []uint
+	MatchAny bool
+}
+
+func (f TaskFilter) query(values url.Values) string {
+	if len(f.LabelIDs) > 0 {
+		ids := make([]string, len(f.LabelIDs))
+		for i, id := range f.LabelIDs {
+			ids[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		values.Set("labels", strings.Join(ids, ","))
+		if f.MatchAny {
+			values.Set("match", "any")
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// Tasks returns the tasks owned by the user.
+func (c *Client) Tasks(ctx context.Context, userID string, filter TaskFilter) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	path := "/tasks/get/user/" + url.PathEscape(userID) + filter.query(url.Values{})
+	if err := c.do(ctx, http.MethodGet, path, "", nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// SearchTasks returns the tasks owned by the user whose text contains every word of the query.
+func (c *Client) SearchTasks(ctx context.Context, userID, query string, filter TaskFilter) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	path := "/tasks/search/user/" + url.PathEscape(userID) + filter.query(url.Values{"q": {query}})
+	if err := c.do(ctx, http.MethodGet, path, "", nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Labels returns the personal labels of the user logged in.
+func (c *Client) Labels(ctx context.Context) ([]models.Label, error) {
+	var labels []models.Label
+	if err := c.do(ctx, http.MethodGet, "/labels/get/all/", "", nil, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// Task returns the task with the given ID.
+func (c *Client) Task(ctx context.Context, id uint) (*models.Tasks, error) {
+	var task models.Tasks
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", id), "", nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask creates a task with the given fields, named as in the JSON of models.Tasks, and returns it.
+func (c *Client) CreateTask(ctx context.Context, fields map[string]interface{}) (*models.Tasks, error) {
+	var task models.Tasks
+	if err := c.doJSON(ctx, http.MethodPost, "/tasks/create", fields, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// QuickAdd creates a task from a line of text such as "Pay rent tomorrow 9am #finance !high" and returns it.
+func (c *Client) QuickAdd(ctx context.Context, text string) (*models.Tasks, error) {
+	var res struct {
+		Task models.Tasks `json:"task"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/tasks/quick-add", map[string]string{"text": text}, &res); err != nil {
+		return nil, err
+	}
+	return &res.Task, nil
+}
+
+// UpdateTask changes the given fields of the task with the given ID, named as in the JSON of models.Tasks,
+// leaving the others as they are, and returns the task.
+func (c *Client) UpdateTask(ctx context.Context, id uint, fields map[string]interface{}) (*models.Tasks, error) {
+	var task models.Tasks
+	if err := c.doJSON(ctx, http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", id), fields, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Board returns the board of the personal tasks of the user logged in: a column per status, each with its tasks in order.
+func (c *Client) Board(ctx context.Context) ([]handlers.BoardColumn, error) {
+	var columns []handlers.BoardColumn
+	if err := c.do(ctx, http.MethodGet, "/tasks/get/board", "", nil, &columns); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// MoveTask moves the task with the given ID on its board, to another status and between other tasks, and returns it.
+func (c *Client) MoveTask(ctx context.Context, id uint, move handlers.MoveRequest) (*models.Tasks, error) {
+	var task models.Tasks
+	if err := c.doJSON(ctx, http.MethodPut, fmt.Sprintf("/tasks/move/id/%d", id), move, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask deletes the task with the given ID.
+func (c *Client) DeleteTask(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", id), "", nil, nil)
+}
+
+// doJSON sends a request with the body encoded as JSON and decodes the JSON response into out, unless it's nil.
+func (c *Client) doJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, path, "application/json", bytes.NewReader(encoded), out)
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// ImportOptions are the options of an import besides its file.
+type ImportOptions struct {
+	// Format is one of the formats of the transfer package.
+	Format string
+	// Mapping maps the fields of the tasks onto the columns of CSV files.
+	Mapping map[string]string
+	// DryRun only reports what the import would do.
+	DryRun bool
+}
+
+// Import uploads the file of tasks with the given name to be imported in the background and returns the pending job.
+func (c *Client) Import(ctx context.Context, options ImportOptions, name string, file io.Reader) (*models.ImportJob, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("format", options.Format)
+	if len(options.Mapping) > 0 {
+		mapping, err := json.Marshal(options.Mapping)
+		if err != nil {
+			return nil, err
+		}
+		form.WriteField("mapping", string(mapping))
+	}
+	form.WriteField("dry_run", strconv.FormatBool(options.DryRun))
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	var job models.ImportJob
+	if err := c.do(ctx, http.MethodPost, "/imports/create", form.FormDataContentType(), &body, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ImportJob returns the import job with the given ID.
+func (c *Client) ImportJob(ctx context.Context, id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/imports/get/id/%d", id), "", nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WaitImport polls the import job with the given ID, every interval, until it finishes or the context is done.
+func (c *Client) WaitImport(ctx context.Context, id uint, interval time.Duration) (*models.ImportJob, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := c.ImportJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Status == models.ImportCompleted || job.Status == models.ImportFailed {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Export writes the personal tasks of the user to w, in the nulltask or csv format.
+func (c *Client) Export(ctx context.Context, format string, w io.Writer) error {
+	res, err := c.send(ctx, http.MethodGet, "/exports/get/tasks?format="+url.QueryEscape(format), "", nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+	return err
+}
@@ -1,12 +1,16 @@
 package config
 
 import (
-	"github.com/charmbracelet/log"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
 	"github.com/devgugga/NullTask/internal/router"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"gorm.io/gorm"
 )
 
 // CustomValidator is a custom implementation of echo.Validator interface.
@@ -27,26 +31,23 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
 }
 
-// StartServer initializes and starts the API server.
+// NewServer builds the Echo application without starting it.
 // It sets up Echo framework, registers middlewares, sets up custom validator,
 // and calls SetupRoutes function to register routes.
 //
 // Parameters:
-// - port: The port number on which the server should listen.
-// - db: A pointer to the gorm.DB instance for database operations.
-// - log: A pointer to the log.Logger instance for logging.
+// - deps: The Dependencies shared by every route: database connection, logger and token manager.
 //
 // Return values:
-// - This function does not return any value.
-//
-// Errors:
-// - If there is an error starting the server, it will be logged and the program will exit.
-func StartServer(port string, db *gorm.DB, log *log.Logger) {
-
+// - *echo.Echo: The configured application, ready to be started or served through httptest.
+func NewServer(deps *router.Dependencies) *echo.Echo {
 	e := echo.New()
 
-	// Use Echo's built-in Logger middleware
-	e.Use(middleware.Logger())
+	// Hide Echo's startup banner, the logger below already reports what the server is doing
+	e.HideBanner = true
+
+	// Attach a per-request logger carrying the request ID, route and latency
+	e.Use(middlewares.RequestLogger(deps.Log))
 
 	// Use Echo's built-in Recover middleware to recover from panics
 	e.Use(middleware.Recover())
@@ -55,12 +56,50 @@ func StartServer(port string, db *gorm.DB, log *log.Logger) {
 	e.Validator = &CustomValidator{validator: validator.New()}
 
 	// Register routes using the SetupRoutes function from the router package
-	router.SetupRoutes(e, db, log)
+	router.SetupRoutes(e, deps)
+
+	return e
+}
+
+// ShutdownTimeout is how long StartServer waits for the requests in flight once asked to stop,
+// before closing their connections.
+const ShutdownTimeout = 10 * time.Second
+
+// StartServer initializes and starts the API server, until the context is done.
+// It builds the application with NewServer and listens on the given port.
+//
+// Parameters:
+// - ctx: Stops the server once done. The server stops accepting connections and waits up to ShutdownTimeout
+// for the requests in flight, such as event streams, before closing their connections.
+// - port: The port number on which the server should listen.
+// - deps: The Dependencies shared by every route: database connection, logger and token manager.
+//
+// Return values:
+// - This function does not return any value. It returns once the server stopped.
+//
+// Errors:
+// - If there is an error starting the server, it will be logged and the program will exit.
+func StartServer(ctx context.Context, port string, deps *router.Dependencies) {
+	e := NewServer(deps)
+
+	// Stop the server once the context is done, closing the connections still open after the timeout
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := e.Shutdown(shutdownCtx); err != nil {
+			deps.Log.Warn("Closing the connections still open", "error", err)
+			e.Close()
+		}
+	}()
 
 	// Start the server on the specified port
 	err := e.Start(":" + port)
-	if err != nil {
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		// Log the error and exit the program if there is an error starting the server
-		log.Fatal("Failed to start the api server", "error", err)
+		deps.Log.Fatal("Failed to start the api server", "error", err)
 	}
+	<-stopped
 }
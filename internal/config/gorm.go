@@ -3,20 +3,41 @@ package config
 import (
 	"fmt"
 
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/rank"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// DBConfig stores the configuration parameters for connecting to a PostgreSQL database.
+const (
+	// DriverPostgres selects the PostgreSQL backend, used in production.
+	DriverPostgres = "postgres"
+
+	// DriverSQLite selects the pure-Go SQLite backend, used for local development and tests.
+	DriverSQLite = "sqlite"
+
+	// DefaultSQLitePath is the SQLite database file used when DBConfig.Path is empty.
+	DefaultSQLitePath = "nulltask.db"
+)
+
+// DBConfig stores the configuration parameters for connecting to the database.
+//
+// Driver is either DriverPostgres (the default) or DriverSQLite.
+// Host, Port, User, Password and DBName are only used by PostgreSQL,
+// while Path is the SQLite database file, or ":memory:" for a throwaway database.
 type DBConfig struct {
+	Driver   string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+	Path     string
 }
 
-// ConnectDB establishes a connection to a PostgreSQL database using the provided configuration.
+// ConnectDB establishes a connection to the database selected by the provided configuration.
 // It returns the GORM database instance and an error if the connection fails.
 //
 // Parameters:
@@ -26,18 +47,186 @@ type DBConfig struct {
 // db: A pointer to a gorm.DB instance representing the established database connection.
 // err: An error object that will be nil if the connection is successful, otherwise it will contain the error details.
 //
-// Note: The function creates a connection string using the provided configuration parameters and opens the connection using gorm.Open.
-// It also executes a SQL command to create the "uuid-ossp" extension if it does not already exist in the database.
+// Note: For PostgreSQL the function creates a connection string using the provided configuration parameters
+// and executes a SQL command to create the "uuid-ossp" extension if it does not already exist in the database.
+// For SQLite it opens the file at conf.Path with foreign keys enforced and a busy timeout, since SQLite
+// ignores foreign keys by default and fails right away when another connection holds the write lock.
 func ConnectDB(conf *DBConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", conf.Host, conf.Port, conf.User, conf.Password, conf.DBName)
+	var dialector gorm.Dialector
+
+	switch conf.Driver {
+	case "", DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", conf.Host, conf.Port, conf.User, conf.Password, conf.DBName)
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		path := conf.Path
+		if path == "" {
+			path = DefaultSQLitePath
+		}
+		dialector = sqlite.Open(path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", conf.Driver)
+	}
 
 	// Open the database connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
 
-	db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"")
+	if db.Name() == DriverPostgres {
+		db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"")
+	}
+
+	if db.Name() == DriverSQLite {
+		// A SQLite database allows a single writer, so serialize the writes instead of failing with SQLITE_BUSY
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
 
 	return db, nil
 }
+
+// Migrate creates or updates the tables of every model and the dialect-specific objects on top of them.
+//
+// Parameters:
+// db: A pointer to a gorm.DB instance connected to PostgreSQL or SQLite.
+//
+// Returns:
+// err: An error object that will be nil if the migration is successful, otherwise it will contain the error details.
+//
+// Note: UUID primary keys are generated in Go by the models' BeforeCreate hooks, so they work the same way on both
+// databases: PostgreSQL stores them in native uuid columns and SQLite as text. The check constraints declared in the
+// model tags are portable SQL. Full-text search is the exception, see setupFullText.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&models.Sequence{},
+		&models.User{},
+		&models.Preferences{},
+		&models.CalendarFeed{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.WorkspaceInvitation{},
+		&models.Workflow{},
+		&models.Tasks{},
+		&models.TaskAssignee{},
+		&models.TaskWatcher{},
+		&models.ChecklistItem{},
+		&models.TaskDependency{},
+		&models.Label{},
+		&models.TaskLabel{},
+		&models.Comment{},
+		&models.CommentRevision{},
+		&models.CommentMention{},
+		&models.Attachment{},
+		&models.CalendarObject{},
+		&models.CalendarSync{},
+		&models.ImportJob{},
+		&models.ImportedTask{},
+	); err != nil {
+		return err
+	}
+
+	// Tasks finished before the workflows existed have no completion time, which is what now tells finished tasks apart
+	err := db.Model(&models.Tasks{}).
+		Where("status IN ? AND completed_at IS NULL", []string{models.TaskDone, models.TaskCancelled}).
+		UpdateColumn("completed_at", gorm.Expr("updated_at")).Error
+	if err != nil {
+		return err
+	}
+	if err := backfillRanks(db); err != nil {
+		return err
+	}
+
+	return setupFullText(db)
+}
+
+// backfillRanks places the tasks without a rank, created before the boards existed,
+// at the end of their board column in the order they were created.
+func backfillRanks(db *gorm.DB) error {
+	var tasks []models.Tasks
+	if err := db.Unscoped().Select("id", "user_id", "workspace_id", "status").Where("rank = ''").Order("id").Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	last := map[string]string{}
+	for _, task := range tasks {
+		column := db.Unscoped().Model(&models.Tasks{}).Where("status = ? AND rank <> ''", task.Status)
+		key := task.Status + "/" + task.UserID
+		if task.WorkspaceID != nil {
+			column = column.Where("workspace_id = ?", *task.WorkspaceID)
+			key = task.Status + "/" + *task.WorkspaceID
+		} else {
+			column = column.Where("user_id = ? AND workspace_id IS NULL", task.UserID)
+		}
+
+		previous, ok := last[key]
+		if !ok {
+			var ranks []string
+			if err := column.Order(repository.RankColumn(db)+" DESC").Limit(1).Pluck("rank", &ranks).Error; err != nil {
+				return err
+			}
+			if len(ranks) > 0 {
+				previous = ranks[0]
+			}
+		}
+
+		next, err := rank.Between(previous, "")
+		if err != nil {
+			return err
+		}
+		if err := db.Unscoped().Model(&models.Tasks{}).Where("id = ?", task.ID).UpdateColumn("rank", next).Error; err != nil {
+			return err
+		}
+		last[key] = next
+	}
+	return nil
+}
+
+// setupFullText creates the objects backing the task search.
+//
+// PostgreSQL gets a GIN index over the tsvector of the task's title, description and notes, which is the
+// same expression the repository searches with. SQLite gets an external-content FTS5 table kept in sync with
+// the tasks table by triggers.
+func setupFullText(db *gorm.DB) error {
+	switch db.Name() {
+	case DriverPostgres:
+		return db.Exec(`CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (` + repository.TaskSearchVector + `)`).Error
+	case DriverSQLite:
+		statements := []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(title, description, notes, content='tasks', content_rowid='id')`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN
+				INSERT INTO tasks_fts(rowid, title, description, notes) VALUES (new.id, new.title, new.description, new.notes);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON tasks BEGIN
+				INSERT INTO tasks_fts(tasks_fts, rowid, title, description, notes) VALUES ('delete', old.id, old.title, old.description, old.notes);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE ON tasks BEGIN
+				INSERT INTO tasks_fts(tasks_fts, rowid, title, description, notes) VALUES ('delete', old.id, old.title, old.description, old.notes);
+				INSERT INTO tasks_fts(rowid, title, description, notes) VALUES (new.id, new.title, new.description, new.notes);
+			END`,
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReindexSearch rebuilds the objects backing the task search from the tasks, for when they drifted apart from them,
+// such as after the tasks were edited straight in the database with the triggers disabled, or the index got corrupted.
+// The objects must exist already, which Migrate takes care of.
+func ReindexSearch(db *gorm.DB) error {
+	switch db.Name() {
+	case DriverPostgres:
+		return db.Exec(`REINDEX INDEX idx_tasks_search`).Error
+	case DriverSQLite:
+		return db.Exec(`INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild')`).Error
+	}
+	return nil
+}
@@ -1,24 +1,104 @@
 package config
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
 )
 
+// LoggerConfig stores the parameters used to build the application logger.
+//
+// Format selects the log encoding: "text" (the default, colorful output meant for terminals)
+// or "json" (one JSON object per line, meant for log aggregators).
+// Level is the minimum level that gets written: debug, info, warn, error or fatal.
+// Output is "stderr" (the default), "stdout" or a path to a file that logs are appended to.
+type LoggerConfig struct {
+	Format string
+	Level  string
+	Output string
+}
+
+// LoggerConfigFromEnv builds a LoggerConfig from the LOG_FORMAT, LOG_LEVEL and LOG_OUTPUT
+// environment variables. Missing variables fall back to text output, info level and stderr.
+func LoggerConfigFromEnv() *LoggerConfig {
+	return &LoggerConfig{
+		Format: os.Getenv("LOG_FORMAT"),
+		Level:  os.Getenv("LOG_LEVEL"),
+		Output: os.Getenv("LOG_OUTPUT"),
+	}
+}
+
 // Logger returns a configured instance of the Charmbracelet log.Logger.
-// This logger is set to write to os.Stderr, with additional settings to report
-// the caller's location and timestamp in the log messages.
-// The time format is set to time.Kitchen, and the prefix is set to "NullTask 🚫".
-func Logger() *log.Logger {
-	// Editing the log settings to make it easily to debug and understanding
-	logger := log.NewWithOptions(os.Stderr, log.Options{
-		ReportCaller:    true,
-		ReportTimestamp: true,
-		TimeFormat:      time.Kitchen,
-		Prefix:          "NullTask 🚫",
-	})
-
-	return logger
+//
+// Parameters:
+// - conf: A pointer to a LoggerConfig. A nil config produces the default text logger on stderr.
+//
+// Returns:
+// - *log.Logger: The configured logger.
+// - error: An error if the format or level are unknown or if the output file can't be opened.
+//
+// The text format reports the caller's location and a time.Kitchen timestamp with the "NullTask 🚫" prefix,
+// which makes it easy to read while developing. The JSON format reports RFC 3339 timestamps in UTC and
+// carries no prefix, so every line can be parsed by a log aggregator.
+func Logger(conf *LoggerConfig) (*log.Logger, error) {
+	if conf == nil {
+		conf = &LoggerConfig{}
+	}
+
+	level := log.InfoLevel
+	if conf.Level != "" {
+		parsed, err := log.ParseLevel(conf.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = parsed
+	}
+
+	out, err := loggerOutput(conf.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(conf.Format) {
+	case "", "text":
+		// Editing the log settings to make it easily to debug and understanding
+		return log.NewWithOptions(out, log.Options{
+			Level:           level,
+			ReportCaller:    true,
+			ReportTimestamp: true,
+			TimeFormat:      time.Kitchen,
+			Prefix:          "NullTask 🚫",
+		}), nil
+	case "json":
+		return log.NewWithOptions(out, log.Options{
+			Level:           level,
+			ReportCaller:    true,
+			ReportTimestamp: true,
+			TimeFormat:      time.RFC3339,
+			TimeFunction:    log.NowUTC,
+			Formatter:       log.JSONFormatter,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", conf.Format)
+	}
+}
+
+// loggerOutput resolves the LOG_OUTPUT value into the writer the logger should use.
+func loggerOutput(output string) (io.Writer, error) {
+	switch strings.ToLower(output) {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	default:
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening log output: %w", err)
+		}
+		return f, nil
+	}
 }
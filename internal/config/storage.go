@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/devgugga/NullTask/internal/storage"
+)
+
+const (
+	// StorageLocal keeps the attachments in a directory of the server.
+	StorageLocal = "local"
+	// StorageS3 keeps the attachments in an S3-compatible service, such as Amazon S3 or MinIO.
+	StorageS3 = "s3"
+
+	// DefaultStoragePath is the directory the local storage keeps the attachments in when StorageConfig.Path is empty.
+	DefaultStoragePath = "attachments"
+
+	// DefaultAttachmentQuota is how many bytes of attachments each user can upload, 100 MiB.
+	DefaultAttachmentQuota = 100 << 20
+)
+
+// StorageConfig stores the parameters used to build the BlobStore holding the attachments.
+//
+// Driver selects "local" (the default), which keeps the files under Path ("attachments" by default),
+// or "s3", which keeps them in the S3 bucket.
+// Quota is how many bytes of attachments each user can upload.
+// ThumbnailSizes are the sizes, in pixels, of the squares image thumbnails are fitted into.
+type StorageConfig struct {
+	Driver         string
+	Path           string
+	S3             storage.S3Config
+	Quota          int64
+	ThumbnailSizes []int
+}
+
+// StorageConfigFromEnv builds a StorageConfig from the STORAGE_DRIVER, STORAGE_PATH, S3_ENDPOINT, S3_ACCESS_KEY,
+// S3_SECRET_KEY, S3_BUCKET, S3_REGION, S3_USE_SSL, ATTACHMENT_QUOTA and THUMBNAIL_SIZES environment variables.
+// ATTACHMENT_QUOTA is a number of bytes, DefaultAttachmentQuota when missing, and THUMBNAIL_SIZES a comma
+// separated list of pixel sizes, thumbnails.DefaultSizes when missing.
+func StorageConfigFromEnv() (*StorageConfig, error) {
+	conf := &StorageConfig{
+		Driver: os.Getenv("STORAGE_DRIVER"),
+		Path:   os.Getenv("STORAGE_PATH"),
+		S3: storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+		},
+		Quota: DefaultAttachmentQuota,
+	}
+
+	if useSSL := os.Getenv("S3_USE_SSL"); useSSL != "" {
+		parsed, err := strconv.ParseBool(useSSL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3_USE_SSL: %w", err)
+		}
+		conf.S3.UseSSL = parsed
+	}
+
+	if quota := os.Getenv("ATTACHMENT_QUOTA"); quota != "" {
+		parsed, err := strconv.ParseInt(quota, 10, 64)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid ATTACHMENT_QUOTA %q", quota)
+		}
+		conf.Quota = parsed
+	}
+
+	for _, size := range strings.Split(os.Getenv("THUMBNAIL_SIZES"), ",") {
+		if size = strings.TrimSpace(size); size == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(size)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid THUMBNAIL_SIZES %q", os.Getenv("THUMBNAIL_SIZES"))
+		}
+		conf.ThumbnailSizes = append(conf.ThumbnailSizes, parsed)
+	}
+
+	return conf, nil
+}
+
+// BlobStore returns the BlobStore selected by the config.
+//
+// Parameters:
+// - ctx: Bounds the connection to the S3 service, when one is used.
+// - conf: A pointer to a StorageConfig.
+//
+// Returns:
+// - storage.BlobStore: The store for the attachments.
+// - error: An error if the driver is unknown, the directory can't be created or the bucket can't be reached.
+func BlobStore(ctx context.Context, conf *StorageConfig) (storage.BlobStore, error) {
+	switch conf.Driver {
+	case "", StorageLocal:
+		path := conf.Path
+		if path == "" {
+			path = DefaultStoragePath
+		}
+		return storage.NewLocalStore(path)
+	case StorageS3:
+		return storage.NewS3Store(ctx, conf.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", conf.Driver)
+	}
+}
@@ -0,0 +1,376 @@
+// Package dav reads the XML bodies of WebDAV (RFC 4918) and CalDAV (RFC 4791, RFC 6578) requests
+// and writes the multistatus responses they are answered with.
+package dav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The XML namespaces of the properties and reports.
+const (
+	// NamespaceDAV is the namespace of WebDAV.
+	NamespaceDAV = "DAV:"
+	// NamespaceCalDAV is the namespace of CalDAV.
+	NamespaceCalDAV = "urn:ietf:params:xml:ns:caldav"
+	// NamespaceCalendarServer is the namespace of the extensions of Apple's Calendar Server, such as getctag.
+	NamespaceCalendarServer = "http://calendarserver.org/ns/"
+)
+
+// prefixes are the prefixes the namespaces are written with.
+var prefixes = map[string]string{NamespaceDAV: "d", NamespaceCalDAV: "c", NamespaceCalendarServer: "cs"}
+
+// DAV returns the name of a WebDAV element.
+func DAV(local string) xml.Name {
+	return xml.Name{Space: NamespaceDAV, Local: local}
+}
+
+// CalDAV returns the name of a CalDAV element.
+func CalDAV(local string) xml.Name {
+	return xml.Name{Space: NamespaceCalDAV, Local: local}
+}
+
+// CalendarServer returns the name of an element of Apple's Calendar Server extensions.
+func CalendarServer(local string) xml.Name {
+	return xml.Name{Space: NamespaceCalendarServer, Local: local}
+}
+
+// ErrInvalidBody is returned for request bodies that aren't the XML expected.
+var ErrInvalidBody = errors.New("dav: invalid request body")
+
+// PropFind is the body of a PROPFIND request.
+type PropFind struct {
+	// AllProp asks for every property, which is also what an empty body asks for.
+	AllProp bool
+	// PropName asks for the names of the properties, without their values.
+	PropName bool
+	// Props are the properties asked for otherwise.
+	Props []xml.Name
+}
+
+// Report is the body of a REPORT request: a calendar-query, a calendar-multiget or a sync-collection.
+type Report struct {
+	// Name is the name of the report, such as CalDAV("calendar-query").
+	Name xml.Name
+	// AllProp asks for every property, and Props for the ones listed otherwise.
+	AllProp bool
+	Props   []xml.Name
+	// Hrefs are the resources asked for by a calendar-multiget.
+	Hrefs []string
+	// SyncToken is the token of a sync-collection, empty for the first synchronization.
+	SyncToken string
+	// Filter is the filter of a calendar-query, or nil when it has none.
+	Filter *CompFilter
+}
+
+// CompFilter matches the calendar components with the given name, as long as they match its own filters,
+// or the ones without such a component when IsNotDefined is set.
+type CompFilter struct {
+	Name         string
+	IsNotDefined bool
+	// Start and End limit, when not nil, the time range the component must overlap.
+	Start, End *time.Time
+	Comps      []CompFilter
+	Props      []PropFilter
+}
+
+// PropFilter matches the components that have the property with the given name, or that don't when IsNotDefined is set.
+type PropFilter struct {
+	Name         string
+	IsNotDefined bool
+}
+
+// Component is what filters are evaluated against: a calendar component, such as a VTODO.
+type Component interface {
+	// Has reports whether the component has the property, or the subcomponent, with the given name.
+	Has(name string) bool
+	// Overlaps reports whether the component overlaps the time range, whose bounds are nil when open.
+	Overlaps(start, end *time.Time) bool
+}
+
+// Matches reports whether a calendar, holding the component with the given name, passes the filter.
+// The filter must be for VCALENDAR, and its component filters are checked against the component.
+func (f *CompFilter) Matches(name string, component Component) bool {
+	if !strings.EqualFold(f.Name, "VCALENDAR") {
+		return f.IsNotDefined
+	}
+	if f.IsNotDefined {
+		return false
+	}
+	for _, comp := range f.Comps {
+		if !comp.matches(name, component) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches checks the component against a filter nested in the filter of the calendar.
+func (f *CompFilter) matches(name string, component Component) bool {
+	if !strings.EqualFold(f.Name, name) {
+		return f.IsNotDefined
+	}
+	if f.IsNotDefined {
+		return false
+	}
+	if (f.Start != nil || f.End != nil) && !component.Overlaps(f.Start, f.End) {
+		return false
+	}
+	for _, prop := range f.Props {
+		if component.Has(strings.ToUpper(prop.Name)) == prop.IsNotDefined {
+			return false
+		}
+	}
+	for _, comp := range f.Comps {
+		if component.Has(strings.ToUpper(comp.Name)) == comp.IsNotDefined {
+			return false
+		}
+	}
+	return true
+}
+
+// propNames collects the names of the children of an element, such as the properties of a DAV:prop.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// marker is an empty element whose presence is what matters, such as DAV:allprop.
+type marker struct{}
+
+type propfindBody struct {
+	XMLName  xml.Name
+	AllProp  *marker   `xml:"DAV: allprop"`
+	PropName *marker   `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+type reportBody struct {
+	XMLName   xml.Name
+	AllProp   *marker   `xml:"DAV: allprop"`
+	Prop      propNames `xml:"DAV: prop"`
+	Hrefs     []string  `xml:"DAV: href"`
+	SyncToken string    `xml:"DAV: sync-token"`
+	Filter    *struct {
+		Comp *compFilterBody `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type compFilterBody struct {
+	Name         string  `xml:"name,attr"`
+	IsNotDefined *marker `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *struct {
+		Start string `xml:"start,attr"`
+		End   string `xml:"end,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Comps []compFilterBody `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Props []struct {
+		Name         string  `xml:"name,attr"`
+		IsNotDefined *marker `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	} `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// ParsePropFind reads the body of a PROPFIND request.
+func ParsePropFind(r io.Reader) (*PropFind, error) {
+	var body propfindBody
+	if err := decode(r, &body); err != nil {
+		if errors.Is(err, io.EOF) {
+			return &PropFind{AllProp: true}, nil
+		}
+		return nil, err
+	}
+	if body.XMLName != DAV("propfind") {
+		return nil, ErrInvalidBody
+	}
+	return &PropFind{AllProp: body.AllProp != nil, PropName: body.PropName != nil, Props: body.Prop}, nil
+}
+
+// ParseReport reads the body of a REPORT request.
+func ParseReport(r io.Reader) (*Report, error) {
+	var body reportBody
+	if err := decode(r, &body); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidBody
+		}
+		return nil, err
+	}
+
+	report := &Report{Name: body.XMLName, AllProp: body.AllProp != nil, Props: body.Prop, Hrefs: body.Hrefs, SyncToken: strings.TrimSpace(body.SyncToken)}
+	for i, href := range report.Hrefs {
+		report.Hrefs[i] = strings.TrimSpace(href)
+	}
+	if body.Filter != nil && body.Filter.Comp != nil {
+		filter, err := compFilter(body.Filter.Comp)
+		if err != nil {
+			return nil, err
+		}
+		report.Filter = filter
+	}
+	return report, nil
+}
+
+func compFilter(body *compFilterBody) (*CompFilter, error) {
+	filter := &CompFilter{Name: body.Name, IsNotDefined: body.IsNotDefined != nil}
+	if body.TimeRange != nil {
+		var err error
+		if filter.Start, err = parseTime(body.TimeRange.Start); err != nil {
+			return nil, err
+		}
+		if filter.End, err = parseTime(body.TimeRange.End); err != nil {
+			return nil, err
+		}
+	}
+	for _, prop := range body.Props {
+		filter.Props = append(filter.Props, PropFilter{Name: prop.Name, IsNotDefined: prop.IsNotDefined != nil})
+	}
+	for i := range body.Comps {
+		comp, err := compFilter(&body.Comps[i])
+		if err != nil {
+			return nil, err
+		}
+		filter.Comps = append(filter.Comps, *comp)
+	}
+	return filter, nil
+}
+
+// parseTime reads the UTC date-time of a time range, which is nil when the bound is left open.
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("20060102T150405Z", value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid time %q", ErrInvalidBody, value)
+	}
+	return &t, nil
+}
+
+func decode(r io.Reader, v interface{}) error {
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	return nil
+}
+
+// Property is a property of a resource, with its value as XML.
+type Property struct {
+	Name  xml.Name
+	Value string
+}
+
+// Text returns a property whose value is the text.
+func Text(name xml.Name, text string) Property {
+	return Property{Name: name, Value: escape(text)}
+}
+
+// Hrefs returns a property whose value is a DAV:href for each of the hrefs.
+func Hrefs(name xml.Name, hrefs ...string) Property {
+	var b strings.Builder
+	for _, href := range hrefs {
+		b.WriteString(Element(DAV("href"), escape(href)))
+	}
+	return Property{Name: name, Value: b.String()}
+}
+
+// Element returns the XML of an element with the given name and content, which is already XML.
+func Element(name xml.Name, content string) string {
+	tag, declaration := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, declaration = "x:"+name.Local, ` xmlns:x="`+escape(name.Space)+`"`
+	}
+	if content == "" {
+		return "<" + tag + declaration + "/>"
+	}
+	return "<" + tag + declaration + ">" + content + "</" + tag + ">"
+}
+
+// Elements returns the XML of empty elements with the given names, one after the other.
+func Elements(names ...xml.Name) string {
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(Element(name, ""))
+	}
+	return b.String()
+}
+
+// Response is a resource in a multistatus response.
+type Response struct {
+	Href string
+	// Found are the properties of the resource, and Missing the names of the ones asked for that it doesn't have.
+	Found   []Property
+	Missing []xml.Name
+	// Status, when not zero, is the status of the resource as a whole, such as http.StatusNotFound
+	// for resources removed since the last synchronization. Its properties are left out.
+	Status int
+}
+
+// Multistatus returns a multistatus response holding the responses and, when not empty, the sync token.
+func Multistatus(responses []Response, syncToken string) []byte {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+	for _, response := range responses {
+		var content strings.Builder
+		content.WriteString(Element(DAV("href"), escape(response.Href)))
+		if response.Status != 0 {
+			content.WriteString(status(response.Status))
+		} else {
+			if len(response.Found) > 0 {
+				var props strings.Builder
+				for _, prop := range response.Found {
+					props.WriteString(Element(prop.Name, prop.Value))
+				}
+				content.WriteString(Element(DAV("propstat"), Element(DAV("prop"), props.String())+status(http.StatusOK)))
+			}
+			if len(response.Missing) > 0 {
+				content.WriteString(Element(DAV("propstat"), Element(DAV("prop"), Elements(response.Missing...))+status(http.StatusNotFound)))
+			}
+		}
+		b.WriteString(Element(DAV("response"), content.String()))
+	}
+	if syncToken != "" {
+		b.WriteString(Element(DAV("sync-token"), escape(syncToken)))
+	}
+	b.WriteString("</d:multistatus>")
+	return []byte(b.String())
+}
+
+// Error returns the body of an error response about the precondition, such as DAV("valid-sync-token").
+func Error(precondition xml.Name) []byte {
+	return []byte(xml.Header + `<d:error xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` + Element(precondition, "") + "</d:error>")
+}
+
+func status(code int) string {
+	return Element(DAV("status"), fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code)))
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
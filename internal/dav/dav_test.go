@@ -0,0 +1,160 @@
+package dav
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// todo is a component with the given properties, due at the given time.
+type todo struct {
+	props map[string]bool
+	due   *time.Time
+}
+
+func (t todo) Has(name string) bool {
+	return t.props[name]
+}
+
+func (t todo) Overlaps(start, end *time.Time) bool {
+	if t.due == nil {
+		return true
+	}
+	return (start == nil || start.Before(*t.due)) && (end == nil || !end.Before(*t.due))
+}
+
+func TestParsePropFind(t *testing.T) {
+	propfind, err := ParsePropFind(strings.NewReader(`<?xml version="1.0"?>
+<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">
+  <d:prop><d:getetag/><cs:getctag/><d:displayname>ignored</d:displayname></d:prop>
+</d:propfind>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []xml.Name{DAV("getetag"), CalendarServer("getctag"), DAV("displayname")}
+	if propfind.AllProp || propfind.PropName || len(propfind.Props) != len(want) {
+		t.Fatalf("unexpected propfind %+v", propfind)
+	}
+	for i, name := range want {
+		if propfind.Props[i] != name {
+			t.Fatalf("expected %v, got %v", name, propfind.Props[i])
+		}
+	}
+
+	if propfind, err := ParsePropFind(strings.NewReader("")); err != nil || !propfind.AllProp {
+		t.Fatalf("expected an empty body to ask for every property, got %+v, %v", propfind, err)
+	}
+	for _, body := range []string{"<d:propfind xmlns:d=\"DAV:\">", `<d:report xmlns:d="DAV:"/>`} {
+		if _, err := ParsePropFind(strings.NewReader(body)); !errors.Is(err, ErrInvalidBody) {
+			t.Fatalf("expected %q to be invalid, got %v", body, err)
+		}
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	report, err := ParseReport(strings.NewReader(`<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VTODO">
+        <c:time-range start="20300101T000000Z"/>
+        <c:prop-filter name="COMPLETED"><c:is-not-defined/></c:prop-filter>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Name != CalDAV("calendar-query") || len(report.Props) != 1 || report.Filter == nil || len(report.Filter.Comps) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	comp := report.Filter.Comps[0]
+	if comp.Name != "VTODO" || comp.Start == nil || comp.End != nil || len(comp.Props) != 1 || !comp.Props[0].IsNotDefined {
+		t.Fatalf("unexpected filter %+v", comp)
+	}
+
+	report, err = ParseReport(strings.NewReader(`<d:sync-collection xmlns:d="DAV:">
+  <d:sync-token> urn:nulltask:sync:1 </d:sync-token><d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop>
+</d:sync-collection>`))
+	if err != nil || report.Name != DAV("sync-collection") || report.SyncToken != "urn:nulltask:sync:1" {
+		t.Fatalf("unexpected report %+v, %v", report, err)
+	}
+
+	report, err = ParseReport(strings.NewReader(`<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-data/></d:prop><d:href>/dav/calendars/tasks/a.ics</d:href><d:href>/dav/calendars/tasks/b.ics</d:href>
+</c:calendar-multiget>`))
+	if err != nil || len(report.Hrefs) != 2 || report.Hrefs[1] != "/dav/calendars/tasks/b.ics" {
+		t.Fatalf("unexpected report %+v, %v", report, err)
+	}
+
+	for _, body := range []string{"", `<c:calendar-query xmlns:c="urn:ietf:params:xml:ns:caldav"><c:filter><c:comp-filter name="VCALENDAR"><c:time-range start="tomorrow"/></c:comp-filter></c:filter></c:calendar-query>`} {
+		if _, err := ParseReport(strings.NewReader(body)); !errors.Is(err, ErrInvalidBody) {
+			t.Fatalf("expected %q to be invalid, got %v", body, err)
+		}
+	}
+}
+
+func TestCompFilterMatches(t *testing.T) {
+	due := time.Date(2030, 3, 4, 15, 0, 0, 0, time.UTC)
+	before, after := due.Add(-time.Hour), due.Add(time.Hour)
+	open := todo{props: map[string]bool{"SUMMARY": true}, due: &due}
+	completed := todo{props: map[string]bool{"SUMMARY": true, "COMPLETED": true}}
+
+	tests := []struct {
+		filter CompFilter
+		todo   todo
+		want   bool
+	}{
+		{CompFilter{Name: "VCALENDAR"}, open, true},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO"}}}, open, true},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VEVENT"}}}, open, false},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VEVENT", IsNotDefined: true}}}, open, true},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO", Start: &before, End: &after}}}, open, true},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO", Start: &after}}}, open, false},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO", Start: &after}}}, completed, true},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO", Props: []PropFilter{{Name: "completed", IsNotDefined: true}}}}}, open, true},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO", Props: []PropFilter{{Name: "COMPLETED", IsNotDefined: true}}}}}, completed, false},
+		{CompFilter{Name: "VCALENDAR", Comps: []CompFilter{{Name: "VTODO", Comps: []CompFilter{{Name: "VALARM"}}}}}, open, false},
+	}
+	for i, test := range tests {
+		if got := test.filter.Matches("VTODO", test.todo); got != test.want {
+			t.Errorf("test %d: expected %v, got %v", i, test.want, got)
+		}
+	}
+}
+
+func TestMultistatus(t *testing.T) {
+	body := string(Multistatus([]Response{
+		{Href: "/dav/calendars/tasks/", Found: []Property{Text(DAV("displayname"), "Tasks & more"), {Name: DAV("resourcetype"), Value: Elements(DAV("collection"), CalDAV("calendar"))}}, Missing: []xml.Name{{Space: "urn:example", Local: "color"}}},
+		{Href: "/dav/calendars/tasks/gone.ics", Status: http.StatusNotFound},
+	}, "urn:nulltask:sync:1"))
+
+	for _, want := range []string{
+		"<d:displayname>Tasks &amp; more</d:displayname>",
+		"<d:resourcetype><d:collection/><c:calendar/></d:resourcetype>",
+		"<d:status>HTTP/1.1 200 OK</d:status>",
+		`<d:prop><x:color xmlns:x="urn:example"/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status>`,
+		"<d:href>/dav/calendars/tasks/gone.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status></d:response>",
+		"<d:sync-token>urn:nulltask:sync:1</d:sync-token></d:multistatus>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in %s", want, body)
+		}
+	}
+
+	// The response must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("invalid XML: %v", err)
+			}
+			break
+		}
+	}
+}
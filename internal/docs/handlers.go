@@ -0,0 +1,30 @@
+package docs
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+// swaggerAssets holds the scripts and styles of Swagger UI, vendored from swagger-ui-dist.
+//
+//go:embed swagger-ui
+var swaggerAssets embed.FS
+
+// SpecHandler serves the OpenAPI document as JSON.
+func SpecHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, Spec())
+}
+
+// UIHandler serves the Swagger UI page, which renders the document served by SpecHandler.
+// The page is embedded in the binary along with the Swagger UI scripts and styles, so it works offline.
+func UIHandler(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, swaggerUI)
+}
+
+// AssetHandler serves the Swagger UI scripts and styles loaded by the page, named by the "*" route parameter.
+var AssetHandler = echo.StaticDirectoryHandler(echo.MustSubFS(swaggerAssets, "swagger-ui"), false)
@@ -0,0 +1,193 @@
+package docs
+
+import (
+	"net/http"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// Routes lists every route served by the API.
+// Add new routes here when registering them with Echo, the integration tests fail for undocumented routes.
+func Routes() []Route {
+	var routes []Route
+	routes = append(routes, docsRoutes()...)
+	routes = append(routes, userRoutes()...)
+	routes = append(routes, taskRoutes()...)
+	routes = append(routes, checklistRoutes()...)
+	routes = append(routes, workspaceRoutes()...)
+	routes = append(routes, labelRoutes()...)
+	routes = append(routes, commentRoutes()...)
+	routes = append(routes, attachmentRoutes()...)
+	routes = append(routes, calendarRoutes()...)
+	routes = append(routes, transferRoutes()...)
+	routes = append(routes, eventRoutes()...)
+	routes = append(routes, caldavRoutes()...)
+	return routes
+}
+
+func docsRoutes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "OpenAPI document describing this API", Response: map[string]interface{}{}},
+		{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "Swagger UI for the OpenAPI document", Response: "", ContentType: "text/html"},
+		{Method: http.MethodGet, Path: "/docs/swagger-ui/*", Tag: "docs", Summary: "Scripts and styles of the Swagger UI", Response: "", ContentType: "*/*", Errors: []int{http.StatusNotFound}},
+	}
+}
+
+func userRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/users/create", Tag: "users", Summary: "Create a user", Body: handlers.UserRequest{}, Status: http.StatusCreated, Response: models.User{}, Errors: []int{http.StatusBadRequest, http.StatusConflict}},
+		{Method: http.MethodPost, Path: "/users/login", Tag: "users", Summary: "Exchange an e-mail and password for a bearer token", Body: handlers.LoginRequest{}, Response: handlers.LoginResponse{}, Errors: []int{http.StatusBadRequest, http.StatusUnauthorized}},
+		{Method: http.MethodGet, Path: "/users/get/email/:email", Tag: "users", Summary: "Get a user by e-mail", Auth: true, Response: models.User{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/users/get/id/:id", Tag: "users", Summary: "Get a user by ID", Auth: true, Response: models.User{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/users/get/all/", Tag: "users", Summary: "List every user, admins only", Auth: true, Response: []models.User{}, Errors: []int{http.StatusForbidden}},
+		{Method: http.MethodPut, Path: "/users/update/email/:email", Tag: "users", Summary: "Update the authenticated user by e-mail", Auth: true, Body: handlers.UserUpdateRequest{}, Response: models.User{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodPut, Path: "/users/update/id/:id", Tag: "users", Summary: "Update the authenticated user by ID", Auth: true, Body: handlers.UserUpdateRequest{}, Response: models.User{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/users/delete/email/:email", Tag: "users", Summary: "Delete the authenticated user by e-mail, along with the workspaces they own and their personal data", Auth: true, Response: "", Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/users/delete/id/:id", Tag: "users", Summary: "Delete the authenticated user by ID, along with the workspaces they own and their personal data", Auth: true, Response: "", Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/users/get/preferences", Tag: "users", Summary: "Get the preferences of the authenticated user: time zone, locale, week start, default reminder and notification channels", Auth: true, Response: models.Preferences{}},
+		{Method: http.MethodPut, Path: "/users/update/preferences", Tag: "users", Summary: "Update the preferences of the authenticated user", Auth: true, Body: models.Preferences{}, Response: models.Preferences{}, Errors: []int{http.StatusBadRequest}},
+	}
+}
+
+func taskRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/tasks/create", Tag: "tasks", Summary: "Create a task owned by the authenticated user, optionally in a workspace", Auth: true, Body: models.Tasks{}, Status: http.StatusCreated, Response: models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodPost, Path: "/tasks/quick-add", Tag: "tasks", Summary: "Create a task from a line of text such as \"Pay rent tomorrow 9am #finance !high every month\", in English or Portuguese", Auth: true, Query: []*Parameter{queryParam("preview", "When true, only shows the task that would be created, with a HTTP status code 200")}, Body: handlers.QuickAddRequest{}, Status: http.StatusCreated, Response: handlers.QuickAddResponse{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id", Tag: "tasks", Summary: "Get a task by ID", Auth: true, Response: models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/user/:userId", Tag: "tasks", Summary: "List the tasks of the authenticated user", Auth: true, Query: []*Parameter{queryParam("labels", "Comma separated IDs of labels the tasks must be tagged with"), queryParam("match", "all, the default, to keep the tasks tagged with every label, or any to keep those tagged with any of them")}, Response: []models.Tasks{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/search/user/:userId", Tag: "tasks", Summary: "Full-text search over the tasks of the authenticated user", Auth: true, Query: []*Parameter{queryParam("q", "Words that must all appear in the task's title, description or notes"), queryParam("labels", "Comma separated IDs of labels the tasks must be tagged with"), queryParam("match", "all, the default, to keep the tasks tagged with every label, or any to keep those tagged with any of them")}, Response: []models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodPut, Path: "/tasks/update/id/:id", Tag: "tasks", Summary: "Update a task by ID, moving its status along the workflow it follows", Auth: true, Query: []*Parameter{queryParam("cascade", "When true and the task is completed, also moves its unfinished subtasks to the same status and checks off their checklists, provided every subtask could take that status on its own"), queryParam("force", "When true, completes the task, and the subtasks moved along, even if blocked by unfinished dependencies")}, Body: models.Tasks{}, Response: models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/tasks/delete/id/:id", Tag: "tasks", Summary: "Delete a task by ID", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/tasks/assign/id/:id", Tag: "tasks", Summary: "Assign a user to a task", Auth: true, Body: handlers.AssignRequest{}, Status: http.StatusCreated, Response: models.TaskAssignee{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/tasks/unassign/id/:id/user/:userId", Tag: "tasks", Summary: "Remove a user from the assignees of a task", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/assignees", Tag: "tasks", Summary: "List the assignees of a task", Auth: true, Response: []models.TaskAssignee{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/assigned", Tag: "tasks", Summary: "List the tasks assigned to the authenticated user", Auth: true, Response: []models.Tasks{}},
+		{Method: http.MethodPost, Path: "/tasks/watch/id/:id", Tag: "tasks", Summary: "Watch a task", Auth: true, Status: http.StatusCreated, Response: models.TaskWatcher{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/tasks/unwatch/id/:id", Tag: "tasks", Summary: "Stop watching a task", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/watchers", Tag: "tasks", Summary: "List the watchers of a task", Auth: true, Response: []models.TaskWatcher{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/watching", Tag: "tasks", Summary: "List the tasks the authenticated user watches", Auth: true, Response: []models.Tasks{}},
+		{Method: http.MethodPost, Path: "/tasks/comment/id/:id", Tag: "comments", Summary: "Write a Markdown comment on a task, mentioning users as @user@example.com", Auth: true, Body: handlers.CommentRequest{}, Status: http.StatusCreated, Response: models.Comment{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/comments", Tag: "comments", Summary: "List the comment threads of a task", Auth: true, Response: []models.Comment{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/tasks/attach/id/:id", Tag: "attachments", Summary: "Upload a file to a task, within the uploader's quota", Auth: true, Body: FileUpload{}, BodyContentType: "multipart/form-data", Status: http.StatusCreated, Response: models.Attachment{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusRequestEntityTooLarge}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/attachments", Tag: "attachments", Summary: "List the attachments of a task", Auth: true, Response: []models.Attachment{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/subtasks", Tag: "tasks", Summary: "List the direct subtasks of a task", Auth: true, Response: []models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/progress", Tag: "tasks", Summary: "Get the share of a task's subtasks and checklist items that are done", Auth: true, Response: handlers.TaskProgressResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/tasks/checklist/id/:id", Tag: "checklist", Summary: "Add an item at the end of the checklist of a task", Auth: true, Body: models.ChecklistItem{}, Status: http.StatusCreated, Response: models.ChecklistItem{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/checklist", Tag: "checklist", Summary: "List the checklist of a task, in order", Auth: true, Response: []models.ChecklistItem{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/tasks/depend/id/:id", Tag: "dependencies", Summary: "Make a task depend on another task of the same workspace", Auth: true, Body: handlers.DependencyRequest{}, Status: http.StatusCreated, Response: models.TaskDependency{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/tasks/undepend/id/:id/task/:dependsOnId", Tag: "dependencies", Summary: "Stop a task from depending on another task", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/dependencies", Tag: "dependencies", Summary: "List the tasks a task depends on", Auth: true, Response: []models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/dependents", Tag: "dependencies", Summary: "List the tasks that depend on a task", Auth: true, Response: []models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/critical-path", Tag: "dependencies", Summary: "Sort tasks by their dependencies and find the longest chain of unfinished ones", Auth: true, Query: []*Parameter{queryParam("ids", "Comma separated IDs of the tasks to order")}, Response: handlers.CriticalPathResponse{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/transitions", Tag: "workflows", Summary: "List the statuses a task can move to", Auth: true, Response: []models.WorkflowStatus{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/tasks/get/board", Tag: "boards", Summary: "Get the board of the authenticated user's personal tasks", Auth: true, Response: []handlers.BoardColumn{}},
+		{Method: http.MethodPut, Path: "/tasks/move/id/:id", Tag: "boards", Summary: "Move a task to another status and/or position of its board", Auth: true, Query: []*Parameter{queryParam("force", "When true, completes the task even if it's blocked by unfinished dependencies")}, Body: handlers.MoveRequest{}, Response: models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodGet, Path: "/tasks/get/next", Tag: "triage", Summary: "Get the open tasks of the authenticated user ranked by what to do next, with the explanation of each score", Auth: true, Query: []*Parameter{queryParam("limit", "How many tasks to return, 20 by default and at most 100")}, Response: []handlers.NextActionResponse{}, Errors: []int{http.StatusBadRequest}},
+		{Method: http.MethodGet, Path: "/tasks/get/eisenhower", Tag: "triage", Summary: "Get the open tasks of the authenticated user on the Eisenhower matrix", Auth: true, Response: handlers.EisenhowerResponse{}},
+		{Method: http.MethodGet, Path: "/tasks/get/today", Tag: "agenda", Summary: "Get the open tasks of the authenticated user due today in their time zone", Auth: true, Response: []models.Tasks{}},
+		{Method: http.MethodGet, Path: "/tasks/get/week", Tag: "agenda", Summary: "Get the open tasks of the authenticated user due this week in their time zone, from the week start of their preferences", Auth: true, Response: []models.Tasks{}},
+		{Method: http.MethodGet, Path: "/tasks/get/overdue", Tag: "agenda", Summary: "Get the open tasks of the authenticated user due on a day before today in their time zone", Auth: true, Response: []models.Tasks{}},
+		{Method: http.MethodGet, Path: "/tasks/get/id/:id/labels", Tag: "labels", Summary: "List the labels tagging a task", Auth: true, Response: []models.Label{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodPut, Path: "/tasks/reorder/id/:id/checklist", Tag: "checklist", Summary: "Reorder the checklist of a task", Auth: true, Body: handlers.ChecklistOrderRequest{}, Response: []models.ChecklistItem{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	}
+}
+
+func checklistRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPut, Path: "/checklist/update/id/:id", Tag: "checklist", Summary: "Rename or check off a checklist item", Auth: true, Body: models.ChecklistItem{}, Response: models.ChecklistItem{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/checklist/delete/id/:id", Tag: "checklist", Summary: "Delete a checklist item", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	}
+}
+
+func workspaceRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/workspaces/create", Tag: "workspaces", Summary: "Create a workspace owned by the authenticated user", Auth: true, Body: models.Workspace{}, Status: http.StatusCreated, Response: models.Workspace{}, Errors: []int{http.StatusBadRequest}},
+		{Method: http.MethodGet, Path: "/workspaces/get/all/", Tag: "workspaces", Summary: "List the workspaces the authenticated user is a member of", Auth: true, Response: []models.Workspace{}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id", Tag: "workspaces", Summary: "Get a workspace by ID", Auth: true, Response: models.Workspace{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPut, Path: "/workspaces/update/id/:id", Tag: "workspaces", Summary: "Rename a workspace, owner only", Auth: true, Body: models.Workspace{}, Response: models.Workspace{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/workspaces/delete/id/:id", Tag: "workspaces", Summary: "Delete a workspace and its tasks, owner only", Auth: true, Response: "", Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id/members", Tag: "workspaces", Summary: "List the members of a workspace", Auth: true, Response: []models.WorkspaceMember{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPut, Path: "/workspaces/update/id/:id/members/:userId", Tag: "workspaces", Summary: "Change the role of a member, owner only", Auth: true, Body: handlers.MemberRoleRequest{}, Response: models.WorkspaceMember{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/workspaces/delete/id/:id/members/:userId", Tag: "workspaces", Summary: "Remove a member, or leave the workspace", Auth: true, Response: "", Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/workspaces/invite/id/:id", Tag: "workspaces", Summary: "Invite an e-mail address to the workspace, owner only. The token accepting the invitation is e-mailed to that address", Auth: true, Body: models.WorkspaceInvitation{}, Status: http.StatusCreated, Response: models.WorkspaceInvitation{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusBadGateway}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id/invitations", Tag: "workspaces", Summary: "List the pending invitations of a workspace, owner only", Auth: true, Response: []models.WorkspaceInvitation{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/workspaces/delete/id/:id/invitations/:invitationId", Tag: "workspaces", Summary: "Revoke an invitation, owner only", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/workspaces/accept/:token", Tag: "workspaces", Summary: "Accept an invitation sent to the authenticated user's e-mail", Auth: true, Response: models.WorkspaceMember{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusGone}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id/tasks", Tag: "workspaces", Summary: "List the tasks of a workspace", Auth: true, Query: []*Parameter{queryParam("labels", "Comma separated IDs of labels the tasks must be tagged with"), queryParam("match", "all, the default, to keep the tasks tagged with every label, or any to keep those tagged with any of them")}, Response: []models.Tasks{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id/workflow", Tag: "workflows", Summary: "Get the status workflow the tasks of a workspace follow", Auth: true, Response: models.Workflow{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPut, Path: "/workspaces/update/id/:id/workflow", Tag: "workflows", Summary: "Configure the status workflow of a workspace, owner only", Auth: true, Body: models.Workflow{}, Response: models.Workflow{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/workspaces/delete/id/:id/workflow", Tag: "workflows", Summary: "Reset the status workflow of a workspace to the default one, owner only", Auth: true, Response: models.Workflow{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id/board", Tag: "boards", Summary: "Get the board of a workspace, with a column per status of its workflow", Auth: true, Response: []handlers.BoardColumn{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/workspaces/get/id/:id/labels", Tag: "labels", Summary: "List the labels of a workspace", Auth: true, Response: []models.Label{}, Errors: []int{http.StatusNotFound}},
+	}
+}
+
+func labelRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/labels/create", Tag: "labels", Summary: "Create a personal label, or a label of a workspace", Auth: true, Body: models.Label{}, Status: http.StatusCreated, Response: models.Label{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodGet, Path: "/labels/get/all/", Tag: "labels", Summary: "List the personal labels of the authenticated user", Auth: true, Response: []models.Label{}},
+		{Method: http.MethodGet, Path: "/labels/get/id/:id", Tag: "labels", Summary: "Get a label by ID", Auth: true, Response: models.Label{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodPut, Path: "/labels/update/id/:id", Tag: "labels", Summary: "Rename or recolor a label", Auth: true, Body: models.Label{}, Response: models.Label{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/labels/delete/id/:id", Tag: "labels", Summary: "Delete a label, untagging its tasks", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/labels/tag/id/:id", Tag: "labels", Summary: "Tag several tasks with a label at once", Auth: true, Body: handlers.LabelTasksRequest{}, Response: []models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/labels/untag/id/:id", Tag: "labels", Summary: "Remove a label from several tasks at once", Auth: true, Body: handlers.LabelTasksRequest{}, Response: []models.Tasks{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+	}
+}
+
+func commentRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPut, Path: "/comments/update/id/:id", Tag: "comments", Summary: "Edit a comment, author only", Auth: true, Body: handlers.CommentRequest{}, Response: models.Comment{}, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/comments/delete/id/:id", Tag: "comments", Summary: "Delete a comment, author or workspace owner only", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/comments/get/id/:id/history", Tag: "comments", Summary: "List the previous bodies of a comment", Auth: true, Response: []models.CommentRevision{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	}
+}
+
+func attachmentRoutes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/attachments/download/id/:id", Tag: "attachments", Summary: "Download an attachment, honouring Range requests", Auth: true, Response: Binary{}, ContentType: "application/octet-stream", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/attachments/thumbnail/id/:id", Tag: "attachments", Summary: "Get the thumbnail of an image attachment", Auth: true, Query: []*Parameter{queryParam("size", "One of the configured thumbnail sizes, in pixels, the smallest by default")}, Response: Binary{}, ContentType: "image/*", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/attachments/delete/id/:id", Tag: "attachments", Summary: "Delete an attachment, uploader or editors only", Auth: true, Response: "", Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/attachments/get/usage", Tag: "attachments", Summary: "Get how many bytes of attachments the authenticated user uploaded and their quota", Auth: true, Response: handlers.AttachmentUsageResponse{}},
+	}
+}
+
+func calendarRoutes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/calendar/get/feed", Tag: "calendar", Summary: "Get when the calendar feed of the authenticated user was created", Auth: true, Response: models.CalendarFeed{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodPost, Path: "/calendar/create/feed", Tag: "calendar", Summary: "Create the calendar feed of the authenticated user, or regenerate its token, revoking the previous link", Auth: true, Status: http.StatusCreated, Response: handlers.CalendarFeedResponse{}, Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodDelete, Path: "/calendar/delete/feed", Tag: "calendar", Summary: "Revoke the calendar feed of the authenticated user", Auth: true, Response: "", Errors: []int{http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/calendar/feed/:token", Tag: "calendar", Summary: "iCalendar feed of the tasks with a due date of the feed's owner, authenticated by its secret token, optionally ending in .ics. Honours If-None-Match", Query: []*Parameter{queryParam("kind", "event, the default, to get the tasks as events, or todo to get them as to-dos")}, Response: "", ContentType: "text/calendar", Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+	}
+}
+
+func transferRoutes() []Route {
+	return []Route{
+		{Method: http.MethodPost, Path: "/imports/create", Tag: "transfer", Summary: "Upload a file of tasks in the nulltask, csv, todoist, trello, todotxt or markdown format and import it in the background, or only preview the import with dry_run. Rows imported before update their tasks", Auth: true, Body: ImportUpload{}, BodyContentType: "multipart/form-data", Status: http.StatusAccepted, Response: models.ImportJob{}, Errors: []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge}},
+		{Method: http.MethodGet, Path: "/imports/get/all", Tag: "transfer", Summary: "List the import jobs of the authenticated user, newest first", Auth: true, Response: []models.ImportJob{}},
+		{Method: http.MethodGet, Path: "/imports/get/id/:id", Tag: "transfer", Summary: "Get an import job with the outcome of each row of its file", Auth: true, Response: models.ImportJob{}, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/exports/get/tasks", Tag: "transfer", Summary: "Download the personal tasks of the authenticated user with their labels and checklists", Auth: true, Query: []*Parameter{queryParam("format", "nulltask, the default, for a JSON document that imports back without losing anything, csv, todotxt or markdown")}, Response: transfer.Document{}, Errors: []int{http.StatusBadRequest}},
+	}
+}
+
+func eventRoutes() []Route {
+	return []Route{
+		{Method: http.MethodGet, Path: "/events/stream", Tag: "events", Summary: "Follow the events of the authenticated user as server-sent events named after their type, such as task.created, task.updated and task.deleted, with the event as JSON data", Auth: true, Response: "", ContentType: "text/event-stream", Errors: []int{http.StatusServiceUnavailable}},
+	}
+}
+
+func caldavRoutes() []Route {
+	const xml = "application/xml"
+	return []Route{
+		{Method: http.MethodGet, Path: "/.well-known/caldav", Tag: "caldav", Summary: "Redirect CalDAV clients to the root of the CalDAV server", Status: http.StatusMovedPermanently},
+		{Method: echo.PROPFIND, Path: "/.well-known/caldav", Tag: "caldav", Summary: "Redirect CalDAV clients to the root of the CalDAV server", Status: http.StatusMovedPermanently},
+		{Method: http.MethodOptions, Path: "/dav/*", Tag: "caldav", Summary: "WebDAV and CalDAV features supported by the CalDAV server, in the DAV and Allow headers"},
+		{Method: echo.PROPFIND, Path: "/dav/*", Tag: "caldav", Summary: "Properties of the root, the principal, the calendar home, the task collection (calendars/tasks/) or its calendar objects, and of their children unless Depth is 0. Accepts HTTP Basic authentication with the user's e-mail and password", Auth: true, Body: "", BodyContentType: xml, Status: http.StatusMultiStatus, Response: "", ContentType: xml, Errors: []int{http.StatusBadRequest, http.StatusNotFound}},
+		{Method: echo.REPORT, Path: "/dav/*", Tag: "caldav", Summary: "calendar-query, calendar-multiget or sync-collection report on the task collection", Auth: true, Body: "", BodyContentType: xml, Status: http.StatusMultiStatus, Response: "", ContentType: xml, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound}},
+		{Method: http.MethodGet, Path: "/dav/*", Tag: "caldav", Summary: "Calendar object of a task, with its ETag", Auth: true, Response: "", ContentType: "text/calendar", Errors: []int{http.StatusNotFound, http.StatusMethodNotAllowed}},
+		{Method: http.MethodPut, Path: "/dav/*", Tag: "caldav", Summary: "Create or update a task from a calendar object holding a VTODO, keeping the iCalendar properties tasks have no field for. Honours If-Match and If-None-Match", Auth: true, Body: "", BodyContentType: "text/calendar", Status: http.StatusCreated, Errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusConflict, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge}},
+		{Method: http.MethodDelete, Path: "/dav/*", Tag: "caldav", Summary: "Delete the task of a calendar object. Honours If-Match", Auth: true, Status: http.StatusNoContent, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusPreconditionFailed}},
+	}
+}
@@ -0,0 +1,237 @@
+package docs
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	binaryType    = reflect.TypeOf(Binary{})
+)
+
+// schemaGenerator turns Go types into JSON Schemas.
+// Named structs are registered once in schemas and referenced everywhere else.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+// schemaOf returns the schema describing the JSON encoding of values of type t.
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == deletedAtType:
+		return nullable(&Schema{Type: "string", Format: "date-time"})
+	case t == binaryType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.schemaOf(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// Register a placeholder first, so recursive types end up referencing themselves
+			g.schemas[t.Name()] = &Schema{}
+			*g.schemas[t.Name()] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema describes a struct as an object whose properties are its JSON fields.
+// Fields of embedded structs are promoted, like encoding/json does.
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.structSchema(field.Type)
+			for prop, s := range embedded.Properties {
+				schema.Properties[prop] = s
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schemaOf(field.Type)
+		if applyValidation(prop, field.Type, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = prop
+	}
+
+	return schema
+}
+
+// applyValidation translates the validator.v10 rules of a field into JSON Schema constraints.
+// It reports whether the field is required.
+func applyValidation(schema *Schema, t reflect.Type, tag string) (required bool) {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	// Constraints apply to the value, not to the null allowed by pointers
+	target := schema
+	if len(schema.OneOf) > 0 {
+		target = schema.OneOf[0]
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			// Rules after dive apply to the elements, which aren't described in that much detail
+			return required
+		case "email":
+			target.Format = "email"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "url", "uri":
+			target.Format = "uri"
+		case "hexcolor":
+			target.Pattern = "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, enumValue(t, value))
+			}
+		case "min", "gte":
+			bound(target, t, param, boundMin)
+		case "max", "lte":
+			bound(target, t, param, boundMax)
+		case "gt":
+			bound(target, t, param, boundExclusiveMin)
+		case "lt":
+			bound(target, t, param, boundExclusiveMax)
+		case "len":
+			bound(target, t, param, boundMin)
+			bound(target, t, param, boundMax)
+		}
+	}
+	return required
+}
+
+type boundKind int
+
+const (
+	boundMin boundKind = iota
+	boundMax
+	boundExclusiveMin
+	boundExclusiveMax
+)
+
+// bound sets a length, size or value constraint, depending on the kind of the field.
+func bound(schema *Schema, t reflect.Type, param string, kind boundKind) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		n := int(value)
+		switch kind {
+		case boundMin, boundExclusiveMin:
+			schema.MinLength = &n
+		case boundMax, boundExclusiveMax:
+			schema.MaxLength = &n
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n := int(value)
+		switch kind {
+		case boundMin, boundExclusiveMin:
+			schema.MinItems = &n
+		case boundMax, boundExclusiveMax:
+			schema.MaxItems = &n
+		}
+	default:
+		switch kind {
+		case boundMin:
+			schema.Minimum = &value
+		case boundMax:
+			schema.Maximum = &value
+		case boundExclusiveMin:
+			schema.ExclusiveMinimum = &value
+		case boundExclusiveMax:
+			schema.ExclusiveMaximum = &value
+		}
+	}
+}
+
+// enumValue converts a oneof value to the JSON type of the field.
+func enumValue(t reflect.Type, value string) interface{} {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// nullable allows the schema to also be null, the way JSON Schema 2020-12 expresses it.
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" || len(schema.OneOf) > 0 {
+		return &Schema{OneOf: []*Schema{schema, {Type: "null"}}}
+	}
+	if typ, ok := schema.Type.(string); ok {
+		schema.Type = []string{typ, "null"}
+	}
+	return schema
+}
+
+func float(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,197 @@
+package docs
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Route documents one endpoint of the API.
+//
+// Path uses Echo's syntax (/users/get/id/:id); every :param becomes a required string path parameter.
+// Body and Response are sample values whose types describe the JSON payloads, nil meaning there's none.
+// Every status listed in Errors responds with the Error schema.
+type Route struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Auth     bool
+	Query    []*Parameter
+	Body     interface{}
+	Status   int
+	Response interface{}
+	Errors   []int
+
+	// ContentType overrides the application/json content type of the response, for routes serving files or feeds.
+	ContentType string
+	// BodyContentType overrides the application/json content type of the body, for routes receiving forms or files.
+	BodyContentType string
+}
+
+// Binary describes raw file contents, in responses or as a field of a multipart body.
+type Binary struct{}
+
+// FileUpload is the multipart/form-data body of the routes receiving a file.
+type FileUpload struct {
+	File Binary `json:"file" validate:"required"`
+}
+
+// ImportUpload is the multipart/form-data body of the route importing a file of tasks.
+type ImportUpload struct {
+	File   Binary `json:"file" validate:"required"`
+	Format string `json:"format" validate:"required,oneof=nulltask csv todoist trello todotxt markdown"`
+	// Mapping is a JSON object mapping the fields of the tasks onto the columns of CSV files.
+	Mapping string `json:"mapping"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// Error is the body of every error response: echo.HTTPError and the validation errors share this shape.
+type Error struct {
+	Message string `json:"message"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// Spec returns the OpenAPI document describing every route in Routes.
+// It is built on first use and shared afterwards.
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = build(Routes())
+	})
+	return spec
+}
+
+// build assembles the OpenAPI document for the given routes.
+func build(routes []Route) *Document {
+	g := &schemaGenerator{schemas: map[string]*Schema{}}
+	errorSchema := g.schemaOf(reflect.TypeOf(Error{}))
+
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:       "NullTask API",
+			Description: "Task management API. Error responses carry a JSON object with a human readable \"message\".",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		path, params := openAPIPath(route.Path)
+		op := &Operation{
+			OperationID: operationID(route.Method, path),
+			Summary:     route.Summary,
+			Tags:        []string{route.Tag},
+			Parameters:  append(params, route.Query...),
+			Responses:   map[string]*Response{},
+		}
+
+		if route.Auth {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+
+		if route.Body != nil {
+			contentType := route.BodyContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{contentType: {Schema: g.schemaOf(reflect.TypeOf(route.Body))}},
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := &Response{Description: http.StatusText(status)}
+		if route.Response != nil {
+			contentType := route.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			success.Content = map[string]*MediaType{contentType: {Schema: g.schemaOf(reflect.TypeOf(route.Response))}}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		codes := append([]int{}, route.Errors...)
+		if route.Auth {
+			codes = append(codes, http.StatusUnauthorized)
+		}
+		for _, code := range append(codes, http.StatusInternalServerError) {
+			op.Responses[strconv.Itoa(code)] = &Response{
+				Description: http.StatusText(code),
+				Content:     map[string]*MediaType{"application/json": {Schema: errorSchema}},
+			}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][operationKey(route.Method)] = op
+	}
+
+	return doc
+}
+
+// openAPIPath converts an Echo path into an OpenAPI path template and its path parameters.
+func openAPIPath(echoPath string) (string, []*Parameter) {
+	var params []*Parameter
+	segments := strings.Split(echoPath, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+			params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		if segment == "*" {
+			segments[i] = "{path}"
+			params = append(params, &Parameter{Name: "path", In: "path", Required: true, Description: "Rest of the path, slashes included", Schema: &Schema{Type: "string"}})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable, unique identifier from the method and the path.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '{' || r == '}' || r == '-' || r == '.' || r == '_' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// HasOperation reports whether the document describes the given method on the given Echo path.
+func (d *Document) HasOperation(method, echoPath string) bool {
+	path, _ := openAPIPath(echoPath)
+	_, ok := d.Paths[path][operationKey(method)]
+	return ok
+}
+
+// operationKey returns the key of the operation for the method in its path item.
+// OpenAPI only knows the methods of HTTP itself, so the ones of WebDAV, such as PROPFIND, go into extensions.
+func operationKey(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace:
+		return strings.ToLower(method)
+	}
+	return "x-" + strings.ToLower(method)
+}
+
+// queryParam documents an optional string query parameter.
+func queryParam(name, description string) *Parameter {
+	return &Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string"}}
+}
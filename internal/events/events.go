@@ -0,0 +1,100 @@
+// Package events carries what happens to tasks to whoever needs to react to it,
+// such as the notification system, without the handlers knowing who is listening.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Type identifies what happened.
+type Type string
+
+const (
+	// TaskAssigned is published when a user is assigned to a task. UserID is the assignee.
+	TaskAssigned Type = "task.assigned"
+	// TaskUnassigned is published when a user is removed from the assignees of a task. UserID is the former assignee.
+	TaskUnassigned Type = "task.unassigned"
+	// CommentCreated is published when a comment is written on a task. UserID is the author.
+	CommentCreated Type = "comment.created"
+	// UserMentioned is published when a comment mentions a user. UserID is the mentioned user.
+	UserMentioned Type = "comment.mentioned"
+	// TaskCreated is published when a task is created. UserID is the owner of the task.
+	TaskCreated Type = "task.created"
+	// TaskUpdated is published when a task changes, including when it moves to another status. UserID is the owner of the task.
+	TaskUpdated Type = "task.updated"
+	// TaskDeleted is published when a task is deleted. UserID is the owner of the task.
+	TaskDeleted Type = "task.deleted"
+)
+
+// Event describes something that happened to a task.
+type Event struct {
+	Type Type `json:"type"`
+	// ActorID is the user who caused the event.
+	ActorID string `json:"actor_id"`
+	// UserID is the user the event is about, such as the assignee.
+	UserID string `json:"user_id"`
+	// TaskID is the task the event happened to.
+	TaskID uint `json:"task_id"`
+	// CommentID is the comment the event is about, zero for events that aren't about comments.
+	CommentID uint `json:"comment_id,omitempty"`
+	// WorkspaceID is the workspace of the task, nil for personal tasks.
+	WorkspaceID *string `json:"workspace_id"`
+	// Recipients are the users that should be notified, the actor is never one of them.
+	Recipients []string  `json:"recipients"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Publisher publishes events.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Subscriber receives the published events.
+type Subscriber func(ctx context.Context, event Event)
+
+// Bus is an in-process Publisher delivering every event to its subscribers, in the order they subscribed.
+// Subscribers run synchronously in the publishing goroutine, so slow deliveries should be handed off to
+// a goroutine or a queue by the subscriber itself. It is safe for concurrent use.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []Subscriber
+}
+
+// NewBus returns a Bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a subscriber for every event published from now on.
+func (b *Bus) Subscribe(subscriber Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Publish delivers the event to every subscriber, filling OccurredAt when it's zero.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	subscribers := append([]Subscriber(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(ctx, event)
+	}
+}
+
+// LogSubscriber returns a Subscriber that logs every event at the debug level.
+// It's the only delivery available until a notification channel subscribes to the bus.
+func LogSubscriber(logger *log.Logger) Subscriber {
+	return func(_ context.Context, event Event) {
+		logger.Debug("Evento publicado", "type", event.Type, "task_id", event.TaskID, "user_id", event.UserID, "actor_id", event.ActorID, "recipients", event.Recipients)
+	}
+}
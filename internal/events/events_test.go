@@ -0,0 +1,23 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBusDeliversToEverySubscriber(t *testing.T) {
+	bus := NewBus()
+
+	var first, second []Event
+	bus.Subscribe(func(_ context.Context, event Event) { first = append(first, event) })
+	bus.Subscribe(func(_ context.Context, event Event) { second = append(second, event) })
+
+	bus.Publish(context.Background(), Event{Type: TaskAssigned, TaskID: 1, UserID: "u1"})
+
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("expected every subscriber to receive the event, got %d and %d", len(first), len(second))
+	}
+	if first[0].Type != TaskAssigned || first[0].TaskID != 1 || first[0].OccurredAt.IsZero() {
+		t.Fatalf("unexpected delivered event: %+v", first[0])
+	}
+}
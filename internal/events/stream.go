@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// StreamBuffer is how many events a listener of a Stream can fall behind before it's dropped.
+const StreamBuffer = 64
+
+// Stream hands the published events over to the listeners following them live, such as the clients of the event stream route.
+// Each listener follows one user and receives the events they caused and the ones they are a recipient of.
+// It is safe for concurrent use.
+type Stream struct {
+	mu        sync.Mutex
+	listeners map[*listener]struct{}
+}
+
+type listener struct {
+	userID string
+	events chan Event
+}
+
+// NewStream returns a Stream without listeners. Register its Subscriber on the bus for it to receive the events.
+func NewStream() *Stream {
+	return &Stream{listeners: map[*listener]struct{}{}}
+}
+
+// Subscriber returns the Subscriber delivering the events to the listeners.
+// Publishing never waits for a listener: one whose buffer is full is dropped, its channel closed,
+// and it's up to the client to reconnect and fetch what it missed.
+func (s *Stream) Subscriber() Subscriber {
+	return func(_ context.Context, event Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for l := range s.listeners {
+			if !concerns(event, l.userID) {
+				continue
+			}
+			select {
+			case l.events <- event:
+			default:
+				delete(s.listeners, l)
+				close(l.events)
+			}
+		}
+	}
+}
+
+// Listen starts following the events of the user. It returns the channel receiving them,
+// closed when the listener falls too far behind, and the function to call to stop listening.
+func (s *Stream) Listen(userID string) (<-chan Event, func()) {
+	l := &listener{userID: userID, events: make(chan Event, StreamBuffer)}
+	s.mu.Lock()
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	return l.events, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.listeners[l]; ok {
+			delete(s.listeners, l)
+			close(l.events)
+		}
+	}
+}
+
+// concerns reports whether the user caused the event or is one of its recipients.
+func concerns(event Event, userID string) bool {
+	if event.ActorID == userID {
+		return true
+	}
+	for _, recipient := range event.Recipients {
+		if recipient == userID {
+			return true
+		}
+	}
+	return false
+}
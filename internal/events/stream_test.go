@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStreamDeliversTheEventsOfTheUser(t *testing.T) {
+	stream := NewStream()
+	bus := NewBus()
+	bus.Subscribe(stream.Subscriber())
+
+	ana, stopAna := stream.Listen("ana")
+	defer stopAna()
+	bob, stopBob := stream.Listen("bob")
+
+	bus.Publish(context.Background(), Event{Type: TaskCreated, ActorID: "ana", TaskID: 1})
+	bus.Publish(context.Background(), Event{Type: TaskUpdated, ActorID: "carl", TaskID: 2, Recipients: []string{"ana"}})
+
+	if event := <-ana; event.TaskID != 1 {
+		t.Fatalf("expected the event Ana caused first, got %+v", event)
+	}
+	if event := <-ana; event.TaskID != 2 {
+		t.Fatalf("expected the event Ana is a recipient of, got %+v", event)
+	}
+	select {
+	case event := <-bob:
+		t.Fatalf("expected Bob to receive nothing, got %+v", event)
+	default:
+	}
+
+	stopBob()
+	if _, ok := <-bob; ok {
+		t.Fatal("expected the channel to be closed once stopped")
+	}
+	stopBob()
+}
+
+func TestStreamDropsListenersFallingBehind(t *testing.T) {
+	stream := NewStream()
+	events, stop := stream.Listen("ana")
+	defer stop()
+
+	deliver := stream.Subscriber()
+	for i := 0; i <= StreamBuffer; i++ {
+		deliver(context.Background(), Event{Type: TaskUpdated, ActorID: "ana", TaskID: uint(i)})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received != StreamBuffer {
+		t.Fatalf("expected the buffered events before the channel closed, got %d", received)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// GetTodayTasks returns, with a HTTP status code 200, the open tasks of the authenticated user, personal or
+// assigned to them, due today in their time zone, including the ones due earlier today. They're ordered by due date.
+func (h *Handler) GetTodayTasks(c echo.Context) (err error) {
+	return h.agenda(c, func(today time.Time, _ *models.Preferences) (time.Time, time.Time) {
+		return today, today.AddDate(0, 0, 1)
+	})
+}
+
+// GetWeekTasks returns, with a HTTP status code 200, the open tasks of the authenticated user, personal or
+// assigned to them, due this week in their time zone, the week starting on the day set in their preferences.
+// They're ordered by due date.
+func (h *Handler) GetWeekTasks(c echo.Context) (err error) {
+	return h.agenda(c, func(today time.Time, preferences *models.Preferences) (time.Time, time.Time) {
+		start := today.AddDate(0, 0, -((int(today.Weekday()) - int(preferences.FirstDay()) + 7) % 7))
+		return start, start.AddDate(0, 0, 7)
+	})
+}
+
+// GetOverdueTasks returns, with a HTTP status code 200, the open tasks of the authenticated user, personal or
+// assigned to them, due on a day before today in their time zone. They're ordered by due date.
+func (h *Handler) GetOverdueTasks(c echo.Context) (err error) {
+	return h.agenda(c, func(today time.Time, _ *models.Preferences) (time.Time, time.Time) {
+		return time.Time{}, today
+	})
+}
+
+// agenda responds with the open tasks of the authenticated user due from the start, inclusive, to the end, exclusive,
+// of the period returned by the given function for the start of today in the user's time zone.
+func (h *Handler) agenda(c echo.Context, period func(today time.Time, preferences *models.Preferences) (time.Time, time.Time)) error {
+	preferences, err := h.userPreferences(c, middlewares.UserID(c))
+	if err != nil {
+		return err
+	}
+	tasks, err := h.openTasks(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(preferences.Location())
+	start, end := period(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), preferences)
+	due := []models.Tasks{}
+	for _, task := range tasks {
+		if task.DueDate != nil && !task.DueDate.Before(start) && task.DueDate.Before(end) {
+			due = append(due, task)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].DueDate.Before(*due[j].DueDate) })
+	return c.JSON(http.StatusOK, due)
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// AssignTask assigns a user to a task.
+// It expects a JSON object in the request body with the "user_id" of the assignee.
+// Personal tasks can only be assigned to their owner, and workspace tasks to the members of the workspace,
+// by a member with at least the editor role.
+// If successful, it publishes a events.TaskAssigned event and returns the assignment with a HTTP status code 201.
+// Assigning a user twice returns a HTTP status code 409.
+func (h *Handler) AssignTask(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	req := new(AssignRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.checkAssignable(c, task, req.UserID); err != nil {
+		return err
+	}
+
+	assignee := &models.TaskAssignee{TaskID: task.ID, UserID: req.UserID, AssignedBy: middlewares.UserID(c)}
+	if err := h.Tasks.AddAssignee(c.Request().Context(), assignee); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return echo.NewHTTPError(http.StatusConflict, "Usuário já atribuído à tarefa")
+		}
+		h.logger(c).Error("Erro ao atribuir a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atribuir tarefa")
+	}
+
+	h.publishAssignment(c, events.TaskAssigned, task, req.UserID)
+	return c.JSON(http.StatusCreated, assignee)
+}
+
+// UnassignTask removes a user, taken from the route parameters, from the assignees of a task.
+// Editors can unassign anyone, and every assignee can unassign themselves.
+// If successful, it publishes a events.TaskUnassigned event and returns a JSON response with a HTTP status code 200
+// and a message "Atribuição Removida".
+func (h *Handler) UnassignTask(c echo.Context) (err error) {
+	userID := c.Param("userId")
+	role := models.RoleEditor
+	if userID == middlewares.UserID(c) {
+		role = models.RoleViewer
+	}
+
+	task, err := h.findTask(c, role)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Tasks.RemoveAssignee(c.Request().Context(), task.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Atribuição não encontrada")
+		}
+		h.logger(c).Error("Erro ao remover a atribuição", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao remover atribuição")
+	}
+
+	h.publishAssignment(c, events.TaskUnassigned, task, userID)
+	return c.JSON(http.StatusOK, "Atribuição Removida")
+}
+
+// GetTaskAssignees returns, with a HTTP status code 200, the assignees of a task.
+func (h *Handler) GetTaskAssignees(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	assignees, err := h.Tasks.ListAssignees(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar atribuições", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar atribuições")
+	}
+	return c.JSON(http.StatusOK, assignees)
+}
+
+// GetAssignedTasks returns, with a HTTP status code 200, every task assigned to the authenticated user
+// that they can still see.
+func (h *Handler) GetAssignedTasks(c echo.Context) (err error) {
+	tasks, err := h.Tasks.FindAssignedTo(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+
+	tasks, err = h.visibleTasks(c, tasks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// WatchTask makes the authenticated user watch a task, so they are notified of what happens to it.
+// Every user who can see the task can watch it. Watching a task twice returns a HTTP status code 409.
+// If successful, it returns the watch with a HTTP status code 201.
+func (h *Handler) WatchTask(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	watcher := &models.TaskWatcher{TaskID: task.ID, UserID: middlewares.UserID(c)}
+	if err := h.Tasks.AddWatcher(c.Request().Context(), watcher); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return echo.NewHTTPError(http.StatusConflict, "Tarefa já observada")
+		}
+		h.logger(c).Error("Erro ao observar a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao observar tarefa")
+	}
+
+	return c.JSON(http.StatusCreated, watcher)
+}
+
+// UnwatchTask stops the authenticated user from watching a task.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Tarefa Não Observada".
+func (h *Handler) UnwatchTask(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Tasks.RemoveWatcher(c.Request().Context(), task.ID, middlewares.UserID(c)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Tarefa não observada")
+		}
+		h.logger(c).Error("Erro ao deixar de observar a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deixar de observar tarefa")
+	}
+
+	return c.JSON(http.StatusOK, "Tarefa Não Observada")
+}
+
+// GetTaskWatchers returns, with a HTTP status code 200, the watchers of a task.
+func (h *Handler) GetTaskWatchers(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	watchers, err := h.Tasks.ListWatchers(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar observadores", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar observadores")
+	}
+	return c.JSON(http.StatusOK, watchers)
+}
+
+// GetWatchedTasks returns, with a HTTP status code 200, every task the authenticated user watches
+// that they can still see.
+func (h *Handler) GetWatchedTasks(c echo.Context) (err error) {
+	tasks, err := h.Tasks.FindWatchedBy(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+
+	tasks, err = h.visibleTasks(c, tasks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// checkAssignable checks that the user exists and can see the task.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) checkAssignable(c echo.Context, task *models.Tasks, userID string) error {
+	ctx := c.Request().Context()
+
+	if _, err := h.Users.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+
+	visible, err := h.canSeeTask(c, task, userID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		if task.WorkspaceID == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Tarefas pessoais só podem ser atribuídas ao dono")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Usuário não é membro do workspace")
+	}
+	return nil
+}
+
+// canSeeTask reports whether the user can see the task: personal tasks are only seen by their owner,
+// and workspace tasks by the members of the workspace.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) canSeeTask(c echo.Context, task *models.Tasks, userID string) (bool, error) {
+	if task.WorkspaceID == nil {
+		return task.UserID == userID, nil
+	}
+
+	if _, err := h.Workspaces.FindMember(c.Request().Context(), *task.WorkspaceID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		h.logger(c).Error("Erro ao buscar membro", "error", err)
+		return false, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar membro")
+	}
+	return true, nil
+}
+
+// publishAssignment publishes an assignment event about the user.
+// The user and the task's watchers are notified, except for whoever made the change.
+func (h *Handler) publishAssignment(c echo.Context, eventType events.Type, task *models.Tasks, userID string) {
+	actorID := middlewares.UserID(c)
+	recipients := []string{}
+	if userID != actorID {
+		recipients = append(recipients, userID)
+	}
+
+	watchers, err := h.Tasks.ListWatchers(c.Request().Context(), task.ID)
+	if err != nil {
+		// The change is already saved, the watchers just won't hear about it
+		h.logger(c).Error("Erro ao buscar observadores", "error", err)
+	}
+	for _, watcher := range watchers {
+		if watcher.UserID != actorID && watcher.UserID != userID {
+			recipients = append(recipients, watcher.UserID)
+		}
+	}
+
+	h.publish(c, events.Event{
+		Type:        eventType,
+		ActorID:     actorID,
+		UserID:      userID,
+		TaskID:      task.ID,
+		WorkspaceID: task.WorkspaceID,
+		Recipients:  recipients,
+	})
+}
+
+// visibleTasks keeps the tasks the authenticated user can still see: their personal tasks
+// and the tasks of the workspaces they are still a member of.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) visibleTasks(c echo.Context, tasks []models.Tasks) ([]models.Tasks, error) {
+	userID := middlewares.UserID(c)
+	member := map[string]bool{}
+	visible := make([]models.Tasks, 0, len(tasks))
+
+	for _, task := range tasks {
+		if task.WorkspaceID == nil {
+			if task.UserID == userID {
+				visible = append(visible, task)
+			}
+			continue
+		}
+
+		isMember, ok := member[*task.WorkspaceID]
+		if !ok {
+			_, err := h.Workspaces.FindMember(c.Request().Context(), *task.WorkspaceID, userID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				h.logger(c).Error("Erro ao buscar membro", "error", err)
+				return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+			}
+			isMember = err == nil
+			member[*task.WorkspaceID] = isMember
+		}
+		if isMember {
+			visible = append(visible, task)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// multipartOverhead is how many bytes, besides the file itself, an upload request can carry:
+// the multipart boundaries and headers.
+const multipartOverhead = 1 << 20
+
+// UploadAttachment attaches a file to a task.
+// It expects a multipart/form-data request with the file in the "file" field. Uploading requires
+// at least the editor role on workspace tasks.
+// The content type is detected from the file's contents, whatever the client claims, and files that
+// would take the uploader over their AttachmentQuota are refused with a HTTP status code 413.
+// JPEG, PNG and GIF images are queued for their thumbnails to be generated in the background.
+// If successful, it returns the attachment with a HTTP status code 201.
+func (h *Handler) UploadAttachment(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	userID := middlewares.UserID(c)
+
+	usage, err := h.Attachments.UsageByUser(ctx, userID)
+	if err != nil {
+		h.logger(c).Error("Erro ao calcular o uso de armazenamento", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao enviar anexo")
+	}
+	remaining := h.AttachmentQuota - usage
+	if remaining <= 0 {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Cota de armazenamento excedida")
+	}
+
+	// Stop reading as soon as the request is larger than what's left of the quota
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, remaining+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Cota de armazenamento excedida")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Envie o arquivo no campo \"file\"")
+	}
+	if header.Size > remaining {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Cota de armazenamento excedida")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.logger(c).Error("Erro ao abrir o arquivo enviado", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao enviar anexo")
+	}
+	defer file.Close()
+
+	detected, err := mimetype.DetectReader(file)
+	if err != nil {
+		h.logger(c).Error("Erro ao detectar o tipo do arquivo", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao enviar anexo")
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		h.logger(c).Error("Erro ao ler o arquivo enviado", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao enviar anexo")
+	}
+
+	attachment := &models.Attachment{
+		TaskID:      task.ID,
+		UploaderID:  userID,
+		FileName:    cleanFileName(header.Filename),
+		ContentType: detected.String(),
+		Size:        header.Size,
+		StorageKey:  "attachments/" + strconv.FormatUint(uint64(task.ID), 10) + "/" + uuid.New().String(),
+	}
+	attachment.ThumbnailStatus = models.ThumbnailNone
+	if h.Thumbnails != nil && thumbnails.Supported(attachment.ContentType) {
+		attachment.ThumbnailStatus = models.ThumbnailPending
+	}
+
+	if err := h.Blobs.Put(ctx, attachment.StorageKey, file, attachment.Size, attachment.ContentType); err != nil {
+		h.logger(c).Error("Erro ao armazenar o anexo", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao enviar anexo")
+	}
+
+	// Uploads running at the same time all read the same usage above, so the quota is checked again as the attachment is saved
+	if err := h.Attachments.CreateWithinQuota(ctx, attachment, h.AttachmentQuota); err != nil {
+		if err := h.Blobs.Delete(ctx, attachment.StorageKey); err != nil {
+			h.logger(c).Error("Erro ao remover o anexo armazenado", "key", attachment.StorageKey, "error", err)
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Cota de armazenamento excedida")
+		}
+		h.logger(c).Error("Erro ao salvar o anexo", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao enviar anexo")
+	}
+
+	if attachment.ThumbnailStatus == models.ThumbnailPending {
+		h.Thumbnails.Enqueue(*attachment)
+	}
+	return c.JSON(http.StatusCreated, attachment)
+}
+
+// GetTaskAttachments returns, with a HTTP status code 200, the attachments of a task.
+func (h *Handler) GetTaskAttachments(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := h.Attachments.FindByTask(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar anexos", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar anexos")
+	}
+	return c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment streams the contents of an attachment.
+// Range requests are honoured, so large files can be resumed or read in parts, and the file is always
+// served as a download with its detected content type.
+func (h *Handler) DownloadAttachment(c echo.Context) (err error) {
+	attachment, _, err := h.findAttachment(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	blob, err := h.Blobs.Open(c.Request().Context(), attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.logger(c).Error("Anexo sem conteúdo armazenado", "key", attachment.StorageKey)
+			return echo.NewHTTPError(http.StatusNotFound, "Anexo não encontrado")
+		}
+		h.logger(c).Error("Erro ao abrir o anexo", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao baixar anexo")
+	}
+	defer blob.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, attachment.ContentType)
+	res.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	res.Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	http.ServeContent(res, c.Request(), attachment.FileName, attachment.CreatedAt, blob)
+	return nil
+}
+
+// DeleteAttachment deletes an attachment and its contents.
+// Its uploader and the editors of the task can delete it.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Anexo Deletado".
+func (h *Handler) DeleteAttachment(c echo.Context) (err error) {
+	attachment, task, err := h.findAttachment(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+	if attachment.UploaderID != middlewares.UserID(c) {
+		if _, err := h.taskAccess(c, task.ID, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+
+	if err := h.Attachments.Delete(c.Request().Context(), attachment.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar o anexo", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar anexo")
+	}
+	h.removeAttachmentFiles(c, *attachment)
+
+	return c.JSON(http.StatusOK, "Anexo Deletado")
+}
+
+// removeAttachmentFiles removes the contents and thumbnails of attachments already deleted from the database.
+// Failures are only logged: the attachments are gone for the users, and the orphan blobs only take space.
+func (h *Handler) removeAttachmentFiles(c echo.Context, attachments ...models.Attachment) {
+	ctx := c.Request().Context()
+	for _, attachment := range attachments {
+		if err := h.Blobs.Delete(ctx, attachment.StorageKey); err != nil {
+			h.logger(c).Error("Erro ao remover o anexo armazenado", "key", attachment.StorageKey, "error", err)
+		}
+		if h.Thumbnails != nil && attachment.ThumbnailStatus != models.ThumbnailNone {
+			if err := h.Thumbnails.Delete(ctx, attachment); err != nil {
+				h.logger(c).Error("Erro ao remover as miniaturas", "key", attachment.StorageKey, "error", err)
+			}
+		}
+	}
+}
+
+// GetAttachmentThumbnail serves the thumbnail of an image attachment.
+// The "size" query parameter picks one of the configured sizes, the smallest one by default.
+// Attachments that aren't images, or whose thumbnails aren't ready yet, return a HTTP status code 404.
+func (h *Handler) GetAttachmentThumbnail(c echo.Context) (err error) {
+	attachment, _, err := h.findAttachment(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	if h.Thumbnails == nil || attachment.ThumbnailStatus == models.ThumbnailNone || attachment.ThumbnailStatus == models.ThumbnailFailed {
+		return echo.NewHTTPError(http.StatusNotFound, "Anexo sem miniatura")
+	}
+	if attachment.ThumbnailStatus == models.ThumbnailPending {
+		return echo.NewHTTPError(http.StatusNotFound, "Miniatura em processamento")
+	}
+
+	sizes := h.Thumbnails.Sizes()
+	size := sizes[0]
+	if param := c.QueryParam("size"); param != "" {
+		size, err = strconv.Atoi(param)
+		if err != nil || !slices.Contains(sizes, size) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Tamanho de miniatura inválido")
+		}
+	}
+
+	blob, err := h.Blobs.Open(c.Request().Context(), attachment.ThumbnailKey(size))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Anexo sem miniatura")
+		}
+		h.logger(c).Error("Erro ao abrir a miniatura", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao baixar miniatura")
+	}
+	defer blob.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, thumbnails.ContentType(attachment.ContentType))
+	res.Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	res.Header().Set("Cache-Control", "private, max-age=86400")
+	http.ServeContent(res, c.Request(), "", attachment.CreatedAt, blob)
+	return nil
+}
+
+// GetAttachmentUsage returns, with a HTTP status code 200, how many bytes of attachments the authenticated user
+// uploaded and their quota.
+func (h *Handler) GetAttachmentUsage(c echo.Context) (err error) {
+	usage, err := h.Attachments.UsageByUser(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao calcular o uso de armazenamento", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao calcular uso")
+	}
+	return c.JSON(http.StatusOK, AttachmentUsageResponse{Used: usage, Quota: h.AttachmentQuota})
+}
+
+// findAttachment loads the attachment whose ID is in the "id" route parameter, along with its task,
+// checking that the authenticated user can reach the task with the given role.
+// Attachments of tasks the user can't see are reported as not found.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) findAttachment(c echo.Context, role models.WorkspaceRole) (*models.Attachment, *models.Tasks, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "ID de anexo inválido")
+	}
+
+	attachment, err := h.Attachments.FindByID(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Anexo não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar o anexo", "error", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar anexo")
+	}
+
+	task, err := h.taskAccess(c, attachment.TaskID, role)
+	if err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Anexo não encontrado")
+		}
+		return nil, nil, err
+	}
+	return attachment, task, nil
+}
+
+// cleanFileName keeps the base name of the uploaded file without control characters,
+// so it can be safely sent back in a Content-Disposition header.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "arquivo"
+	}
+	return name
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/rank"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// GetBoard returns, with a HTTP status code 200, the board of the authenticated user's personal tasks:
+// a column per status of the default workflow, each with its tasks in order.
+func (h *Handler) GetBoard(c echo.Context) (err error) {
+	tasks, err := h.Tasks.FindByUser(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+	return c.JSON(http.StatusOK, board(models.DefaultWorkflow(), tasks))
+}
+
+// GetWorkspaceBoard returns, with a HTTP status code 200, the board of a workspace:
+// a column per status of its workflow, with its WIP limit and its tasks in order.
+// Tasks left in a status the workflow no longer has get a column of their own after the others.
+func (h *Handler) GetWorkspaceBoard(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := h.workspaceWorkflow(c, workspace.ID)
+	if err != nil {
+		return err
+	}
+	tasks, err := h.Tasks.FindByWorkspace(c.Request().Context(), workspace.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+	return c.JSON(http.StatusOK, board(workflow, tasks))
+}
+
+// MoveTask moves a task on its board, changing its status and its position in the column in a single update.
+// It expects a JSON object in the request body with the "status" to move to, along the transitions of the workflow,
+// and the "after_id" and/or "before_id" of the tasks of that column it should land between.
+// Without either of them the task goes to the end of the column.
+// Moving to another column over its WIP limit returns a HTTP status code 409, as does completing a blocked task
+// unless the "force" query parameter is set to true.
+// If successful, it returns the task with a HTTP status code 200.
+func (h *Handler) MoveTask(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	req := new(MoveRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	status, completed := task.Status, task.CompletedAt != nil
+	if req.Status != "" {
+		task.Status = req.Status
+	}
+	limit, err := h.applyStatus(c, task, status)
+	if err != nil {
+		return err
+	}
+	if force, _ := strconv.ParseBool(c.QueryParam("force")); task.Blocked && !force && !completed && task.CompletedAt != nil {
+		return echo.NewHTTPError(http.StatusConflict, "Tarefa bloqueada por dependências não concluídas")
+	}
+	if err := h.placeTask(c, task, req.AfterID, req.BeforeID); err != nil {
+		return err
+	}
+
+	if err := h.Tasks.UpdateInColumn(c.Request().Context(), task, limit); err != nil {
+		if errors.Is(err, repository.ErrColumnFull) {
+			return columnFull(task.Status)
+		}
+		h.logger(c).Error("Erro ao mover a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao mover tarefa")
+	}
+	h.publishTask(c, events.TaskUpdated, task)
+	return c.JSON(http.StatusOK, task)
+}
+
+// enterColumn puts the task, which is moving to the given status, at the end of that column,
+// unless the column already holds as many tasks as its WIP limit allows.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) enterColumn(c echo.Context, task *models.Tasks, status models.WorkflowStatus) error {
+	column, err := h.Tasks.FindColumn(c.Request().Context(), task.UserID, task.WorkspaceID, task.Status)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a coluna", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar coluna")
+	}
+	if status.WIPLimit > 0 && len(column) >= status.WIPLimit {
+		return columnFull(status.Name)
+	}
+
+	last := ""
+	if len(column) > 0 {
+		last = column[len(column)-1].Rank
+	}
+	return h.setRank(c, task, last, "")
+}
+
+// columnFull returns the error of a task refused by the board column of the given status, which is at its WIP limit.
+func columnFull(status string) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusConflict, "Limite de tarefas da coluna "+status+" atingido")
+}
+
+// placeTask puts the task between the given tasks of its column, or at its end when both are nil.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) placeTask(c echo.Context, task *models.Tasks, afterID, beforeID *uint) error {
+	column, err := h.Tasks.FindColumn(c.Request().Context(), task.UserID, task.WorkspaceID, task.Status)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a coluna", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar coluna")
+	}
+	others := make([]models.Tasks, 0, len(column))
+	for _, other := range column {
+		if other.ID != task.ID {
+			others = append(others, other)
+		}
+	}
+	find := func(id uint) int {
+		for i, other := range others {
+			if other.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	previous, next := "", ""
+	switch {
+	case afterID != nil:
+		i := find(*afterID)
+		if i < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Tarefa anterior inválida")
+		}
+		previous = others[i].Rank
+		if beforeID == nil && i+1 < len(others) {
+			next = others[i+1].Rank
+		}
+	case len(others) > 0 && beforeID == nil:
+		previous = others[len(others)-1].Rank
+	}
+	if beforeID != nil {
+		j := find(*beforeID)
+		if j < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Tarefa seguinte inválida")
+		}
+		next = others[j].Rank
+		if afterID == nil && j > 0 {
+			previous = others[j-1].Rank
+		}
+	}
+	return h.setRank(c, task, previous, next)
+}
+
+// setRank gives the task a rank between the given ones.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) setRank(c echo.Context, task *models.Tasks, previous, next string) error {
+	key, err := rank.Between(previous, next)
+	if errors.Is(err, rank.ErrOrder) {
+		return echo.NewHTTPError(http.StatusBadRequest, "A tarefa anterior deve vir antes da seguinte")
+	}
+	if err != nil {
+		h.logger(c).Error("Erro ao posicionar a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao posicionar tarefa")
+	}
+	task.Rank = key
+	return nil
+}
+
+// board groups the tasks in a column per status of the workflow, ordered by rank and then by ID.
+// Statuses the workflow doesn't have get a column after the others, in alphabetical order.
+func board(workflow *models.Workflow, tasks []models.Tasks) []BoardColumn {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Rank != tasks[j].Rank {
+			return tasks[i].Rank < tasks[j].Rank
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	columns := make([]BoardColumn, 0, len(workflow.Statuses))
+	index := map[string]int{}
+	for _, status := range workflow.Statuses {
+		index[status.Name] = len(columns)
+		columns = append(columns, BoardColumn{Status: status.Name, Terminal: status.Terminal, WIPLimit: status.WIPLimit, Tasks: []models.Tasks{}})
+	}
+
+	var unknown []string
+	for _, task := range tasks {
+		if _, ok := index[task.Status]; !ok {
+			index[task.Status] = -1
+			unknown = append(unknown, task.Status)
+		}
+	}
+	sort.Strings(unknown)
+	for _, status := range unknown {
+		index[status] = len(columns)
+		columns = append(columns, BoardColumn{Status: status, Tasks: []models.Tasks{}})
+	}
+
+	for _, task := range tasks {
+		i := index[task.Status]
+		columns[i].Tasks = append(columns[i].Tasks, task)
+	}
+	return columns
+}
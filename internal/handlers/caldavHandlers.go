@@ -0,0 +1,779 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/dav"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/ical"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The paths of the CalDAV tree. Every user sees their own principal and a single collection holding their tasks.
+const (
+	davRoot       = "/dav/"
+	davPrincipal  = "/dav/principal/"
+	davHome       = "/dav/calendars/"
+	davCollection = "/dav/calendars/tasks/"
+)
+
+// CalendarSyncTTL is how long the sync tokens handed out to CalDAV clients stay valid.
+// Clients with an expired token synchronize the whole collection again.
+const CalendarSyncTTL = 30 * 24 * time.Hour
+
+// davSyncPrefix starts the sync tokens, which must be URIs.
+const davSyncPrefix = "urn:nulltask:sync:"
+
+// maxCalendarObjectSize is the largest calendar object clients can upload.
+const maxCalendarObjectSize = 1 << 20
+
+// davKind is the kind of resource of the CalDAV tree a request is for.
+type davKind int
+
+const (
+	davUnknownKind davKind = iota
+	davRootKind
+	davPrincipalKind
+	davHomeKind
+	davCollectionKind
+	davObjectKind
+)
+
+// davResource is a task in the CalDAV collection of the authenticated user, along with its calendar object.
+type davResource struct {
+	task models.Tasks
+	// object is how a client last wrote the task, or nil when none did.
+	object *models.CalendarObject
+	body   []byte
+	etag   string
+}
+
+// name returns the name of the resource in the collection.
+func (r *davResource) name() string {
+	if r.object != nil && r.object.Name != "" {
+		return r.object.Name
+	}
+	return davDefaultName(r.task.ID)
+}
+
+// davDefaultName returns the name of the resource of a task no client wrote.
+func davDefaultName(id uint) string {
+	return fmt.Sprintf("task-%d.ics", id)
+}
+
+// isDAVDefaultName reports whether the name has the form of the names davDefaultName gives.
+func isDAVDefaultName(name string) bool {
+	id, ok := strings.CutPrefix(name, "task-")
+	if !ok {
+		return false
+	}
+	id, ok = strings.CutSuffix(id, ".ics")
+	if !ok {
+		return false
+	}
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
+
+// uid returns the UID of the task's VTODO.
+func (r *davResource) uid() string {
+	if r.object != nil && r.object.UID != "" {
+		return r.object.UID
+	}
+	return ical.UID(&r.task)
+}
+
+// Has reports whether the VTODO of the resource has the property or the subcomponent, for calendar-query filters.
+func (r *davResource) Has(name string) bool {
+	switch name {
+	case "UID", "DTSTAMP", "CREATED", "LAST-MODIFIED", "SUMMARY", "STATUS":
+		return true
+	case "DESCRIPTION":
+		return r.task.Description != nil && *r.task.Description != ""
+	case "DUE":
+		return r.task.DueDate != nil
+	case "COMPLETED":
+		return r.task.CompletedAt != nil
+	case "PRIORITY":
+		return r.task.Priority != models.PriorityNone
+	case "RRULE":
+		return r.task.Recurrence != nil
+	case "VALARM":
+		if r.task.Reminder != nil && r.task.DueDate != nil {
+			return true
+		}
+	}
+	if r.object != nil {
+		for _, line := range r.object.Properties {
+			if strings.HasPrefix(strings.ToUpper(line), name+":") || strings.HasPrefix(strings.ToUpper(line), name+";") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether the VTODO of the resource overlaps the time range, for calendar-query filters.
+// To-dos without a due date overlap every range, and the others overlap the ranges they are due in.
+func (r *davResource) Overlaps(start, end *time.Time) bool {
+	due := r.task.DueDate
+	if due == nil {
+		return true
+	}
+	return (start == nil || start.Before(*due)) && (end == nil || !end.Before(*due))
+}
+
+// davState is the CalDAV collection of the authenticated user.
+type davState struct {
+	resources []*davResource
+	byName    map[string]*davResource
+}
+
+// etags returns the ETag of each resource, by name.
+func (s *davState) etags() map[string]string {
+	etags := make(map[string]string, len(s.resources))
+	for _, resource := range s.resources {
+		etags[resource.name()] = resource.etag
+	}
+	return etags
+}
+
+// tag returns a tag that changes whenever anything in the collection does: its CTag, and the sync token of its state.
+func (s *davState) tag() string {
+	hash := sha256.New()
+	for _, resource := range s.resources {
+		fmt.Fprintf(hash, "%s %s\n", resource.name(), resource.etag)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// CheckCredentials returns the ID of the user with the given e-mail and password,
+// for the clients that authenticate with them rather than with a bearer token, such as CalDAV clients.
+// The credentials of deactivated users are refused.
+func (h *Handler) CheckCredentials(c echo.Context, email, password string) (string, error) {
+	user, err := h.Users.FindByEmail(c.Request().Context(), email)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			h.logger(c).Error("Erro ao buscar o usuário", "error", err)
+		}
+		return "", err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return "", err
+	}
+	if user.DeactivatedAt != nil {
+		return "", errUserDeactivated
+	}
+	return user.ID, nil
+}
+
+// CalDAVWellKnown redirects the clients discovering the CalDAV server to its root.
+func (h *Handler) CalDAVWellKnown(c echo.Context) (err error) {
+	return c.Redirect(http.StatusMovedPermanently, davRoot)
+}
+
+// CalDAVOptions tells clients which WebDAV and CalDAV features the server supports, with a HTTP status code 200.
+func (h *Handler) CalDAVOptions(c echo.Context) (err error) {
+	c.Response().Header().Set("DAV", "1, 3, calendar-access")
+	c.Response().Header().Set(echo.HeaderAllow, "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	return c.NoContent(http.StatusOK)
+}
+
+// CalDAVPropFind answers a PROPFIND request with the properties asked for of the resource and,
+// unless the Depth header is 0, of its children, in a multistatus response with a HTTP status code 207.
+// The tree has the root, the principal of the authenticated user, their calendar home and the collection holding their tasks,
+// personal or assigned to them, each as a calendar object.
+func (h *Handler) CalDAVPropFind(c echo.Context) (err error) {
+	propfind, err := dav.ParsePropFind(io.LimitReader(c.Request().Body, maxCalendarObjectSize))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Corpo da requisição inválido")
+	}
+	kind, name := davPath(c)
+	if kind == davUnknownKind {
+		return echo.NewHTTPError(http.StatusNotFound, "Recurso não encontrado")
+	}
+	deep := c.Request().Header.Get("Depth") != "0"
+	selected := func(href string, props []dav.Property) dav.Response {
+		return davSelect(href, props, propfind.Props, propfind.AllProp, propfind.PropName)
+	}
+
+	var responses []dav.Response
+	switch kind {
+	case davRootKind, davPrincipalKind:
+		props, err := h.davPrincipalProps(c, kind)
+		if err != nil {
+			return err
+		}
+		href := davRoot
+		if kind == davPrincipalKind {
+			href = davPrincipal
+		}
+		responses = append(responses, selected(href, props))
+	case davHomeKind:
+		responses = append(responses, selected(davHome, davHomeProps()))
+		if deep {
+			state, err := h.davState(c)
+			if err != nil {
+				return err
+			}
+			props, err := h.davCollectionProps(c, state, propfind.AllProp || davAsks(propfind.Props, dav.DAV("sync-token")))
+			if err != nil {
+				return err
+			}
+			responses = append(responses, selected(davCollection, props))
+		}
+	case davCollectionKind:
+		state, err := h.davState(c)
+		if err != nil {
+			return err
+		}
+		props, err := h.davCollectionProps(c, state, propfind.AllProp || davAsks(propfind.Props, dav.DAV("sync-token")))
+		if err != nil {
+			return err
+		}
+		responses = append(responses, selected(davCollection, props))
+		if deep {
+			for _, resource := range state.resources {
+				responses = append(responses, selected(davHref(resource), davObjectProps(resource)))
+			}
+		}
+	case davObjectKind:
+		state, err := h.davState(c)
+		if err != nil {
+			return err
+		}
+		resource, ok := state.byName[name]
+		if !ok {
+			return echo.NewHTTPError(http.StatusNotFound, "Tarefa não encontrada")
+		}
+		responses = append(responses, selected(davHref(resource), davObjectProps(resource)))
+	}
+	return davMultistatus(c, responses, "")
+}
+
+// CalDAVReport answers a REPORT request on the collection with a multistatus response with a HTTP status code 207.
+// A calendar-query returns the calendar objects passing its filter, a calendar-multiget the ones it lists,
+// and a sync-collection the ones that changed since its sync token, along with the ones removed since then,
+// and a new sync token. Unknown or expired sync tokens are answered with a HTTP status code 403.
+func (h *Handler) CalDAVReport(c echo.Context) (err error) {
+	report, err := dav.ParseReport(io.LimitReader(c.Request().Body, maxCalendarObjectSize))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Corpo da requisição inválido")
+	}
+	if kind, _ := davPath(c); kind != davCollectionKind {
+		return echo.NewHTTPError(http.StatusNotFound, "Recurso não encontrado")
+	}
+
+	state, err := h.davState(c)
+	if err != nil {
+		return err
+	}
+	selected := func(resource *davResource) dav.Response {
+		return davSelect(davHref(resource), davObjectProps(resource), report.Props, report.AllProp, false)
+	}
+
+	var responses []dav.Response
+	switch report.Name {
+	case dav.CalDAV("calendar-query"):
+		for _, resource := range state.resources {
+			if report.Filter == nil || report.Filter.Matches("VTODO", resource) {
+				responses = append(responses, selected(resource))
+			}
+		}
+		return davMultistatus(c, responses, "")
+
+	case dav.CalDAV("calendar-multiget"):
+		for _, href := range report.Hrefs {
+			if resource, ok := state.byName[davObjectName(href)]; ok {
+				responses = append(responses, selected(resource))
+			} else {
+				responses = append(responses, dav.Response{Href: href, Status: http.StatusNotFound})
+			}
+		}
+		return davMultistatus(c, responses, "")
+
+	case dav.DAV("sync-collection"):
+		previous := map[string]string{}
+		if report.SyncToken != "" {
+			sync, err := h.Calendar.FindSync(c.Request().Context(), middlewares.UserID(c), report.SyncToken)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return c.Blob(http.StatusForbidden, echo.MIMEApplicationXMLCharsetUTF8, dav.Error(dav.DAV("valid-sync-token")))
+				}
+				h.logger(c).Error("Erro ao buscar o estado da sincronização", "error", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao sincronizar tarefas")
+			}
+			previous = sync.ETags
+		}
+
+		current := state.etags()
+		for _, resource := range state.resources {
+			if previous[resource.name()] != resource.etag {
+				responses = append(responses, selected(resource))
+			}
+		}
+		removed := []string{}
+		for name := range previous {
+			if _, ok := current[name]; !ok {
+				removed = append(removed, name)
+			}
+		}
+		sort.Strings(removed)
+		for _, name := range removed {
+			responses = append(responses, dav.Response{Href: davCollection + url.PathEscape(name), Status: http.StatusNotFound})
+		}
+
+		token, err := h.saveDAVState(c, state)
+		if err != nil {
+			return err
+		}
+		return davMultistatus(c, responses, token)
+	}
+	return c.Blob(http.StatusForbidden, echo.MIMEApplicationXMLCharsetUTF8, dav.Error(dav.DAV("supported-report")))
+}
+
+// CalDAVGet returns, with a HTTP status code 200, the calendar object of a task in the collection, along with its ETag.
+func (h *Handler) CalDAVGet(c echo.Context) (err error) {
+	kind, name := davPath(c)
+	if kind != davObjectKind {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "Método não permitido neste recurso")
+	}
+	state, err := h.davState(c)
+	if err != nil {
+		return err
+	}
+	resource, ok := state.byName[name]
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "Tarefa não encontrada")
+	}
+	c.Response().Header().Set("ETag", resource.etag)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", resource.body)
+}
+
+// CalDAVPut creates or updates a task from the calendar object in the request body.
+// New objects become personal tasks of the authenticated user, while the existing ones update their task,
+// which the user must be able to edit. New objects can't take the task-<id>.ics names of the tasks no client wrote,
+// which return a HTTP status code 409. The title, description, due date, status, priority, recurrence and first alarm
+// of the VTODO go into the task, and the rest of the calendar is kept to be written back as it was.
+// If-Match and If-None-Match are honoured, failing with a HTTP status code 412.
+// If successful, it returns a HTTP status code 201 for new tasks and 204 for the updated ones.
+func (h *Handler) CalDAVPut(c echo.Context) (err error) {
+	kind, name := davPath(c)
+	if kind != davObjectKind {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "Método não permitido neste recurso")
+	}
+	userID := middlewares.UserID(c)
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxCalendarObjectSize+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Corpo da requisição inválido")
+	}
+	if len(body) > maxCalendarObjectSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Tarefa grande demais")
+	}
+
+	preferences, err := h.userPreferences(c, userID)
+	if err != nil {
+		return err
+	}
+	entry, err := ical.Parse(body, preferences.Location())
+	if err != nil {
+		if errors.Is(err, ical.ErrNoTodo) {
+			return c.Blob(http.StatusForbidden, echo.MIMEApplicationXMLCharsetUTF8, dav.Error(dav.CalDAV("supported-calendar-component")))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Calendário inválido")
+	}
+
+	state, err := h.davState(c)
+	if err != nil {
+		return err
+	}
+	existing := state.byName[name]
+	if err := davPreconditions(c, existing); err != nil {
+		return err
+	}
+	// The names of the tasks no client wrote are theirs, even before they exist
+	if existing == nil && isDAVDefaultName(name) {
+		return echo.NewHTTPError(http.StatusConflict, "Nome reservado para outra tarefa")
+	}
+	for _, resource := range state.resources {
+		if entry.UID != "" && resource.uid() == entry.UID && resource.name() != name {
+			return c.Blob(http.StatusForbidden, echo.MIMEApplicationXMLCharsetUTF8, dav.Error(dav.CalDAV("no-uid-conflict")))
+		}
+	}
+
+	status := http.StatusNoContent
+	var task *models.Tasks
+	if existing == nil {
+		task = &models.Tasks{UserID: userID}
+		applyEntry(task, entry)
+		task.Status = davStatus(models.DefaultWorkflow(), "", entry.Status)
+		if err := c.Validate(task); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if err := h.createTask(c, task); err != nil {
+			return err
+		}
+		status = http.StatusCreated
+	} else {
+		task, err = h.taskAccess(c, existing.task.ID, models.RoleEditor)
+		if err != nil {
+			return err
+		}
+		previous, completed, blocked := task.Status, task.CompletedAt != nil, task.Blocked
+		applyEntry(task, entry)
+		if err := c.Validate(task); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		workflow, err := h.taskWorkflow(c, task)
+		if err != nil {
+			return err
+		}
+		task.Status = davStatus(workflow, previous, entry.Status)
+		limit, err := h.applyStatus(c, task, previous)
+		if err != nil {
+			return err
+		}
+		if blocked && !completed && task.CompletedAt != nil {
+			return echo.NewHTTPError(http.StatusConflict, "Tarefa bloqueada por dependências não concluídas")
+		}
+
+		if err := h.Tasks.UpdateInColumn(c.Request().Context(), task, limit); err != nil {
+			if errors.Is(err, repository.ErrColumnFull) {
+				return columnFull(task.Status)
+			}
+			h.logger(c).Error("Erro ao atualizar a tarefa", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar tarefa")
+		}
+		h.publishTask(c, events.TaskUpdated, task)
+	}
+
+	object := &models.CalendarObject{TaskID: task.ID, Name: name, UID: entry.UID, Properties: entry.Properties, Components: entry.Components}
+	if err := h.Calendar.SaveObject(c.Request().Context(), object); err != nil {
+		h.logger(c).Error("Erro ao salvar o objeto de calendário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao salvar tarefa")
+	}
+	return c.NoContent(status)
+}
+
+// CalDAVDelete deletes the task of a calendar object in the collection, which the user must be able to edit.
+// If-Match is honoured, failing with a HTTP status code 412. If successful, it returns a HTTP status code 204.
+func (h *Handler) CalDAVDelete(c echo.Context) (err error) {
+	kind, name := davPath(c)
+	if kind != davObjectKind {
+		return echo.NewHTTPError(http.StatusMethodNotAllowed, "Método não permitido neste recurso")
+	}
+	state, err := h.davState(c)
+	if err != nil {
+		return err
+	}
+	resource, ok := state.byName[name]
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "Tarefa não encontrada")
+	}
+	if err := davPreconditions(c, resource); err != nil {
+		return err
+	}
+
+	task, err := h.taskAccess(c, resource.task.ID, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+	if err := h.Tasks.Delete(c.Request().Context(), task.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar tarefa")
+	}
+	h.publishTask(c, events.TaskDeleted, task)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// davPath returns which resource of the CalDAV tree the request is for and, for calendar objects, their name.
+func davPath(c echo.Context) (davKind, string) {
+	rest, err := url.PathUnescape(c.Param("*"))
+	if err != nil {
+		return davUnknownKind, ""
+	}
+	switch strings.Trim(rest, "/") {
+	case "":
+		return davRootKind, ""
+	case "principal":
+		return davPrincipalKind, ""
+	case "calendars":
+		return davHomeKind, ""
+	case "calendars/tasks":
+		return davCollectionKind, ""
+	}
+	if name, ok := strings.CutPrefix(rest, "calendars/tasks/"); ok && name != "" && !strings.Contains(name, "/") {
+		return davObjectKind, name
+	}
+	return davUnknownKind, ""
+}
+
+// davObjectName returns the name of the calendar object an href, such as the ones of a calendar-multiget, points to.
+func davObjectName(href string) string {
+	if parsed, err := url.Parse(href); err == nil {
+		href = parsed.Path
+	}
+	if !strings.HasPrefix(href, davCollection) {
+		return ""
+	}
+	return path.Base(href)
+}
+
+// davHref returns the href of a calendar object.
+func davHref(resource *davResource) string {
+	return davCollection + url.PathEscape(resource.name())
+}
+
+// davState loads the CalDAV collection of the authenticated user: their tasks, personal or assigned to them,
+// as calendar objects, in the order of their IDs.
+func (h *Handler) davState(c echo.Context) (*davState, error) {
+	tasks, err := h.userTasks(c)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	objects, err := h.Calendar.FindObjects(c.Request().Context(), ids)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar os objetos de calendário", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+	byTask := make(map[uint]*models.CalendarObject, len(objects))
+	for i := range objects {
+		byTask[objects[i].TaskID] = &objects[i]
+	}
+
+	state := &davState{byName: make(map[string]*davResource, len(tasks))}
+	for _, task := range tasks {
+		resource := &davResource{task: task, object: byTask[task.ID]}
+		if resource.object != nil {
+			resource.body = ical.Object(&resource.task, resource.uid(), resource.object.Properties, resource.object.Components)
+		} else {
+			resource.body = ical.Object(&resource.task, resource.uid(), nil, nil)
+		}
+		sum := sha256.Sum256(resource.body)
+		resource.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		if _, taken := state.byName[resource.name()]; taken {
+			h.logger(c).Warn("Nome de recurso CalDAV repetido", "name", resource.name(), "task", task.ID)
+			continue
+		}
+		state.resources = append(state.resources, resource)
+		state.byName[resource.name()] = resource
+	}
+	return state, nil
+}
+
+// saveDAVState saves the state of the collection and returns its sync token.
+func (h *Handler) saveDAVState(c echo.Context, state *davState) (string, error) {
+	sync := &models.CalendarSync{UserID: middlewares.UserID(c), Token: davSyncPrefix + state.tag(), ETags: state.etags()}
+	if err := h.Calendar.SaveSync(c.Request().Context(), sync, time.Now().Add(-CalendarSyncTTL)); err != nil {
+		h.logger(c).Error("Erro ao salvar o estado da sincronização", "error", err)
+		return "", echo.NewHTTPError(http.StatusInternalServerError, "Erro ao sincronizar tarefas")
+	}
+	return sync.Token, nil
+}
+
+// davPrincipalProps returns the properties of the root and of the principal of the authenticated user.
+func (h *Handler) davPrincipalProps(c echo.Context, kind davKind) ([]dav.Property, error) {
+	user, err := h.Users.FindByID(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+
+	resourceType := dav.Elements(dav.DAV("collection"))
+	if kind == davPrincipalKind {
+		resourceType = dav.Elements(dav.DAV("principal"))
+	}
+	return []dav.Property{
+		{Name: dav.DAV("resourcetype"), Value: resourceType},
+		dav.Text(dav.DAV("displayname"), user.Name),
+		dav.Hrefs(dav.DAV("current-user-principal"), davPrincipal),
+		dav.Hrefs(dav.DAV("principal-URL"), davPrincipal),
+		dav.Hrefs(dav.CalDAV("calendar-home-set"), davHome),
+		dav.Hrefs(dav.CalDAV("calendar-user-address-set"), "mailto:"+user.Email),
+	}, nil
+}
+
+// davHomeProps returns the properties of the calendar home.
+func davHomeProps() []dav.Property {
+	return []dav.Property{
+		{Name: dav.DAV("resourcetype"), Value: dav.Elements(dav.DAV("collection"))},
+		dav.Hrefs(dav.DAV("current-user-principal"), davPrincipal),
+		dav.Hrefs(dav.DAV("owner"), davPrincipal),
+	}
+}
+
+// davCollectionProps returns the properties of the collection. Its sync token is only handed out,
+// which saves the state it stands for, when withToken is set.
+func (h *Handler) davCollectionProps(c echo.Context, state *davState, withToken bool) ([]dav.Property, error) {
+	privileges := ""
+	for _, privilege := range []string{"read", "write", "write-content", "bind", "unbind"} {
+		privileges += dav.Element(dav.DAV("privilege"), dav.Elements(dav.DAV(privilege)))
+	}
+	reports := ""
+	for _, report := range []xml.Name{dav.CalDAV("calendar-query"), dav.CalDAV("calendar-multiget"), dav.DAV("sync-collection")} {
+		reports += dav.Element(dav.DAV("supported-report"), dav.Element(dav.DAV("report"), dav.Elements(report)))
+	}
+
+	props := []dav.Property{
+		{Name: dav.DAV("resourcetype"), Value: dav.Elements(dav.DAV("collection"), dav.CalDAV("calendar"))},
+		dav.Text(dav.DAV("displayname"), "NullTask"),
+		{Name: dav.CalDAV("supported-calendar-component-set"), Value: `<c:comp name="VTODO"/>`},
+		{Name: dav.DAV("supported-report-set"), Value: reports},
+		{Name: dav.DAV("current-user-privilege-set"), Value: privileges},
+		dav.Hrefs(dav.DAV("current-user-principal"), davPrincipal),
+		dav.Hrefs(dav.DAV("owner"), davPrincipal),
+		dav.Text(dav.CalendarServer("getctag"), state.tag()),
+	}
+	if withToken {
+		token, err := h.saveDAVState(c, state)
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, dav.Text(dav.DAV("sync-token"), token))
+	}
+	return props, nil
+}
+
+// davObjectProps returns the properties of a calendar object.
+func davObjectProps(resource *davResource) []dav.Property {
+	return []dav.Property{
+		{Name: dav.DAV("resourcetype")},
+		dav.Text(dav.DAV("getetag"), resource.etag),
+		dav.Text(dav.DAV("getcontenttype"), "text/calendar; charset=utf-8; component=VTODO"),
+		dav.Text(dav.DAV("getcontentlength"), fmt.Sprint(len(resource.body))),
+		dav.Text(dav.DAV("getlastmodified"), resource.task.UpdatedAt.UTC().Format(http.TimeFormat)),
+		dav.Text(dav.CalDAV("calendar-data"), string(resource.body)),
+	}
+}
+
+// davSelect returns the response for a resource with the given properties: every one of them, only their names,
+// or the ones asked for, reporting the ones it doesn't have as missing.
+func davSelect(href string, props []dav.Property, names []xml.Name, all, namesOnly bool) dav.Response {
+	response := dav.Response{Href: href}
+	if all || namesOnly || len(names) == 0 {
+		for _, prop := range props {
+			if namesOnly {
+				prop.Value = ""
+			}
+			response.Found = append(response.Found, prop)
+		}
+		return response
+	}
+
+	for _, name := range names {
+		found := false
+		for _, prop := range props {
+			if prop.Name == name {
+				response.Found = append(response.Found, prop)
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.Missing = append(response.Missing, name)
+		}
+	}
+	return response
+}
+
+// davAsks reports whether the property is among the ones asked for.
+func davAsks(names []xml.Name, name xml.Name) bool {
+	for _, asked := range names {
+		if asked == name {
+			return true
+		}
+	}
+	return false
+}
+
+// davMultistatus responds with a multistatus response and a HTTP status code 207.
+func davMultistatus(c echo.Context, responses []dav.Response, syncToken string) error {
+	return c.Blob(http.StatusMultiStatus, echo.MIMEApplicationXMLCharsetUTF8, dav.Multistatus(responses, syncToken))
+}
+
+// davPreconditions checks the If-Match and If-None-Match headers of a request changing a calendar object,
+// which is nil when it doesn't exist yet.
+func davPreconditions(c echo.Context, resource *davResource) error {
+	ifMatch, ifNoneMatch := c.Request().Header.Get("If-Match"), c.Request().Header.Get("If-None-Match")
+	if ifMatch != "" && (resource == nil || !matchesETag(ifMatch, resource.etag)) {
+		return echo.NewHTTPError(http.StatusPreconditionFailed, "A tarefa foi alterada")
+	}
+	if ifNoneMatch != "" && resource != nil && matchesETag(ifNoneMatch, resource.etag) {
+		return echo.NewHTTPError(http.StatusPreconditionFailed, "A tarefa já existe")
+	}
+	return nil
+}
+
+// applyEntry copies the fields read from a VTODO into the task.
+func applyEntry(task *models.Tasks, entry *ical.Entry) {
+	task.Title = strings.TrimSpace(entry.Summary)
+	task.Description = entry.Description
+	task.DueDate = entry.Due
+	task.Reminder = entry.Reminder
+	task.Priority = entry.Priority
+	task.Recurrence = entry.Recurrence
+	if entry.Completed != nil && task.CompletedAt == nil {
+		task.CompletedAt = entry.Completed
+	}
+}
+
+// davStatus returns the status of the workflow a task in the current status, empty for new tasks, moves to
+// for the status of its VTODO. Statuses of the same kind are kept, so custom workflows aren't flattened:
+// completed to-dos go to done, or to the first terminal status of the workflow, and the ones that need action
+// are reopened into the initial status only when they were completed.
+func davStatus(workflow *models.Workflow, current, status string) string {
+	currentStatus, known := workflow.Status(current)
+	terminal := known && currentStatus.Terminal
+
+	switch status {
+	case "COMPLETED":
+		if terminal && current != models.TaskCancelled {
+			return current
+		}
+		if _, ok := workflow.Status(models.TaskDone); ok {
+			return models.TaskDone
+		}
+		for _, status := range workflow.Statuses {
+			if status.Terminal && status.Name != models.TaskCancelled {
+				return status.Name
+			}
+		}
+	case "CANCELLED":
+		if _, ok := workflow.Status(models.TaskCancelled); ok {
+			return models.TaskCancelled
+		}
+	case "IN-PROCESS":
+		if _, ok := workflow.Status(models.TaskInProgress); ok && (current == "" || current == workflow.Initial || terminal) {
+			return models.TaskInProgress
+		}
+	}
+	if terminal {
+		return workflow.Initial
+	}
+	return current
+}
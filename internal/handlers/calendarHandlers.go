@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/ical"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// GetCalendarFeed returns, with a HTTP status code 200, when the calendar feed of the authenticated user was created.
+// Users without a feed get a HTTP status code 404. The token itself can't be retrieved again, only regenerated.
+func (h *Handler) GetCalendarFeed(c echo.Context) (err error) {
+	feed, err := h.Users.FindCalendarFeed(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Feed de calendário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar o feed de calendário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar feed de calendário")
+	}
+	return c.JSON(http.StatusOK, feed)
+}
+
+// CreateCalendarFeed creates the calendar feed of the authenticated user or, when they already have one,
+// regenerates its token, so the previous link stops working.
+// If successful, it returns the token and the URL calendar applications subscribe to with a HTTP status code 201.
+// They are only ever returned here.
+func (h *Handler) CreateCalendarFeed(c echo.Context) (err error) {
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		h.logger(c).Error("Erro ao gerar token do feed de calendário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar feed de calendário")
+	}
+
+	feed := &models.CalendarFeed{UserID: middlewares.UserID(c), TokenHash: hash, CreatedAt: time.Now()}
+	if err := h.Users.SaveCalendarFeed(c.Request().Context(), feed); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao criar o feed de calendário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar feed de calendário")
+	}
+
+	url := c.Scheme() + "://" + c.Request().Host + "/calendar/feed/" + token + ".ics"
+	return c.JSON(http.StatusCreated, CalendarFeedResponse{CalendarFeed: *feed, Token: token, URL: url})
+}
+
+// DeleteCalendarFeed revokes the calendar feed of the authenticated user.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Feed Revogado".
+func (h *Handler) DeleteCalendarFeed(c echo.Context) (err error) {
+	if err := h.Users.DeleteCalendarFeed(c.Request().Context(), middlewares.UserID(c)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Feed de calendário não encontrado")
+		}
+		h.logger(c).Error("Erro ao revogar o feed de calendário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao revogar feed de calendário")
+	}
+	return c.JSON(http.StatusOK, "Feed Revogado")
+}
+
+// GetCalendarFeedEntries serves the calendar feed whose token comes from the route parameters, optionally ending in ".ics",
+// without any other authentication. It holds the tasks of the feed's owner with a due date, personal or assigned to them,
+// as events or, when the "kind" query parameter is "todo", as to-dos, along with their reminders and recurrence.
+// Unknown tokens return a HTTP status code 404, and the tokens of deactivated users a HTTP status code 401.
+// The response has an ETag, and requests whose If-None-Match holds it get a HTTP status code 304 without a body.
+func (h *Handler) GetCalendarFeedEntries(c echo.Context) (err error) {
+	component := ical.Event
+	switch c.QueryParam("kind") {
+	case "", "event":
+	case "todo":
+		component = ical.Todo
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "Tipo de calendário inválido")
+	}
+
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	feed, err := h.Users.FindCalendarFeedByTokenHash(c.Request().Context(), auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Feed de calendário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar o feed de calendário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar feed de calendário")
+	}
+
+	if err := h.CheckActive(c, feed.UserID); err != nil {
+		return err
+	}
+
+	// The token stands for the owner of the feed, so the tasks are the ones they can see
+	middlewares.SetUserID(c, feed.UserID)
+	tasks, err := h.userTasks(c)
+	if err != nil {
+		return err
+	}
+
+	body := ical.Calendar("NullTask", component, tasks)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", "private, no-cache")
+	if matchesETag(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// matchesETag reports whether the value of an If-None-Match header holds the ETag, compared weakly, or is "*".
+func matchesETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateChecklistItem adds an item at the end of the checklist of a task.
+// It expects a JSON object in the request body with the item's "title" and, optionally, whether it's already "done".
+// Changing the checklist of workspace tasks requires at least the editor role.
+// If successful, it returns the item and a HTTP status code 201.
+func (h *Handler) CreateChecklistItem(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	item := new(models.ChecklistItem)
+	if err := c.Bind(item); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	item.ID, item.TaskID, item.CreatedAt, item.UpdatedAt = 0, task.ID, time.Time{}, time.Time{}
+
+	if err := c.Validate(item); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.Tasks.AddChecklistItem(c.Request().Context(), item); err != nil {
+		h.logger(c).Error("Erro ao criar item da checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar item da checklist")
+	}
+	return c.JSON(http.StatusCreated, item)
+}
+
+// GetTaskChecklist returns, with a HTTP status code 200, the checklist of a task, in order.
+func (h *Handler) GetTaskChecklist(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	items, err := h.Tasks.ListChecklistItems(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar checklist")
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// ReorderChecklist changes the order of the checklist of a task.
+// It expects a JSON object in the request body with the "ids" of every item of the checklist, in the new order.
+// Lists missing items, repeating them or naming items of other tasks return a HTTP status code 400.
+// If successful, it returns the reordered checklist with a HTTP status code 200.
+func (h *Handler) ReorderChecklist(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	req := new(ChecklistOrderRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	items, err := h.Tasks.ListChecklistItems(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar checklist")
+	}
+
+	pending := map[uint]bool{}
+	for _, item := range items {
+		pending[item.ID] = true
+	}
+	for _, id := range req.IDs {
+		if !pending[id] {
+			return echo.NewHTTPError(http.StatusBadRequest, "A nova ordem deve conter cada item da checklist uma vez")
+		}
+		delete(pending, id)
+	}
+	if len(pending) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "A nova ordem deve conter cada item da checklist uma vez")
+	}
+
+	if err := h.Tasks.ReorderChecklist(c.Request().Context(), task.ID, req.IDs); err != nil {
+		h.logger(c).Error("Erro ao reordenar a checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao reordenar checklist")
+	}
+
+	items, err = h.Tasks.ListChecklistItems(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar checklist")
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+// UpdateChecklistItem renames a checklist item or checks it off.
+// It binds the request body onto the item, so fields left out keep their values.
+// The task, the position and the dates of the item can't be changed through this endpoint, see ReorderChecklist
+// for the position.
+// If successful, it returns the updated item with a HTTP status code 200.
+func (h *Handler) UpdateChecklistItem(c echo.Context) (err error) {
+	item, err := h.findChecklistItem(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	id, task, position, created := item.ID, item.TaskID, item.Position, item.CreatedAt
+	if err := c.Bind(item); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	item.ID, item.TaskID, item.Position, item.CreatedAt = id, task, position, created
+
+	if err := c.Validate(item); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.Tasks.UpdateChecklistItem(c.Request().Context(), item); err != nil {
+		h.logger(c).Error("Erro ao atualizar o item da checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar item da checklist")
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// DeleteChecklistItem removes an item from the checklist of its task.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Item Deletado".
+func (h *Handler) DeleteChecklistItem(c echo.Context) (err error) {
+	item, err := h.findChecklistItem(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Tasks.DeleteChecklistItem(c.Request().Context(), item.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar o item da checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar item da checklist")
+	}
+	return c.JSON(http.StatusOK, "Item Deletado")
+}
+
+// findChecklistItem loads the checklist item whose ID is in the "id" route parameter,
+// checking that the authenticated user can reach its task with the given role.
+// Items of tasks the user can't see are reported as not found.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) findChecklistItem(c echo.Context, role models.WorkspaceRole) (*models.ChecklistItem, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "ID de item inválido")
+	}
+
+	item, err := h.Tasks.FindChecklistItem(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Item não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar o item da checklist", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar item da checklist")
+	}
+
+	if _, err := h.taskAccess(c, item.TaskID, role); err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Item não encontrado")
+		}
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/markdown"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateComment writes a comment on a task.
+// It expects a JSON object in the request body with the Markdown "body" and, to answer another comment
+// of the same task, its "parent_id". Commenting requires at least the editor role on workspace tasks.
+// Users mentioned as @user@example.com who can see the task are recorded as mentions and notified
+// through a events.UserMentioned event, while the task's watchers get a events.CommentCreated event.
+// If successful, it returns the comment, with its body rendered to sanitized HTML, and a HTTP status code 201.
+func (h *Handler) CreateComment(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	req := new(CommentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if req.ParentID != nil {
+		parent, err := h.Comments.FindByID(c.Request().Context(), *req.ParentID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			h.logger(c).Error("Erro ao buscar o comentário", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar comentário")
+		}
+		if err != nil || parent.TaskID != task.ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "Comentário respondido inválido")
+		}
+	}
+
+	mentions, err := h.resolveMentions(c, task, req.Body)
+	if err != nil {
+		return err
+	}
+
+	comment := &models.Comment{
+		TaskID:   task.ID,
+		ParentID: req.ParentID,
+		AuthorID: middlewares.UserID(c),
+		Body:     req.Body,
+		Mentions: mentions,
+	}
+	if err := h.Comments.Create(c.Request().Context(), comment); err != nil {
+		h.logger(c).Error("Erro ao criar comentário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar comentário")
+	}
+
+	h.publishComment(c, task, comment, mentions)
+	return c.JSON(http.StatusCreated, presentComment(*comment))
+}
+
+// GetTaskComments returns, with a HTTP status code 200, the comments of a task arranged in threads:
+// the comments that don't answer another one, each with its replies nested in "replies".
+// Deleted comments are kept, without their body, so the replies they got are still reachable.
+func (h *Handler) GetTaskComments(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	comments, err := h.Comments.FindByTask(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar comentários", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar comentários")
+	}
+
+	return c.JSON(http.StatusOK, commentThreads(comments))
+}
+
+// UpdateComment edits the body of a comment. Only its author can edit it, while they can still see the task.
+// It expects a JSON object in the request body with the new Markdown "body". The previous body is kept in the
+// comment's history, and users mentioned for the first time are notified.
+// If successful, it returns the edited comment with a HTTP status code 200.
+func (h *Handler) UpdateComment(c echo.Context) (err error) {
+	comment, task, err := h.findComment(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != middlewares.UserID(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "Apenas o autor pode editar o comentário")
+	}
+
+	req := new(CommentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if req.Body == comment.Body {
+		return c.JSON(http.StatusOK, presentComment(*comment))
+	}
+
+	mentions, err := h.resolveMentions(c, task, req.Body)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]bool{}
+	for _, mention := range comment.Mentions {
+		previous[mention.UserID] = true
+	}
+	var added []models.CommentMention
+	for _, mention := range mentions {
+		if !previous[mention.UserID] {
+			added = append(added, mention)
+		}
+	}
+
+	revision := &models.CommentRevision{CommentID: comment.ID, Body: comment.Body, EditedBy: middlewares.UserID(c)}
+	now := time.Now()
+	comment.Body = req.Body
+	comment.EditedAt = &now
+	comment.Mentions = mentions
+
+	if err := h.Comments.Update(c.Request().Context(), comment, revision); err != nil {
+		h.logger(c).Error("Erro ao atualizar o comentário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar comentário")
+	}
+
+	for _, mention := range added {
+		h.publishMention(c, task, comment, mention.UserID)
+	}
+	return c.JSON(http.StatusOK, presentComment(*comment))
+}
+
+// DeleteComment soft deletes a comment. Its author and the owner of the task's workspace can delete it.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Comentário Deletado".
+func (h *Handler) DeleteComment(c echo.Context) (err error) {
+	comment, task, err := h.findComment(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != middlewares.UserID(c) {
+		if task.WorkspaceID == nil {
+			return echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+		}
+		if _, _, err := h.workspaceAccess(c, *task.WorkspaceID, models.RoleOwner); err != nil {
+			return err
+		}
+	}
+
+	if err := h.Comments.Delete(c.Request().Context(), comment.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar o comentário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar comentário")
+	}
+
+	return c.JSON(http.StatusOK, "Comentário Deletado")
+}
+
+// GetCommentHistory returns, with a HTTP status code 200, the bodies a comment had before each of its edits, oldest first.
+func (h *Handler) GetCommentHistory(c echo.Context) (err error) {
+	comment, _, err := h.findComment(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := h.Comments.ListRevisions(c.Request().Context(), comment.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar o histórico", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar histórico")
+	}
+	return c.JSON(http.StatusOK, revisions)
+}
+
+// findComment loads the comment whose ID is in the "id" route parameter, along with its task,
+// checking that the authenticated user can reach the task with the given role.
+// Deleted comments and comments on tasks the user can't see are reported as not found.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) findComment(c echo.Context, role models.WorkspaceRole) (*models.Comment, *models.Tasks, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "ID de comentário inválido")
+	}
+
+	comment, err := h.Comments.FindByID(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Comentário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar o comentário", "error", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar comentário")
+	}
+
+	task, err := h.taskAccess(c, comment.TaskID, role)
+	if err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Comentário não encontrado")
+		}
+		return nil, nil, err
+	}
+	return comment, task, nil
+}
+
+// resolveMentions turns the @mentions of the body into mention records.
+// Mentions of unknown e-mails and of users who can't see the task are ignored.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) resolveMentions(c echo.Context, task *models.Tasks, body string) ([]models.CommentMention, error) {
+	mentions := []models.CommentMention{}
+	for _, email := range markdown.Mentions(body) {
+		user, err := h.Users.FindByEmail(c.Request().Context(), email)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			h.logger(c).Error("Erro ao buscar usuário", "error", err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+		}
+
+		visible, err := h.canSeeTask(c, task, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if visible {
+			mentions = append(mentions, models.CommentMention{UserID: user.ID})
+		}
+	}
+	return mentions, nil
+}
+
+// publishComment publishes the events of a new comment: one events.CommentCreated for the watchers of the task
+// and one events.UserMentioned for each mentioned user. The author is never notified.
+func (h *Handler) publishComment(c echo.Context, task *models.Tasks, comment *models.Comment, mentions []models.CommentMention) {
+	recipients := []string{}
+	watchers, err := h.Tasks.ListWatchers(c.Request().Context(), task.ID)
+	if err != nil {
+		// The comment is already saved, the watchers just won't hear about it
+		h.logger(c).Error("Erro ao buscar observadores", "error", err)
+	}
+	for _, watcher := range watchers {
+		if watcher.UserID != comment.AuthorID {
+			recipients = append(recipients, watcher.UserID)
+		}
+	}
+
+	h.publish(c, events.Event{
+		Type:        events.CommentCreated,
+		ActorID:     comment.AuthorID,
+		UserID:      comment.AuthorID,
+		TaskID:      task.ID,
+		CommentID:   comment.ID,
+		WorkspaceID: task.WorkspaceID,
+		Recipients:  recipients,
+	})
+
+	for _, mention := range mentions {
+		h.publishMention(c, task, comment, mention.UserID)
+	}
+}
+
+// publishMention publishes a events.UserMentioned event for the user, unless they mentioned themselves.
+func (h *Handler) publishMention(c echo.Context, task *models.Tasks, comment *models.Comment, userID string) {
+	if userID == comment.AuthorID {
+		return
+	}
+	h.publish(c, events.Event{
+		Type:        events.UserMentioned,
+		ActorID:     comment.AuthorID,
+		UserID:      userID,
+		TaskID:      task.ID,
+		CommentID:   comment.ID,
+		WorkspaceID: task.WorkspaceID,
+		Recipients:  []string{userID},
+	})
+}
+
+// presentComment prepares a comment to be returned: the body is rendered to HTML,
+// and deleted comments lose their body and mentions.
+func presentComment(comment models.Comment) models.Comment {
+	if comment.DeletedAt.Valid {
+		comment.Body = ""
+		comment.BodyHTML = ""
+		comment.Mentions = []models.CommentMention{}
+		return comment
+	}
+	comment.BodyHTML = markdown.Render(comment.Body)
+	if comment.Mentions == nil {
+		comment.Mentions = []models.CommentMention{}
+	}
+	return comment
+}
+
+// commentThreads arranges the comments, in the order they were written, into threads.
+// Replies to comments missing from the list are treated as threads of their own.
+func commentThreads(comments []models.Comment) []models.Comment {
+	known := map[uint]bool{}
+	for _, comment := range comments {
+		known[comment.ID] = true
+	}
+
+	replies := map[uint][]models.Comment{}
+	roots := make([]models.Comment, 0)
+	for _, comment := range comments {
+		comment = presentComment(comment)
+		if comment.ParentID != nil && known[*comment.ParentID] {
+			replies[*comment.ParentID] = append(replies[*comment.ParentID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var attach func(comment models.Comment) models.Comment
+	attach = func(comment models.Comment) models.Comment {
+		for _, reply := range replies[comment.ID] {
+			comment.Replies = append(comment.Replies, attach(reply))
+		}
+		return comment
+	}
+	for i := range roots {
+		roots[i] = attach(roots[i])
+	}
+	return roots
+}
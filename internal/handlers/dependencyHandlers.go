@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/taskgraph"
+	"github.com/labstack/echo/v4"
+)
+
+// MaxCriticalPathTasks is how many tasks a single GetCriticalPath request can order.
+const MaxCriticalPathTasks = 500
+
+// AddDependency makes a task depend on another one, which must be completed before the task can be.
+// It expects a JSON object in the request body with the "depends_on_id" of the other task, which must be
+// in the same workspace. Adding dependencies requires at least the editor role on workspace tasks.
+// A dependency that would close a cycle, including a task depending on itself, returns a HTTP status code 409,
+// as does adding the same dependency twice.
+// If successful, it returns the dependency with a HTTP status code 201.
+func (h *Handler) AddDependency(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	req := new(DependencyRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	dependsOn, err := h.taskAccess(c, req.DependsOnID, models.RoleViewer)
+	if err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+			return echo.NewHTTPError(http.StatusBadRequest, "Dependência inválida")
+		}
+		return err
+	}
+	if !sameWorkspace(task, dependsOn) {
+		return echo.NewHTTPError(http.StatusBadRequest, "A dependência deve estar no mesmo workspace")
+	}
+
+	cycle, err := h.dependsOn(c, dependsOn.ID, task.ID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return echo.NewHTTPError(http.StatusConflict, "A dependência criaria um ciclo")
+	}
+
+	dependency := &models.TaskDependency{TaskID: task.ID, DependsOnID: dependsOn.ID, CreatedBy: middlewares.UserID(c)}
+	if err := h.Tasks.AddDependency(c.Request().Context(), dependency); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return echo.NewHTTPError(http.StatusConflict, "A tarefa já depende desta tarefa")
+		}
+		h.logger(c).Error("Erro ao criar a dependência", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar dependência")
+	}
+	return c.JSON(http.StatusCreated, dependency)
+}
+
+// RemoveDependency stops a task from depending on the task whose ID is in the "dependsOnId" route parameter.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Dependência Removida".
+func (h *Handler) RemoveDependency(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	dependsOnID, err := strconv.ParseUint(c.Param("dependsOnId"), 10, 0)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID de tarefa inválido")
+	}
+
+	if err := h.Tasks.RemoveDependency(c.Request().Context(), task.ID, uint(dependsOnID)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Dependência não encontrada")
+		}
+		h.logger(c).Error("Erro ao remover a dependência", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao remover dependência")
+	}
+	return c.JSON(http.StatusOK, "Dependência Removida")
+}
+
+// GetTaskDependencies returns, with a HTTP status code 200, the tasks a task depends on.
+func (h *Handler) GetTaskDependencies(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.Tasks.FindDependencies(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar dependências", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar dependências")
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// GetTaskDependents returns, with a HTTP status code 200, the tasks that depend on a task.
+func (h *Handler) GetTaskDependents(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.Tasks.FindDependents(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar dependências", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar dependências")
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// GetCriticalPath orders a set of tasks by their dependencies.
+// It expects the comma separated IDs of the tasks in the "ids" query parameter, up to MaxCriticalPathTasks of them,
+// and only takes into account the dependencies between those tasks.
+// It returns, with a HTTP status code 200, every task in topological order, each after the tasks it depends on,
+// and the critical path: the chain of dependencies holding the most unfinished tasks, which is the least
+// number of tasks that still have to be done one after the other. Completed tasks don't count towards its length.
+// Tasks the user can't see return a HTTP status code 404.
+func (h *Handler) GetCriticalPath(c echo.Context) (err error) {
+	ids, ok := parseIDs(c.QueryParam("ids"))
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID de tarefa inválido")
+	}
+	if len(ids) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Informe as tarefas")
+	}
+	if len(ids) > MaxCriticalPathTasks {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Informe no máximo %d tarefas", MaxCriticalPathTasks))
+	}
+
+	tasks := map[uint]*models.Tasks{}
+	for _, id := range ids {
+		task, err := h.taskAccess(c, id, models.RoleViewer)
+		if err != nil {
+			return err
+		}
+		tasks[id] = task
+	}
+
+	dependencies, err := h.Tasks.ListDependenciesAmong(c.Request().Context(), ids)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar dependências", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar dependências")
+	}
+	edges := make([]taskgraph.Edge, len(dependencies))
+	for i, dependency := range dependencies {
+		edges[i] = taskgraph.Edge{Task: dependency.TaskID, DependsOn: dependency.DependsOnID}
+	}
+
+	graph := taskgraph.New(ids, edges)
+	order, err := graph.Sort()
+	if err != nil {
+		h.logger(c).Error("Dependências em ciclo", "error", err, "tasks", ids)
+		return echo.NewHTTPError(http.StatusConflict, "As dependências formam um ciclo")
+	}
+	path, err := graph.CriticalPath(func(id uint) int {
+		if tasks[id].CompletedAt != nil {
+			return 0
+		}
+		return 1
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, "As dependências formam um ciclo")
+	}
+
+	res := CriticalPathResponse{Order: make([]models.Tasks, len(order)), CriticalPath: make([]models.Tasks, len(path))}
+	for i, id := range order {
+		res.Order[i] = *tasks[id]
+	}
+	for i, id := range path {
+		res.CriticalPath[i] = *tasks[id]
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// dependsOn tells whether the task from depends on the task target, directly or through other tasks.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) dependsOn(c echo.Context, from, target uint) (bool, error) {
+	seen := map[uint]bool{from: true}
+	queue := []uint{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == target {
+			return true, nil
+		}
+
+		dependencies, err := h.Tasks.FindDependencies(c.Request().Context(), id)
+		if err != nil {
+			h.logger(c).Error("Erro ao buscar dependências", "error", err)
+			return false, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar dependências")
+		}
+		for _, dependency := range dependencies {
+			if !seen[dependency.ID] {
+				seen[dependency.ID] = true
+				queue = append(queue, dependency.ID)
+			}
+		}
+	}
+	return false, nil
+}
+
+// parseIDs parses a comma separated list of IDs, dropping the repeated ones.
+// It reports false when any of them isn't a valid ID.
+func parseIDs(list string) ([]uint, bool) {
+	var ids []uint
+	seen := map[uint]bool{}
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(field, 10, 0)
+		if err != nil {
+			return nil, false
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, true
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// StreamHeartbeat is how often StreamEvents writes a comment to an idle stream, so proxies don't close the connection.
+const StreamHeartbeat = 30 * time.Second
+
+// StreamEvents follows the events of the authenticated user as server-sent events: the ones they caused, from any client,
+// and the ones they are a recipient of, such as the changes to the tasks of their workspaces.
+// Each event is sent with its type as the event name and the events.Event as JSON data.
+// The stream ends when the client disconnects or falls too far behind, in which case it should fetch its tasks again and reconnect.
+// Without an events.Stream it returns a HTTP status code 503.
+func (h *Handler) StreamEvents(c echo.Context) error {
+	if h.Stream == nil {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Transmissão de eventos indisponível")
+	}
+	received, stop := h.Stream.Listen(middlewares.UserID(c))
+	defer stop()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, ": connected\n\n")
+	res.Flush()
+
+	heartbeat := time.NewTicker(StreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(res, ": heartbeat\n\n")
+		case event, ok := <-received:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger(c).Error("Erro ao codificar evento", "error", err)
+				continue
+			}
+			fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		res.Flush()
+	}
+}
+
+// publishTask publishes an event about the task to the users who can see it: its owner, or the members of its workspace.
+// Whoever made the change is not among the recipients.
+func (h *Handler) publishTask(c echo.Context, eventType events.Type, task *models.Tasks) {
+	if h.Events == nil {
+		return
+	}
+	actorID := middlewares.UserID(c)
+	recipients := []string{}
+	if task.WorkspaceID == nil {
+		if task.UserID != actorID {
+			recipients = append(recipients, task.UserID)
+		}
+	} else {
+		members, err := h.Workspaces.ListMembers(c.Request().Context(), *task.WorkspaceID)
+		if err != nil {
+			// The change is already saved, the members just won't hear about it
+			h.logger(c).Error("Erro ao buscar membros", "error", err)
+		}
+		for _, member := range members {
+			if member.UserID != actorID {
+				recipients = append(recipients, member.UserID)
+			}
+		}
+	}
+
+	h.publish(c, events.Event{
+		Type:        eventType,
+		ActorID:     actorID,
+		UserID:      task.UserID,
+		TaskID:      task.ID,
+		WorkspaceID: task.WorkspaceID,
+		Recipients:  recipients,
+	})
+}
@@ -2,10 +2,70 @@ package handlers
 
 import (
 	"github.com/charmbracelet/log"
-	"gorm.io/gorm"
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/mail"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+	"github.com/devgugga/NullTask/internal/transfer"
+	"github.com/labstack/echo/v4"
 )
 
+// Handler groups the HTTP handlers of the API and the dependencies they share.
+// Storage is reached only through the repository interfaces, so handlers can be
+// exercised against the in-memory repositories as well as the database ones.
 type Handler struct {
-	DB  *gorm.DB
-	Log *log.Logger
+	Users      repository.UserRepository
+	Tasks      repository.TaskRepository
+	Workspaces repository.WorkspaceRepository
+	Comments   repository.CommentRepository
+	Labels     repository.LabelRepository
+	Tokens     *auth.TokenManager
+	Log        *log.Logger
+
+	// Events receives what happens to tasks, such as assignments, for the notification system to deliver.
+	// It may be nil, in which case nothing is published.
+	Events events.Publisher
+	// Stream hands the published events over to the clients following them live.
+	// It may be nil, in which case the event stream is unavailable.
+	Stream *events.Stream
+
+	// Mailer delivers the e-mails of the server, such as the workspace invitations.
+	Mailer mail.Mailer
+
+	// Attachments holds the metadata of the uploaded files, whose contents are kept in Blobs.
+	Attachments repository.AttachmentRepository
+	Blobs       storage.BlobStore
+	// AttachmentQuota is how many bytes of attachments each user can upload.
+	AttachmentQuota int64
+	// Thumbnails generates the previews of image attachments in the background.
+	// It may be nil, in which case attachments get no thumbnails.
+	Thumbnails *thumbnails.Worker
+
+	// Calendar keeps how CalDAV clients wrote the tasks and the states of the collection they synchronized.
+	Calendar repository.CalendarRepository
+
+	// Imports keeps the import jobs, which Importer runs in the background.
+	// Importer may be nil, in which case the jobs stay pending until an importer starts.
+	Imports  repository.ImportRepository
+	Importer *transfer.Importer
+}
+
+// logger returns the per-request logger attached by the RequestLogger middleware,
+// which carries the request ID, the route and the user ID.
+// It falls back to the application logger when the middleware isn't registered.
+func (h *Handler) logger(c echo.Context) *log.Logger {
+	if logger, ok := c.Get(middlewares.LoggerKey).(*log.Logger); ok {
+		return logger
+	}
+	return h.Log
+}
+
+// publish hands the event to the Events publisher, when there's one.
+func (h *Handler) publish(c echo.Context, event events.Event) {
+	if h.Events != nil {
+		h.Events.Publish(c.Request().Context(), event)
+	}
 }
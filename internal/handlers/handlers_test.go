@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+const testPassword = "secret-password"
+
+// testValidator validates the request bodies as config.CustomValidator does.
+type testValidator struct {
+	validator *validator.Validate
+}
+
+func (v *testValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+// newTestServer returns the user and task routes of a Handler on the in-memory repositories,
+// registered as the router package does.
+func newTestServer(t *testing.T) *echo.Echo {
+	t.Helper()
+	return testRoutes(newTestHandler(t))
+}
+
+// newTestHandler returns a Handler on the in-memory repositories, storing attachments in a temporary directory.
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := repository.NewMemoryTaskRepository()
+	return &Handler{
+		Users:       repository.NewMemoryUserRepository(),
+		Tasks:       tasks,
+		Workspaces:  repository.NewMemoryWorkspaceRepository(tasks),
+		Comments:    repository.NewMemoryCommentRepository(),
+		Labels:      repository.NewMemoryLabelRepository(),
+		Tokens:      auth.NewTokenManager("test-secret", time.Hour),
+		Log:         log.New(&bytes.Buffer{}),
+		Attachments: repository.NewMemoryAttachmentRepository(),
+		Blobs:       blobs,
+		Calendar:    repository.NewMemoryCalendarRepository(),
+		Imports:     repository.NewMemoryImportRepository(),
+	}
+}
+
+// testRoutes registers the user and task routes of the handler as the router package does.
+func testRoutes(h *Handler) *echo.Echo {
+	e := echo.New()
+	e.Validator = &testValidator{validator: validator.New()}
+	authenticate := middlewares.Authenticate(h.Tokens, h.CheckActive)
+
+	users := e.Group("/users")
+	users.POST("/create", h.CreateUser)
+	users.POST("/login", h.Login)
+	users.PUT("/update/id/:id", h.UpdateUserById, authenticate)
+	users.DELETE("/delete/id/:id", h.DeleteUserById, authenticate)
+
+	tasksGroup := e.Group("/tasks", authenticate)
+	tasksGroup.POST("/create", h.CreateTask)
+	tasksGroup.GET("/get/id/:id", h.GetTaskById)
+	tasksGroup.GET("/get/user/:userId", h.GetTasksByUser)
+	tasksGroup.PUT("/update/id/:id", h.UpdateTaskById)
+	tasksGroup.DELETE("/delete/id/:id", h.DeleteTaskById)
+	tasksGroup.POST("/attach/id/:id", h.UploadAttachment)
+	return e
+}
+
+// serve sends the request, with the body encoded as JSON and the token as bearer token when they're given.
+func serve(e *echo.Echo, method, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("expected the status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body.String(), err)
+	}
+}
+
+// newUser creates a user with the given e-mail and returns them along with a token of theirs.
+func newUser(t *testing.T, e *echo.Echo, email string) (models.User, string) {
+	t.Helper()
+
+	rec := serve(e, http.MethodPost, "/users/create", map[string]interface{}{
+		"name": "Test", "email": email, "password": testPassword, "age": 30,
+	}, "")
+	expectStatus(t, rec, http.StatusCreated)
+	var user models.User
+	decode(t, rec, &user)
+
+	rec = serve(e, http.MethodPost, "/users/login", LoginRequest{Email: email, Password: testPassword}, "")
+	expectStatus(t, rec, http.StatusOK)
+	var login LoginResponse
+	decode(t, rec, &login)
+	return user, login.Token
+}
+
+func TestUsers(t *testing.T) {
+	e := newTestServer(t)
+
+	expectStatus(t, serve(e, http.MethodPost, "/users/create", map[string]interface{}{
+		"name": "Minor", "email": "minor@nulltask.test", "password": testPassword, "age": 17,
+	}, ""), http.StatusBadRequest)
+
+	alice, aliceToken := newUser(t, e, "alice@nulltask.test")
+	bob, _ := newUser(t, e, "bob@nulltask.test")
+	if alice.ID == "" || alice.ID == bob.ID {
+		t.Fatalf("expected distinct IDs, got %q and %q", alice.ID, bob.ID)
+	}
+
+	expectStatus(t, serve(e, http.MethodPost, "/users/create", map[string]interface{}{
+		"name": "Again", "email": alice.Email, "password": testPassword, "age": 30,
+	}, ""), http.StatusConflict)
+	expectStatus(t, serve(e, http.MethodPost, "/users/login", LoginRequest{Email: alice.Email, Password: "wrong-password"}, ""), http.StatusUnauthorized)
+	expectStatus(t, serve(e, http.MethodPost, "/users/login", LoginRequest{Email: "nobody@nulltask.test", Password: testPassword}, ""), http.StatusUnauthorized)
+
+	expectStatus(t, serve(e, http.MethodPut, "/users/update/id/"+bob.ID, map[string]interface{}{
+		"name": "Bobby", "email": bob.Email, "password": testPassword, "age": 30,
+	}, aliceToken), http.StatusForbidden)
+	expectStatus(t, serve(e, http.MethodDelete, "/users/delete/id/"+bob.ID, nil, aliceToken), http.StatusForbidden)
+	expectStatus(t, serve(e, http.MethodDelete, "/users/delete/id/"+bob.ID, nil, ""), http.StatusUnauthorized)
+
+	expectStatus(t, serve(e, http.MethodDelete, "/users/delete/id/"+alice.ID, nil, aliceToken), http.StatusOK)
+	expectStatus(t, serve(e, http.MethodPost, "/users/login", LoginRequest{Email: alice.Email, Password: testPassword}, ""), http.StatusUnauthorized)
+	expectStatus(t, serve(e, http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Read"}, aliceToken), http.StatusUnauthorized)
+}
+
+func TestTasks(t *testing.T) {
+	e := newTestServer(t)
+	alice, aliceToken := newUser(t, e, "alice@nulltask.test")
+	_, bobToken := newUser(t, e, "bob@nulltask.test")
+
+	expectStatus(t, serve(e, http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Read", "user_id": alice.ID}, ""), http.StatusUnauthorized)
+	expectStatus(t, serve(e, http.MethodPost, "/tasks/create", map[string]interface{}{"priority": 1}, aliceToken), http.StatusBadRequest)
+
+	rec := serve(e, http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Read", "priority": 2}, aliceToken)
+	expectStatus(t, rec, http.StatusCreated)
+	var task models.Tasks
+	decode(t, rec, &task)
+	if task.ID == 0 || task.UserID != alice.ID {
+		t.Fatalf("expected a task of alice, got %+v", task)
+	}
+	path := fmt.Sprintf("/tasks/%%s/id/%d", task.ID)
+
+	expectStatus(t, serve(e, http.MethodGet, fmt.Sprintf(path, "get"), nil, aliceToken), http.StatusOK)
+	expectStatus(t, serve(e, http.MethodGet, fmt.Sprintf(path, "get"), nil, bobToken), http.StatusNotFound)
+	expectStatus(t, serve(e, http.MethodGet, "/tasks/get/user/"+alice.ID, nil, bobToken), http.StatusForbidden)
+	expectStatus(t, serve(e, http.MethodDelete, fmt.Sprintf(path, "delete"), nil, bobToken), http.StatusNotFound)
+	expectStatus(t, serve(e, http.MethodGet, "/tasks/get/id/abc", nil, aliceToken), http.StatusBadRequest)
+
+	rec = serve(e, http.MethodPut, fmt.Sprintf(path, "update"), map[string]interface{}{"title": "Read a book", "priority": 3}, aliceToken)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &task)
+	if task.Title != "Read a book" || task.Priority != 3 {
+		t.Fatalf("expected the task to be updated, got %+v", task)
+	}
+
+	expectStatus(t, serve(e, http.MethodDelete, fmt.Sprintf(path, "delete"), nil, aliceToken), http.StatusOK)
+	expectStatus(t, serve(e, http.MethodGet, fmt.Sprintf(path, "get"), nil, aliceToken), http.StatusNotFound)
+}
+
+// TestConcurrentTasks creates tasks from several goroutines at once, which, with -race, checks that the handlers
+// and the in-memory repositories they share can serve concurrent requests.
+func TestConcurrentTasks(t *testing.T) {
+	e := newTestServer(t)
+	alice, token := newUser(t, e, "alice@nulltask.test")
+
+	const count = 20
+	ids := make(chan uint, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := serve(e, http.MethodPost, "/tasks/create", map[string]interface{}{"title": fmt.Sprintf("Task %d", i)}, token)
+			var task models.Tasks
+			if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &task) != nil {
+				t.Errorf("creating task %d: %d %s", i, rec.Code, rec.Body.String())
+				return
+			}
+			ids <- task.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[uint]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("expected distinct task IDs, got %d twice", id)
+		}
+		seen[id] = true
+	}
+
+	rec := serve(e, http.MethodGet, "/tasks/get/user/"+alice.ID, nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	if len(tasks) != count || len(seen) != count {
+		t.Fatalf("expected %d tasks, got %d listed and %d created", count, len(tasks), len(seen))
+	}
+}
+
+// barrierStore is a BlobStore whose Put only returns once every expected upload has reached it,
+// and which keeps track of the blobs stored.
+type barrierStore struct {
+	storage.BlobStore
+	arrived sync.WaitGroup
+	mu      sync.Mutex
+	keys    map[string]bool
+}
+
+func (s *barrierStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	s.arrived.Done()
+	s.arrived.Wait()
+	s.mu.Lock()
+	s.keys[key] = true
+	s.mu.Unlock()
+	return s.BlobStore.Put(ctx, key, r, size, contentType)
+}
+
+func (s *barrierStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.keys, key)
+	s.mu.Unlock()
+	return s.BlobStore.Delete(ctx, key)
+}
+
+// TestConcurrentUploads has several uploads, that fit in the quota one by one but not all together,
+// read the usage of their uploader before any of them is saved.
+func TestConcurrentUploads(t *testing.T) {
+	const count, size = 6, 1 << 10
+	h := newTestHandler(t)
+	h.AttachmentQuota = 2 * size
+	blobs := &barrierStore{BlobStore: h.Blobs, keys: map[string]bool{}}
+	blobs.arrived.Add(count)
+	h.Blobs = blobs
+	e := testRoutes(h)
+	_, token := newUser(t, e, "alice@nulltask.test")
+	rec := serve(e, http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Invoices"}, token)
+	expectStatus(t, rec, http.StatusCreated)
+	var task models.Tasks
+	decode(t, rec, &task)
+
+	codes := make(chan int, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			part, _ := form.CreateFormFile("file", fmt.Sprintf("invoice-%d.txt", i))
+			part.Write(bytes.Repeat([]byte("a"), size))
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/attach/id/%d", task.ID), &body)
+			req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			codes <- rec.Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	uploaded := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			uploaded++
+		case http.StatusRequestEntityTooLarge:
+		default:
+			t.Fatalf("expected the uploads to succeed or exceed the quota, got %d", code)
+		}
+	}
+	if uploaded != 2 || len(blobs.keys) != 2 {
+		t.Fatalf("expected 2 uploads to fill the quota, %d succeeded and %d files are stored", uploaded, len(blobs.keys))
+	}
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateLabel handles the creation of a new label owned by the authenticated user.
+// It expects a JSON object in the request body with the label's "name" and, optionally, its "color"
+// as a hexadecimal color such as #ff8800. Labels without a color get models.DefaultLabelColor.
+// When the body carries a "workspace_id", the label is created in that workspace and the user must be
+// at least an editor of it. Otherwise it's a personal label.
+// A name already used by another label of the same workspace, or by another personal label of the user,
+// returns a HTTP status code 409.
+// If successful, it returns the label with a HTTP status code 201.
+func (h *Handler) CreateLabel(c echo.Context) (err error) {
+	label := new(models.Label)
+	if err := c.Bind(label); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	label.ID, label.CreatedAt, label.UpdatedAt = 0, time.Time{}, time.Time{}
+	label.UserID = middlewares.UserID(c)
+	if label.Color == "" {
+		label.Color = models.DefaultLabelColor
+	}
+
+	if err := c.Validate(label); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	if label.WorkspaceID != nil {
+		if _, _, err := h.workspaceAccess(c, *label.WorkspaceID, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := h.checkLabelName(c, label); err != nil {
+		return err
+	}
+
+	if err := h.Labels.Create(c.Request().Context(), label); err != nil {
+		h.logger(c).Error("Erro ao criar etiqueta", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar etiqueta")
+	}
+	return c.JSON(http.StatusCreated, label)
+}
+
+// GetMyLabels returns, with a HTTP status code 200, the personal labels of the authenticated user, ordered by name.
+func (h *Handler) GetMyLabels(c echo.Context) (err error) {
+	labels, err := h.Labels.FindByUser(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar etiquetas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar etiquetas")
+	}
+	return c.JSON(http.StatusOK, labels)
+}
+
+// GetWorkspaceLabels returns, with a HTTP status code 200, the labels of a workspace, ordered by name.
+func (h *Handler) GetWorkspaceLabels(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	labels, err := h.Labels.FindByWorkspace(c.Request().Context(), workspace.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar etiquetas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar etiquetas")
+	}
+	return c.JSON(http.StatusOK, labels)
+}
+
+// GetLabelById returns the label whose ID is in the route, with a HTTP status code 200.
+func (h *Handler) GetLabelById(c echo.Context) (err error) {
+	label, err := h.findLabel(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, label)
+}
+
+// UpdateLabelById renames or recolors a label. Workspace labels can only be updated by editors.
+// The ID, the owner, the workspace and the dates of the label can't be changed through this endpoint,
+// and a name already used in the same place returns a HTTP status code 409.
+// If successful, it returns the label with a HTTP status code 200.
+func (h *Handler) UpdateLabelById(c echo.Context) (err error) {
+	label, err := h.findLabel(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	id, owner, workspace, created := label.ID, label.UserID, label.WorkspaceID, label.CreatedAt
+	if err := c.Bind(label); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	label.ID, label.UserID, label.WorkspaceID, label.CreatedAt = id, owner, workspace, created
+	if label.Color == "" {
+		label.Color = models.DefaultLabelColor
+	}
+
+	if err := c.Validate(label); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	if err := h.checkLabelName(c, label); err != nil {
+		return err
+	}
+
+	if err := h.Labels.Update(c.Request().Context(), label); err != nil {
+		h.logger(c).Error("Erro ao atualizar a etiqueta", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar etiqueta")
+	}
+	return c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabelById deletes a label, removing it from every task it tagged. Workspace labels can only be deleted by editors.
+// If successful, it returns a HTTP status code 200 with the message "Etiqueta Deletada".
+func (h *Handler) DeleteLabelById(c echo.Context) (err error) {
+	label, err := h.findLabel(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Labels.Delete(c.Request().Context(), label.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar a etiqueta", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar etiqueta")
+	}
+	return c.JSON(http.StatusOK, "Etiqueta Deletada")
+}
+
+// TagTasks tags several tasks with a label at once.
+// It expects a JSON object in the request body with the "task_ids" to tag, which the user must be able to edit
+// and which must be in the label's workspace, or be personal tasks for a personal label.
+// Either every task is tagged or, when any of them can't be, none is. Tasks already tagged stay as they are.
+// If successful, it returns the tagged tasks with a HTTP status code 200.
+func (h *Handler) TagTasks(c echo.Context) (err error) {
+	label, tasks, err := h.labelTasks(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Labels.Tag(c.Request().Context(), label.ID, taskIDs(tasks)); err != nil {
+		h.logger(c).Error("Erro ao etiquetar as tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao etiquetar tarefas")
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// UntagTasks removes a label from several tasks at once, with the same body and checks as TagTasks.
+// If successful, it returns the untagged tasks with a HTTP status code 200.
+func (h *Handler) UntagTasks(c echo.Context) (err error) {
+	label, tasks, err := h.labelTasks(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Labels.Untag(c.Request().Context(), label.ID, taskIDs(tasks)); err != nil {
+		h.logger(c).Error("Erro ao remover a etiqueta das tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao remover etiqueta das tarefas")
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// GetTaskLabels returns, with a HTTP status code 200, the labels tagging a task, ordered by name.
+func (h *Handler) GetTaskLabels(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	labels, err := h.Labels.FindByTask(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar etiquetas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar etiquetas")
+	}
+	return c.JSON(http.StatusOK, labels)
+}
+
+// findLabel loads the label whose ID is in the "id" route parameter.
+// Personal labels are only reachable by their owner, and workspace labels by the workspace members with at least the given role.
+// Labels the user can't see are reported as not found, while members without the required role get a HTTP status code 403.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) findLabel(c echo.Context, role models.WorkspaceRole) (*models.Label, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "ID de etiqueta inválido")
+	}
+
+	label, err := h.Labels.FindByID(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Etiqueta não encontrada")
+		}
+		h.logger(c).Error("Erro ao buscar a etiqueta", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar etiqueta")
+	}
+	if label.WorkspaceID != nil {
+		if _, _, err := h.workspaceAccess(c, *label.WorkspaceID, role); err != nil {
+			if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+				return nil, echo.NewHTTPError(http.StatusNotFound, "Etiqueta não encontrada")
+			}
+			return nil, err
+		}
+		return label, nil
+	}
+	if label.UserID != middlewares.UserID(c) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Etiqueta não encontrada")
+	}
+	return label, nil
+}
+
+// checkLabelName checks that no other label in the same place as the label, its workspace or the personal
+// labels of its owner, has the same name, ignoring case.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) checkLabelName(c echo.Context, label *models.Label) error {
+	var labels []models.Label
+	var err error
+	if label.WorkspaceID != nil {
+		labels, err = h.Labels.FindByWorkspace(c.Request().Context(), *label.WorkspaceID)
+	} else {
+		labels, err = h.Labels.FindByUser(c.Request().Context(), label.UserID)
+	}
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar etiquetas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar etiquetas")
+	}
+
+	for _, other := range labels {
+		if other.ID != label.ID && strings.EqualFold(other.Name, label.Name) {
+			return echo.NewHTTPError(http.StatusConflict, "Já existe uma etiqueta com esse nome")
+		}
+	}
+	return nil
+}
+
+// labelTasks loads the label whose ID is in the route and the tasks in the LabelTasksRequest body,
+// checking that the user can edit both and that the label can tag every task.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) labelTasks(c echo.Context) (*models.Label, []models.Tasks, error) {
+	label, err := h.findLabel(c, models.RoleEditor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := new(LabelTasksRequest)
+	if err := c.Bind(req); err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Dados inválidos")
+	}
+	if err := c.Validate(req); err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	tasks := make([]models.Tasks, 0, len(req.TaskIDs))
+	seen := map[uint]bool{}
+	for _, id := range req.TaskIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		task, err := h.taskAccess(c, id, models.RoleEditor)
+		if err != nil {
+			if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+				return nil, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Tarefa inválida: %d", id))
+			}
+			return nil, nil, err
+		}
+		if !sameLabelWorkspace(label, task) {
+			return nil, nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("A tarefa %d não está no workspace da etiqueta", id))
+		}
+		tasks = append(tasks, *task)
+	}
+	return label, tasks, nil
+}
+
+// filterByLabels keeps the tasks tagged with the labels whose comma separated IDs are in the "labels" query parameter:
+// with every one of them when the "match" query parameter is "all", the default, or with any of them when it's "any".
+// Without the "labels" query parameter the tasks are returned as they are.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) filterByLabels(c echo.Context, tasks []models.Tasks) ([]models.Tasks, error) {
+	if c.QueryParam("labels") == "" {
+		return tasks, nil
+	}
+	ids, ok := parseIDs(c.QueryParam("labels"))
+	if !ok || len(ids) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "ID de etiqueta inválido")
+	}
+
+	var all bool
+	switch c.QueryParam("match") {
+	case "", "all":
+		all = true
+	case "any":
+		all = false
+	default:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Modo de filtro inválido, use all ou any")
+	}
+
+	tagged, err := h.Labels.FindTaggedTaskIDs(c.Request().Context(), ids, all)
+	if err != nil {
+		h.logger(c).Error("Erro ao filtrar as tarefas por etiqueta", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+	keep := make(map[uint]bool, len(tagged))
+	for _, id := range tagged {
+		keep[id] = true
+	}
+
+	filtered := make([]models.Tasks, 0, len(tagged))
+	for _, task := range tasks {
+		if keep[task.ID] {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered, nil
+}
+
+// sameLabelWorkspace tells whether the label can tag the task: both must be in the same workspace,
+// or be the personal label and task of the same user.
+func sameLabelWorkspace(label *models.Label, task *models.Tasks) bool {
+	if label.WorkspaceID == nil || task.WorkspaceID == nil {
+		return label.WorkspaceID == nil && task.WorkspaceID == nil && label.UserID == task.UserID
+	}
+	return *label.WorkspaceID == *task.WorkspaceID
+}
+
+// taskIDs returns the IDs of the tasks.
+func taskIDs(tasks []models.Tasks) []uint {
+	ids := make([]uint, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// GetPreferences returns, with a HTTP status code 200, the preferences of the authenticated user,
+// or the default ones when they never saved theirs.
+func (h *Handler) GetPreferences(c echo.Context) (err error) {
+	preferences, err := h.userPreferences(c, middlewares.UserID(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences changes the preferences of the authenticated user.
+// It expects a JSON object in the request body with the preferences to change, the others keep their values:
+// the IANA "timezone", the BCP 47 "locale", the "week_start" day, the "default_reminder" in minutes before
+// the due date, null to remind nothing by default, and the "notification_channels" among in_app, email and push.
+// If successful, it returns the preferences with a HTTP status code 200.
+func (h *Handler) UpdatePreferences(c echo.Context) (err error) {
+	userID := middlewares.UserID(c)
+	preferences, err := h.userPreferences(c, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Bind(preferences); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	preferences.UserID = userID
+	if preferences.NotificationChannels == nil {
+		preferences.NotificationChannels = []string{}
+	}
+	if err := c.Validate(preferences); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.Users.SavePreferences(c.Request().Context(), preferences); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao salvar as preferências", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao salvar preferências")
+	}
+	return c.JSON(http.StatusOK, preferences)
+}
+
+// userPreferences returns the preferences of the user, or the default ones when they never saved theirs.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) userPreferences(c echo.Context, userID string) (*models.Preferences, error) {
+	preferences, err := h.Users.FindPreferences(c.Request().Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.DefaultPreferences(userID), nil
+	}
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar as preferências", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar preferências")
+	}
+	return preferences, nil
+}
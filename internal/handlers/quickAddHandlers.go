@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/quickadd"
+	"github.com/labstack/echo/v4"
+)
+
+// QuickAddTask creates a task from a line of text, as typed in the quick-add box:
+// "Pay rent tomorrow 9am #finance !high every month" becomes the task "Pay rent", due tomorrow at 9am
+// in the time zone of the request or, without one, of the user's preferences, with a high priority, repeating every month
+// and tagged with the "finance" label.
+// It expects a JSON object in the request body with the "text", optionally the IANA "timezone" relative dates are read in,
+// and, to create the task in a workspace, its "workspace_id".
+// Labels are looked up by name among the user's personal labels, or the workspace's, and created when missing.
+// The response carries the words that looked like dates, times or markers but couldn't be understood,
+// so they can be highlighted, while recurrences that can't repeat, such as "every 0 days", return a HTTP status code 400.
+// With the "preview" query parameter set to true, nothing is saved and the response
+// shows the task and labels that would be created, with a HTTP status code 200.
+// If successful, it returns the task, its labels and the unparsed words with a HTTP status code 201.
+func (h *Handler) QuickAddTask(c echo.Context) (err error) {
+	req := new(QuickAddRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	userID := middlewares.UserID(c)
+	preferences, err := h.userPreferences(c, userID)
+	if err != nil {
+		return err
+	}
+
+	// The validation already checked that the time zone of the request loads
+	loc := preferences.Location()
+	if req.Timezone != "" {
+		loc, _ = time.LoadLocation(req.Timezone)
+	}
+
+	parsed, err := quickadd.Parse(req.Text, time.Now(), loc, preferences.FirstDay())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "A recorrência deve se repetir a cada 1 ou mais unidades de tempo")
+	}
+	if strings.TrimSpace(parsed.Title) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "O texto não deixa um título para a tarefa")
+	}
+	task := &models.Tasks{
+		Title:       parsed.Title,
+		UserID:      userID,
+		WorkspaceID: req.WorkspaceID,
+		Priority:    parsed.Priority,
+		DueDate:     parsed.DueDate,
+		Reminder:    parsed.Reminder,
+	}
+	if parsed.Recurrence != "" {
+		task.Recurrence = &parsed.Recurrence
+	}
+	if err := c.Validate(task); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	if task.WorkspaceID != nil {
+		if _, _, err := h.workspaceAccess(c, *task.WorkspaceID, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+
+	labels, err := h.labelsNamed(c, task, parsed.Labels)
+	if err != nil {
+		return err
+	}
+	if preview, _ := strconv.ParseBool(c.QueryParam("preview")); preview {
+		return c.JSON(http.StatusOK, QuickAddResponse{Task: *task, Labels: labels, Unparsed: parsed.Unparsed})
+	}
+
+	if err := h.createTask(c, task); err != nil {
+		return err
+	}
+	for i := range labels {
+		if labels[i].ID == 0 {
+			if err := h.Labels.Create(c.Request().Context(), &labels[i]); err != nil {
+				h.logger(c).Error("Erro ao criar etiqueta", "error", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar etiqueta")
+			}
+		}
+		if err := h.Labels.Tag(c.Request().Context(), labels[i].ID, []uint{task.ID}); err != nil {
+			h.logger(c).Error("Erro ao etiquetar a tarefa", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao etiquetar tarefa")
+		}
+	}
+	return c.JSON(http.StatusCreated, QuickAddResponse{Task: *task, Labels: labels, Unparsed: parsed.Unparsed})
+}
+
+// labelsNamed returns the labels with the given names, matched regardless of case, among the personal labels
+// of the task's owner or the labels of its workspace. Names without a label get a new, unsaved, one.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) labelsNamed(c echo.Context, task *models.Tasks, names []string) ([]models.Label, error) {
+	labels := []models.Label{}
+	if len(names) == 0 {
+		return labels, nil
+	}
+
+	var existing []models.Label
+	var err error
+	if task.WorkspaceID != nil {
+		existing, err = h.Labels.FindByWorkspace(c.Request().Context(), *task.WorkspaceID)
+	} else {
+		existing, err = h.Labels.FindByUser(c.Request().Context(), task.UserID)
+	}
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar etiquetas", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar etiquetas")
+	}
+
+	for _, name := range names {
+		label := models.Label{Name: name, Color: models.DefaultLabelColor, UserID: task.UserID, WorkspaceID: task.WorkspaceID}
+		for _, other := range existing {
+			if strings.EqualFold(other.Name, name) {
+				label = other
+				break
+			}
+		}
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/quickadd"
+	"github.com/devgugga/NullTask/internal/triage"
+)
+
+// UserRequest is the body expected by the CreateUser handler.
+// The password is only ever received: the users the API returns don't carry it.
+type UserRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=6"`
+	Age      uint8  `json:"age" validate:"required,gte=18"`
+}
+
+// UserUpdateRequest is the body expected by the UpdateUserByEmail and UpdateUserById handlers.
+// The fields left out keep their values, and the password is only changed when one is sent.
+type UserUpdateRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"omitempty,min=6"`
+	Age      uint8  `json:"age" validate:"required,gte=18"`
+}
+
+// LoginRequest is the body expected by the Login handler.
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// LoginResponse is the body returned by the Login handler.
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MemberRoleRequest is the body expected by the UpdateWorkspaceMember handler.
+type MemberRoleRequest struct {
+	Role models.WorkspaceRole `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+// AssignRequest is the body expected by the AssignTask handler.
+type AssignRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+// CommentRequest is the body expected by the CreateComment and UpdateComment handlers.
+// ParentID, the comment being answered, is only read when creating a comment.
+type CommentRequest struct {
+	Body     string `json:"body" validate:"required,max=10000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// AttachmentUsageResponse is the body returned by the GetAttachmentUsage handler, in bytes.
+type AttachmentUsageResponse struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+}
+
+// ChecklistOrderRequest is the body expected by the ReorderChecklist handler.
+// IDs lists every checklist item of the task, in the new order.
+type ChecklistOrderRequest struct {
+	IDs []uint `json:"ids" validate:"required"`
+}
+
+// TaskProgressResponse is the body returned by the GetTaskProgress handler.
+// Percent is the share of the direct subtasks and checklist items that are done, from 0 to 100.
+type TaskProgressResponse struct {
+	Subtasks           int `json:"subtasks"`
+	SubtasksDone       int `json:"subtasks_done"`
+	ChecklistItems     int `json:"checklist_items"`
+	ChecklistItemsDone int `json:"checklist_items_done"`
+	Percent            int `json:"percent"`
+}
+
+// DependencyRequest is the body expected by the AddDependency handler.
+type DependencyRequest struct {
+	DependsOnID uint `json:"depends_on_id" validate:"required"`
+}
+
+// CriticalPathResponse is the body returned by the GetCriticalPath handler.
+// Order holds every requested task, each after the tasks it depends on, and CriticalPath the longest
+// chain of unfinished tasks through them, from the first to do to the last.
+type CriticalPathResponse struct {
+	Order        []models.Tasks `json:"order"`
+	CriticalPath []models.Tasks `json:"critical_path"`
+}
+
+// BoardColumn is a column of the boards returned by the GetBoard and GetWorkspaceBoard handlers:
+// a status and the tasks in it, in order.
+type BoardColumn struct {
+	Status   string         `json:"status"`
+	Terminal bool           `json:"terminal"`
+	WIPLimit int            `json:"wip_limit,omitempty"`
+	Tasks    []models.Tasks `json:"tasks"`
+}
+
+// MoveRequest is the body expected by the MoveTask handler: the status the task moves to, empty to keep the current one,
+// and the tasks of that column it should land between. Either neighbour can be left out.
+type MoveRequest struct {
+	Status   string `json:"status" validate:"max=50"`
+	AfterID  *uint  `json:"after_id"`
+	BeforeID *uint  `json:"before_id"`
+}
+
+// LabelTasksRequest is the body expected by the TagTasks and UntagTasks handlers.
+type LabelTasksRequest struct {
+	TaskIDs []uint `json:"task_ids" validate:"required,min=1,max=500"`
+}
+
+// NextActionResponse is an item of the list returned by the GetNextActions handler:
+// a task, its score and the factors adding up to it.
+type NextActionResponse struct {
+	Task    models.Tasks    `json:"task"`
+	Score   float64         `json:"score"`
+	Factors []triage.Factor `json:"factors"`
+}
+
+// EisenhowerResponse is the body returned by the GetEisenhower handler: the open tasks of the user
+// in each quadrant of the Eisenhower matrix.
+type EisenhowerResponse struct {
+	Do        []models.Tasks `json:"do"`
+	Schedule  []models.Tasks `json:"schedule"`
+	Delegate  []models.Tasks `json:"delegate"`
+	Eliminate []models.Tasks `json:"eliminate"`
+}
+
+// QuickAddRequest is the body expected by the QuickAddTask handler.
+// Timezone is the IANA time zone, such as "America/Sao_Paulo", relative dates are read in.
+// When empty, they are read in the time zone of the user's preferences.
+type QuickAddRequest struct {
+	Text        string  `json:"text" validate:"required,max=500"`
+	Timezone    string  `json:"timezone" validate:"omitempty,timezone"`
+	WorkspaceID *string `json:"workspace_id" validate:"omitempty,uuid"`
+}
+
+// QuickAddResponse is the body returned by the QuickAddTask handler: the task, the labels tagging it,
+// and the words of the text that couldn't be understood.
+type QuickAddResponse struct {
+	Task     models.Tasks     `json:"task"`
+	Labels   []models.Label   `json:"labels"`
+	Unparsed []quickadd.Token `json:"unparsed"`
+}
+
+// CalendarFeedResponse is the body returned by the CreateCalendarFeed handler.
+// The token is only ever returned here, along with the URL calendar applications subscribe to.
+type CalendarFeedResponse struct {
+	models.CalendarFeed
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
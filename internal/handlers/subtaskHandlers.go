@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// GetSubtasks returns, with a HTTP status code 200, the direct subtasks of a task.
+// Their own subtasks are reached by asking for the subtasks of each of them.
+func (h *Handler) GetSubtasks(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	children, err := h.Tasks.FindChildren(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar subtarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar subtarefas")
+	}
+	return c.JSON(http.StatusOK, children)
+}
+
+// GetTaskProgress returns, with a HTTP status code 200, how much of a task is done:
+// the number of direct subtasks and checklist items, how many of them are done, and the percentage they represent.
+// Subtasks are done once they reach a terminal status of their workflow.
+// A task without subtasks nor checklist items is either 0 or 100 percent done, depending on whether it's completed.
+func (h *Handler) GetTaskProgress(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	children, err := h.Tasks.FindChildren(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar subtarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar subtarefas")
+	}
+	items, err := h.Tasks.ListChecklistItems(c.Request().Context(), task.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a checklist", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar checklist")
+	}
+
+	progress := TaskProgressResponse{Subtasks: len(children), ChecklistItems: len(items)}
+	for _, child := range children {
+		if child.CompletedAt != nil {
+			progress.SubtasksDone++
+		}
+	}
+	for _, item := range items {
+		if item.Done {
+			progress.ChecklistItemsDone++
+		}
+	}
+
+	total := progress.Subtasks + progress.ChecklistItems
+	switch {
+	case total > 0:
+		progress.Percent = (progress.SubtasksDone + progress.ChecklistItemsDone) * 100 / total
+	case task.CompletedAt != nil:
+		progress.Percent = 100
+	}
+	return c.JSON(http.StatusOK, progress)
+}
+
+// checkParent checks that the task can be a subtask of the task in its ParentID: the parent must be a task the user
+// can edit, in the same workspace as the task, and can't be the task itself nor one of its subtasks,
+// which would turn the tree into a cycle.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) checkParent(c echo.Context, task *models.Tasks) error {
+	parent, err := h.taskAccess(c, *task.ParentID, models.RoleEditor)
+	if err != nil {
+		if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+			return echo.NewHTTPError(http.StatusBadRequest, "Tarefa pai inválida")
+		}
+		return err
+	}
+	if !sameWorkspace(parent, task) {
+		return echo.NewHTTPError(http.StatusBadRequest, "A tarefa pai deve estar no mesmo workspace")
+	}
+
+	// New tasks have no subtasks yet, so they can't form a cycle
+	if task.ID == 0 {
+		return nil
+	}
+
+	// Walk up from the parent, the task must not be one of its ancestors
+	seen := map[uint]bool{}
+	for ancestor := parent; !seen[ancestor.ID]; {
+		if ancestor.ID == task.ID {
+			return echo.NewHTTPError(http.StatusConflict, "A tarefa não pode ser subtarefa de si mesma ou de suas subtarefas")
+		}
+		seen[ancestor.ID] = true
+		if ancestor.ParentID == nil {
+			break
+		}
+
+		ancestor, err = h.Tasks.FindByID(c.Request().Context(), *ancestor.ParentID)
+		if errors.Is(err, repository.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			h.logger(c).Error("Erro ao buscar a tarefa", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefa")
+		}
+	}
+	return nil
+}
+
+// cascadeRanks checks that every unfinished subtask of the task, at any depth, can follow it to its terminal status
+// the way it could on its own: along a transition of the workflow, without being blocked by unfinished dependencies
+// unless forced, and within the WIP limit of the status' column, which entering tells whether the task itself joins.
+// It returns the board position each of them takes, after the others at the end of that column.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) cascadeRanks(c echo.Context, task *models.Tasks, entering, force bool) (map[uint]string, error) {
+	workflow, err := h.taskWorkflow(c, task)
+	if err != nil {
+		return nil, err
+	}
+	status, _ := workflow.Status(task.Status)
+
+	// Walk down the subtree, subtasks share the workflow of the task since they're in its workspace
+	var pending []models.Tasks
+	seen := map[uint]bool{task.ID: true}
+	for queue := []uint{task.ID}; len(queue) > 0; queue = queue[1:] {
+		children, err := h.Tasks.FindChildren(c.Request().Context(), queue[0])
+		if err != nil {
+			h.logger(c).Error("Erro ao buscar as subtarefas", "error", err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar subtarefas")
+		}
+		for _, child := range children {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			queue = append(queue, child.ID)
+			if child.CompletedAt != nil {
+				continue
+			}
+
+			if !workflow.CanTransition(child.Status, task.Status) {
+				return nil, echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Transição de status não permitida para a subtarefa %d: %s → %s", child.ID, child.Status, task.Status))
+			}
+			if child.Blocked && !force {
+				return nil, echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Subtarefa %d bloqueada por dependências não concluídas", child.ID))
+			}
+			pending = append(pending, child)
+		}
+	}
+
+	column, err := h.Tasks.FindColumn(c.Request().Context(), task.UserID, task.WorkspaceID, task.Status)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar a coluna", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar coluna")
+	}
+	count, last := len(column)+len(pending), ""
+	if len(column) > 0 {
+		last = column[len(column)-1].Rank
+	}
+	if entering {
+		// The task was already placed at the end of the column, which doesn't hold it yet
+		count, last = count+1, task.Rank
+	}
+	if status.WIPLimit > 0 && count > status.WIPLimit {
+		return nil, columnFull(status.Name)
+	}
+
+	ranks := make(map[uint]string, len(pending))
+	for _, child := range pending {
+		if err := h.setRank(c, &child, last, ""); err != nil {
+			return nil, err
+		}
+		ranks[child.ID], last = child.Rank, child.Rank
+	}
+	return ranks, nil
+}
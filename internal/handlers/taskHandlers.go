@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// CreateTask handles the creation of a new task.
+// It expects a JSON object in the request body with the task's information.
+// The task is owned by the authenticated user, whatever "user_id" the body carries, and its ID and dates are
+// left to the database.
+// When the body carries a "workspace_id", the task is created in that workspace and the user must be at least an editor of it.
+// When it carries a "parent_id", the task is created as a subtask of that task, which must be in the same workspace.
+// The task starts in the initial status of the workflow it follows unless the body carries another "status" of it,
+// at the end of that status' board column. A column at its WIP limit returns a HTTP status code 409.
+// A task due without a "reminder" is reminded as set in the default reminder of the user's preferences.
+// The function validates the input, checks that the owner exists and saves the task through the task repository.
+// If successful, it returns a JSON object with the created task and a HTTP status code 201.
+// If there are any errors during the process, it returns an appropriate error message and a HTTP status code.
+func (h *Handler) CreateTask(c echo.Context) (err error) {
+	t := new(models.Tasks)
+	// Bind the request body to the Tasks struct
+	if err = c.Bind(t); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request"})
+	}
+	t.UserID = middlewares.UserID(c)
+	// The database numbers and dates the task, and a new task doesn't depend on anything yet
+	t.ID, t.CreatedAt, t.UpdatedAt, t.DeletedAt = 0, time.Time{}, time.Time{}, gorm.DeletedAt{}
+	t.Blocked = false
+
+	// Validate the Tasks struct
+	if err := c.Validate(t); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.createTask(c, t); err != nil {
+		return err
+	}
+
+	// Return the created task and a HTTP status code 201
+	return c.JSON(http.StatusCreated, t)
+}
+
+// createTask saves a new, already validated, task of the authenticated user, checking that they can add it
+// to its workspace and under its parent, and putting it in its status' board column.
+// Tasks due without a reminder are reminded the default number of minutes before set in the owner's preferences.
+// Once saved, a events.TaskCreated event is published.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) createTask(c echo.Context, t *models.Tasks) error {
+	// Check that the user can add tasks to the workspace
+	if t.WorkspaceID != nil {
+		if _, _, err := h.workspaceAccess(c, *t.WorkspaceID, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+
+	// Check that the task can be a subtask of its parent
+	if t.ParentID != nil {
+		if err := h.checkParent(c, t); err != nil {
+			return err
+		}
+	}
+
+	// Check the status against the workflow the task follows
+	limit, err := h.applyStatus(c, t, "")
+	if err != nil {
+		return err
+	}
+
+	// Check that the owner of the task exists
+	if _, err := h.Users.FindByID(c.Request().Context(), t.UserID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+
+	// Remind the task as the owner prefers when it's due without a reminder of its own
+	if t.DueDate != nil && t.Reminder == nil {
+		preferences, err := h.userPreferences(c, t.UserID)
+		if err != nil {
+			return err
+		}
+		if preferences.DefaultReminder != nil {
+			reminder := t.DueDate.Add(-time.Duration(*preferences.DefaultReminder) * time.Minute)
+			t.Reminder = &reminder
+		}
+	}
+
+	// Save the task through the repository, as long as its column still has room for it
+	if err := h.Tasks.CreateInColumn(c.Request().Context(), t, limit); err != nil {
+		if errors.Is(err, repository.ErrColumnFull) {
+			return columnFull(t.Status)
+		}
+		h.logger(c).Error("Erro ao criar tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar tarefa")
+	}
+	h.publishTask(c, events.TaskCreated, t)
+	return nil
+}
+
+// GetTaskById retrieves a task based on its ID.
+//
+// Parameters:
+// c echo.Context: Provides information about the request and response.
+//
+// Returns:
+// err error: An error if any occurred during the process. If successful, it returns nil.
+//
+// The function extracts the task's ID from the request parameters and queries the task repository.
+// If the task is found, it returns the task as a JSON response with a HTTP status code 200.
+// If the ID is malformed, it returns a HTTP status code 400, and if the task is not found, a HTTP status code 404.
+func (h *Handler) GetTaskById(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, task)
+}
+
+// GetTasksByUser retrieves every task owned by a user.
+//
+// Parameters:
+// c echo.Context: Provides information about the request and response.
+//
+// Returns:
+// err error: An error if any occurred during the process. If successful, it returns nil.
+//
+// The function extracts the user's ID from the request parameters and checks that the user exists.
+// It then queries the task repository for the user's tasks and returns them as a JSON response with a HTTP status code 200.
+// If the user is not found, it returns a HTTP status code 404 with an appropriate error message,
+// and listing the tasks of another user returns a HTTP status code 403.
+// The "labels" and "match" query parameters keep the tasks tagged with all, or any, of the given labels.
+func (h *Handler) GetTasksByUser(c echo.Context) (err error) {
+	userId := c.Param("userId")
+	if userId != middlewares.UserID(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+	}
+
+	if _, err := h.Users.FindByID(c.Request().Context(), userId); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+
+	tasks, err := h.Tasks.FindByUser(c.Request().Context(), userId)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+
+	tasks, err = h.filterByLabels(c, tasks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// SearchTasks performs a full-text search over a user's tasks.
+//
+// Parameters:
+// c echo.Context: Provides information about the request and response.
+//
+// Returns:
+// err error: An error if any occurred during the process. If successful, it returns nil.
+//
+// The function extracts the user's ID from the request parameters and the search text from the "q" query parameter.
+// It returns, with a HTTP status code 200, the user's tasks whose title, description or notes contain every word of the text.
+// If the text is empty, it returns a HTTP status code 400, and if the user is not found, a HTTP status code 404.
+// Searching the tasks of another user returns a HTTP status code 403.
+// The "labels" and "match" query parameters narrow the results as they do for GetTasksByUser.
+func (h *Handler) SearchTasks(c echo.Context) (err error) {
+	userId := c.Param("userId")
+	if userId != middlewares.UserID(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+	}
+
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Informe o texto da busca")
+	}
+
+	if _, err := h.Users.FindByID(c.Request().Context(), userId); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+
+	tasks, err := h.Tasks.Search(c.Request().Context(), userId, query)
+	if err != nil {
+		h.logger(c).Error("Erro ao pesquisar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao pesquisar tarefas")
+	}
+
+	tasks, err = h.filterByLabels(c, tasks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// UpdateTaskById updates an existing task based on its ID.
+//
+// Parameters:
+// c echo.Context: Provides information about the request and response.
+//
+// Returns:
+// err error: An error if any occurred during the process. If successful, it returns nil.
+//
+// The function loads the task with the ID from the request parameters, binds the request body onto it,
+// validates the result and saves it through the task repository.
+// The ID, the owner, the workspace, the board position and the dates of the task can't be changed through this endpoint,
+// and workspace tasks can only be updated by editors.
+// The "status" can only change along the transitions of the workflow the task follows, other changes return a
+// HTTP status code 409, as do changes to a status whose board column is at its WIP limit.
+// Moving to a terminal status completes the task, and leaving it reopens the task.
+// Changing the "parent_id" moves the task under another task of the same workspace, and making a task a subtask
+// of itself or of one of its own subtasks returns a HTTP status code 409.
+// With the "cascade" query parameter set to true, completing the task also moves its unfinished subtasks,
+// at any depth, to the same status and checks off their checklists. The subtasks must be able to take that status
+// on their own, otherwise nothing is changed and a HTTP status code 409 is returned.
+// Completing a task that is blocked by unfinished dependencies returns a HTTP status code 409,
+// unless the "force" query parameter is set to true, which also applies to the subtasks moved along.
+// If the update is successful, it returns the updated task as a JSON response with a HTTP status code 200.
+func (h *Handler) UpdateTaskById(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	id, owner, workspace, parent := task.ID, task.UserID, task.WorkspaceID, task.ParentID
+	status, completed, blocked, position := task.Status, task.CompletedAt != nil, task.Blocked, task.Rank
+	created, updated, deleted := task.CreatedAt, task.UpdatedAt, task.DeletedAt
+	if err := c.Bind(task); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	task.ID, task.UserID, task.WorkspaceID, task.Blocked, task.Rank = id, owner, workspace, blocked, position
+	// Tasks are only deleted through DeleteTaskById, and their dates are kept by the database
+	task.CreatedAt, task.UpdatedAt, task.DeletedAt = created, updated, deleted
+
+	if err := c.Validate(task); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	limit, err := h.applyStatus(c, task, status)
+	if err != nil {
+		return err
+	}
+	completing := !completed && task.CompletedAt != nil
+	force, _ := strconv.ParseBool(c.QueryParam("force"))
+	if blocked && !force && completing {
+		return echo.NewHTTPError(http.StatusConflict, "Tarefa bloqueada por dependências não concluídas")
+	}
+
+	var ranks map[uint]string
+	cascade, _ := strconv.ParseBool(c.QueryParam("cascade"))
+	if cascade = cascade && task.CompletedAt != nil; cascade {
+		if ranks, err = h.cascadeRanks(c, task, task.Status != status, force); err != nil {
+			return err
+		}
+	}
+
+	if task.ParentID != nil && (parent == nil || *parent != *task.ParentID) {
+		if err := h.checkParent(c, task); err != nil {
+			return err
+		}
+	}
+
+	if err := h.Tasks.UpdateInColumn(c.Request().Context(), task, limit); err != nil {
+		if errors.Is(err, repository.ErrColumnFull) {
+			return columnFull(task.Status)
+		}
+		h.logger(c).Error("Erro ao atualizar a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar tarefa")
+	}
+
+	if cascade {
+		if err := h.Tasks.CompleteDescendants(c.Request().Context(), task.ID, task.Status, *task.CompletedAt, ranks); err != nil {
+			h.logger(c).Error("Erro ao concluir as subtarefas", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao concluir subtarefas")
+		}
+	}
+
+	h.publishTask(c, events.TaskUpdated, task)
+	return c.JSON(http.StatusOK, task)
+}
+
+// DeleteTaskById deletes a task based on its ID.
+//
+// Parameters:
+// c echo.Context: Provides information about the request and response.
+//
+// Returns:
+// err error: An error if any occurred during the process. If successful, it returns nil.
+//
+// If the task is not found, it returns a HTTP status code 404 with an appropriate error message.
+// Workspace tasks can only be deleted by editors, and the subtasks of the deleted task become top-level tasks.
+// If the deletion is successful, it returns a JSON response with a HTTP status code 200 and a message "Tarefa Deletada".
+func (h *Handler) DeleteTaskById(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Tasks.Delete(c.Request().Context(), task.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar a tarefa", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar tarefa")
+	}
+
+	h.publishTask(c, events.TaskDeleted, task)
+	return c.JSON(http.StatusOK, "Tarefa Deletada")
+}
+
+// findTask loads the task whose ID is in the "id" route parameter.
+// Personal tasks are only reachable by their owner, and workspace tasks by the workspace members with at least the given role.
+// Tasks the user can't see are reported as not found, so their IDs aren't disclosed,
+// while members without the required role get a HTTP status code 403.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) findTask(c echo.Context, role models.WorkspaceRole) (*models.Tasks, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "ID de tarefa inválido")
+	}
+	return h.taskAccess(c, uint(id), role)
+}
+
+// taskAccess loads the task with the given ID and checks that the authenticated user can reach it with the given role,
+// the same way findTask does.
+func (h *Handler) taskAccess(c echo.Context, id uint, role models.WorkspaceRole) (*models.Tasks, error) {
+	task, err := h.Tasks.FindByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Tarefa não encontrada")
+		}
+		h.logger(c).Error("Erro ao buscar a tarefa", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefa")
+	}
+	if task.WorkspaceID != nil {
+		if _, _, err := h.workspaceAccess(c, *task.WorkspaceID, role); err != nil {
+			if he, ok := err.(*echo.HTTPError); ok && he.Code == http.StatusNotFound {
+				return nil, echo.NewHTTPError(http.StatusNotFound, "Tarefa não encontrada")
+			}
+			return nil, err
+		}
+		return task, nil
+	}
+	if task.UserID != middlewares.UserID(c) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Tarefa não encontrada")
+	}
+	return task, nil
+}
+
+// sameWorkspace tells whether both tasks are in the same workspace, or are both personal tasks.
+// Personal tasks the user can reach are always their own, so two of them are in the same place too.
+func sameWorkspace(a, b *models.Tasks) bool {
+	if a.WorkspaceID == nil || b.WorkspaceID == nil {
+		return a.WorkspaceID == nil && b.WorkspaceID == nil
+	}
+	return *a.WorkspaceID == *b.WorkspaceID
+}
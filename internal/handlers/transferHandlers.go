@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// MaxImportSize is the size, in bytes, of the largest file that can be imported.
+const MaxImportSize = 10 << 20
+
+// CreateImport starts importing a file of tasks into personal tasks of the authenticated user, in the background.
+// It expects a multipart/form-data request with the file in the "file" field and its format, one of the
+// transfer.Formats, in the "format" field. CSV files can map the fields of the tasks onto their columns with
+// a JSON object in the "mapping" field, and "dry_run" set to true only reports what the import would do.
+// Files larger than MaxImportSize are refused with a HTTP status code 413.
+// If successful, it returns the pending job, to be followed through GetImport, with a HTTP status code 202.
+func (h *Handler) CreateImport(c echo.Context) (err error) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, MaxImportSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Arquivo de importação muito grande")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Envie o arquivo no campo \"file\"")
+	}
+	if header.Size > MaxImportSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Arquivo de importação muito grande")
+	}
+
+	format, err := transfer.ParseFormat(c.FormValue("format"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var mapping map[string]string
+	if value := c.FormValue("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Mapeamento de colunas inválido")
+		}
+		if err := transfer.CheckMapping(mapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	dryRun := false
+	if value := c.FormValue("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Valor de dry_run inválido")
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.logger(c).Error("Erro ao abrir o arquivo enviado", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao importar tarefas")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.logger(c).Error("Erro ao ler o arquivo enviado", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao importar tarefas")
+	}
+
+	job := &models.ImportJob{
+		UserID:  middlewares.UserID(c),
+		Format:  string(format),
+		Mapping: mapping,
+		DryRun:  dryRun,
+		Status:  models.ImportPending,
+		Data:    data,
+	}
+	if err := h.Imports.Create(c.Request().Context(), job); err != nil {
+		h.logger(c).Error("Erro ao criar a importação", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao importar tarefas")
+	}
+
+	if h.Importer != nil {
+		h.Importer.Enqueue(*job)
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// GetImport returns, with a HTTP status code 200, the import job whose ID comes from the route parameters,
+// with what happened to each row of its file once it finished.
+// Jobs of other users are reported as not found.
+func (h *Handler) GetImport(c echo.Context) (err error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID de importação inválido")
+	}
+
+	job, err := h.Imports.FindByID(c.Request().Context(), uint(id))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		h.logger(c).Error("Erro ao buscar a importação", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar importação")
+	}
+	if err != nil || job.UserID != middlewares.UserID(c) {
+		return echo.NewHTTPError(http.StatusNotFound, "Importação não encontrada")
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// GetImports returns, with a HTTP status code 200, the import jobs of the authenticated user, newest first.
+func (h *Handler) GetImports(c echo.Context) (err error) {
+	jobs, err := h.Imports.FindByUser(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar importações", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar importações")
+	}
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// ExportTasks returns the personal tasks of the authenticated user, with their labels and checklists, as a download.
+// The "format" query parameter selects a NullTask JSON document, the default, which imports back without losing
+// anything, a CSV file with a column for each of the transfer.Columns, a todo.txt file or a Markdown checklist,
+// whose dates are written in the time zone of the user.
+func (h *Handler) ExportTasks(c echo.Context) (err error) {
+	format := transfer.NullTask
+	if value := c.QueryParam("format"); value != "" {
+		format = transfer.Format(value)
+	}
+	plain, ok := exporters[format]
+	if !ok && format != transfer.NullTask {
+		return echo.NewHTTPError(http.StatusBadRequest, "Formato de exportação inválido")
+	}
+
+	ctx := c.Request().Context()
+	userID := middlewares.UserID(c)
+	tasks, err := h.Tasks.FindByUser(ctx, userID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao exportar tarefas")
+	}
+	labels := map[uint][]models.Label{}
+	checklists := map[uint][]models.ChecklistItem{}
+	for _, task := range tasks {
+		if labels[task.ID], err = h.Labels.FindByTask(ctx, task.ID); err != nil {
+			h.logger(c).Error("Erro ao buscar etiquetas", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao exportar tarefas")
+		}
+		if checklists[task.ID], err = h.Tasks.ListChecklistItems(ctx, task.ID); err != nil {
+			h.logger(c).Error("Erro ao buscar checklist", "error", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao exportar tarefas")
+		}
+	}
+	document := transfer.Export(tasks, labels, checklists)
+
+	name := "nulltask-" + time.Now().Format("20060102")
+	if format == transfer.NullTask {
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`.json"`)
+		return c.JSON(http.StatusOK, document)
+	}
+
+	preferences, err := h.userPreferences(c, userID)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	if err := plain.write(&body, document, preferences.Location()); err != nil {
+		h.logger(c).Error("Erro ao gerar o arquivo exportado", "format", format, "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao exportar tarefas")
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+plain.extension+`"`)
+	return c.Blob(http.StatusOK, plain.contentType, body.Bytes())
+}
+
+// exporter writes the tasks of a document in one of the plain text formats ExportTasks serves.
+type exporter struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, document *transfer.Document, loc *time.Location) error
+}
+
+// exporters are the plain text formats ExportTasks serves, besides NullTask JSON documents.
+var exporters = map[transfer.Format]exporter{
+	transfer.CSV: {"text/csv; charset=utf-8", ".csv", func(w io.Writer, document *transfer.Document, _ *time.Location) error {
+		return transfer.WriteCSV(w, document)
+	}},
+	transfer.TodoTxt:  {"text/plain; charset=utf-8", ".txt", transfer.WriteTodoTxt},
+	transfer.Markdown: {"text/markdown; charset=utf-8", ".md", transfer.WriteMarkdown},
+}
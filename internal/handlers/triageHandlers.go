@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/triage"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultNextActions is how many tasks GetNextActions returns when no limit is given.
+	defaultNextActions = 20
+	// maxNextActions is the most tasks GetNextActions returns at once.
+	maxNextActions = 100
+)
+
+// GetNextActions returns, with a HTTP status code 200, the open tasks of the authenticated user, personal or assigned to them,
+// ranked by the score of the triage package, the highest first, along with the factors explaining each score.
+// Tasks with the same score are ordered by due date, the ones without a due date last, and then by ID.
+// The "limit" query parameter sets how many tasks are returned, 20 by default and at most 100.
+func (h *Handler) GetNextActions(c echo.Context) (err error) {
+	limit := defaultNextActions
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxNextActions {
+			return echo.NewHTTPError(http.StatusBadRequest, "Limite inválido")
+		}
+	}
+
+	tasks, err := h.openTasks(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	actions := make([]NextActionResponse, 0, len(tasks))
+	for _, task := range tasks {
+		score, factors := triage.Score(task, now)
+		actions = append(actions, NextActionResponse{Task: task, Score: score, Factors: factors})
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		a, b := actions[i], actions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if (a.Task.DueDate == nil) != (b.Task.DueDate == nil) {
+			return a.Task.DueDate != nil
+		}
+		if a.Task.DueDate != nil && !a.Task.DueDate.Equal(*b.Task.DueDate) {
+			return a.Task.DueDate.Before(*b.Task.DueDate)
+		}
+		return a.Task.ID < b.Task.ID
+	})
+
+	if len(actions) > limit {
+		actions = actions[:limit]
+	}
+	return c.JSON(http.StatusOK, actions)
+}
+
+// GetEisenhower returns, with a HTTP status code 200, the open tasks of the authenticated user, personal or assigned to them,
+// placed on the Eisenhower matrix by their urgency and importance.
+func (h *Handler) GetEisenhower(c echo.Context) (err error) {
+	tasks, err := h.openTasks(c)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	matrix := EisenhowerResponse{Do: []models.Tasks{}, Schedule: []models.Tasks{}, Delegate: []models.Tasks{}, Eliminate: []models.Tasks{}}
+	for _, task := range tasks {
+		switch triage.QuadrantOf(task, now) {
+		case triage.QuadrantDo:
+			matrix.Do = append(matrix.Do, task)
+		case triage.QuadrantSchedule:
+			matrix.Schedule = append(matrix.Schedule, task)
+		case triage.QuadrantDelegate:
+			matrix.Delegate = append(matrix.Delegate, task)
+		default:
+			matrix.Eliminate = append(matrix.Eliminate, task)
+		}
+	}
+	return c.JSON(http.StatusOK, matrix)
+}
+
+// openTasks returns the tasks of the authenticated user that aren't completed yet: their personal tasks
+// and the tasks assigned to them that they can still see, each once, by ID.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) openTasks(c echo.Context) ([]models.Tasks, error) {
+	tasks, err := h.userTasks(c)
+	if err != nil {
+		return nil, err
+	}
+
+	open := []models.Tasks{}
+	for _, task := range tasks {
+		if task.CompletedAt == nil {
+			open = append(open, task)
+		}
+	}
+	return open, nil
+}
+
+// userTasks returns the tasks of the authenticated user, completed or not: their personal tasks
+// and the tasks assigned to them that they can still see, each once, by ID.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) userTasks(c echo.Context) ([]models.Tasks, error) {
+	ctx := c.Request().Context()
+	userID := middlewares.UserID(c)
+
+	personal, err := h.Tasks.FindByUser(ctx, userID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+	assigned, err := h.Tasks.FindAssignedTo(ctx, userID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+	assigned, err = h.visibleTasks(c, assigned)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[uint]bool{}
+	tasks := []models.Tasks{}
+	for _, task := range append(personal, assigned...) {
+		if seen[task.ID] {
+			continue
+		}
+		seen[task.ID] = true
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
@@ -4,10 +4,11 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/devgugga/NullTask/internal/middlewares"
 	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 // CreateUser handles the creation of a new user.
@@ -17,22 +18,24 @@ import (
 // If successful, it returns a JSON object with the created user and a HTTP status code 201.
 // If there are any errors during the process, it returns an appropriate error message and a HTTP status code.
 func (h *Handler) CreateUser(c echo.Context) (err error) {
-	u := new(models.User)
-	// Bind the request body to the User struct
-	if err = c.Bind(u); err != nil {
+	req := new(UserRequest)
+	// Bind the request body to the UserRequest struct
+	if err = c.Bind(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request"})
 	}
 
-	// Validate the User struct
-	if err := c.Validate(u); err != nil {
+	// Validate the UserRequest struct
+	if err := c.Validate(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
 	}
 
-	var existingUser models.User
+	// Only the operators create admins and deactivate users, from the command line
+	u := &models.User{Name: req.Name, Email: req.Email, Password: req.Password, Age: req.Age}
+
 	// Check if the email is already registered
-	if err := h.DB.Where("email =?", u.Email).First(&existingUser).Error; err == nil {
+	if _, err := h.Users.FindByEmail(c.Request().Context(), u.Email); err == nil {
 		return echo.NewHTTPError(http.StatusConflict, "E-mail já cadastrado")
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+	} else if !errors.Is(err, repository.ErrNotFound) {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao verificar e-mail")
 	}
 
@@ -44,8 +47,11 @@ func (h *Handler) CreateUser(c echo.Context) (err error) {
 	u.Password = string(hashedPassword)
 
 	// Save the user to the database
-	if err := h.DB.Create(&u).Error; err != nil {
-		h.Log.Error("Error creating user", "error", err)
+	if err := h.Users.Create(c.Request().Context(), u); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return echo.NewHTTPError(http.StatusConflict, "E-mail já cadastrado")
+		}
+		h.logger(c).Error("Error creating user", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar usuário")
 	}
 
@@ -53,22 +59,83 @@ func (h *Handler) CreateUser(c echo.Context) (err error) {
 	return c.JSON(http.StatusCreated, u)
 }
 
+// Login authenticates a user by e-mail and password.
+// It expects a JSON object in the request body with the user's "email" and "password".
+// If the credentials match, it returns a JSON object with a bearer "token" for the other endpoints,
+// its expiration in "expires_at", and a HTTP status code 200.
+// Wrong credentials return a HTTP status code 401, without telling whether the e-mail is registered,
+// and the credentials of a deactivated user a HTTP status code 403.
+func (h *Handler) Login(c echo.Context) (err error) {
+	credentials := new(LoginRequest)
+	if err = c.Bind(credentials); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request"})
+	}
+
+	if err := c.Validate(credentials); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	user, err := h.Users.FindByEmail(c.Request().Context(), credentials.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "E-mail ou senha inválidos")
+		}
+		h.logger(c).Error("Erro ao buscar o usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(credentials.Password)); err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "E-mail ou senha inválidos")
+	}
+	if user.DeactivatedAt != nil {
+		return echo.NewHTTPError(http.StatusForbidden, "Usuário desativado")
+	}
+
+	token, expiresAt, err := h.Tokens.Issue(user.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao gerar token", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao gerar token")
+	}
+
+	return c.JSON(http.StatusOK, LoginResponse{Token: token, ExpiresAt: expiresAt})
+}
+
+// errUserDeactivated is returned by the checks of the users' credentials when the user was deactivated.
+var errUserDeactivated = errors.New("user deactivated")
+
+// CheckActive refuses, with a HTTP status code 401, the requests made with the tokens of users that were deleted
+// or that an operator deactivated after they got them.
+func (h *Handler) CheckActive(c echo.Context, userID string) error {
+	user, err := h.Users.FindByID(c.Request().Context(), userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Usuário não encontrado")
+	}
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar o usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+	if user.DeactivatedAt != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Usuário desativado")
+	}
+	return nil
+}
+
 // GetUserByEmail retrieves a user from the database based on their email.
 // It accepts an echo.Context as a parameter, which provides information about the request and response.
 // The function extracts the user's email from the request parameters.
-// It then queries the database using GORM to find a user with the given email.
+// It then queries the user repository to find a user with the given email.
 // If the user is found, it returns the user as a JSON response with a HTTP status code 200.
 // If the user is not found, it returns a HTTP status code 404 with an appropriate error message.
 // If there is any error during the process, it logs the error using the provided logger and returns a HTTP status code 500 with an appropriate error message.
 func (h *Handler) GetUserByEmail(c echo.Context) (err error) {
 	userEmail := c.Param("email")
 
-	var user models.User
-	if err := h.DB.Where("email =?", userEmail).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := h.Users.FindByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
 		}
-		h.Log.Error("Erro ao buscar o usuário", "error", err)
+		h.logger(c).Error("Erro ao buscar o usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
 	}
 	return c.JSON(http.StatusOK, user)
@@ -83,19 +150,19 @@ func (h *Handler) GetUserByEmail(c echo.Context) (err error) {
 // err error: An error if any occurred during the process. If successful, it returns nil.
 //
 // The function extracts the user's ID from the request parameters.
-// It then queries the database using GORM to find a user with the given ID.
+// It then queries the user repository to find a user with the given ID.
 // If the user is found, it returns the user as a JSON response with a HTTP status code 200.
 // If the user is not found, it returns a HTTP status code 404 with an appropriate error message.
 // If there is any error during the process, it logs the error using the provided logger and returns a HTTP status code 500 with an appropriate error message.
 func (h *Handler) GetUserById(c echo.Context) (err error) {
 	userId := c.Param("id")
 
-	var user models.User
-	if err := h.DB.Where("id =?", userId).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := h.Users.FindByID(c.Request().Context(), userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
 		}
-		h.Log.Error("Erro ao buscar o usuário", "error", err)
+		h.logger(c).Error("Erro ao buscar o usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
 	}
 	return c.JSON(http.StatusOK, user)
@@ -109,16 +176,23 @@ func (h *Handler) GetUserById(c echo.Context) (err error) {
 // Returns:
 // err error: An error if any occurred during the process. If successful, it returns nil.
 //
-// The function initializes an empty slice of User structs.
-// It then queries the database using GORM's Find method to retrieve all users and stores them in the slice.
+// Only the admins can list the users: the request of any other user gets a HTTP status code 403.
+// It then asks the user repository for every stored user.
 // If there is an error during the database query, it logs the error using the provided logger and returns a HTTP status code 500 with an appropriate error message.
 // If the query is successful, it returns the retrieved users as a JSON response with a HTTP status code 200.
 func (h *Handler) GetAllUsers(c echo.Context) (err error) {
-	var users []models.User
-	result := h.DB.Find(&users)
+	caller, err := h.Users.FindByID(c.Request().Context(), middlewares.UserID(c))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		h.logger(c).Error("Erro ao buscar o usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+	if err != nil || !caller.Admin {
+		return echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+	}
 
-	if result.Error != nil {
-		h.Log.Error("Erro ao buscar usuários", "error", result.Error)
+	users, err := h.Users.FindAll(c.Request().Context())
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar usuários", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuários")
 	}
 
@@ -134,39 +208,26 @@ func (h *Handler) GetAllUsers(c echo.Context) (err error) {
 // err error: An error if any occurred during the process. If successful, it returns nil.
 //
 // The function extracts the user's email from the request parameters.
-// It then queries the database using GORM to find a user with the given email.
+// It then queries the user repository to find a user with the given email.
 // If the user is not found, it returns a HTTP status code 404 with an appropriate error message.
-// If the user is found, it binds the request body to the user struct, validates the struct,
-// and saves the updated user to the database.
+// If the user is found and is the authenticated user, it binds the request body to a UserUpdateRequest, validates it,
+// and saves the updated user to the database. Updating another user returns a HTTP status code 403.
 // If there is any error during the binding, validation, or saving process, it logs the error using the provided logger
 // and returns a HTTP status code 500 with an appropriate error message.
 // If the update is successful, it returns the updated user as a JSON response with a HTTP status code 200.
 func (h *Handler) UpdateUserByEmail(c echo.Context) (err error) {
 	userEmail := c.Param("email")
 
-	var user models.User
-	if err := h.DB.Where("email =?", userEmail).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := h.Users.FindByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
 		}
-		h.Log.Error("Erro ao buscar o usuário", "error", err)
+		h.logger(c).Error("Erro ao buscar o usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
 	}
 
-	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
-	}
-
-	if err := c.Validate(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
-	}
-
-	if err := h.DB.Save(&user).Error; err != nil {
-		h.Log.Error("Erro ao atualizar o usuário", "error", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar usuário")
-	}
-
-	return c.JSON(http.StatusOK, user)
+	return h.updateUser(c, user)
 }
 
 // UpdateUserById updates an existing user in the database based on their ID.
@@ -178,39 +239,26 @@ func (h *Handler) UpdateUserByEmail(c echo.Context) (err error) {
 // err error: An error if any occurred during the process. If successful, it returns nil.
 //
 // The function extracts the user's ID from the request parameters.
-// It then queries the database using GORM to find a user with the given ID.
+// It then queries the user repository to find a user with the given ID.
 // If the user is not found, it returns a HTTP status code 404 with an appropriate error message.
-// If the user is found, it binds the request body to the user struct, validates the struct,
-// and saves the updated user to the database.
+// If the user is found and is the authenticated user, it binds the request body to a UserUpdateRequest, validates it,
+// and saves the updated user to the database. Updating another user returns a HTTP status code 403.
 // If there is any error during the binding, validation, or saving process, it logs the error using the provided logger
 // and returns a HTTP status code 500 with an appropriate error message.
 // If the update is successful, it returns the updated user as a JSON response with a HTTP status code 200.
 func (h *Handler) UpdateUserById(c echo.Context) (err error) {
 	userId := c.Param("id")
 
-	var user models.User
-	if err := h.DB.Where("id =?", userId).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := h.Users.FindByID(c.Request().Context(), userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
 		}
-		h.Log.Error("Erro ao buscar usuário", "error", err)
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
 	}
 
-	if err := c.Bind(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
-	}
-
-	if err := c.Validate(&user); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
-	}
-
-	if err := h.DB.Save(&user).Error; err != nil {
-		h.Log.Error("Erro ao atualizar o usuário", "error", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar usuário")
-	}
-
-	return c.JSON(http.StatusOK, user)
+	return h.updateUser(c, user)
 }
 
 // DeleteUserByEmail deletes a user from the database based on their email.
@@ -222,30 +270,28 @@ func (h *Handler) UpdateUserById(c echo.Context) (err error) {
 // err error: An error if any occurred during the process. If successful, it returns nil.
 //
 // The function extracts the user's email from the request parameters.
-// It then queries the database using GORM to find a user with the given email.
+// It then queries the user repository to find a user with the given email.
 // If the user is not found, it returns a HTTP status code 404 with an appropriate error message.
-// If the user is found, it deletes the user from the database.
+// If the user is found and is the authenticated user, it deletes the user from the database, along with the workspaces
+// they own and their personal tasks, labels and comments, while the tasks they created in the workspaces of others
+// are handed over to the owners of those workspaces.
+// Deleting another user returns a HTTP status code 403.
 // If there is any error during the deletion process, it logs the error using the provided logger
 // and returns a HTTP status code 500 with an appropriate error message.
 // If the deletion is successful, it returns a JSON response with a HTTP status code 200 and a message "Usuário Deletado".
 func (h *Handler) DeleteUserByEmail(c echo.Context) (err error) {
 	userEmail := c.Param("email")
 
-	var user models.User
-	if err := h.DB.Where("email =?", userEmail).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := h.Users.FindByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
 		}
-		h.Log.Error("Erro ao buscar usuário", "error", err)
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
 	}
 
-	if err := h.DB.Delete(&user).Error; err != nil {
-		h.Log.Error("Erro ao deletar o usuário", "error", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar usuário")
-	}
-
-	return c.JSON(http.StatusOK, "Usuário Deletado")
+	return h.deleteUser(c, user)
 }
 
 // DeleteUserById deletes a user from the database based on their ID.
@@ -257,28 +303,85 @@ func (h *Handler) DeleteUserByEmail(c echo.Context) (err error) {
 // err error: An error if any occurred during the process. If successful, it returns nil.
 //
 // The function extracts the user's ID from the request parameters.
-// It then queries the database using GORM to find a user with the given ID.
+// It then queries the user repository to find a user with the given ID.
 // If the user is not found, it returns a HTTP status code 404 with an appropriate error message.
-// If the user is found, it deletes the user from the database.
+// If the user is found and is the authenticated user, it deletes the user from the database, along with the workspaces
+// they own and their personal tasks, labels and comments, while the tasks they created in the workspaces of others
+// are handed over to the owners of those workspaces.
+// Deleting another user returns a HTTP status code 403.
 // If there is any error during the deletion process, it logs the error using the provided logger
 // and returns a HTTP status code 500 with an appropriate error message.
 // If the deletion is successful, it returns a JSON response with a HTTP status code 200 and a message "Usuário Deletado".
 func (h *Handler) DeleteUserById(c echo.Context) (err error) {
 	userId := c.Param("id")
 
-	var user models.User
-	if err := h.DB.Where("id =?", userId).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	user, err := h.Users.FindByID(c.Request().Context(), userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
 		}
-		h.Log.Error("Erro ao buscar usuário", "error", err)
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
 	}
 
-	if err := h.DB.Delete(&user).Error; err != nil {
-		h.Log.Error("Erro ao deletar o usuário", "error", err)
+	return h.deleteUser(c, user)
+}
+
+// deleteUser deletes an existing user, along with the workspaces they own and their personal data,
+// and removes the files of the attachments that go with them.
+// Only the authenticated user can delete themselves.
+func (h *Handler) deleteUser(c echo.Context, user *models.User) error {
+	if user.ID != middlewares.UserID(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+	}
+
+	// The attachments are listed first, since their rows go with the user
+	attachments, err := h.Attachments.FindByOwner(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar os anexos do usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar usuário")
+	}
+	if err := h.Users.Delete(c.Request().Context(), user.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar o usuário", "error", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar usuário")
 	}
+	h.removeAttachmentFiles(c, attachments...)
 
 	return c.JSON(http.StatusOK, "Usuário Deletado")
 }
+
+// updateUser applies the request body to an existing user and saves it.
+// Only the authenticated user can update themselves, and only their name, e-mail, age and password.
+// A new password sent in the body is hashed before being stored.
+func (h *Handler) updateUser(c echo.Context, user *models.User) error {
+	if user.ID != middlewares.UserID(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+	}
+
+	req := &UserUpdateRequest{Name: user.Name, Email: user.Email, Age: user.Age}
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	user.Name, user.Email, user.Age = req.Name, req.Email, req.Age
+
+	if req.Password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao gerar hash da senha")
+		}
+		user.Password = string(hashed)
+	}
+
+	if err := h.Users.Update(c.Request().Context(), user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return echo.NewHTTPError(http.StatusConflict, "E-mail já cadastrado")
+		}
+		h.logger(c).Error("Erro ao atualizar o usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar usuário")
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// GetWorkspaceWorkflow returns, with a HTTP status code 200, the workflow the tasks of a workspace follow:
+// the one configured by its owner, or models.DefaultWorkflow.
+func (h *Handler) GetWorkspaceWorkflow(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := h.workspaceWorkflow(c, workspace.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, workflow)
+}
+
+// UpdateWorkspaceWorkflow configures the workflow of a workspace. Only the owner can configure it.
+// It expects a JSON object in the request body with the "statuses", each with a "name" and whether it's "terminal",
+// the "initial" status of new tasks, which can't be terminal, and the allowed "transitions" between statuses.
+// At least one status must be terminal. Tasks left in a status the new workflow doesn't have keep it,
+// and can move to any status of the new workflow.
+// If successful, it returns the workflow with a HTTP status code 200.
+func (h *Handler) UpdateWorkspaceWorkflow(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	workflow := new(models.Workflow)
+	if err := c.Bind(workflow); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	workflow.WorkspaceID = workspace.ID
+	if workflow.Transitions == nil {
+		workflow.Transitions = []models.WorkflowTransition{}
+	}
+
+	if err := c.Validate(workflow); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+	if message := checkWorkflow(workflow); message != "" {
+		return echo.NewHTTPError(http.StatusBadRequest, message)
+	}
+
+	if err := h.Workspaces.SaveWorkflow(c.Request().Context(), workflow); err != nil {
+		h.logger(c).Error("Erro ao salvar o workflow", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao salvar workflow")
+	}
+	return c.JSON(http.StatusOK, workflow)
+}
+
+// DeleteWorkspaceWorkflow drops the workflow configured for a workspace, whose tasks go back to following
+// models.DefaultWorkflow. Only the owner can drop it.
+// If successful, it returns the default workflow with a HTTP status code 200.
+func (h *Handler) DeleteWorkspaceWorkflow(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if err := h.Workspaces.DeleteWorkflow(c.Request().Context(), workspace.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		h.logger(c).Error("Erro ao deletar o workflow", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar workflow")
+	}
+
+	workflow := models.DefaultWorkflow()
+	workflow.WorkspaceID = workspace.ID
+	return c.JSON(http.StatusOK, workflow)
+}
+
+// GetTaskTransitions returns, with a HTTP status code 200, the statuses a task can move to from its current one.
+func (h *Handler) GetTaskTransitions(c echo.Context) (err error) {
+	task, err := h.findTask(c, models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := h.taskWorkflow(c, task)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, workflow.Next(task.Status))
+}
+
+// taskWorkflow returns the workflow the task follows.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) taskWorkflow(c echo.Context, task *models.Tasks) (*models.Workflow, error) {
+	if task.WorkspaceID == nil {
+		return models.DefaultWorkflow(), nil
+	}
+	return h.workspaceWorkflow(c, *task.WorkspaceID)
+}
+
+// workspaceWorkflow returns the workflow the tasks of the workspace follow.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) workspaceWorkflow(c echo.Context, workspaceID string) (*models.Workflow, error) {
+	workflow, err := h.Workspaces.FindWorkflow(c.Request().Context(), workspaceID)
+	if errors.Is(err, repository.ErrNotFound) {
+		workflow = models.DefaultWorkflow()
+		workflow.WorkspaceID = workspaceID
+		return workflow, nil
+	}
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar o workflow", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar workflow")
+	}
+	return workflow, nil
+}
+
+// applyStatus checks the status of the task against the workflow it follows and keeps its CompletedAt in step.
+// New tasks, whose previous status is empty, start in the initial status unless they ask for another one,
+// while existing tasks can only take the transitions of the workflow.
+// Tasks in a terminal status are completed, keeping the completion time they already have, and the others aren't.
+// Tasks changing status go to the end of the new status' board column, as long as its WIP limit allows it.
+// It returns the WIP limit of the column the task enters, which must be enforced again when the task is saved
+// with TaskRepository.CreateInColumn or UpdateInColumn, or 0 when the task stays in its column.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) applyStatus(c echo.Context, task *models.Tasks, previous string) (int, error) {
+	workflow, err := h.taskWorkflow(c, task)
+	if err != nil {
+		return 0, err
+	}
+
+	if task.Status == "" {
+		task.Status = workflow.Initial
+	}
+	status, ok := workflow.Status(task.Status)
+	if !ok {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "Status inválido: "+task.Status)
+	}
+	if previous != "" && !workflow.CanTransition(previous, task.Status) {
+		return 0, echo.NewHTTPError(http.StatusConflict, "Transição de status não permitida: "+previous+" → "+task.Status)
+	}
+
+	switch {
+	case !status.Terminal:
+		task.CompletedAt = nil
+	case task.CompletedAt == nil:
+		now := time.Now()
+		task.CompletedAt = &now
+	}
+
+	if task.Status != previous {
+		return status.WIPLimit, h.enterColumn(c, task, status)
+	}
+	return 0, nil
+}
+
+// checkWorkflow returns why the workflow is invalid, or an empty string when it's valid.
+func checkWorkflow(workflow *models.Workflow) string {
+	names := map[string]bool{}
+	terminal := false
+	for _, status := range workflow.Statuses {
+		if names[status.Name] {
+			return "Status repetido: " + status.Name
+		}
+		names[status.Name] = true
+		terminal = terminal || status.Terminal
+	}
+	if !terminal {
+		return "O workflow precisa de ao menos um status terminal"
+	}
+
+	initial, ok := workflow.Status(workflow.Initial)
+	if !ok {
+		return "Status inicial inválido: " + workflow.Initial
+	}
+	if initial.Terminal {
+		return "O status inicial não pode ser terminal"
+	}
+
+	for _, transition := range workflow.Transitions {
+		if !names[transition.From] || !names[transition.To] || transition.From == transition.To {
+			return "Transição inválida: " + transition.From + " → " + transition.To
+		}
+	}
+	return ""
+}
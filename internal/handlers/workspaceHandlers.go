@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/mail"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// InvitationTTL is how long a workspace invitation can be accepted after being created.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// CreateWorkspace handles the creation of a new workspace.
+// It expects a JSON object in the request body with the workspace's "name".
+// The authenticated user becomes the owner and first member of the workspace.
+// If successful, it returns a JSON object with the created workspace and a HTTP status code 201.
+func (h *Handler) CreateWorkspace(c echo.Context) (err error) {
+	w := new(models.Workspace)
+	if err = c.Bind(w); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid request"})
+	}
+	w.OwnerID = middlewares.UserID(c)
+	w.Members = nil
+	w.CreatedAt, w.UpdatedAt = time.Time{}, time.Time{}
+
+	if err := c.Validate(w); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.Workspaces.Create(c.Request().Context(), w); err != nil {
+		h.logger(c).Error("Erro ao criar workspace", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar workspace")
+	}
+
+	return c.JSON(http.StatusCreated, w)
+}
+
+// GetMyWorkspaces returns, with a HTTP status code 200, every workspace the authenticated user is a member of.
+func (h *Handler) GetMyWorkspaces(c echo.Context) (err error) {
+	workspaces, err := h.Workspaces.FindByMember(c.Request().Context(), middlewares.UserID(c))
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar workspaces", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar workspaces")
+	}
+	return c.JSON(http.StatusOK, workspaces)
+}
+
+// GetWorkspaceById retrieves a workspace based on its ID.
+// Only members can see a workspace, for anyone else it returns a HTTP status code 404.
+func (h *Handler) GetWorkspaceById(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspaceById renames a workspace. Its ID, owner and dates can't be changed through this endpoint.
+// Only the owner can update it, other members get a HTTP status code 403.
+// If the update is successful, it returns the updated workspace as a JSON response with a HTTP status code 200.
+func (h *Handler) UpdateWorkspaceById(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	id, owner, created := workspace.ID, workspace.OwnerID, workspace.CreatedAt
+	if err := c.Bind(workspace); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	workspace.ID, workspace.OwnerID, workspace.Members, workspace.CreatedAt = id, owner, nil, created
+
+	if err := c.Validate(workspace); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	if err := h.Workspaces.Update(c.Request().Context(), workspace); err != nil {
+		h.logger(c).Error("Erro ao atualizar o workspace", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar workspace")
+	}
+
+	return c.JSON(http.StatusOK, workspace)
+}
+
+// DeleteWorkspaceById deletes a workspace along with its members, invitations and tasks,
+// and removes the files of the attachments of those tasks.
+// Only the owner can delete it, other members get a HTTP status code 403.
+// If the deletion is successful, it returns a JSON response with a HTTP status code 200 and a message "Workspace Deletado".
+func (h *Handler) DeleteWorkspaceById(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	// The attachments are listed first, since their rows go with the tasks
+	attachments, err := h.Attachments.FindByWorkspace(c.Request().Context(), workspace.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar os anexos do workspace", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar workspace")
+	}
+	if err := h.Workspaces.Delete(c.Request().Context(), workspace.ID); err != nil {
+		h.logger(c).Error("Erro ao deletar o workspace", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao deletar workspace")
+	}
+	h.removeAttachmentFiles(c, attachments...)
+
+	return c.JSON(http.StatusOK, "Workspace Deletado")
+}
+
+// GetWorkspaceMembers returns, with a HTTP status code 200, the members of a workspace and their roles.
+// Only members can list them.
+func (h *Handler) GetWorkspaceMembers(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	members, err := h.Workspaces.ListMembers(c.Request().Context(), workspace.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar membros", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar membros")
+	}
+	return c.JSON(http.StatusOK, members)
+}
+
+// UpdateWorkspaceMember changes the role of a member of a workspace to "editor" or "viewer".
+// Only the owner can change roles, and the owner's own role can't be changed.
+// If successful, it returns the updated membership as a JSON response with a HTTP status code 200.
+func (h *Handler) UpdateWorkspaceMember(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	req := new(MemberRoleRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	member, err := h.findMember(c, workspace, c.Param("userId"))
+	if err != nil {
+		return err
+	}
+	if member.Role == models.RoleOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "O papel do dono não pode ser alterado")
+	}
+
+	member.Role = req.Role
+	if err := h.Workspaces.SaveMember(c.Request().Context(), member); err != nil {
+		h.logger(c).Error("Erro ao atualizar o membro", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao atualizar membro")
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// RemoveWorkspaceMember removes a member from a workspace.
+// The owner can remove any other member, and every member can remove themselves to leave the workspace.
+// The owner can't leave their own workspace, they must delete it instead.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Membro Removido".
+func (h *Handler) RemoveWorkspaceMember(c echo.Context) (err error) {
+	targetID := c.Param("userId")
+	required := models.RoleOwner
+	if targetID == middlewares.UserID(c) {
+		required = models.RoleViewer
+	}
+
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), required)
+	if err != nil {
+		return err
+	}
+
+	member, err := h.findMember(c, workspace, targetID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.RoleOwner {
+		return echo.NewHTTPError(http.StatusForbidden, "O dono não pode sair do workspace")
+	}
+
+	if err := h.Workspaces.RemoveMember(c.Request().Context(), workspace.ID, member.UserID); err != nil {
+		h.logger(c).Error("Erro ao remover o membro", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao remover membro")
+	}
+
+	return c.JSON(http.StatusOK, "Membro Removido")
+}
+
+// InviteToWorkspace invites an e-mail address to join a workspace as an "editor" or a "viewer".
+// It expects a JSON object in the request body with the "email" and the "role". Only the owner can invite.
+// The secret token of the invitation is e-mailed to the invited person through the Mailer, and never returned,
+// so only they can accept the invitation with it, within InvitationTTL.
+// The response carries the invitation, with a HTTP status code 201. When the e-mail can't be sent,
+// the invitation is dropped and a HTTP status code 502 is returned.
+func (h *Handler) InviteToWorkspace(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	invitation := new(models.WorkspaceInvitation)
+	if err := c.Bind(invitation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Dados inválidos"})
+	}
+	if err := c.Validate(invitation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		h.logger(c).Error("Erro ao gerar token do convite", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar convite")
+	}
+
+	invitation.ID = 0
+	invitation.WorkspaceID = workspace.ID
+	invitation.Email = strings.ToLower(invitation.Email)
+	invitation.TokenHash = hash
+	invitation.InvitedBy = middlewares.UserID(c)
+	invitation.ExpiresAt = time.Now().Add(InvitationTTL)
+	invitation.AcceptedAt = nil
+
+	if err := h.Workspaces.CreateInvitation(c.Request().Context(), invitation); err != nil {
+		h.logger(c).Error("Erro ao criar convite", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao criar convite")
+	}
+
+	if err := h.Mailer.Send(c.Request().Context(), invitationMessage(workspace, invitation, token)); err != nil {
+		h.logger(c).Error("Erro ao enviar o convite", "error", err)
+		// Nobody could accept the invitation without its token, so it doesn't stay pending
+		if err := h.Workspaces.DeleteInvitation(c.Request().Context(), workspace.ID, invitation.ID); err != nil {
+			h.logger(c).Error("Erro ao revogar o convite", "error", err)
+		}
+		return echo.NewHTTPError(http.StatusBadGateway, "Erro ao enviar convite")
+	}
+
+	return c.JSON(http.StatusCreated, invitation)
+}
+
+// invitationMessage returns the e-mail handing the token of the invitation over to the invited person.
+func invitationMessage(workspace *models.Workspace, invitation *models.WorkspaceInvitation, token string) mail.Message {
+	return mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("Convite para o workspace %s", workspace.Name),
+		Body: fmt.Sprintf("Você foi convidado para participar do workspace %s como %s.\n\n"+
+			"Para aceitar, entre com este e-mail e envie o token abaixo para POST /workspaces/accept/{token} até %s.\n\n"+
+			"Token: %s\n", workspace.Name, invitation.Role, invitation.ExpiresAt.UTC().Format("02/01/2006 15:04 UTC"), token),
+	}
+}
+
+// GetWorkspaceInvitations returns, with a HTTP status code 200, the invitations of a workspace that weren't accepted yet.
+// Only the owner can list them.
+func (h *Handler) GetWorkspaceInvitations(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	invitations, err := h.Workspaces.ListInvitations(c.Request().Context(), workspace.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar convites", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar convites")
+	}
+	return c.JSON(http.StatusOK, invitations)
+}
+
+// DeleteWorkspaceInvitation revokes an invitation. Only the owner can revoke invitations.
+// If successful, it returns a JSON response with a HTTP status code 200 and a message "Convite Revogado".
+func (h *Handler) DeleteWorkspaceInvitation(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("invitationId"), 10, 0)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID de convite inválido")
+	}
+
+	if err := h.Workspaces.DeleteInvitation(c.Request().Context(), workspace.ID, uint(invitationID)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Convite não encontrado")
+		}
+		h.logger(c).Error("Erro ao revogar o convite", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao revogar convite")
+	}
+
+	return c.JSON(http.StatusOK, "Convite Revogado")
+}
+
+// AcceptWorkspaceInvitation makes the authenticated user a member of the workspace they were invited to.
+// The token comes from the route parameters, and the invitation must have been sent to the user's e-mail.
+// Unknown or already accepted tokens return a HTTP status code 404, expired ones a HTTP status code 410,
+// and invitations to another e-mail a HTTP status code 403.
+// If successful, it returns the new membership as a JSON response with a HTTP status code 200.
+func (h *Handler) AcceptWorkspaceInvitation(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+
+	invitation, err := h.Workspaces.FindInvitationByTokenHash(ctx, auth.HashToken(c.Param("token")))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Convite não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar o convite", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar convite")
+	}
+	if invitation.AcceptedAt != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Convite não encontrado")
+	}
+	if invitation.Expired(time.Now()) {
+		return echo.NewHTTPError(http.StatusGone, "Convite expirado")
+	}
+
+	user, err := h.Users.FindByID(ctx, middlewares.UserID(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Usuário não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar usuário", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar usuário")
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return echo.NewHTTPError(http.StatusForbidden, "Convite enviado para outro e-mail")
+	}
+
+	if _, err := h.Workspaces.FindMember(ctx, invitation.WorkspaceID, user.ID); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "Usuário já é membro do workspace")
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		h.logger(c).Error("Erro ao buscar membro", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar membro")
+	}
+
+	member := &models.WorkspaceMember{WorkspaceID: invitation.WorkspaceID, UserID: user.ID, Role: invitation.Role}
+	if err := h.Workspaces.AcceptInvitation(ctx, invitation, member); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Convite não encontrado")
+		}
+		h.logger(c).Error("Erro ao aceitar o convite", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao aceitar convite")
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// GetWorkspaceTasks returns, with a HTTP status code 200, every task of a workspace. Only members can list them.
+// The "labels" and "match" query parameters keep the tasks tagged with all, or any, of the given labels.
+func (h *Handler) GetWorkspaceTasks(c echo.Context) (err error) {
+	workspace, _, err := h.workspaceAccess(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.Tasks.FindByWorkspace(c.Request().Context(), workspace.ID)
+	if err != nil {
+		h.logger(c).Error("Erro ao buscar tarefas", "error", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar tarefas")
+	}
+
+	tasks, err = h.filterByLabels(c, tasks)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, tasks)
+}
+
+// workspaceAccess loads a workspace and checks that the authenticated user is a member with at least the given role.
+// Workspaces the user isn't a member of are reported as not found, so their IDs aren't disclosed,
+// while members without the required role get a HTTP status code 403.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) workspaceAccess(c echo.Context, workspaceID string, role models.WorkspaceRole) (*models.Workspace, *models.WorkspaceMember, error) {
+	ctx := c.Request().Context()
+
+	member, err := h.Workspaces.FindMember(ctx, workspaceID, middlewares.UserID(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Workspace não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar membro", "error", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar workspace")
+	}
+
+	workspace, err := h.Workspaces.FindByID(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Workspace não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar workspace", "error", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar workspace")
+	}
+
+	if !member.Role.Includes(role) {
+		return nil, nil, echo.NewHTTPError(http.StatusForbidden, "Acesso negado")
+	}
+	return workspace, member, nil
+}
+
+// findMember loads the membership of the user in the workspace.
+// The returned error is already an *echo.HTTPError ready to be returned by the handler.
+func (h *Handler) findMember(c echo.Context, workspace *models.Workspace, userID string) (*models.WorkspaceMember, error) {
+	member, err := h.Workspaces.FindMember(c.Request().Context(), workspace.ID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Membro não encontrado")
+		}
+		h.logger(c).Error("Erro ao buscar membro", "error", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "Erro ao buscar membro")
+	}
+	return member, nil
+}
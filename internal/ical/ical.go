@@ -0,0 +1,228 @@
+// Package ical writes tasks as iCalendar (RFC 5545) documents, the format calendar applications subscribe to,
+// and reads back the to-dos CalDAV clients send.
+package ical
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// Component is the kind of calendar component tasks are written as.
+type Component string
+
+const (
+	// Todo writes tasks as VTODO components, which carry their status and completion but aren't shown by every calendar.
+	Todo Component = "VTODO"
+	// Event writes tasks as VEVENT components starting at their due date, which every calendar shows.
+	Event Component = "VEVENT"
+)
+
+// ProductID identifies NullTask as the producer of the calendars.
+const ProductID = "-//NullTask//NullTask//PT"
+
+// stampFormat is the format of UTC date-times in iCalendar.
+const stampFormat = "20060102T150405Z"
+
+// rulePattern matches the recurrence rules that are safe to write as they are: no line breaks nor property separators.
+var rulePattern = regexp.MustCompile(`^[A-Za-z0-9=;,+-]+$`)
+
+// Calendar returns the calendar named name holding a component for every task with a due date, in the given order.
+// Tasks without a due date have no place in a calendar and are left out.
+func Calendar(name string, component Component, tasks []models.Tasks) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", ProductID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", escape(name))
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+	for _, task := range tasks {
+		if task.DueDate != nil {
+			w.task(component, &task, UID(&task), nil)
+		}
+	}
+	w.line("END", "VCALENDAR")
+	return []byte(w.b.String())
+}
+
+// Object returns the calendar object of a task on CalDAV: a calendar holding the task as a VTODO with the given UID,
+// due date or not. The properties, raw content lines the task has no field for, are written in the VTODO as they are,
+// and the components, raw content lines of the other components of the calendar such as VTIMEZONE, after it.
+func Object(task *models.Tasks, uid string, properties, components []string) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", ProductID)
+	w.task(Todo, task, uid, properties)
+	for _, line := range components {
+		w.raw(line)
+	}
+	w.line("END", "VCALENDAR")
+	return []byte(w.b.String())
+}
+
+// UID returns the unique identifier of the task's component, the same in every calendar it's written to.
+func UID(task *models.Tasks) string {
+	return fmt.Sprintf("task-%d@nulltask", task.ID)
+}
+
+// writer builds a document line by line.
+type writer struct {
+	b strings.Builder
+}
+
+// task writes the task as a component of the given kind, followed by the raw content lines of properties.
+// Events must have a due date.
+func (w *writer) task(component Component, task *models.Tasks, uid string, properties []string) {
+	w.line("BEGIN", string(component))
+	w.line("UID", escape(uid))
+	w.line("DTSTAMP", stamp(task.UpdatedAt))
+	w.line("CREATED", stamp(task.CreatedAt))
+	w.line("LAST-MODIFIED", stamp(task.UpdatedAt))
+	w.line("SUMMARY", escape(task.Title))
+	if task.Description != nil && *task.Description != "" {
+		w.line("DESCRIPTION", escape(*task.Description))
+	}
+
+	if component == Todo {
+		if task.DueDate != nil {
+			w.line("DUE", stamp(*task.DueDate))
+		}
+		w.line("STATUS", todoStatus(task))
+		if task.CompletedAt != nil {
+			w.line("COMPLETED", stamp(*task.CompletedAt))
+		}
+	} else {
+		w.line("DTSTART", stamp(*task.DueDate))
+		if task.Status == models.TaskCancelled {
+			w.line("STATUS", "CANCELLED")
+		} else {
+			w.line("STATUS", "CONFIRMED")
+		}
+	}
+
+	if priority := priority(task.Priority); priority != 0 {
+		w.line("PRIORITY", fmt.Sprint(priority))
+	}
+	if task.Recurrence != nil && rulePattern.MatchString(*task.Recurrence) {
+		w.line("RRULE", *task.Recurrence)
+	}
+
+	// The alarm is relative to the due date, so it repeats along with a recurring task
+	if task.Reminder != nil && task.DueDate != nil {
+		w.line("BEGIN", "VALARM")
+		w.line("ACTION", "DISPLAY")
+		w.line("DESCRIPTION", escape(task.Title))
+		trigger := duration(task.Reminder.Sub(*task.DueDate))
+		if component == Todo {
+			w.line("TRIGGER;RELATED=END", trigger)
+		} else {
+			w.line("TRIGGER", trigger)
+		}
+		w.line("END", "VALARM")
+	}
+	for _, line := range properties {
+		w.raw(line)
+	}
+	w.line("END", string(component))
+}
+
+// line writes a content line with the given name and value.
+func (w *writer) line(name, value string) {
+	w.raw(name + ":" + value)
+}
+
+// raw writes an unfolded content line, folding it into lines of at most 75 octets without splitting characters.
+func (w *writer) raw(line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.b.WriteString(line[:cut])
+		w.b.WriteString("\r\n ")
+		line = line[cut:]
+		// The space starting the continuation line counts towards its length
+		limit = 74
+	}
+	w.b.WriteString(line)
+	w.b.WriteString("\r\n")
+}
+
+// textEscaper escapes the characters with a meaning of their own in TEXT values.
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escape returns the text as a TEXT value.
+func escape(text string) string {
+	return textEscaper.Replace(text)
+}
+
+// stamp returns the time as a UTC date-time.
+func stamp(t time.Time) string {
+	return t.UTC().Format(stampFormat)
+}
+
+// duration returns d as a DURATION value, such as "-PT30M" or "-P1DT2H".
+func duration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	seconds := int64(d / time.Second)
+	days, seconds := seconds/86400, seconds%86400
+	hours, seconds := seconds/3600, seconds%3600
+	minutes, seconds := seconds/60, seconds%60
+
+	var b strings.Builder
+	b.WriteString(sign + "P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if seconds > 0 || hours == 0 && minutes == 0 {
+			fmt.Fprintf(&b, "%dS", seconds)
+		}
+	}
+	return b.String()
+}
+
+// todoStatus returns the status of a VTODO for the task.
+func todoStatus(task *models.Tasks) string {
+	switch {
+	case task.Status == models.TaskCancelled:
+		return "CANCELLED"
+	case task.CompletedAt != nil:
+		return "COMPLETED"
+	case task.Status == models.TaskInProgress:
+		return "IN-PROCESS"
+	}
+	return "NEEDS-ACTION"
+}
+
+// priority returns the iCalendar priority of a task's priority, where 1 is the highest, 9 the lowest and 0 undefined.
+func priority(priority int) int {
+	switch priority {
+	case models.PriorityHigh:
+		return 1
+	case models.PriorityMedium:
+		return 5
+	case models.PriorityLow:
+		return 9
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestCalendar(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 6, 10, 17, 0, 0, 0, time.FixedZone("America/Sao_Paulo", -3*60*60))
+	reminder := due.Add(-30 * time.Minute)
+	description := "Aluguel, condomínio; e luz\nantes das 18h"
+	rule := "FREQ=MONTHLY"
+	tasks := []models.Tasks{
+		{ID: 1, Title: "Pagar contas", Description: &description, Status: models.TaskPending, Priority: models.PriorityHigh,
+			DueDate: &due, Reminder: &reminder, Recurrence: &rule, CreatedAt: created, UpdatedAt: created},
+		{ID: 2, Title: "Sem data", Status: models.TaskPending, CreatedAt: created, UpdatedAt: created},
+	}
+
+	calendar := string(Calendar("NullTask", Todo, tasks))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"BEGIN:VTODO\r\nUID:task-1@nulltask\r\nDTSTAMP:20240601T120000Z\r\n",
+		"SUMMARY:Pagar contas\r\n",
+		`DESCRIPTION:Aluguel\, condomínio\; e luz\nantes das 18h` + "\r\n",
+		"DUE:20240610T200000Z\r\nSTATUS:NEEDS-ACTION\r\n",
+		"PRIORITY:1\r\nRRULE:FREQ=MONTHLY\r\n",
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nDESCRIPTION:Pagar contas\r\nTRIGGER;RELATED=END:-PT30M\r\nEND:VALARM\r\n",
+		"END:VTODO\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(calendar, want) {
+			t.Fatalf("expected the calendar to contain %q, got\n%s", want, calendar)
+		}
+	}
+	if strings.Contains(calendar, "task-2@nulltask") {
+		t.Fatalf("expected tasks without a due date to be left out, got\n%s", calendar)
+	}
+
+	calendar = string(Calendar("NullTask", Event, tasks))
+	if !strings.Contains(calendar, "BEGIN:VEVENT\r\n") || !strings.Contains(calendar, "DTSTART:20240610T200000Z\r\n") ||
+		!strings.Contains(calendar, "TRIGGER:-PT30M\r\n") {
+		t.Fatalf("expected the task as an event, got\n%s", calendar)
+	}
+}
+
+func TestCalendarSkipsUnsafeRules(t *testing.T) {
+	due := time.Date(2024, 6, 10, 17, 0, 0, 0, time.UTC)
+	rule := "FREQ=DAILY\r\nATTENDEE:mailto:someone@example.com"
+	calendar := string(Calendar("NullTask", Event, []models.Tasks{{ID: 1, Title: "Regar", DueDate: &due, Recurrence: &rule}}))
+	if strings.Contains(calendar, "RRULE") || strings.Contains(calendar, "ATTENDEE") {
+		t.Fatalf("expected the rule to be left out, got\n%s", calendar)
+	}
+}
+
+func TestFolding(t *testing.T) {
+	w := &writer{}
+	w.line("SUMMARY", strings.Repeat("ação ", 30))
+	for _, line := range strings.Split(strings.TrimSuffix(w.b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("expected lines of at most 75 octets, got %d in %q", len(line), line)
+		}
+	}
+	unfolded := strings.ReplaceAll(w.b.String(), "\r\n ", "")
+	if unfolded != "SUMMARY:"+strings.Repeat("ação ", 30)+"\r\n" {
+		t.Fatalf("expected the folded line to unfold to the original one, got %q", unfolded)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                "PT0S",
+		-30 * time.Minute:                "-PT30M",
+		-(26*time.Hour + 15*time.Minute): "-P1DT2H15M",
+		-48 * time.Hour:                  "-P2D",
+		90 * time.Second:                 "PT1M30S",
+	}
+	for d, want := range tests {
+		if got := duration(d); got != want {
+			t.Fatalf("duration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
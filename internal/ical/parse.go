@@ -0,0 +1,364 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// ErrNoTodo is returned by Parse for calendars without a VTODO.
+var ErrNoTodo = errors.New("ical: the calendar has no VTODO")
+
+// Entry is a VTODO read by Parse, with its properties mapped onto the fields of a task.
+type Entry struct {
+	UID         string
+	Summary     string
+	Description *string
+	Due         *time.Time
+	// Status is the iCalendar status, such as NEEDS-ACTION or COMPLETED, or empty when the VTODO has none.
+	Status    string
+	Completed *time.Time
+	// Priority is the priority of the task, from models.PriorityNone to models.PriorityHigh.
+	Priority   int
+	Recurrence *string
+	// Reminder is when the first alarm of the VTODO goes off, or nil when it has no alarm with a known time.
+	Reminder *time.Time
+	// Properties are the raw content lines of the VTODO that have no field of their own,
+	// along with its subcomponents other than the alarm read into Reminder.
+	Properties []string
+	// Components are the raw content lines of the other components of the calendar,
+	// such as the VTIMEZONE its times refer to or the overrides of a recurring VTODO.
+	Components []string
+}
+
+// contentLine is an unfolded content line split into its name, parameters and value.
+type contentLine struct {
+	raw    string
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the first VTODO of an iCalendar document.
+// Times without a time zone, or in a time zone that can't be loaded, are taken to be in loc,
+// and due dates without a time of day are due at 23:59 of that day.
+func Parse(data []byte, loc *time.Location) (*Entry, error) {
+	entry := &Entry{}
+	var stack []string
+	var start *time.Time
+	var alarm []contentLine
+	found, inTodo, inAlarm := false, false, false
+
+	for _, raw := range unfold(string(data)) {
+		line, err := splitLine(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		// depth is how deep the line is, counting the component it begins or ends
+		depth := len(stack)
+		switch line.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(line.value))
+			depth = len(stack)
+			if depth == 2 && stack[1] == "VTODO" && !found {
+				found, inTodo = true, true
+				continue
+			}
+			if depth == 3 && inTodo && stack[2] == "VALARM" && alarm == nil {
+				inAlarm = true
+			}
+		case "END":
+			if depth == 0 {
+				return nil, fmt.Errorf("ical: unexpected END:%s", line.value)
+			}
+			stack = stack[:depth-1]
+			if depth == 2 && inTodo {
+				inTodo = false
+				continue
+			}
+		}
+
+		switch {
+		case depth <= 1:
+			// The properties of the calendar itself, such as VERSION and PRODID, are written anew
+		case !inTodo:
+			entry.Components = append(entry.Components, line.raw)
+		case inAlarm:
+			alarm = append(alarm, line)
+			inAlarm = !(line.name == "END" && depth == 3)
+		case depth > 2:
+			entry.Properties = append(entry.Properties, line.raw)
+		default:
+			if err := entry.set(line, loc, &start); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !found {
+		return nil, ErrNoTodo
+	}
+
+	if reminder, ok := alarmTime(alarm, entry.Due, start, loc); ok {
+		entry.Reminder = &reminder
+	} else {
+		for _, line := range alarm {
+			entry.Properties = append(entry.Properties, line.raw)
+		}
+	}
+	return entry, nil
+}
+
+// set reads a property of the VTODO into the entry, keeping the ones without a field of their own as they are.
+// The start of the VTODO, which alarms may be relative to, goes into start.
+func (entry *Entry) set(line contentLine, loc *time.Location, start **time.Time) error {
+	switch line.name {
+	case "UID":
+		entry.UID = unescape(line.value)
+	case "SUMMARY":
+		entry.Summary = unescape(line.value)
+	case "DESCRIPTION":
+		description := unescape(line.value)
+		entry.Description = &description
+	case "DUE":
+		due, err := parseTime(line, loc)
+		if err != nil {
+			return err
+		}
+		entry.Due = &due
+	case "STATUS":
+		entry.Status = strings.ToUpper(line.value)
+	case "COMPLETED":
+		completed, err := parseTime(line, loc)
+		if err != nil {
+			return err
+		}
+		entry.Completed = &completed
+	case "PRIORITY":
+		priority, err := strconv.Atoi(line.value)
+		if err != nil {
+			return fmt.Errorf("ical: invalid PRIORITY %q", line.value)
+		}
+		entry.Priority = taskPriority(priority)
+	case "RRULE":
+		if !rulePattern.MatchString(line.value) {
+			entry.Properties = append(entry.Properties, line.raw)
+			break
+		}
+		rule := line.value
+		entry.Recurrence = &rule
+	case "DTSTAMP", "CREATED", "LAST-MODIFIED":
+		// Written anew from the task
+	default:
+		if line.name == "DTSTART" {
+			if t, err := parseTime(line, loc); err == nil {
+				*start = &t
+			}
+		}
+		entry.Properties = append(entry.Properties, line.raw)
+	}
+	return nil
+}
+
+// alarmTime returns when the alarm, given by its content lines, goes off.
+// Relative triggers are relative to the start of the VTODO, or to its due date when it has no start or they say so.
+func alarmTime(alarm []contentLine, due, start *time.Time, loc *time.Location) (time.Time, bool) {
+	for _, line := range alarm {
+		if line.name != "TRIGGER" {
+			continue
+		}
+		if line.params["VALUE"] == "DATE-TIME" {
+			t, err := parseTime(line, loc)
+			return t, err == nil
+		}
+
+		offset, err := parseDuration(line.value)
+		if err != nil {
+			return time.Time{}, false
+		}
+		base := due
+		if line.params["RELATED"] != "END" && start != nil {
+			base = start
+		}
+		if base == nil {
+			return time.Time{}, false
+		}
+		return base.Add(offset), true
+	}
+	return time.Time{}, false
+}
+
+// unfold splits the document in content lines, joining the lines folded by the writer.
+func unfold(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitLine splits a content line in its name, parameters and value.
+func splitLine(raw string) (contentLine, error) {
+	line := contentLine{raw: raw, params: map[string]string{}}
+	i := strings.IndexAny(raw, ";:")
+	if i <= 0 {
+		return line, fmt.Errorf("ical: invalid content line %q", raw)
+	}
+	line.name = strings.ToUpper(raw[:i])
+
+	rest := raw[i:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return line, fmt.Errorf("ical: invalid parameter in %q", raw)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return line, fmt.Errorf("ical: unterminated quote in %q", raw)
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return line, fmt.Errorf("ical: invalid parameter in %q", raw)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		line.params[key] = value
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return line, fmt.Errorf("ical: invalid content line %q", raw)
+	}
+	line.value = rest[1:]
+	return line, nil
+}
+
+// textUnescaper undoes what textEscaper does.
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// unescape returns the text of a TEXT value.
+func unescape(value string) string {
+	return textUnescaper.Replace(value)
+}
+
+// parseTime reads a DATE or DATE-TIME value. Dates are read as 23:59 of that day in loc.
+func parseTime(line contentLine, loc *time.Location) (time.Time, error) {
+	value := line.value
+	if line.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		day, err := time.Parse("20060102", value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("ical: invalid %s %q", line.name, value)
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 0, 0, loc), nil
+	}
+
+	var t time.Time
+	var err error
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(stampFormat, value)
+	} else {
+		t, err = time.ParseInLocation("20060102T150405", value, zone(line.params["TZID"], loc))
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ical: invalid %s %q", line.name, value)
+	}
+	return t, nil
+}
+
+// zone loads the time zone with the given TZID, falling back to loc.
+// Clients prefix the IANA names with paths of their own, such as "/mozilla.org/20050126_1/Europe/Berlin",
+// so the shorter suffixes of the TZID are tried as well.
+func zone(tzid string, loc *time.Location) *time.Location {
+	if tzid == "" {
+		return loc
+	}
+	parts := strings.Split(tzid, "/")
+	for i := range parts {
+		if z, err := time.LoadLocation(strings.Join(parts[i:], "/")); err == nil && parts[i] != "" {
+			return z
+		}
+	}
+	return loc
+}
+
+// parseDuration reads a DURATION value, such as "-PT15M" or "P1W".
+func parseDuration(value string) (time.Duration, error) {
+	s, negative := strings.CutPrefix(value, "-")
+	if !negative {
+		s = strings.TrimPrefix(s, "+")
+	}
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok || s == "" {
+		return 0, fmt.Errorf("ical: invalid duration %q", value)
+	}
+
+	var d time.Duration
+	n, digits, inTime, units := 0, false, false, 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			n, digits = n*10+int(r-'0'), true
+			continue
+		case r == 'T' && !digits && !inTime:
+			inTime = true
+			continue
+		case !digits:
+			return 0, fmt.Errorf("ical: invalid duration %q", value)
+		}
+
+		var unit time.Duration
+		switch {
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("ical: invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+		n, digits = 0, false
+		units++
+	}
+	if digits || units == 0 {
+		return 0, fmt.Errorf("ical: invalid duration %q", value)
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+// taskPriority returns the priority of a task for an iCalendar priority, where 1 is the highest and 9 the lowest.
+func taskPriority(priority int) int {
+	switch {
+	case priority >= 1 && priority <= 4:
+		return models.PriorityHigh
+	case priority == 5:
+		return models.PriorityMedium
+	case priority >= 6 && priority <= 9:
+		return models.PriorityLow
+	}
+	return models.PriorityNone
+}
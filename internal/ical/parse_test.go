@@ -0,0 +1,154 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// thunderbird is a to-do as Thunderbird sends it, with a time zone, a relative alarm and properties of its own.
+const thunderbird = "BEGIN:VCALENDAR\r\n" +
+	"PRODID:-//Mozilla.org/NONSGML Mozilla Calendar V1.1//EN\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:America/Sao_Paulo\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"TZOFFSETFROM:-0300\r\n" +
+	"TZOFFSETTO:-0300\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VTODO\r\n" +
+	"CREATED:20240601T120000Z\r\n" +
+	"LAST-MODIFIED:20240601T120000Z\r\n" +
+	"DTSTAMP:20240601T120000Z\r\n" +
+	"UID:3f5a3c1e-0b6a-4a2e-9d41-7a7c1b0e7a11\r\n" +
+	"SUMMARY:Pagar contas\\, todas\r\n" +
+	"DESCRIPTION:Aluguel\\nLuz\r\n" +
+	"PRIORITY:5\r\n" +
+	"STATUS:NEEDS-ACTION\r\n" +
+	"DUE;TZID=/mozilla.org/20050126_1/America/Sao_Paulo:20240610T170000\r\n" +
+	"RRULE:FREQ=MONTHLY\r\n" +
+	"CATEGORIES:Finanças\r\n" +
+	"X-MOZ-GENERATION:3\r\n" +
+	"X-LONG-PROPERTY:Lorem ipsum dolor sit amet\\, consectetur adipiscing elit\\, sed do eiusmod te\r\n" +
+	" mpor incididunt\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER;RELATED=END:-PT30M\r\n" +
+	"DESCRIPTION:Default Mozilla Description\r\n" +
+	"END:VALARM\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:AUDIO\r\n" +
+	"TRIGGER;VALUE=DATE-TIME:20240610T190000Z\r\n" +
+	"END:VALARM\r\n" +
+	"END:VTODO\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	entry, err := Parse([]byte(thunderbird), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	due := time.Date(2024, 6, 10, 20, 0, 0, 0, time.UTC)
+	if entry.UID != "3f5a3c1e-0b6a-4a2e-9d41-7a7c1b0e7a11" || entry.Summary != "Pagar contas, todas" ||
+		entry.Description == nil || *entry.Description != "Aluguel\nLuz" || entry.Priority != models.PriorityMedium ||
+		entry.Status != "NEEDS-ACTION" || entry.Completed != nil || entry.Recurrence == nil || *entry.Recurrence != "FREQ=MONTHLY" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if entry.Due == nil || !entry.Due.Equal(due) {
+		t.Fatalf("expected the task to be due %v, got %v", due, entry.Due)
+	}
+	if entry.Reminder == nil || !entry.Reminder.Equal(due.Add(-30*time.Minute)) {
+		t.Fatalf("expected the reminder 30 minutes before, got %v", entry.Reminder)
+	}
+
+	properties := strings.Join(entry.Properties, "\n")
+	for _, want := range []string{"CATEGORIES:Finanças", "X-MOZ-GENERATION:3", "sed do eiusmod tempor incididunt", "ACTION:AUDIO"} {
+		if !strings.Contains(properties, want) {
+			t.Fatalf("expected %q to be kept, got %q", want, properties)
+		}
+	}
+	if strings.Contains(properties, "Default Mozilla Description") || strings.Contains(properties, "DTSTAMP") {
+		t.Fatalf("expected the properties with fields of their own to be left out, got %q", properties)
+	}
+	if components := strings.Join(entry.Components, "\n"); !strings.HasPrefix(components, "BEGIN:VTIMEZONE") || !strings.HasSuffix(components, "END:VTIMEZONE") {
+		t.Fatalf("expected the time zone to be kept, got %q", components)
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	entry, err := Parse([]byte(thunderbird), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := "FREQ=MONTHLY"
+	task := &models.Tasks{ID: 7, Title: entry.Summary, Description: entry.Description, DueDate: entry.Due, Reminder: entry.Reminder,
+		Priority: entry.Priority, Recurrence: &rule, Status: models.TaskPending}
+
+	again, err := Parse(Object(task, entry.UID, entry.Properties, entry.Components), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.UID != entry.UID || again.Summary != entry.Summary || !again.Due.Equal(*entry.Due) || !again.Reminder.Equal(*entry.Reminder) ||
+		again.Priority != entry.Priority || fmt.Sprint(again.Properties) != fmt.Sprint(entry.Properties) ||
+		fmt.Sprint(again.Components) != fmt.Sprint(entry.Components) {
+		t.Fatalf("expected the entry to survive the round trip, got %+v, want %+v", again, entry)
+	}
+}
+
+func TestParseDatesAndStatus(t *testing.T) {
+	saoPaulo := time.FixedZone("America/Sao_Paulo", -3*60*60)
+	entry, err := Parse([]byte("BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nSUMMARY:Taxes\nDUE;VALUE=DATE:20240701\n"+
+		"STATUS:COMPLETED\nCOMPLETED:20240630T100000Z\nPRIORITY:1\nEND:VTODO\nEND:VCALENDAR\n"), saoPaulo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 7, 1, 23, 59, 0, 0, saoPaulo); entry.Due == nil || !entry.Due.Equal(want) {
+		t.Fatalf("expected dates to be due at the end of the day, got %v", entry.Due)
+	}
+	if entry.Status != "COMPLETED" || entry.Completed == nil || entry.Priority != models.PriorityHigh || entry.Reminder != nil {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"), time.UTC); !errors.Is(err, ErrNoTodo) {
+		t.Fatalf("expected ErrNoTodo, got %v", err)
+	}
+	for _, data := range []string{
+		"not a calendar",
+		"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nDUE:tomorrow\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nX-PARAM;FOO=\"unterminated:value\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if _, err := Parse([]byte(data), time.UTC); err == nil {
+			t.Fatalf("expected %q to be invalid", data)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"-PT15M":    -15 * time.Minute,
+		"PT0S":      0,
+		"+P1W":      7 * 24 * time.Hour,
+		"-P1DT2H":   -26 * time.Hour,
+		"PT1H30M5S": time.Hour + 30*time.Minute + 5*time.Second,
+	}
+	for value, want := range tests {
+		if got, err := parseDuration(value); err != nil || got != want {
+			t.Fatalf("parseDuration(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "P", "PT", "-PT15", "P1H", "PT1D", "15M"} {
+		if _, err := parseDuration(value); err == nil {
+			t.Fatalf("expected %q to be invalid", value)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package mail delivers the e-mails the server sends, such as workspace invitations,
+// without the handlers knowing how they're delivered.
+package mail
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+)
+
+// Message is a plain-text e-mail.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers e-mails.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// MailerFunc adapts a function to a Mailer.
+type MailerFunc func(ctx context.Context, message Message) error
+
+// Send calls the function.
+func (f MailerFunc) Send(ctx context.Context, message Message) error {
+	return f(ctx, message)
+}
+
+// LogMailer returns a Mailer that writes every e-mail to the log, at the info level, rather than sending it.
+// It's the only delivery available until the server is configured with an e-mail provider,
+// so operators hand the invitations over themselves.
+func LogMailer(logger *log.Logger) Mailer {
+	return MailerFunc(func(_ context.Context, message Message) error {
+		logger.Info("E-mail", "to", message.To, "subject", message.Subject, "body", message.Body)
+		return nil
+	})
+}
@@ -0,0 +1,25 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func TestLogMailer(t *testing.T) {
+	var out bytes.Buffer
+	mailer := LogMailer(log.New(&out))
+
+	err := mailer.Send(context.Background(), Message{To: "ada@nulltask.test", Subject: "Convite", Body: "Token: abc123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ada@nulltask.test", "Convite", "Token: abc123"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected the log to contain %q, got %q", want, out.String())
+		}
+	}
+}
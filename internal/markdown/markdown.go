@@ -0,0 +1,74 @@
+// Package markdown renders the Markdown written by users into HTML that is safe to embed in a page,
+// and finds the users mentioned in it.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	renderer = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	// policy allows the formatting, links and images users commonly write, and nothing that runs code or styles the page.
+	policy = bluemonday.UGCPolicy()
+
+	// mentionPattern matches an @ followed by an e-mail address, the way users are mentioned.
+	// The @ must start the text or follow a character that can't be part of an address, so plain e-mails aren't mentions.
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w.+-])@([\w.%+-]+@[\w-]+(?:\.[\w-]+)*\.[A-Za-z]{2,})`)
+)
+
+// Render converts the Markdown source into sanitized HTML.
+// Raw HTML in the source is escaped by the renderer, and whatever the renderer produces goes through
+// bluemonday's user generated content policy, so the result can be embedded as is.
+func Render(source string) string {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		// Rendering only fails when writing to the buffer fails, fall back to the escaped text
+		return policy.Sanitize(source)
+	}
+	return policy.Sanitize(buf.String())
+}
+
+// Mentions returns the e-mail addresses mentioned in the source as @user@example.com, lower-cased,
+// without duplicates, in the order they first appear. Mentions inside code are ignored.
+func Mentions(source string) []string {
+	seen := map[string]bool{}
+	var mentions []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(stripCode(source), -1) {
+		email := strings.ToLower(strings.TrimRight(match[1], "."))
+		if !seen[email] {
+			seen[email] = true
+			mentions = append(mentions, email)
+		}
+	}
+	return mentions
+}
+
+// stripCode removes fenced code blocks and inline code spans, where an @ is just text.
+func stripCode(source string) string {
+	var b strings.Builder
+	fenced := false
+	for _, line := range strings.Split(source, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			b.WriteString("\n")
+			continue
+		}
+		if fenced {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(codeSpan.ReplaceAllString(line, " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// codeSpan matches inline code spans delimited by backticks.
+var codeSpan = regexp.MustCompile("`+[^`]*`+")
@@ -0,0 +1,45 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	html := Render("**bold** and [link](https://nulltask.dev)")
+	if !strings.Contains(html, "<strong>bold</strong>") || !strings.Contains(html, `href="https://nulltask.dev"`) {
+		t.Fatalf("unexpected rendered HTML: %s", html)
+	}
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	for _, source := range []string{
+		"<script>alert(1)</script>",
+		"[click](javascript:alert(1))",
+		`<img src="x" onerror="alert(1)">`,
+	} {
+		html := Render(source)
+		if strings.Contains(html, "<script") || strings.Contains(html, "javascript:") || strings.Contains(html, "onerror") {
+			t.Errorf("Render(%q) = %q, expected it to be sanitized", source, html)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{"hey @Ana@Example.com, can you check?", []string{"ana@example.com"}},
+		{"@ana@example.com and @bob@example.com and @ana@example.com.", []string{"ana@example.com", "bob@example.com"}},
+		{"write to ana@example.com", nil},
+		{"inline `@ana@example.com` code", nil},
+		{"```\n@ana@example.com\n```\n@bob@example.com", []string{"bob@example.com"}},
+	}
+	for _, tt := range tests {
+		if got := Mentions(tt.source); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Mentions(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticate returns a middleware that only lets through requests carrying a valid bearer token.
+//
+// Parameters:
+// - tokens: The TokenManager that issued the tokens.
+// - active: Returns an error, answered instead of the request, when the user the token identifies can no longer use it.
+//
+// Returns:
+// - echo.MiddlewareFunc: The middleware to be registered with Echo.
+//
+// The token is read from the "Authorization: Bearer <token>" header. Requests without a token or with
+// an invalid one are answered with a HTTP status code 401. For the others, the ID of the authenticated
+// user is recorded with SetUserID, so handlers can read it with UserID and it shows up in the logs.
+func Authenticate(tokens *auth.TokenManager, active func(c echo.Context, userID string) error) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Token de acesso ausente")
+			}
+
+			userID, err := tokens.Parse(token)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Token de acesso inválido")
+			}
+			if err := active(c, userID); err != nil {
+				return err
+			}
+
+			SetUserID(c, userID)
+			return next(c)
+		}
+	}
+}
+
+// AuthenticateCredentials returns a middleware that, besides bearer tokens, lets through requests carrying
+// an e-mail and password through HTTP Basic authentication, the only kind some clients such as calendar applications support.
+//
+// Parameters:
+// - tokens: The TokenManager that issued the tokens.
+// - check: Returns the ID of the user with the given e-mail and password, or an error when they don't match.
+// - active: Returns an error, answered instead of the request, when the user a bearer token identifies can no longer use it.
+//
+// Returns:
+// - echo.MiddlewareFunc: The middleware to be registered with Echo.
+//
+// Requests without valid credentials are answered with a HTTP status code 401 and a WWW-Authenticate header
+// asking for Basic credentials. For the others, the ID of the authenticated user is recorded with SetUserID.
+func AuthenticateCredentials(tokens *auth.TokenManager, check func(c echo.Context, email, password string) (string, error), active func(c echo.Context, userID string) error) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			if token, found := strings.CutPrefix(header, "Bearer "); found && token != "" {
+				userID, err := tokens.Parse(token)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Token de acesso inválido")
+				}
+				if err := active(c, userID); err != nil {
+					return err
+				}
+				SetUserID(c, userID)
+				return next(c)
+			}
+
+			if email, password, ok := c.Request().BasicAuth(); ok {
+				userID, err := check(c, email, password)
+				if err == nil {
+					SetUserID(c, userID)
+					return next(c)
+				}
+			}
+
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="NullTask", charset="UTF-8"`)
+			return echo.NewHTTPError(http.StatusUnauthorized, "E-mail ou senha inválidos")
+		}
+	}
+}
+
+// UserID returns the ID of the authenticated user, or an empty string when the request isn't authenticated.
+func UserID(c echo.Context) string {
+	userID, _ := c.Get(UserIDKey).(string)
+	return userID
+}
@@ -0,0 +1,96 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// LoggerKey is the echo.Context key holding the per-request logger.
+	LoggerKey = "logger"
+
+	// UserIDKey is the echo.Context key holding the ID of the user making the request.
+	UserIDKey = "user_id"
+
+	// RequestIDKey is the echo.Context key holding the ID of the current request.
+	RequestIDKey = "request_id"
+)
+
+// RequestLogger returns a middleware that attaches a per-request logger to the context.
+//
+// Parameters:
+// - base: The application logger the per-request loggers are derived from.
+//
+// Returns:
+// - echo.MiddlewareFunc: The middleware to be registered with Echo.
+//
+// Every request gets a request ID, taken from the X-Request-ID header when the client sends one
+// or generated otherwise, which is echoed back in the response headers. The per-request logger
+// carries the request ID, the method and the route, and handlers retrieve it with Logger.
+// Once the request is handled, a summary line is written with the status code, the latency and
+// the ID of the user when one was set with SetUserID.
+func RequestLogger(base *log.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			c.Set(RequestIDKey, requestID)
+
+			setLogger(c, base.With("request_id", requestID, "method", req.Method, "route", c.Path()))
+
+			err := next(c)
+			if err != nil {
+				// Let Echo write the error response now so the logged status is the one sent to the client
+				c.Error(err)
+			}
+
+			logger := Logger(c)
+			status := c.Response().Status
+			keyvals := []interface{}{"status", status, "latency", time.Since(start).String()}
+
+			switch {
+			case status >= 500:
+				logger.Error("Request completed", append(keyvals, "error", err)...)
+			case status >= 400:
+				logger.Warn("Request completed", keyvals...)
+			default:
+				logger.Info("Request completed", keyvals...)
+			}
+
+			return nil
+		}
+	}
+}
+
+// Logger returns the per-request logger attached by RequestLogger.
+// When the middleware isn't registered it falls back to the default Charmbracelet logger.
+func Logger(c echo.Context) *log.Logger {
+	if logger, ok := c.Get(LoggerKey).(*log.Logger); ok {
+		return logger
+	}
+	return log.FromContext(c.Request().Context())
+}
+
+// SetUserID records the ID of the user making the request and adds it to the per-request logger,
+// so every line logged afterwards identifies who made the request.
+func SetUserID(c echo.Context, userID string) {
+	c.Set(UserIDKey, userID)
+	setLogger(c, Logger(c).With("user_id", userID))
+}
+
+// setLogger stores the logger both in the echo.Context and in the request's context.Context,
+// so code that only receives a context.Context can also retrieve it with log.FromContext.
+func setLogger(c echo.Context, logger *log.Logger) {
+	c.Set(LoggerKey, logger)
+	req := c.Request()
+	c.SetRequest(req.WithContext(log.WithContext(req.Context(), logger)))
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TaskAssignee assigns a user to a task, a task can have several assignees.
+type TaskAssignee struct {
+	TaskID     uint      `json:"task_id" gorm:"primaryKey"`
+	UserID     string    `json:"user_id" validate:"required,uuid" gorm:"type:uuid;primaryKey;index"`
+	AssignedBy string    `json:"assigned_by" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	Task       Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	User       User      `json:"-" validate:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TaskWatcher subscribes a user to the changes of a task.
+type TaskWatcher struct {
+	TaskID    uint      `json:"task_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+	Task      Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	User      User      `json:"-" validate:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// ThumbnailStatus tells whether the thumbnails of an attachment can be served.
+type ThumbnailStatus string
+
+const (
+	// ThumbnailNone is the status of attachments that aren't images, they never get thumbnails.
+	ThumbnailNone ThumbnailStatus = "none"
+	// ThumbnailPending is the status of images waiting for their thumbnails to be generated.
+	ThumbnailPending ThumbnailStatus = "pending"
+	// ThumbnailReady is the status of images whose thumbnails were generated.
+	ThumbnailReady ThumbnailStatus = "ready"
+	// ThumbnailFailed is the status of images that couldn't be decoded.
+	ThumbnailFailed ThumbnailStatus = "failed"
+)
+
+// Attachment is a file uploaded to a task. Its contents live in the BlobStore under StorageKey,
+// only the metadata is kept in the database. Images also get thumbnails, stored next to the original.
+type Attachment struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	TaskID      uint   `json:"task_id" gorm:"not null;index"`
+	UploaderID  string `json:"uploader_id" gorm:"type:uuid;not null;index"`
+	FileName    string `json:"file_name" gorm:"not null"`
+	ContentType string `json:"content_type" gorm:"not null"`
+	Size        int64  `json:"size" gorm:"not null"`
+	StorageKey  string `json:"-" gorm:"not null;uniqueIndex"`
+	// ThumbnailStatus tells whether the thumbnails can be served.
+	ThumbnailStatus ThumbnailStatus `json:"thumbnail_status" gorm:"not null;default:'none';index"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Task            Tasks           `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Uploader        User            `json:"-" validate:"-" gorm:"foreignKey:UploaderID;constraint:OnDelete:CASCADE"`
+}
+
+// ThumbnailKey is the key the thumbnail of the attachment fitting a size×size square is stored under.
+func (a *Attachment) ThumbnailKey(size int) string {
+	return a.StorageKey + ".thumb-" + strconv.Itoa(size)
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// CalendarFeed is the secret link through which the calendar applications of UserID subscribe to their tasks.
+// Only the hash of its token is stored: the token is shown once, when the feed is created or regenerated,
+// and regenerating or deleting the feed revokes the previous link.
+type CalendarFeed struct {
+	UserID    string    `json:"user_id" gorm:"type:uuid;primaryKey"`
+	TokenHash string    `json:"-" gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// CalendarObject is how a CalDAV client last wrote a task: the Name of its resource in the collection,
+// the UID the client gave it, and the iCalendar content NullTask has no field for, written back as it was.
+// Properties are the raw content lines of the VTODO, and Components those of the rest of the calendar, such as its VTIMEZONE.
+// Tasks never written through CalDAV have no CalendarObject.
+type CalendarObject struct {
+	TaskID     uint      `json:"task_id" gorm:"primaryKey;autoIncrement:false"`
+	Name       string    `json:"name" gorm:"not null"`
+	UID        string    `json:"uid" gorm:"not null"`
+	Properties []string  `json:"properties" gorm:"type:text;serializer:json;not null"`
+	Components []string  `json:"components" gorm:"type:text;serializer:json;not null"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Task       Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// CalendarSync is the state of the CalDAV collection of UserID when the sync token Token was handed out:
+// the ETag of each of its resources, by name. What changed since then is found by comparing it with the current state.
+type CalendarSync struct {
+	UserID    string            `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Token     string            `json:"token" gorm:"primaryKey"`
+	ETags     map[string]string `json:"etags" gorm:"type:text;serializer:json;not null"`
+	CreatedAt time.Time         `json:"created_at" gorm:"index"`
+	User      User              `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
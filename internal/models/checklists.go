@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ChecklistItem is a lightweight step of a task, checked off without being a task of its own.
+// The items of a task are ordered by Position, starting at zero.
+type ChecklistItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"task_id" gorm:"not null;index"`
+	Title     string    `json:"title" validate:"required,max=500" gorm:"not null"`
+	Done      bool      `json:"done" gorm:"not null"`
+	Position  int       `json:"position" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Task      Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
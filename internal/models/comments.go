@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a Markdown message about a task. Replies point to the comment they answer through ParentID,
+// forming threads. Deleted comments are kept, without their body, so the threads stay intact.
+type Comment struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	TaskID   uint   `json:"task_id" gorm:"not null;index"`
+	ParentID *uint  `json:"parent_id" gorm:"index"`
+	AuthorID string `json:"author_id" gorm:"type:uuid;not null;index"`
+	Body     string `json:"body" validate:"required,max=10000" gorm:"not null"`
+	// BodyHTML is Body rendered to sanitized HTML, it's only filled in responses.
+	BodyHTML  string           `json:"body_html" gorm:"-"`
+	EditedAt  *time.Time       `json:"edited_at"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	DeletedAt gorm.DeletedAt   `json:"deleted_at" gorm:"index"`
+	Mentions  []CommentMention `json:"mentions" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	// Replies holds the answers to the comment when the comments are returned as threads.
+	Replies []Comment `json:"replies,omitempty" validate:"-" gorm:"-"`
+	Task    Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Author  User      `json:"-" validate:"-" gorm:"foreignKey:AuthorID"`
+}
+
+// CommentRevision keeps the body a comment had before one of its edits.
+type CommentRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CommentID uint      `json:"comment_id" gorm:"not null;index"`
+	Body      string    `json:"body" gorm:"not null"`
+	EditedBy  string    `json:"edited_by" gorm:"type:uuid;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	Comment   Comment   `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// CommentMention records that a user was mentioned in a comment.
+type CommentMention struct {
+	CommentID uint      `json:"comment_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+	User      User      `json:"-" validate:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TaskDependency says that the task TaskID can't be completed before the task DependsOnID is done.
+// The dependencies of every task form a graph without cycles.
+type TaskDependency struct {
+	TaskID      uint      `json:"task_id" gorm:"primaryKey"`
+	DependsOnID uint      `json:"depends_on_id" gorm:"primaryKey;index"`
+	CreatedBy   string    `json:"created_by" gorm:"type:uuid;not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	Task        Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	DependsOn   Tasks     `json:"-" validate:"-" gorm:"foreignKey:DependsOnID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// ImportStatus tells how far an import job got.
+type ImportStatus string
+
+const (
+	// ImportPending is the status of jobs waiting for the importer.
+	ImportPending ImportStatus = "pending"
+	// ImportRunning is the status of the jobs being imported.
+	ImportRunning ImportStatus = "running"
+	// ImportCompleted is the status of the jobs whose every row was handled, even when some of them failed.
+	ImportCompleted ImportStatus = "completed"
+	// ImportFailed is the status of the jobs whose file couldn't be read at all.
+	ImportFailed ImportStatus = "failed"
+)
+
+// The actions an import takes, or would take in a dry run, on each row.
+const (
+	// ImportCreate creates a task for a row imported for the first time.
+	ImportCreate = "create"
+	// ImportUpdate updates the task a row was imported into before.
+	ImportUpdate = "update"
+	// ImportSkip leaves out a row that has nothing to import, such as an archived card.
+	ImportSkip = "skip"
+	// ImportError reports a row that couldn't be imported.
+	ImportError = "error"
+)
+
+// ImportJob is a file of tasks UserID is importing in the background, in one of the formats of the transfer package.
+// Dry runs go through every row without saving anything, reporting what a real import would do.
+// The file is kept in Data until the job finishes, and each of its rows gets an ImportRow in Rows.
+type ImportJob struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID string `json:"user_id" gorm:"type:uuid;not null;index"`
+	Format string `json:"format" gorm:"not null"`
+	// Mapping maps the fields of the tasks to the columns of CSV files.
+	Mapping    map[string]string `json:"mapping,omitempty" gorm:"type:text;serializer:json"`
+	DryRun     bool              `json:"dry_run" gorm:"not null"`
+	Status     ImportStatus      `json:"status" gorm:"not null;default:'pending';index"`
+	Data       []byte            `json:"-"`
+	Total      int               `json:"total" gorm:"not null"`
+	Created    int               `json:"created" gorm:"not null"`
+	Updated    int               `json:"updated" gorm:"not null"`
+	Skipped    int               `json:"skipped" gorm:"not null"`
+	Failed     int               `json:"failed" gorm:"not null"`
+	Rows       []ImportRow       `json:"rows" gorm:"type:text;serializer:json"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	FinishedAt *time.Time        `json:"finished_at"`
+	User       User              `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// ImportRow is what an import did, or would do, with a row of its file.
+// Row is where the row is in the file, and Ref the ID it has there, which re-imports are matched by.
+// Error tells why the row couldn't be imported, and Note why it was skipped, or what couldn't be imported along with it,
+// such as the task it's a subtask of.
+type ImportRow struct {
+	Row    int    `json:"row"`
+	Ref    string `json:"ref"`
+	Title  string `json:"title"`
+	Action string `json:"action"`
+	TaskID *uint  `json:"task_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// ImportedTask remembers the task a row of a file was imported into, so importing the file again
+// updates the task rather than creating another one. Source is the format of the file, and ExternalID the Ref of the row.
+type ImportedTask struct {
+	UserID     string    `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Source     string    `json:"source" gorm:"primaryKey"`
+	ExternalID string    `json:"external_id" gorm:"primaryKey"`
+	TaskID     uint      `json:"task_id" gorm:"not null;index"`
+	CreatedAt  time.Time `json:"created_at"`
+	User       User      `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Task       Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// DefaultLabelColor is the color of the labels created without one.
+const DefaultLabelColor = "#9e9e9e"
+
+// Label tags tasks by context, such as @home or @office, or by project.
+// Labels without a WorkspaceID are personal: only their owner sees them, and they tag the owner's personal tasks.
+// The others belong to the workspace, are shared with its members and tag the workspace's tasks.
+// Names are unique within the personal labels of a user and within the labels of a workspace.
+type Label struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	Name        string     `json:"name" validate:"required,max=50" gorm:"not null"`
+	Color       string     `json:"color" validate:"omitempty,hexcolor" gorm:"not null"`
+	UserID      string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	WorkspaceID *string    `json:"workspace_id" validate:"omitempty,uuid" gorm:"type:uuid;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	User        User       `json:"-" validate:"-" gorm:"foreignKey:UserID"`
+	Workspace   *Workspace `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// TaskLabel tags a task with a label.
+type TaskLabel struct {
+	TaskID    uint      `json:"task_id" gorm:"primaryKey"`
+	LabelID   uint      `json:"label_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+	Task      Tasks     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Label     Label     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// The channels notifications can be delivered through.
+const (
+	// ChannelInApp delivers notifications inside the application.
+	ChannelInApp = "in_app"
+	// ChannelEmail delivers notifications by e-mail.
+	ChannelEmail = "email"
+	// ChannelPush delivers notifications to the user's devices.
+	ChannelPush = "push"
+)
+
+// Preferences are the settings of a user. Users who never saved theirs have DefaultPreferences.
+//
+// Timezone is the IANA name of the time zone in which the user's days start and end, such as "America/Sao_Paulo",
+// and Locale the BCP 47 tag of their language, such as "pt-BR". WeekStart is the day, in English and lower case,
+// their weeks start on. DefaultReminder is how many minutes before the due date new tasks are reminded,
+// when they aren't given a reminder of their own, or nil to leave them without one.
+type Preferences struct {
+	UserID               string    `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Timezone             string    `json:"timezone" validate:"required,timezone"`
+	Locale               string    `json:"locale" validate:"required,bcp47_language_tag"`
+	WeekStart            string    `json:"week_start" validate:"required,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	DefaultReminder      *int      `json:"default_reminder" validate:"omitempty,min=0,max=40320"`
+	NotificationChannels []string  `json:"notification_channels" validate:"max=3,unique,dive,oneof=in_app email push" gorm:"type:text;serializer:json;not null"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	User                 User      `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// DefaultPreferences returns the preferences of a user who never saved theirs:
+// UTC, Brazilian Portuguese, weeks starting on Sunday, no default reminder and notifications inside the application.
+func DefaultPreferences(userID string) *Preferences {
+	return &Preferences{
+		UserID:               userID,
+		Timezone:             "UTC",
+		Locale:               "pt-BR",
+		WeekStart:            "sunday",
+		NotificationChannels: []string{ChannelInApp},
+	}
+}
+
+// Location returns the time zone of the user, falling back to UTC when it can't be loaded.
+func (p *Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// FirstDay returns the day the user's weeks start on.
+func (p *Preferences) FirstDay() time.Weekday {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if p.WeekStart == strings.ToLower(day.String()) {
+			return day
+		}
+	}
+	return time.Sunday
+}
@@ -1,23 +1,77 @@
 package models
 
 import (
-	"database/sql"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-type Tasks struct {
-	gorm.Model
-	Title       string `gorm:"not null"`
-	Description sql.NullString
-	Status      string `gorm:"not null;default:'pending'"`
-	DueDate     *time.Time
-	UserID      uint 
-	CategoryID  sql.NullInt64
-	CompletedAt sql.NullTime
-	Reminder    *time.Time
-	Notes       sql.NullString
-	User        User `gorm:"foreignKey:UserID"`
+// The statuses of DefaultWorkflow.
+const (
+	// TaskPending is the status of new tasks.
+	TaskPending = "pending"
+	// TaskInProgress is the status of tasks someone is working on.
+	TaskInProgress = "in_progress"
+	// TaskDone is the status of completed tasks.
+	TaskDone = "done"
+	// TaskCancelled is the status of tasks that won't be done.
+	TaskCancelled = "cancelled"
+)
+
+// The priorities of a task, from none to high.
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// PriorityName returns the Portuguese name of the priority, as shown to users.
+func PriorityName(priority int) string {
+	switch priority {
+	case PriorityLow:
+		return "baixa"
+	case PriorityMedium:
+		return "média"
+	case PriorityHigh:
+		return "alta"
+	}
+	return "nenhuma"
 }
 
+// Tasks is a task created by UserID.
+// Tasks without a WorkspaceID are personal and only visible to their creator,
+// the others belong to the workspace and are shared with its members.
+// Subtasks point to the task they are part of through ParentID, forming a tree of any depth.
+// The Status must be one of the statuses of the workflow the task follows, and CompletedAt is set while it's a terminal one.
+// Blocked is computed when the task is loaded: it's true while any task it depends on isn't completed.
+// Priority goes from PriorityNone to PriorityHigh, while Urgent and Important, for the Eisenhower matrix,
+// are left nil until the user decides, in which case they are derived from the due date and the priority.
+// Recurrence is an iCalendar RRULE saying how the task repeats, such as "FREQ=WEEKLY;BYDAY=MO", or nil when it doesn't.
+// Rank orders the task within its board column, the tasks of its workspace, or the personal tasks of its owner,
+// in the same status. It's a key generated by the rank package, and ties are broken by ID.
+type Tasks struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Title       string         `json:"title" validate:"required" gorm:"not null"`
+	Description *string        `json:"description"`
+	Status      string         `json:"status" gorm:"not null;default:'pending'"`
+	Priority    int            `json:"priority" validate:"min=0,max=3" gorm:"not null;default:0"`
+	Urgent      *bool          `json:"urgent"`
+	Important   *bool          `json:"important"`
+	DueDate     *time.Time     `json:"due_date"`
+	UserID      string         `json:"user_id" validate:"required,uuid" gorm:"type:uuid;not null;index"`
+	WorkspaceID *string        `json:"workspace_id" validate:"omitempty,uuid" gorm:"type:uuid;index"`
+	ParentID    *uint          `json:"parent_id" gorm:"index"`
+	CategoryID  *uint          `json:"category_id"`
+	CompletedAt *time.Time     `json:"completed_at"`
+	Reminder    *time.Time     `json:"reminder"`
+	Recurrence  *string        `json:"recurrence" validate:"omitempty,max=255"`
+	Notes       *string        `json:"notes"`
+	Rank        string         `json:"rank" gorm:"not null;default:'';index"`
+	Blocked     bool           `json:"blocked" gorm:"->;-:migration"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	User        User           `json:"-" validate:"-" gorm:"foreignKey:UserID"`
+	Workspace   *Workspace     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Serial is an auto-incremented integer column that isn't the primary key.
+//
+// PostgreSQL backs it with a sequence. SQLite can only auto-increment the primary key,
+// so there it's a plain integer column and the model's BeforeCreate hook fills it with nextSerial.
+type Serial int
+
+// GormDBDataType keeps the PostgreSQL column a bigserial and makes it a plain integer on other databases.
+func (Serial) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return ""
+	}
+	return "integer"
+}
+
+// Sequence is the last value handed out for a Serial column on databases without sequences.
+// Name is the column, qualified by its table.
+type Sequence struct {
+	Name  string `gorm:"primaryKey"`
+	Value int64  `gorm:"not null"`
+}
+
+// nextSerial returns the value the given Serial column of the table should take on databases without sequences,
+// advancing its row of the sequences table in a single statement, so concurrent inserts never get the same value
+// and the values of deleted rows aren't handed out again. The first call for a column starts after its largest value.
+// It returns zero on PostgreSQL, which leaves the column to the database's sequence.
+func nextSerial(tx *gorm.DB, table, column string) (Serial, error) {
+	if tx == nil || tx.Dialector.Name() == "postgres" {
+		return 0, nil
+	}
+
+	var next Serial
+	err := tx.Session(&gorm.Session{NewDB: true}).Raw(
+		`INSERT INTO sequences (name, value) SELECT ?, COALESCE(MAX(`+column+`), 0) + 1 FROM `+table+` WHERE true
+		ON CONFLICT (name) DO UPDATE SET value = sequences.value + 1 RETURNING value`,
+		table+"."+column,
+	).Scan(&next).Error
+	return next, err
+}
@@ -7,20 +7,28 @@ import (
 	"gorm.io/gorm"
 )
 
+// User is an account of the server.
+// Admin marks the operators' accounts, which are created with the "create-admin" command of the server
+// and are the only ones allowed to list every user.
+// Password is the bcrypt hash of the user's password, which is never written out as JSON.
+// DeactivatedAt is when an operator deactivated the account: deactivated users can neither log in nor use the tokens they hold.
 type User struct {
-	ID           string     `json:"id" gorm:"type:uuid;primary_key;"`
-	Name         string     `json:"name" validate:"required" gorm:"not null"`
-	Email        string     `json:"email" validate:"required,email" gorm:"uniqueIndex"`
-	Password     string     `json:"password" validate:"required,min=6" gorm:"not null"`
-	Age          uint8      `json:"age" validate:"required,gte=18" gorm:"check:age >= 0"`
-	MemberNumber int        `json:"member_number" gorm:"autoIncrement"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at" sql:"index"`
-	Tasks        []Tasks    // `gorm:"foreignKey:UserId"`
+	ID            string     `json:"id" gorm:"type:uuid;primary_key;"`
+	Name          string     `json:"name" gorm:"not null"`
+	Email         string     `json:"email" gorm:"uniqueIndex"`
+	Password      string     `json:"-" gorm:"not null"`
+	Age           uint8      `json:"age" gorm:"check:age >= 0"`
+	MemberNumber  Serial     `json:"member_number" gorm:"autoIncrement"`
+	Admin         bool       `json:"admin" gorm:"not null;default:false"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at" sql:"index"`
+	Tasks         []Tasks    `json:"tasks,omitempty" gorm:"foreignKey:UserID"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	u.ID = uuid.New().String()
+	u.MemberNumber, err = nextSerial(tx, "users", "member_number")
 	return
 }
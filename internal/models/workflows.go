@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// WorkflowStatus is one of the statuses tasks can be in, and a column of the workspace's board.
+// Reaching a terminal status completes the task, leaving it reopens the task.
+// A positive WIPLimit caps how many tasks can be in the status at once.
+type WorkflowStatus struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Terminal bool   `json:"terminal"`
+	WIPLimit int    `json:"wip_limit,omitempty" validate:"min=0"`
+}
+
+// WorkflowTransition allows tasks to move from the status From to the status To.
+type WorkflowTransition struct {
+	From string `json:"from" validate:"required"`
+	To   string `json:"to" validate:"required"`
+}
+
+// Workflow defines the statuses the tasks of a workspace can be in and how they move between them.
+// New tasks start in the Initial status. Workspaces without a workflow of their own,
+// and personal tasks, follow DefaultWorkflow.
+type Workflow struct {
+	WorkspaceID string               `json:"workspace_id" gorm:"type:uuid;primaryKey"`
+	Initial     string               `json:"initial" validate:"required"`
+	Statuses    []WorkflowStatus     `json:"statuses" validate:"required,min=1,max=50,dive" gorm:"type:text;serializer:json;not null"`
+	Transitions []WorkflowTransition `json:"transitions" validate:"max=500,dive" gorm:"type:text;serializer:json;not null"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+	Workspace   Workspace            `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// DefaultWorkflow returns the workflow of personal tasks and of the workspaces that didn't configure one:
+// tasks go from pending to in_progress, and from either of them to done or cancelled, which can be reopened.
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Initial: TaskPending,
+		Statuses: []WorkflowStatus{
+			{Name: TaskPending},
+			{Name: TaskInProgress},
+			{Name: TaskDone, Terminal: true},
+			{Name: TaskCancelled, Terminal: true},
+		},
+		Transitions: []WorkflowTransition{
+			{From: TaskPending, To: TaskInProgress},
+			{From: TaskInProgress, To: TaskPending},
+			{From: TaskPending, To: TaskDone},
+			{From: TaskPending, To: TaskCancelled},
+			{From: TaskInProgress, To: TaskDone},
+			{From: TaskInProgress, To: TaskCancelled},
+			{From: TaskDone, To: TaskPending},
+			{From: TaskCancelled, To: TaskPending},
+		},
+	}
+}
+
+// Status returns the status with the given name, and whether the workflow has it.
+func (w *Workflow) Status(name string) (WorkflowStatus, bool) {
+	for _, status := range w.Statuses {
+		if status.Name == name {
+			return status, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// CanTransition reports whether tasks can move from one status to the other.
+// Tasks in a status the workflow doesn't have, left behind by an older workflow, can move to any status.
+func (w *Workflow) CanTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	if _, ok := w.Status(from); !ok {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == from && transition.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the statuses tasks in the given status can move to.
+func (w *Workflow) Next(from string) []WorkflowStatus {
+	next := []WorkflowStatus{}
+	for _, status := range w.Statuses {
+		if status.Name != from && w.CanTransition(from, status.Name) {
+			next = append(next, status)
+		}
+	}
+	return next
+}
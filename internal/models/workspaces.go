@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceRole is the role of a member inside a workspace.
+type WorkspaceRole string
+
+const (
+	// RoleOwner can do everything, including managing members and deleting the workspace.
+	RoleOwner WorkspaceRole = "owner"
+	// RoleEditor can create, update and delete the workspace's tasks.
+	RoleEditor WorkspaceRole = "editor"
+	// RoleViewer can only read the workspace's tasks.
+	RoleViewer WorkspaceRole = "viewer"
+)
+
+// roleRanks orders the roles, a role includes every permission of the roles ranked below it.
+var roleRanks = map[WorkspaceRole]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Includes reports whether the role grants at least the permissions of the other role.
+func (r WorkspaceRole) Includes(other WorkspaceRole) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
+
+// Workspace is a shared task list. Its tasks are visible to every member,
+// and what each member can do with them depends on their WorkspaceRole.
+type Workspace struct {
+	ID        string            `json:"id" gorm:"type:uuid;primary_key;"`
+	Name      string            `json:"name" validate:"required,max=100" gorm:"not null"`
+	OwnerID   string            `json:"owner_id" gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Members   []WorkspaceMember `json:"members,omitempty" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (w *Workspace) BeforeCreate(tx *gorm.DB) (err error) {
+	w.ID = uuid.New().String()
+	return
+}
+
+// WorkspaceMember links a user to a workspace with a role.
+type WorkspaceMember struct {
+	WorkspaceID string        `json:"workspace_id" gorm:"type:uuid;primaryKey"`
+	UserID      string        `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Role        WorkspaceRole `json:"role" validate:"required,oneof=owner editor viewer" gorm:"not null"`
+	CreatedAt   time.Time     `json:"created_at"`
+	User        User          `json:"-" validate:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// WorkspaceInvitation invites an e-mail address to join a workspace with a role.
+// The invitation is accepted with a secret token, only its SHA-256 hash is stored.
+type WorkspaceInvitation struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	WorkspaceID string        `json:"workspace_id" gorm:"type:uuid;not null;index"`
+	Email       string        `json:"email" validate:"required,email" gorm:"not null"`
+	Role        WorkspaceRole `json:"role" validate:"required,oneof=editor viewer" gorm:"not null"`
+	TokenHash   string        `json:"-" gorm:"uniqueIndex;not null"`
+	InvitedBy   string        `json:"invited_by" gorm:"type:uuid;not null"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	AcceptedAt  *time.Time    `json:"accepted_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Workspace   Workspace     `json:"-" validate:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// Expired reports whether the invitation can no longer be accepted at the given moment.
+func (i *WorkspaceInvitation) Expired(now time.Time) bool {
+	return now.After(i.ExpiresAt)
+}
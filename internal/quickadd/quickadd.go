@@ -0,0 +1,778 @@
+// Package quickadd parses the one line descriptions of the quick-add box into tasks, in English or Portuguese.
+//
+// In "Pay rent tomorrow 9am #finance !high every month", the words it understands are taken out of the title:
+//
+//   - #name tags the task with the label of that name.
+//   - !low, !medium and !high, or !baixa, !media and !alta, !1 to !3, !! and !!! set its priority.
+//   - Dates: today, tonight, tomorrow, the day after tomorrow, weekdays ("friday", "next friday", "sexta-feira",
+//     "na sexta"), next week/month/year, "in 3 days", "em 2 semanas", "daqui a 1 mês", 2024-06-30,
+//     30/06 or 30/06/2024 (06/30 when the day can't be first), "june 30", "30 de junho" and "dia 30".
+//   - Times of day: 9am, 9:30pm, 21:00, 21h, 21h30, "at 9", "às 9 da noite", noon and midnight. "in 2 hours" and
+//     "em 30 minutos" set both the date and the time.
+//   - Recurrence: daily, weekly, monthly, yearly, "every 2 weeks", "every other day", "every monday and thursday",
+//     "every weekday", "todo mês", "toda segunda", "todos os dias úteis", "a cada 3 dias".
+//   - Reminders: "remind me 30 minutes before", "lembrar 1h antes".
+//
+// Dates and times are read in the given location, and "next week" is the first day of the next week. Dates without a time are due at the end of the day,
+// times without a date are due today, or tomorrow once passed, and weekdays are the next ones after today,
+// unless preceded by "this" or "esta". Tasks due at a time of day are reminded then, unless a reminder says otherwise.
+// A recurrence without a date makes the task due on its first occurrence.
+//
+// Words that look like something it understands but aren't, such as "!urgent", "25:00" or "31/02",
+// are kept in the title and reported as unparsed. Recurrences that can't repeat, such as "every 0 days", are errors.
+package quickadd
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLabel is the longest label name a #tag can carry, as for labels created otherwise.
+const maxLabel = 50
+
+// ErrInterval is returned by Parse for a recurrence repeating every 0 units of time or fewer, such as "every 0 days".
+var ErrInterval = errors.New("recurrence interval below 1")
+
+// Token is a word of the parsed text, located by the offsets, in runes, of its first rune and past its last one.
+type Token struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// Result is what Parse understood of a text.
+type Result struct {
+	// Title is what's left of the text once the words it understood are taken out.
+	Title string
+	// DueDate and Reminder are nil when the text doesn't set them.
+	DueDate  *time.Time
+	Reminder *time.Time
+	// Labels holds the names of the labels, without the #, in order and without repeating any.
+	Labels   []string
+	Priority int
+	// Recurrence is an iCalendar RRULE, such as "FREQ=WEEKLY;BYDAY=MO", or empty when the task doesn't repeat.
+	Recurrence string
+	// Unparsed holds the words kept in the title that look like something Parse understands but aren't.
+	Unparsed []Token
+}
+
+// rule is how often a task repeats.
+type rule struct {
+	freq     string
+	interval int
+	byDay    []time.Weekday
+}
+
+// String returns the rule as an iCalendar RRULE.
+func (r rule) String() string {
+	s := "FREQ=" + r.freq
+	if r.interval > 1 {
+		s += ";INTERVAL=" + strconv.Itoa(r.interval)
+	}
+	if len(r.byDay) > 0 {
+		codes := make([]string, len(r.byDay))
+		for i, day := range r.byDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		s += ";BYDAY=" + strings.Join(codes, ",")
+	}
+	return s
+}
+
+// weekdaysRule repeats on every weekday, from Monday to Friday.
+var weekdaysRule = rule{freq: "WEEKLY", byDay: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}}
+
+var (
+	isoDate     = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	slashDate   = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?$`)
+	ordinal     = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th|º|ª)?$`)
+	year        = regexp.MustCompile(`^\d{4}$`)
+	clock12     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	clock24     = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	clockHours  = regexp.MustCompile(`^(\d{1,2})h(\d{2})?$`)
+	bareHour    = regexp.MustCompile(`^\d{1,2}$`)
+	compactSpan = regexp.MustCompile(`^(\d+)(m|min|h|d)$`)
+
+	// syntax matches the keys of the words that look like something Parse understands.
+	syntax = regexp.MustCompile(`^[#!].|^\d{1,2}(:\d{2})?(am|pm)$|^\d{1,2}:\d{2}$|^\d{1,2}h\d{0,2}$|^\d{1,4}[/-]\d{1,2}([/-]\d{1,4})?$`)
+)
+
+// parser holds the state of the parsing of a text.
+type parser struct {
+	words     []word
+	i         int
+	now       time.Time
+	today     time.Time
+	weekStart time.Weekday
+
+	title    []string
+	unparsed []Token
+	labels   []string
+
+	priority int
+	date     *time.Time
+	clock    *time.Duration
+	exact    *time.Time
+	evening  bool
+	repeat   *rule
+	before   *time.Duration
+	// err is the first error found in the text.
+	err error
+	// reminder holds the words of the reminder, put back at reminderAt in the title when there's no due date.
+	reminder   Token
+	reminderAt int
+}
+
+// Parse parses the text at the given time, reading dates and times in the given location,
+// where weeks start on the given day. It returns ErrInterval, along with what it understood, when the text
+// has a recurrence that can't repeat.
+func Parse(text string, now time.Time, loc *time.Location, weekStart time.Weekday) (Result, error) {
+	now = now.In(loc)
+	p := &parser{
+		words:     split(text),
+		now:       now,
+		today:     time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc),
+		weekStart: weekStart,
+	}
+
+	for p.i < len(p.words) {
+		if n := p.match(); n > 0 {
+			p.i += n
+			continue
+		}
+		w := p.words[p.i]
+		p.title = append(p.title, w.text)
+		if syntax.MatchString(w.key) {
+			p.unparsed = append(p.unparsed, Token{w.text, w.start, w.end})
+		}
+		p.i++
+	}
+	return p.result(), p.err
+}
+
+// key returns the key of the word at the given distance from the current one, or "" past the end of the text.
+func (p *parser) key(at int) string {
+	if p.i+at >= len(p.words) {
+		return ""
+	}
+	return p.words[p.i+at].key
+}
+
+// match applies what the words from the current one mean, returning how many it took, or 0 if it understood none.
+func (p *parser) match() int {
+	for _, matcher := range []func() int{p.label, p.priorityMarker, p.reminderRule, p.recurrence, p.relative, p.dueDate, p.timeOfDay} {
+		if n := matcher(); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// label reads a #label.
+func (p *parser) label() int {
+	text := p.words[p.i].text
+	if !strings.HasPrefix(text, "#") {
+		return 0
+	}
+	name := strings.TrimRight(text[1:], ",;.")
+	if name == "" || utf8.RuneCountInString(name) > maxLabel {
+		return 0
+	}
+	for _, label := range p.labels {
+		if strings.EqualFold(label, name) {
+			return 1
+		}
+	}
+	p.labels = append(p.labels, name)
+	return 1
+}
+
+// priorityMarker reads a priority, unless the text already set one.
+func (p *parser) priorityMarker() int {
+	priority, ok := priorities[p.key(0)]
+	if !ok || p.priority != 0 {
+		return 0
+	}
+	p.priority = priority
+	return 1
+}
+
+// reminderRule reads how long before the due date the task should be reminded: "remind me 1 hour before".
+func (p *parser) reminderRule() int {
+	if !reminderWords[p.key(0)] || p.before != nil {
+		return 0
+	}
+	n := 1
+	if p.key(n) == "me" {
+		n++
+	}
+	span, m := p.duration(n)
+	if m == 0 {
+		return 0
+	}
+	n += m
+	if k := p.key(n); k != "before" && k != "antes" {
+		return 0
+	}
+	n++
+
+	p.before = &span
+	first, last := p.words[p.i], p.words[p.i+n-1]
+	p.reminder = Token{strings.Join(p.texts(0, n), " "), first.start, last.end}
+	p.reminderAt = len(p.title)
+	return n
+}
+
+// duration reads a duration in minutes, hours, days or weeks, as "30m" or "30 minutes",
+// returning it and how many words it took.
+func (p *parser) duration(at int) (time.Duration, int) {
+	if match := compactSpan.FindStringSubmatch(p.key(at)); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		unit := map[string]string{"m": unitMinute, "min": unitMinute, "h": unitHour, "d": unitDay}[match[2]]
+		return time.Duration(n) * spans[unit], 1
+	}
+	n, ok := number(p.key(at))
+	if span, known := spans[units[p.key(at+1)]]; ok && known {
+		return time.Duration(n) * span, 2
+	}
+	return 0, 0
+}
+
+// spans maps the units of fixed length to their lengths.
+var spans = map[string]time.Duration{
+	unitMinute: time.Minute,
+	unitHour:   time.Hour,
+	unitDay:    24 * time.Hour,
+	unitWeek:   7 * 24 * time.Hour,
+}
+
+// recurrence reads how often the task repeats, unless the text already said it.
+func (p *parser) recurrence() int {
+	if p.repeat != nil {
+		return 0
+	}
+	if r, ok := adverbs[p.key(0)]; ok {
+		p.repeat = &r
+		return 1
+	}
+
+	n := 0
+	switch p.key(0) {
+	case "every", "each", "cada":
+		n = 1
+	case "todo", "toda", "todos", "todas":
+		n = 1
+		if k := p.key(1); k == "os" || k == "as" {
+			n = 2
+		}
+	case "a":
+		if p.key(1) != "cada" {
+			return 0
+		}
+		n = 2
+	default:
+		return 0
+	}
+
+	r, m := p.every(n)
+	if m == 0 {
+		return 0
+	}
+	p.repeat = &r
+	return n + m
+}
+
+// every reads what comes after "every": an interval and a unit, the weekdays, or days of the week.
+func (p *parser) every(at int) (rule, int) {
+	n := at
+	interval := 1
+	if value, err := strconv.Atoi(p.key(n)); err == nil && value < 1 && units[p.key(n+1)] != "" {
+		if p.err == nil {
+			p.err = ErrInterval
+		}
+		return rule{}, 0
+	}
+	if p.key(n) == "other" {
+		interval, n = 2, n+1
+	} else if value, ok := number(p.key(n)); ok && units[p.key(n+1)] != "" {
+		interval, n = value, n+1
+	}
+
+	switch k := p.key(n); {
+	case interval == 1 && (k == "weekday" || k == "weekdays"):
+		return weekdaysRule, n - at + 1
+	case interval == 1 && (k == "dia" || k == "dias") && (p.key(n+1) == "util" || p.key(n+1) == "uteis"):
+		return weekdaysRule, n - at + 2
+	case frequencies[units[k]] != "":
+		return rule{freq: frequencies[units[k]], interval: interval}, n - at + 1
+	}
+
+	var days []time.Weekday
+	for {
+		day, _, ok := weekday(p.key(n))
+		if !ok {
+			break
+		}
+		days = append(days, day)
+		n++
+		if k := p.key(n); k == "and" || k == "e" {
+			if _, _, next := weekday(p.key(n + 1)); next {
+				n++
+			}
+		}
+	}
+	if len(days) == 0 {
+		return rule{}, 0
+	}
+	return rule{freq: "WEEKLY", interval: interval, byDay: mondayFirst(days)}, n - at
+}
+
+// relative reads a date or time relative to now: "in 3 days", "em 2 horas", "daqui a uma semana".
+func (p *parser) relative() int {
+	if p.date != nil || p.exact != nil {
+		return 0
+	}
+	n := 0
+	switch p.key(0) {
+	case "in", "em":
+		n = 1
+	case "daqui":
+		n = 1
+		if p.key(1) == "a" {
+			n = 2
+		}
+	default:
+		return 0
+	}
+
+	value, ok := number(p.key(n))
+	if !ok {
+		return 0
+	}
+	switch units[p.key(n+1)] {
+	case unitMinute, unitHour:
+		if p.clock != nil {
+			return 0
+		}
+		at := p.now.Add(time.Duration(value) * spans[units[p.key(n+1)]]).Truncate(time.Minute)
+		p.exact = &at
+	case unitDay:
+		p.setDate(p.today.AddDate(0, 0, value))
+	case unitWeek:
+		p.setDate(p.today.AddDate(0, 0, 7*value))
+	case unitMonth:
+		p.setDate(p.today.AddDate(0, value, 0))
+	case unitYear:
+		p.setDate(p.today.AddDate(value, 0, 0))
+	default:
+		return 0
+	}
+	return n + 2
+}
+
+// dueDate reads a date, optionally after a preposition, unless the text already set one.
+func (p *parser) dueDate() int {
+	if p.date != nil || p.exact != nil {
+		return 0
+	}
+	if connector := p.key(0); dateConnectors[connector] {
+		if n := p.dateAt(1, connector); n > 0 {
+			return n + 1
+		}
+		return 0
+	}
+	return p.dateAt(0, "")
+}
+
+// dateAt reads a date from the word at the given distance, after the given preposition if any,
+// returning how many words it took.
+func (p *parser) dateAt(at int, connector string) int {
+	k := p.key(at)
+	switch {
+	case k == "today" || k == "hoje":
+		if p.key(at+1) == "a" && p.key(at+2) == "noite" {
+			p.evening = true
+			p.setDate(p.today)
+			return 3
+		}
+		p.setDate(p.today)
+		return 1
+	case k == "tonight" || (thisWords[k] && p.key(at+1) == "noite"):
+		p.evening = true
+		p.setDate(p.today)
+		if k == "tonight" {
+			return 1
+		}
+		return 2
+	case k == "tomorrow" || k == "tmr" || k == "tmrw" || k == "amanha":
+		p.setDate(p.today.AddDate(0, 0, 1))
+		return 1
+	case k == "depois" && p.key(at+1) == "de" && p.key(at+2) == "amanha",
+		k == "day" && p.key(at+1) == "after" && p.key(at+2) == "tomorrow":
+		p.setDate(p.today.AddDate(0, 0, 2))
+		return 3
+	}
+
+	if unit, n := p.nextUnit(at); n > 0 {
+		switch unit {
+		case unitWeek:
+			p.setDate(p.next(p.weekStart, false))
+		case unitMonth:
+			p.setDate(p.today.AddDate(0, 1, 0))
+		case unitYear:
+			p.setDate(p.today.AddDate(1, 0, 0))
+		}
+		return n
+	}
+
+	if n := p.weekdayDate(at, connector); n > 0 {
+		return n
+	}
+	return p.calendarDate(at, connector)
+}
+
+// nextUnit reads "next week", "semana que vem" or "próximo mês", returning the unit and how many words it took.
+func (p *parser) nextUnit(at int) (string, int) {
+	if nextWords[p.key(at)] {
+		if unit := units[p.key(at+1)]; unit == unitWeek || unit == unitMonth || unit == unitYear {
+			return unit, 2
+		}
+	}
+	if unit := units[p.key(at)]; unit == unitWeek || unit == unitMonth || unit == unitYear {
+		if p.key(at+1) == "que" && p.key(at+2) == "vem" {
+			return unit, 3
+		}
+		if p.key(at+1) == "seguinte" {
+			return unit, 2
+		}
+	}
+	return "", 0
+}
+
+// weekdayDate reads a day of the week, possibly after "next" or "this".
+func (p *parser) weekdayDate(at int, connector string) int {
+	n := at
+	prefix := p.key(n)
+	if nextWords[prefix] || thisWords[prefix] {
+		n++
+	} else {
+		prefix = ""
+	}
+	day, short, ok := weekday(p.key(n))
+	if !ok || (short && prefix == "" && connector == "") {
+		return 0
+	}
+	p.setDate(p.next(day, thisWords[prefix]))
+	return n - at + 1
+}
+
+// calendarDate reads a date of the calendar: 2024-06-30, 30/06, "june 30", "30 de junho" or, after "dia", "30".
+func (p *parser) calendarDate(at int, connector string) int {
+	k := p.key(at)
+	if match := isoDate.FindStringSubmatch(k); match != nil {
+		y, m, d := atoi(match[1]), atoi(match[2]), atoi(match[3])
+		return p.setCalendarDate(y, m, d, false, 1)
+	}
+	if match := slashDate.FindStringSubmatch(k); match != nil {
+		d, m := atoi(match[1]), atoi(match[2])
+		if m > 12 && d <= 12 {
+			d, m = m, d
+		}
+		y := p.today.Year()
+		if match[3] != "" {
+			y = atoi(match[3])
+			if y < 100 {
+				y += 2000
+			}
+		}
+		return p.setCalendarDate(y, m, d, match[3] == "", 1)
+	}
+
+	// "june 30", "june 30 2025"
+	if month, ok := months[k]; ok {
+		if match := ordinal.FindStringSubmatch(p.key(at + 1)); match != nil {
+			if year.MatchString(p.key(at + 2)) {
+				return p.setCalendarDate(atoi(p.key(at+2)), int(month), atoi(match[1]), false, 3)
+			}
+			return p.setCalendarDate(p.today.Year(), int(month), atoi(match[1]), true, 2)
+		}
+		return 0
+	}
+
+	// "30 june", "30 de junho", "30 de junho de 2025", or "30" after "dia"
+	match := ordinal.FindStringSubmatch(k)
+	if match == nil {
+		return 0
+	}
+	d, n := atoi(match[1]), at+1
+	if p.key(n) == "de" {
+		n++
+	}
+	if month, ok := months[p.key(n)]; ok {
+		n++
+		if p.key(n) == "de" && year.MatchString(p.key(n+1)) {
+			return p.setCalendarDate(atoi(p.key(n+1)), int(month), d, false, n-at+2)
+		}
+		if year.MatchString(p.key(n)) {
+			return p.setCalendarDate(atoi(p.key(n)), int(month), d, false, n-at+1)
+		}
+		return p.setCalendarDate(p.today.Year(), int(month), d, true, n-at)
+	}
+	if connector == "dia" {
+		for ahead := 0; ahead < 3; ahead++ {
+			first := time.Date(p.today.Year(), p.today.Month()+time.Month(ahead), 1, 0, 0, 0, 0, p.today.Location())
+			date := first.AddDate(0, 0, d-1)
+			if date.Month() == first.Month() && !date.Before(p.today) {
+				p.setDate(date)
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// setCalendarDate sets the date if it exists, moving it to the next year when it's passed and the year was left out,
+// and returns the given number of words, or 0 when the date doesn't exist.
+func (p *parser) setCalendarDate(y, m, d int, guessYear bool, n int) int {
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, p.today.Location())
+	if date.Year() != y || date.Month() != time.Month(m) || date.Day() != d {
+		return 0
+	}
+	if guessYear && date.Before(p.today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	p.setDate(date)
+	return n
+}
+
+// timeOfDay reads a time of day, optionally after a preposition, unless the text already set one.
+func (p *parser) timeOfDay() int {
+	if p.clock != nil || p.exact != nil {
+		return 0
+	}
+	if timeConnectors[p.key(0)] {
+		if n := p.clockAt(1, true); n > 0 {
+			return n + 1
+		}
+		return 0
+	}
+	return p.clockAt(0, false)
+}
+
+// clockAt reads a time of day from the word at the given distance, where a bare hour is only one after a preposition.
+func (p *parser) clockAt(at int, connected bool) int {
+	k := p.key(at)
+	switch k {
+	case "noon", "meio-dia", "meiodia":
+		return p.setClock(12, 0, -1, 1)
+	case "midnight", "meia-noite", "meianoite":
+		return p.setClock(0, 0, -1, 1)
+	}
+
+	if match := clock12.FindStringSubmatch(k); match != nil {
+		return p.setClock12(match[1], match[2], match[3], 1)
+	}
+	if match := clock24.FindStringSubmatch(k); match != nil {
+		if next := p.key(at + 1); next == "am" || next == "pm" {
+			return p.setClock12(match[1], match[2], next, 2)
+		}
+		return p.setClock(atoi(match[1]), atoi(match[2]), at, 1)
+	}
+	if match := clockHours.FindStringSubmatch(k); match != nil {
+		return p.setClock(atoi(match[1]), atoi(match[2]), at, 1)
+	}
+	if bareHour.MatchString(k) {
+		if next := p.key(at + 1); next == "am" || next == "pm" {
+			return p.setClock12(k, "", next, 2)
+		}
+		if connected {
+			return p.setClock(atoi(k), 0, at, 1)
+		}
+	}
+	return 0
+}
+
+// setClock12 sets a time of day on the 12-hour clock, returning the given number of words,
+// or 0 when the time doesn't exist.
+func (p *parser) setClock12(hour, minute, period string, n int) int {
+	h, m := atoi(hour), atoi(minute)
+	if h < 1 || h > 12 {
+		return 0
+	}
+	h %= 12
+	if period == "pm" {
+		h += 12
+	}
+	return p.setClock(h, m, -1, n)
+}
+
+// setClock sets a time of day on the 24-hour clock, returning the given number of words, or 0 when the time doesn't exist.
+// When the word at the given distance is an hour up to 12, the part of the day that may follow it is read too:
+// "9 da noite", "3 in the afternoon".
+func (p *parser) setClock(h, m, at, n int) int {
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0
+	}
+	if at >= 0 && h <= 12 {
+		switch part, size := p.partOfDay(at + n); part {
+		case "morning":
+			if h == 12 {
+				h = 0
+			}
+			n += size
+		case "afternoon", "evening":
+			if h < 12 {
+				h += 12
+			}
+			n += size
+		}
+	}
+	clock := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	p.clock = &clock
+	return n
+}
+
+// partOfDay reads "da manhã", "de tarde", "da noite", "in the morning" and so on from the word at the given distance,
+// returning the part of the day and how many words it took.
+func (p *parser) partOfDay(at int) (string, int) {
+	if k := p.key(at); k == "da" || k == "de" {
+		if part, ok := map[string]string{"manha": "morning", "tarde": "afternoon", "noite": "evening"}[p.key(at+1)]; ok {
+			return part, 2
+		}
+	}
+	if p.key(at) == "in" && p.key(at+1) == "the" {
+		if part, ok := map[string]string{"morning": "morning", "afternoon": "afternoon", "evening": "evening"}[p.key(at+2)]; ok {
+			return part, 3
+		}
+	}
+	return "", 0
+}
+
+// setDate sets the date.
+func (p *parser) setDate(date time.Time) {
+	p.date = &date
+}
+
+// next returns the date of the next given day of the week after today, or from today on when today may be it.
+func (p *parser) next(day time.Weekday, today bool) time.Time {
+	days := (int(day) - int(p.today.Weekday()) + 7) % 7
+	if days == 0 && !today {
+		days = 7
+	}
+	return p.today.AddDate(0, 0, days)
+}
+
+// texts returns the texts of the given number of words from the one at the given distance.
+func (p *parser) texts(at, n int) []string {
+	texts := make([]string, 0, n)
+	for _, w := range p.words[p.i+at : p.i+at+n] {
+		texts = append(texts, w.text)
+	}
+	return texts
+}
+
+// result puts together what the parser understood.
+func (p *parser) result() Result {
+	if p.clock == nil && p.evening {
+		clock := 20 * time.Hour
+		p.clock = &clock
+	}
+
+	var due *time.Time
+	switch {
+	case p.exact != nil:
+		due = p.exact
+	case p.date != nil || p.clock != nil || p.repeat != nil:
+		clock := 23*time.Hour + 59*time.Minute
+		if p.clock != nil {
+			clock = *p.clock
+		}
+		date := p.at(p.firstDay(), clock)
+		due = &date
+	case p.before != nil:
+		// There's no due date to remind the task before
+		p.title = append(p.title[:p.reminderAt], append([]string{p.reminder.Text}, p.title[p.reminderAt:]...)...)
+		p.unparsed = append(p.unparsed, p.reminder)
+		sort.Slice(p.unparsed, func(i, j int) bool { return p.unparsed[i].Start < p.unparsed[j].Start })
+	}
+
+	result := Result{
+		Title:    strings.Join(p.title, " "),
+		DueDate:  due,
+		Labels:   p.labels,
+		Priority: p.priority,
+		Unparsed: p.unparsed,
+	}
+	if p.repeat != nil {
+		result.Recurrence = p.repeat.String()
+	}
+	switch {
+	case due != nil && p.before != nil:
+		reminder := due.Add(-*p.before)
+		result.Reminder = &reminder
+	case due != nil && (p.clock != nil || p.exact != nil):
+		reminder := *due
+		result.Reminder = &reminder
+	}
+	return result
+}
+
+// firstDay returns the day the task is due when the text didn't give a date, or the date it gave.
+// Without a date, it's the first day the task repeats on from today, skipping today when the time of day is past.
+func (p *parser) firstDay() time.Time {
+	if p.date != nil {
+		return *p.date
+	}
+	day := p.today
+	for i := 0; i < 7; i++ {
+		passed := p.clock != nil && i == 0 && !p.at(day, *p.clock).After(p.now)
+		if p.repeats(day) && !passed {
+			break
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// at returns the given time of day on the given day, on the wall clock even across changes of daylight saving time.
+func (p *parser) at(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, p.today.Location())
+}
+
+// repeats tells whether the task repeats on the given day, which is any day when it doesn't repeat on given weekdays.
+func (p *parser) repeats(day time.Time) bool {
+	if p.repeat == nil || len(p.repeat.byDay) == 0 {
+		return true
+	}
+	for _, weekday := range p.repeat.byDay {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// mondayFirst sorts the days of the week from Monday to Sunday, dropping repeated ones.
+func mondayFirst(days []time.Weekday) []time.Weekday {
+	sorted := []time.Weekday{}
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		for _, d := range days {
+			if d == day {
+				sorted = append(sorted, day)
+				break
+			}
+		}
+	}
+	return sorted
+}
+
+// atoi converts the digits matched by the regular expressions above, or "" to 0.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
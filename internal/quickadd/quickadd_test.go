@@ -0,0 +1,136 @@
+package quickadd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// saoPaulo is UTC-3, and now a Monday morning there.
+var (
+	saoPaulo = time.FixedZone("America/Sao_Paulo", -3*60*60)
+	now      = time.Date(2024, 6, 10, 10, 0, 0, 0, saoPaulo)
+)
+
+func on(month time.Month, day, hour, minute int) *time.Time {
+	t := time.Date(2024, month, day, hour, minute, 0, 0, saoPaulo)
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text       string
+		title      string
+		due        *time.Time
+		reminder   *time.Time
+		labels     []string
+		priority   int
+		recurrence string
+	}{
+		{"Pay rent tomorrow 9am #finance !high every month", "Pay rent", on(6, 11, 9, 0), on(6, 11, 9, 0), []string{"finance"}, 3, "FREQ=MONTHLY"},
+		{"Pagar aluguel amanhã às 9h #finanças !alta todo mês", "Pagar aluguel", on(6, 11, 9, 0), on(6, 11, 9, 0), []string{"finanças"}, 3, "FREQ=MONTHLY"},
+		{"Buy milk", "Buy milk", nil, nil, nil, 0, ""},
+		{"Buy milk today", "Buy milk", on(6, 10, 23, 59), nil, nil, 0, ""},
+		{"Call mom tonight", "Call mom", on(6, 10, 20, 0), on(6, 10, 20, 0), nil, 0, ""},
+		{"Ligar para a mãe hoje à noite", "Ligar para a mãe", on(6, 10, 20, 0), on(6, 10, 20, 0), nil, 0, ""},
+		{"Dentist on friday at 3 in the afternoon", "Dentist", on(6, 14, 15, 0), on(6, 14, 15, 0), nil, 0, ""},
+		{"Dentista na sexta às 3 da tarde", "Dentista", on(6, 14, 15, 0), on(6, 14, 15, 0), nil, 0, ""},
+		{"Segunda via do boleto", "Segunda via do boleto", nil, nil, nil, 0, ""},
+		{"Standup next monday 9:30am", "Standup", on(6, 17, 9, 30), on(6, 17, 9, 30), nil, 0, ""},
+		{"Review this monday", "Review", on(6, 10, 23, 59), nil, nil, 0, ""},
+		{"Revisar depois de amanhã", "Revisar", on(6, 12, 23, 59), nil, nil, 0, ""},
+		{"Water plants in 3 days", "Water plants", on(6, 13, 23, 59), nil, nil, 0, ""},
+		{"Regar plantas daqui a uma semana", "Regar plantas", on(6, 17, 23, 59), nil, nil, 0, ""},
+		{"Check the oven in 45 minutes", "Check the oven", on(6, 10, 10, 45), on(6, 10, 10, 45), nil, 0, ""},
+		{"Plan the trip next week", "Plan the trip", on(6, 17, 23, 59), nil, nil, 0, ""},
+		{"Planejar a viagem semana que vem", "Planejar a viagem", on(6, 17, 23, 59), nil, nil, 0, ""},
+		{"Taxes 2024-07-01", "Taxes", on(7, 1, 23, 59), nil, nil, 0, ""},
+		{"Impostos 30/06 18:00", "Impostos", on(6, 30, 18, 0), on(6, 30, 18, 0), nil, 0, ""},
+		{"Taxes 06/30", "Taxes", on(6, 30, 23, 59), nil, nil, 0, ""},
+		{"Party june 20th", "Party", on(6, 20, 23, 59), nil, nil, 0, ""},
+		{"Festa 20 de junho", "Festa", on(6, 20, 23, 59), nil, nil, 0, ""},
+		{"Pagar a fatura dia 5", "Pagar a fatura", on(7, 5, 23, 59), nil, nil, 0, ""},
+		{"Lunch at noon", "Lunch", on(6, 10, 12, 0), on(6, 10, 12, 0), nil, 0, ""},
+		{"Breakfast 8am", "Breakfast", on(6, 11, 8, 0), on(6, 11, 8, 0), nil, 0, ""},
+		{"Meeting tomorrow 14:00 remind me 30 minutes before", "Meeting", on(6, 11, 14, 0), on(6, 11, 13, 30), nil, 0, ""},
+		{"Reunião amanhã 14h lembrar 1h antes", "Reunião", on(6, 11, 14, 0), on(6, 11, 13, 0), nil, 0, ""},
+		{"Gym every monday and thursday 7pm", "Gym", on(6, 10, 19, 0), on(6, 10, 19, 0), nil, 0, "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{"Gym every monday 7am", "Gym", on(6, 17, 7, 0), on(6, 17, 7, 0), nil, 0, "FREQ=WEEKLY;BYDAY=MO"},
+		{"Academia toda quarta", "Academia", on(6, 12, 23, 59), nil, nil, 0, "FREQ=WEEKLY;BYDAY=WE"},
+		{"Backup every 2 weeks", "Backup", on(6, 10, 23, 59), nil, nil, 0, "FREQ=WEEKLY;INTERVAL=2"},
+		{"Ponto todos os dias úteis", "Ponto", on(6, 10, 23, 59), nil, nil, 0, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"Trocar filtro a cada 3 meses", "Trocar filtro", on(6, 10, 23, 59), nil, nil, 0, "FREQ=MONTHLY;INTERVAL=3"},
+		{"Arrumar todo o quarto", "Arrumar todo o quarto", nil, nil, nil, 0, ""},
+		{"Stretch daily #health #Health #home !2", "Stretch", on(6, 10, 23, 59), nil, []string{"health", "home"}, 2, "FREQ=DAILY"},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.text, now, saoPaulo, time.Monday)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.text, err)
+		}
+		if result.Title != tt.title || !sameTime(result.DueDate, tt.due) || !sameTime(result.Reminder, tt.reminder) ||
+			fmt.Sprint(result.Labels) != fmt.Sprint(tt.labels) || result.Priority != tt.priority || result.Recurrence != tt.recurrence {
+			t.Fatalf("Parse(%q) = %+v, want title %q due %v reminder %v labels %v priority %d recurrence %q",
+				tt.text, result, tt.title, tt.due, tt.reminder, tt.labels, tt.priority, tt.recurrence)
+		}
+		if len(result.Unparsed) != 0 {
+			t.Fatalf("Parse(%q) left %+v unparsed", tt.text, result.Unparsed)
+		}
+	}
+}
+
+func TestParseUnparsed(t *testing.T) {
+	tests := []struct {
+		text     string
+		title    string
+		unparsed []Token
+	}{
+		{"Fix it !urgent", "Fix it !urgent", []Token{{"!urgent", 7, 14}}},
+		{"Café às 25:00", "Café às 25:00", []Token{{"25:00", 8, 13}}},
+		{"Taxes 31/02 !high !low", "Taxes 31/02 !low", []Token{{"31/02", 6, 11}, {"!low", 18, 22}}},
+		{"Stretch remind me 10 min before", "Stretch remind me 10 min before", []Token{{"remind me 10 min before", 8, 31}}},
+	}
+	for _, tt := range tests {
+		result, err := Parse(tt.text, now, saoPaulo, time.Monday)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.text, err)
+		}
+		if result.Title != tt.title || fmt.Sprint(result.Unparsed) != fmt.Sprint(tt.unparsed) {
+			t.Fatalf("Parse(%q) = %+v, want title %q unparsed %v", tt.text, result, tt.title, tt.unparsed)
+		}
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	for _, text := range []string{"Water the plants every 0 days", "Regar a cada 0 dias", "Backup every -1 weeks"} {
+		if result, err := Parse(text, now, saoPaulo, time.Monday); !errors.Is(err, ErrInterval) || result.Recurrence != "" {
+			t.Fatalf("Parse(%q) = %+v, %v, want ErrInterval and no recurrence", text, result, err)
+		}
+	}
+}
+
+func TestParseInLocation(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+
+	// Late on Monday in São Paulo is already Tuesday in Tokyo
+	late := time.Date(2024, 6, 10, 22, 0, 0, 0, saoPaulo)
+	result, _ := Parse("Call tomorrow 9am", late, tokyo, time.Sunday)
+	want := time.Date(2024, 6, 12, 9, 0, 0, 0, tokyo)
+	if result.DueDate == nil || !result.DueDate.Equal(want) {
+		t.Fatalf("expected the task to be due %v, got %v", want, result.DueDate)
+	}
+
+	// Next week starts on the first day of the week where the user is
+	result, _ = Parse("Plan next week", now, saoPaulo, time.Sunday)
+	if want := on(6, 16, 23, 59); result.DueDate == nil || !result.DueDate.Equal(*want) {
+		t.Fatalf("expected the task to be due %v, got %v", want, result.DueDate)
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
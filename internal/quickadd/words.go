@@ -0,0 +1,183 @@
+package quickadd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// word is a word of the text being parsed, along with its key: the word folded to lower case, without accents
+// and without trailing punctuation, which is what the vocabulary below is matched against.
+type word struct {
+	text       string
+	key        string
+	start, end int
+}
+
+// split splits the text in words at white space, keeping the rune offsets of each word.
+func split(text string) []word {
+	var words []word
+	var b strings.Builder
+	start, pos := -1, 0
+	flush := func() {
+		if start >= 0 {
+			words = append(words, word{text: b.String(), key: fold(b.String()), start: start, end: pos})
+			b.Reset()
+			start = -1
+		}
+	}
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			flush()
+		} else {
+			if start < 0 {
+				start = pos
+			}
+			b.WriteRune(r)
+		}
+		pos++
+	}
+	flush()
+	return words
+}
+
+// accents maps the accented letters of Portuguese to their plain counterparts.
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+// fold returns the key of a word.
+func fold(s string) string {
+	return strings.TrimRight(accents.Replace(strings.ToLower(s)), ",;.")
+}
+
+// priorities maps the priority markers to their priorities.
+var priorities = map[string]int{
+	"!1": 1, "!low": 1, "!baixa": 1,
+	"!2": 2, "!!": 2, "!medium": 2, "!media": 2,
+	"!3": 3, "!!!": 3, "!high": 3, "!alta": 3,
+}
+
+// numbers maps the numbers written as words to their values.
+var numbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "um": 1, "uma": 1,
+	"two": 2, "dois": 2, "duas": 2,
+	"three": 3, "tres": 3,
+	"four": 4, "quatro": 4,
+	"five": 5, "cinco": 5,
+	"six": 6, "seis": 6,
+	"seven": 7, "sete": 7,
+	"eight": 8, "oito": 8,
+	"nine": 9, "nove": 9,
+	"ten": 10, "dez": 10,
+}
+
+// number returns the value of a key holding a positive number, in digits or in words.
+func number(key string) (int, bool) {
+	if n, ok := numbers[key]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(key)
+	return n, err == nil && n > 0
+}
+
+// The units of the durations and relative dates.
+const (
+	unitMinute = "minute"
+	unitHour   = "hour"
+	unitDay    = "day"
+	unitWeek   = "week"
+	unitMonth  = "month"
+	unitYear   = "year"
+)
+
+// units maps the names of the units, singular and plural, to the units.
+var units = map[string]string{
+	"minute": unitMinute, "minutes": unitMinute, "min": unitMinute, "mins": unitMinute, "minuto": unitMinute, "minutos": unitMinute,
+	"hour": unitHour, "hours": unitHour, "hr": unitHour, "hrs": unitHour, "hora": unitHour, "horas": unitHour,
+	"day": unitDay, "days": unitDay, "dia": unitDay, "dias": unitDay,
+	"week": unitWeek, "weeks": unitWeek, "semana": unitWeek, "semanas": unitWeek,
+	"month": unitMonth, "months": unitMonth, "mes": unitMonth, "meses": unitMonth,
+	"year": unitYear, "years": unitYear, "ano": unitYear, "anos": unitYear,
+}
+
+// frequencies maps the units to the RRULE frequencies repeating every one of them.
+var frequencies = map[string]string{
+	unitDay:   "DAILY",
+	unitWeek:  "WEEKLY",
+	unitMonth: "MONTHLY",
+	unitYear:  "YEARLY",
+}
+
+// adverbs maps the words saying on their own how often a task repeats to their rules.
+var adverbs = map[string]rule{
+	"daily": {freq: "DAILY"}, "diariamente": {freq: "DAILY"},
+	"weekly": {freq: "WEEKLY"}, "semanalmente": {freq: "WEEKLY"},
+	"biweekly": {freq: "WEEKLY", interval: 2}, "fortnightly": {freq: "WEEKLY", interval: 2}, "quinzenalmente": {freq: "WEEKLY", interval: 2},
+	"monthly": {freq: "MONTHLY"}, "mensalmente": {freq: "MONTHLY"},
+	"yearly": {freq: "YEARLY"}, "annually": {freq: "YEARLY"}, "anualmente": {freq: "YEARLY"},
+}
+
+// weekdays maps the names of the days of the week to the days.
+// The Portuguese names of the weekdays without "-feira" are common words of their own,
+// so shortWeekdays lists them to be read as dates only after a preposition.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+	"domingo": time.Sunday, "segunda": time.Monday, "terca": time.Tuesday, "quarta": time.Wednesday,
+	"quinta": time.Thursday, "sexta": time.Friday, "sabado": time.Saturday,
+}
+
+var shortWeekdays = map[string]bool{"segunda": true, "terca": true, "quarta": true, "quinta": true, "sexta": true}
+
+// weekday returns the day of the week named by the key, in singular or plural, and whether it's short.
+func weekday(key string) (day time.Weekday, short bool, ok bool) {
+	name, long := strings.CutSuffix(key, "-feira")
+	if !long {
+		name, long = strings.CutSuffix(key, "-feiras")
+	}
+	if _, found := weekdays[name]; !found {
+		name = strings.TrimSuffix(name, "s")
+	}
+	day, ok = weekdays[name]
+	return day, ok && !long && shortWeekdays[name], ok
+}
+
+// months maps the names of the months, and their abbreviations, to the months.
+// The Portuguese "set" and "out" are left out for being English words.
+var months = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April, "jun": time.June, "jul": time.July,
+	"aug": time.August, "sep": time.September, "sept": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+	"janeiro": time.January, "fevereiro": time.February, "marco": time.March, "abril": time.April,
+	"maio": time.May, "junho": time.June, "julho": time.July, "agosto": time.August,
+	"setembro": time.September, "outubro": time.October, "novembro": time.November, "dezembro": time.December,
+	"fev": time.February, "abr": time.April, "mai": time.May, "ago": time.August, "dez": time.December,
+}
+
+// reminderWords start a reminder: "remind me 30 minutes before", "lembrar 1h antes".
+var reminderWords = map[string]bool{"remind": true, "reminder": true, "lembrar": true, "lembrete": true, "avisar": true}
+
+// dateConnectors are the prepositions that may come before a date: "on friday", "até sexta", "dia 30".
+var dateConnectors = map[string]bool{
+	"on": true, "by": true, "due": true, "until": true, "till": true,
+	"para": true, "pra": true, "ate": true, "no": true, "na": true, "em": true, "dia": true,
+}
+
+// timeConnectors are the prepositions that may come before a time of day: "at 9am", "às 9h".
+var timeConnectors = map[string]bool{"at": true, "@": true, "as": true}
+
+// nextWords come before a day of the week or a unit to mean the next one: "next friday", "próxima semana".
+// thisWords come before a day of the week that may be today: "this friday", "nesta sexta".
+var (
+	nextWords = map[string]bool{"next": true, "proxima": true, "proximo": true}
+	thisWords = map[string]bool{"this": true, "esta": true, "este": true, "nesta": true, "neste": true, "essa": true, "esse": true, "nessa": true, "nesse": true}
+)
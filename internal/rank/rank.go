@@ -0,0 +1,218 @@
+// Package rank generates the keys that order tasks within a board column.
+//
+// Keys are strings compared byte by byte, and a key can always be generated between any two others,
+// so moving a task only rewrites its own key instead of renumbering the whole column. It's the fractional
+// indexing scheme: a key is a variable-length integer part, whose first character tells its length,
+// followed by a fraction in base 62. Appending to the end of a column increments the integer part,
+// which keeps keys short, while inserting between two neighbours grows the fraction.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits are the base 62 digits, in byte order.
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// smallestInteger is the lowest integer part. It's never returned on its own, so there is always room before a key.
+const smallestInteger = "A" + "00000000000000000000000000"
+
+var (
+	// ErrInvalidKey is returned when a key wasn't generated by this package.
+	ErrInvalidKey = errors.New("invalid rank key")
+
+	// ErrOrder is returned when the key that should come first doesn't sort before the other one.
+	ErrOrder = errors.New("rank keys out of order")
+)
+
+// Between returns a key that sorts after a and before b.
+// An empty a stands for the start of the column and an empty b for its end, so Between("", "") returns
+// the key of the first task of an empty column.
+func Between(a, b string) (string, error) {
+	if a != "" {
+		if err := validate(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := validate(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrOrder
+	}
+
+	switch {
+	case a == "" && b == "":
+		return "a0", nil
+	case a == "":
+		ib := integerPart(b)
+		if ib == smallestInteger {
+			return ib + midpoint("", b[len(ib):]), nil
+		}
+		if ib < b {
+			return ib, nil
+		}
+		if key, ok := decrement(ib); ok {
+			return key, nil
+		}
+		return "", ErrInvalidKey
+	case b == "":
+		ia := integerPart(a)
+		if key, ok := increment(ia); ok {
+			return key, nil
+		}
+		return ia + midpoint(a[len(ia):], ""), nil
+	}
+
+	ia, ib := integerPart(a), integerPart(b)
+	if ia == ib {
+		return ia + midpoint(a[len(ia):], b[len(ib):]), nil
+	}
+	if key, ok := increment(ia); ok && key < b {
+		return key, nil
+	}
+	return ia + midpoint(a[len(ia):], ""), nil
+}
+
+// validate checks that the key has a complete integer part, base 62 digits and no trailing zero in its fraction.
+func validate(key string) error {
+	length, ok := integerLength(key[0])
+	if !ok || len(key) < length || key == smallestInteger {
+		return ErrInvalidKey
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return ErrInvalidKey
+		}
+	}
+	if len(key) > length && key[len(key)-1] == digits[0] {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// integerLength returns the length of the integer part starting with head: lowercase heads start the
+// non-negative integers, from 2 characters for 'a' up, and uppercase heads the negative ones, from 2
+// characters for 'Z' up.
+func integerLength(head byte) (int, bool) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, true
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, true
+	}
+	return 0, false
+}
+
+// integerPart returns the integer part of a valid key.
+func integerPart(key string) string {
+	length, _ := integerLength(key[0])
+	return key[:length]
+}
+
+// increment returns the integer part following the given one, or false when it's already the largest one.
+func increment(integer string) (string, bool) {
+	head, digs := integer[0], []byte(integer[1:])
+	for i := len(digs) - 1; i >= 0; i-- {
+		d := strings.IndexByte(digits, digs[i]) + 1
+		if d < len(digits) {
+			digs[i] = digits[d]
+			return string(head) + string(digs), true
+		}
+		digs[i] = digits[0]
+	}
+
+	// Every digit carried over, so the integer needs a longer, or for negatives a shorter, part
+	switch head {
+	case 'Z':
+		return "a" + digits[:1], true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		digs = append(digs, digits[0])
+	} else {
+		digs = digs[:len(digs)-1]
+	}
+	return string(head) + string(digs), true
+}
+
+// decrement returns the integer part preceding the given one, or false when it's already the smallest one.
+func decrement(integer string) (string, bool) {
+	head, digs := integer[0], []byte(integer[1:])
+	last := digits[len(digits)-1]
+	for i := len(digs) - 1; i >= 0; i-- {
+		d := strings.IndexByte(digits, digs[i]) - 1
+		if d >= 0 {
+			digs[i] = digits[d]
+			return string(head) + string(digs), true
+		}
+		digs[i] = last
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + string(last), true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		digs = append(digs, last)
+	} else {
+		digs = digs[:len(digs)-1]
+	}
+	return string(head) + string(digs), true
+}
+
+// midpoint returns a fraction between the fractions a and b, where an empty b stands for 1.
+// Neither fraction may end with a zero, and a must sort before b.
+func midpoint(a, b string) string {
+	if b != "" {
+		// Skip the common prefix, reading a as padded with zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	da, db := 0, len(digits)
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db+1)/2])
+	}
+
+	// The first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(digits[da]) + midpoint(rest, "")
+}
+
+// digitAt returns the digit at position i of the fraction, or zero past its end.
+func digitAt(fraction string, i int) byte {
+	if i < len(fraction) {
+		return fraction[i]
+	}
+	return digits[0]
+}
@@ -0,0 +1,110 @@
+package rank
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"", "", "a0"},
+		{"a0", "", "a1"},
+		{"az", "", "b00"},
+		{"", "a0", "Zz"},
+		{"a0", "a1", "a0V"},
+		{"a0V", "a1", "a0l"},
+		{"a0", "a0V", "a0G"},
+		{"a1", "a2", "a1V"},
+		{"a0", "b00", "a1"},
+		{"Zz", "a01", "a0"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.a, tt.b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q) returned %v", tt.a, tt.b, err)
+		}
+		if got != tt.want {
+			t.Fatalf("Between(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		a, b string
+		err  error
+	}{
+		{"a1", "a0", ErrOrder},
+		{"a0", "a0", ErrOrder},
+		{"a", "", ErrInvalidKey},
+		{"a00", "", ErrInvalidKey},
+		{"", "a0-", ErrInvalidKey},
+		{"0", "", ErrInvalidKey},
+		{smallestInteger, "", ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		if _, err := Between(tt.a, tt.b); !errors.Is(err, tt.err) {
+			t.Fatalf("Between(%q, %q) returned %v, want %v", tt.a, tt.b, err, tt.err)
+		}
+	}
+}
+
+func TestAppendKeepsKeysShort(t *testing.T) {
+	key := ""
+	for i := 0; i < 10000; i++ {
+		next, err := Between(key, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next <= key {
+			t.Fatalf("expected %q to sort after %q", next, key)
+		}
+		key = next
+	}
+	if len(key) > 4 {
+		t.Fatalf("expected short keys after appending 10000 tasks, got %q", key)
+	}
+
+	key = ""
+	for i := 0; i < 10000; i++ {
+		next, err := Between("", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != "" && next >= key {
+			t.Fatalf("expected %q to sort before %q", next, key)
+		}
+		key = next
+	}
+}
+
+func TestRandomInsertionsStayOrdered(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	keys := []string{}
+	for i := 0; i < 2000; i++ {
+		at := r.Intn(len(keys) + 1)
+		a, b := "", ""
+		if at > 0 {
+			a = keys[at-1]
+		}
+		if at < len(keys) {
+			b = keys[at]
+		}
+
+		key, err := Between(a, b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q) returned %v", a, b, err)
+		}
+		if (a != "" && key <= a) || (b != "" && key >= b) {
+			t.Fatalf("Between(%q, %q) = %q, out of order", a, b, key)
+		}
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Fatal("expected the keys to stay sorted")
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormAttachmentRepository is the AttachmentRepository backed by a GORM connection to PostgreSQL or SQLite.
+type gormAttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAttachmentRepository returns an AttachmentRepository that stores attachments through the given GORM connection.
+func NewGormAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &gormAttachmentRepository{db: db}
+}
+
+func (r *gormAttachmentRepository) Create(ctx context.Context, attachment *models.Attachment) error {
+	return translateError(r.db.WithContext(ctx).Omit(clause.Associations).Create(attachment).Error)
+}
+
+func (r *gormAttachmentRepository) CreateWithinQuota(ctx context.Context, attachment *models.Attachment, quota int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// On PostgreSQL, the uploads of a user wait for each other on their row, SQLite runs one transaction at a time already
+		if tx.Name() == "postgres" {
+			var locked []string
+			err := tx.Model(&models.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", attachment.UploaderID).
+				Pluck("id", &locked).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var usage int64
+		err := tx.Model(&models.Attachment{}).
+			Select("COALESCE(SUM(size), 0)").
+			Where("uploader_id = ?", attachment.UploaderID).
+			Scan(&usage).Error
+		if err != nil {
+			return err
+		}
+		if usage+attachment.Size > quota {
+			return ErrQuotaExceeded
+		}
+		return tx.Omit(clause.Associations).Create(attachment).Error
+	}))
+}
+
+func (r *gormAttachmentRepository) FindByID(ctx context.Context, id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.WithContext(ctx).First(&attachment, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &attachment, nil
+}
+
+func (r *gormAttachmentRepository) FindByTask(ctx context.Context, taskID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("id").Find(&attachments).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return attachments, nil
+}
+
+func (r *gormAttachmentRepository) FindByOwner(ctx context.Context, userID string) ([]models.Attachment, error) {
+	owned := r.db.Model(&models.Workspace{}).Select("id").Where("owner_id = ?", userID)
+	tasks := r.db.Unscoped().Model(&models.Tasks{}).Select("id").
+		Where("(user_id = ? AND workspace_id IS NULL) OR workspace_id IN (?)", userID, owned)
+
+	var attachments []models.Attachment
+	err := r.db.WithContext(ctx).Where("uploader_id = ? OR task_id IN (?)", userID, tasks).Order("id").Find(&attachments).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return attachments, nil
+}
+
+func (r *gormAttachmentRepository) FindByWorkspace(ctx context.Context, workspaceID string) ([]models.Attachment, error) {
+	tasks := r.db.Unscoped().Model(&models.Tasks{}).Select("id").Where("workspace_id = ?", workspaceID)
+
+	var attachments []models.Attachment
+	if err := r.db.WithContext(ctx).Where("task_id IN (?)", tasks).Order("id").Find(&attachments).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return attachments, nil
+}
+
+func (r *gormAttachmentRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Attachment{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormAttachmentRepository) UsageByUser(ctx context.Context, userID string) (int64, error) {
+	var usage int64
+	err := r.db.WithContext(ctx).Model(&models.Attachment{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("uploader_id = ?", userID).
+		Scan(&usage).Error
+	return usage, translateError(err)
+}
+
+func (r *gormAttachmentRepository) FindByThumbnailStatus(ctx context.Context, status models.ThumbnailStatus) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if err := r.db.WithContext(ctx).Where("thumbnail_status = ?", status).Order("id").Find(&attachments).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return attachments, nil
+}
+
+func (r *gormAttachmentRepository) SetThumbnailStatus(ctx context.Context, id uint, status models.ThumbnailStatus) error {
+	result := r.db.WithContext(ctx).Model(&models.Attachment{}).Where("id = ?", id).Update("thumbnail_status", status)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
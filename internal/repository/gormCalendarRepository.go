@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+)
+
+// gormCalendarRepository is the CalendarRepository backed by a GORM database.
+type gormCalendarRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCalendarRepository returns a CalendarRepository that stores the CalDAV state of tasks through GORM.
+func NewGormCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &gormCalendarRepository{db: db}
+}
+
+func (r *gormCalendarRepository) FindObjects(ctx context.Context, taskIDs []uint) ([]models.CalendarObject, error) {
+	objects := []models.CalendarObject{}
+	if len(taskIDs) == 0 {
+		return objects, nil
+	}
+	if err := r.db.WithContext(ctx).Where("task_id IN ?", taskIDs).Order("task_id").Find(&objects).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return objects, nil
+}
+
+func (r *gormCalendarRepository) SaveObject(ctx context.Context, object *models.CalendarObject) error {
+	return translateError(r.db.WithContext(ctx).Omit("Task").Save(object).Error)
+}
+
+func (r *gormCalendarRepository) FindSync(ctx context.Context, userID, token string) (*models.CalendarSync, error) {
+	var state models.CalendarSync
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND token = ?", userID, token).First(&state).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &state, nil
+}
+
+func (r *gormCalendarRepository) SaveSync(ctx context.Context, state *models.CalendarSync, before time.Time) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.CreatedAt = time.Now()
+		if err := tx.Omit("User").Save(state).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND created_at < ?", state.UserID, before).Delete(&models.CalendarSync{}).Error
+	}))
+}
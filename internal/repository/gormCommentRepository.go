@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormCommentRepository is the CommentRepository backed by a GORM connection to PostgreSQL or SQLite.
+type gormCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCommentRepository returns a CommentRepository that stores comments through the given GORM connection.
+func NewGormCommentRepository(db *gorm.DB) CommentRepository {
+	return &gormCommentRepository{db: db}
+}
+
+func (r *gormCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(comment).Error; err != nil {
+			return err
+		}
+		return createMentions(tx, comment)
+	}))
+}
+
+func (r *gormCommentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).Preload("Mentions").First(&comment, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &comment, nil
+}
+
+func (r *gormCommentRepository) FindByTask(ctx context.Context, taskID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.WithContext(ctx).Unscoped().Preload("Mentions").Where("task_id = ?", taskID).Order("id").Find(&comments).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return comments, nil
+}
+
+func (r *gormCommentRepository) Update(ctx context.Context, comment *models.Comment, revision *models.CommentRevision) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(comment).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Create(revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		return createMentions(tx, comment)
+	}))
+}
+
+func (r *gormCommentRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Comment{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormCommentRepository) ListRevisions(ctx context.Context, commentID uint) ([]models.CommentRevision, error) {
+	var revisions []models.CommentRevision
+	if err := r.db.WithContext(ctx).Where("comment_id = ?", commentID).Order("id").Find(&revisions).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return revisions, nil
+}
+
+// createMentions stores the mentions of the comment, pointing them to it.
+func createMentions(tx *gorm.DB, comment *models.Comment) error {
+	if len(comment.Mentions) == 0 {
+		return nil
+	}
+	for i := range comment.Mentions {
+		comment.Mentions[i].CommentID = comment.ID
+	}
+	return tx.Omit("User").Create(&comment.Mentions).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+)
+
+// gormImportRepository is the ImportRepository backed by a GORM database.
+type gormImportRepository struct {
+	db *gorm.DB
+}
+
+// NewGormImportRepository returns an ImportRepository that stores import jobs through GORM.
+func NewGormImportRepository(db *gorm.DB) ImportRepository {
+	return &gormImportRepository{db: db}
+}
+
+func (r *gormImportRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	return translateError(r.db.WithContext(ctx).Omit("User").Create(job).Error)
+}
+
+func (r *gormImportRepository) FindByID(ctx context.Context, id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &job, nil
+}
+
+func (r *gormImportRepository) FindByUser(ctx context.Context, userID string) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	if err := r.db.WithContext(ctx).Omit("Data").Where("user_id = ?", userID).Order("id DESC").Find(&jobs).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return jobs, nil
+}
+
+func (r *gormImportRepository) FindByStatus(ctx context.Context, status models.ImportStatus) ([]models.ImportJob, error) {
+	var jobs []models.ImportJob
+	if err := r.db.WithContext(ctx).Where("status = ?", status).Order("id").Find(&jobs).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return jobs, nil
+}
+
+func (r *gormImportRepository) Update(ctx context.Context, job *models.ImportJob) error {
+	return translateError(r.db.WithContext(ctx).Omit("User").Save(job).Error)
+}
+
+func (r *gormImportRepository) FindImported(ctx context.Context, userID, source string, externalIDs []string) ([]models.ImportedTask, error) {
+	imported := []models.ImportedTask{}
+	if len(externalIDs) == 0 {
+		return imported, nil
+	}
+	err := r.db.WithContext(ctx).Where("user_id = ? AND source = ? AND external_id IN ?", userID, source, externalIDs).
+		Order("external_id").Find(&imported).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return imported, nil
+}
+
+func (r *gormImportRepository) SaveImported(ctx context.Context, imported *models.ImportedTask) error {
+	return translateError(r.db.WithContext(ctx).Omit("User", "Task").Save(imported).Error)
+}
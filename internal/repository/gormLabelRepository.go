@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormLabelRepository is the LabelRepository backed by a GORM connection to PostgreSQL or SQLite.
+type gormLabelRepository struct {
+	db *gorm.DB
+}
+
+// NewGormLabelRepository returns a LabelRepository that stores labels through the given GORM connection.
+func NewGormLabelRepository(db *gorm.DB) LabelRepository {
+	return &gormLabelRepository{db: db}
+}
+
+func (r *gormLabelRepository) Create(ctx context.Context, label *models.Label) error {
+	return translateError(r.db.WithContext(ctx).Omit(clause.Associations).Create(label).Error)
+}
+
+func (r *gormLabelRepository) FindByID(ctx context.Context, id uint) (*models.Label, error) {
+	var label models.Label
+	if err := r.db.WithContext(ctx).First(&label, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &label, nil
+}
+
+func (r *gormLabelRepository) FindByUser(ctx context.Context, userID string) ([]models.Label, error) {
+	var labels []models.Label
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND workspace_id IS NULL", userID).Order("name, id").Find(&labels).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return labels, nil
+}
+
+func (r *gormLabelRepository) FindByWorkspace(ctx context.Context, workspaceID string) ([]models.Label, error) {
+	var labels []models.Label
+	if err := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Order("name, id").Find(&labels).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return labels, nil
+}
+
+func (r *gormLabelRepository) Update(ctx context.Context, label *models.Label) error {
+	return translateError(r.db.WithContext(ctx).Omit(clause.Associations).Save(label).Error)
+}
+
+func (r *gormLabelRepository) Delete(ctx context.Context, id uint) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("label_id = ?", id).Delete(&models.TaskLabel{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Label{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
+
+func (r *gormLabelRepository) Tag(ctx context.Context, labelID uint, taskIDs []uint) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	tags := make([]models.TaskLabel, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		tags = append(tags, models.TaskLabel{TaskID: taskID, LabelID: labelID})
+	}
+	return translateError(r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error)
+}
+
+func (r *gormLabelRepository) Untag(ctx context.Context, labelID uint, taskIDs []uint) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	return translateError(r.db.WithContext(ctx).Where("label_id = ? AND task_id IN ?", labelID, taskIDs).Delete(&models.TaskLabel{}).Error)
+}
+
+func (r *gormLabelRepository) FindByTask(ctx context.Context, taskID uint) ([]models.Label, error) {
+	var labels []models.Label
+	err := r.db.WithContext(ctx).
+		Joins("JOIN task_labels ON task_labels.label_id = labels.id").
+		Where("task_labels.task_id = ?", taskID).
+		Order("labels.name, labels.id").
+		Find(&labels).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return labels, nil
+}
+
+func (r *gormLabelRepository) FindTaggedTaskIDs(ctx context.Context, labelIDs []uint, all bool) ([]uint, error) {
+	if len(labelIDs) == 0 {
+		return []uint{}, nil
+	}
+	tx := r.db.WithContext(ctx).Model(&models.TaskLabel{}).Where("label_id IN ?", labelIDs).Group("task_id")
+	if all {
+		tx = tx.Having("COUNT(DISTINCT label_id) = ?", len(labelIDs))
+	}
+
+	var ids []uint
+	if err := tx.Order("task_id").Pluck("task_id", &ids).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskSearchVector is the PostgreSQL expression the task search matches against.
+// The GIN index created by the migrations is built over this same expression, so keep them in sync.
+const TaskSearchVector = `to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(description, '') || ' ' || coalesce(notes, ''))`
+
+// RankColumn returns the expression ordering tasks by rank on the given connection. Ranks compare byte by byte,
+// as the rank package and the boards do, so PostgreSQL sorts them with the "C" collation instead of the
+// database's locale, which would put "a" before "B".
+func RankColumn(db *gorm.DB) string {
+	if db.Name() == "postgres" {
+		return `rank COLLATE "C"`
+	}
+	return "rank"
+}
+
+// taskBlocked is the column filling models.Tasks.Blocked: whether any task the task depends on isn't completed yet.
+const taskBlocked = `EXISTS (
+	SELECT 1 FROM task_dependencies AS d JOIN tasks AS p ON p.id = d.depends_on_id
+	WHERE d.task_id = tasks.id AND p.completed_at IS NULL AND p.deleted_at IS NULL
+) AS blocked`
+
+// gormTaskRepository is the TaskRepository backed by a GORM connection to PostgreSQL or SQLite.
+type gormTaskRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTaskRepository returns a TaskRepository that stores tasks through the given GORM connection.
+func NewGormTaskRepository(db *gorm.DB) TaskRepository {
+	return &gormTaskRepository{db: db}
+}
+
+// tasks starts a query over the tasks that fills their Blocked field.
+func (r *gormTaskRepository) tasks(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&models.Tasks{}).Select("tasks.*, " + taskBlocked)
+}
+
+func (r *gormTaskRepository) Create(ctx context.Context, task *models.Tasks) error {
+	return translateError(r.db.WithContext(ctx).Omit("User", "Workspace").Create(task).Error)
+}
+
+func (r *gormTaskRepository) FindByID(ctx context.Context, id uint) (*models.Tasks, error) {
+	var task models.Tasks
+	if err := r.tasks(ctx).First(&task, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &task, nil
+}
+
+func (r *gormTaskRepository) FindByUser(ctx context.Context, userID string) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	if err := r.tasks(ctx).Where("user_id = ? AND workspace_id IS NULL", userID).Order("id").Find(&tasks).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) FindByWorkspace(ctx context.Context, workspaceID string) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	if err := r.tasks(ctx).Where("workspace_id = ?", workspaceID).Order("id").Find(&tasks).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) FindColumn(ctx context.Context, userID string, workspaceID *string, status string) ([]models.Tasks, error) {
+	tx := r.tasks(ctx).Where("status = ?", status)
+	if workspaceID != nil {
+		tx = tx.Where("workspace_id = ?", *workspaceID)
+	} else {
+		tx = tx.Where("user_id = ? AND workspace_id IS NULL", userID)
+	}
+
+	var tasks []models.Tasks
+	if err := tx.Order(RankColumn(r.db) + ", id").Find(&tasks).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) Search(ctx context.Context, userID, query string) ([]models.Tasks, error) {
+	tx := r.tasks(ctx).Where("user_id = ? AND workspace_id IS NULL", userID)
+
+	switch r.db.Name() {
+	case "postgres":
+		tx = tx.Where(TaskSearchVector+" @@ plainto_tsquery('simple', ?)", query)
+	case "sqlite":
+		tx = tx.Where("id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH ?)", ftsQuery(query))
+	default:
+		for _, term := range strings.Fields(query) {
+			like := "%" + strings.ToLower(term) + "%"
+			tx = tx.Where("LOWER(title) LIKE ? OR LOWER(description) LIKE ? OR LOWER(notes) LIKE ?", like, like, like)
+		}
+	}
+
+	var tasks []models.Tasks
+	if err := tx.Order("id").Find(&tasks).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) Update(ctx context.Context, task *models.Tasks) error {
+	return translateError(r.db.WithContext(ctx).Omit("User", "Workspace").Save(task).Error)
+}
+
+func (r *gormTaskRepository) CreateInColumn(ctx context.Context, task *models.Tasks, limit int) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkColumn(tx, task, limit); err != nil {
+			return err
+		}
+		return tx.Omit("User", "Workspace").Create(task).Error
+	}))
+}
+
+func (r *gormTaskRepository) UpdateInColumn(ctx context.Context, task *models.Tasks, limit int) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkColumn(tx, task, limit); err != nil {
+			return err
+		}
+		return tx.Omit("User", "Workspace").Save(task).Error
+	}))
+}
+
+// checkColumn returns ErrColumnFull when the board column of the task's status already holds limit tasks other than it.
+// On PostgreSQL it locks the workspace, or the owner of personal tasks, and the tasks of the column until the transaction
+// ends, so that tasks entering the column one after the other see those that entered before them, even in an empty column.
+// SQLite runs one transaction at a time already.
+func checkColumn(tx *gorm.DB, task *models.Tasks, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	column := tx.Model(&models.Tasks{}).Where("status = ? AND id <> ?", task.Status, task.ID)
+	if task.WorkspaceID != nil {
+		column = column.Where("workspace_id = ?", *task.WorkspaceID)
+	} else {
+		column = column.Where("user_id = ? AND workspace_id IS NULL", task.UserID)
+	}
+	if tx.Name() == "postgres" {
+		lock := clause.Locking{Strength: "UPDATE"}
+		board := tx.Model(&models.User{}).Where("id = ?", task.UserID)
+		if task.WorkspaceID != nil {
+			board = tx.Model(&models.Workspace{}).Where("id = ?", *task.WorkspaceID)
+		}
+		var locked []string
+		if err := board.Clauses(lock).Pluck("id", &locked).Error; err != nil {
+			return err
+		}
+		column = column.Clauses(lock)
+	}
+
+	var ids []uint
+	if err := column.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) >= limit {
+		return ErrColumnFull
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) Delete(ctx context.Context, id uint) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Tasks{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Model(&models.Tasks{}).Where("parent_id = ?", id).Update("parent_id", nil).Error
+	}))
+}
+
+func (r *gormTaskRepository) FindDeleted(ctx context.Context, before time.Time) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	err := r.tasks(ctx).Unscoped().
+		Where("tasks.deleted_at IS NOT NULL AND tasks.deleted_at < ?", before).
+		Order("tasks.deleted_at, tasks.id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) Purge(ctx context.Context, id uint) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// What's attached to the task goes with it through the ON DELETE CASCADE of the foreign keys
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&models.Tasks{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		// Subtasks deleted along with their parent still point at it
+		return tx.Unscoped().Model(&models.Tasks{}).Where("parent_id = ?", id).Update("parent_id", nil).Error
+	}))
+}
+
+func (r *gormTaskRepository) FindChildren(ctx context.Context, parentID uint) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	if err := r.tasks(ctx).Where("parent_id = ?", parentID).Order("id").Find(&tasks).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) CompleteDescendants(ctx context.Context, id uint, status string, at time.Time, ranks map[uint]string) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// UNION, rather than UNION ALL, stops the recursion should the tree ever contain a cycle
+		var ids []uint
+		err := tx.Raw(`WITH RECURSIVE subtree(id) AS (
+				SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+				UNION
+				SELECT tasks.id FROM tasks JOIN subtree ON tasks.parent_id = subtree.id WHERE tasks.deleted_at IS NULL
+			) SELECT id FROM subtree`, id).Scan(&ids).Error
+		if err != nil {
+			return err
+		}
+
+		for taskID, key := range ranks {
+			err := tx.Model(&models.Tasks{}).
+				Where("id = ? AND completed_at IS NULL", taskID).
+				Updates(map[string]interface{}{"status": status, "completed_at": at, "rank": key}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.ChecklistItem{}).
+			Where("task_id IN ? AND done = ?", append(ids, id), false).
+			Updates(map[string]interface{}{"done": true}).Error
+	}))
+}
+
+func (r *gormTaskRepository) AddChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ChecklistItem{}).
+			Select("COALESCE(MAX(position), -1) + 1").
+			Where("task_id = ?", item.TaskID).
+			Scan(&item.Position).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit("Task").Create(item).Error
+	}))
+}
+
+func (r *gormTaskRepository) FindChecklistItem(ctx context.Context, id uint) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &item, nil
+}
+
+func (r *gormTaskRepository) ListChecklistItems(ctx context.Context, taskID uint) ([]models.ChecklistItem, error) {
+	var items []models.ChecklistItem
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("position, id").Find(&items).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return items, nil
+}
+
+func (r *gormTaskRepository) UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) error {
+	return translateError(r.db.WithContext(ctx).Omit("Task").Save(item).Error)
+}
+
+func (r *gormTaskRepository) DeleteChecklistItem(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.ChecklistItem{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) ReorderChecklist(ctx context.Context, taskID uint, ids []uint) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			err := tx.Model(&models.ChecklistItem{}).
+				Where("id = ? AND task_id = ?", id, taskID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (r *gormTaskRepository) AddDependency(ctx context.Context, dependency *models.TaskDependency) error {
+	return translateError(r.db.WithContext(ctx).Omit("Task", "DependsOn").Create(dependency).Error)
+}
+
+func (r *gormTaskRepository) RemoveDependency(ctx context.Context, taskID, dependsOnID uint) error {
+	result := r.db.WithContext(ctx).Where("task_id = ? AND depends_on_id = ?", taskID, dependsOnID).Delete(&models.TaskDependency{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) FindDependencies(ctx context.Context, taskID uint) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	err := r.tasks(ctx).
+		Where("id IN (?)", r.db.Model(&models.TaskDependency{}).Select("depends_on_id").Where("task_id = ?", taskID)).
+		Order("id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) FindDependents(ctx context.Context, taskID uint) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	err := r.tasks(ctx).
+		Where("id IN (?)", r.db.Model(&models.TaskDependency{}).Select("task_id").Where("depends_on_id = ?", taskID)).
+		Order("id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) ListDependenciesAmong(ctx context.Context, ids []uint) ([]models.TaskDependency, error) {
+	var dependencies []models.TaskDependency
+	if len(ids) == 0 {
+		return dependencies, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("task_id IN ? AND depends_on_id IN ?", ids, ids).
+		Order("task_id, depends_on_id").
+		Find(&dependencies).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return dependencies, nil
+}
+
+func (r *gormTaskRepository) AddAssignee(ctx context.Context, assignee *models.TaskAssignee) error {
+	return translateError(r.db.WithContext(ctx).Omit("Task", "User").Create(assignee).Error)
+}
+
+func (r *gormTaskRepository) RemoveAssignee(ctx context.Context, taskID uint, userID string) error {
+	result := r.db.WithContext(ctx).Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskAssignee{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) ListAssignees(ctx context.Context, taskID uint) ([]models.TaskAssignee, error) {
+	var assignees []models.TaskAssignee
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at").Find(&assignees).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return assignees, nil
+}
+
+func (r *gormTaskRepository) FindAssignedTo(ctx context.Context, userID string) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	err := r.tasks(ctx).
+		Where("id IN (?)", r.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userID)).
+		Order("id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) AddWatcher(ctx context.Context, watcher *models.TaskWatcher) error {
+	return translateError(r.db.WithContext(ctx).Omit("Task", "User").Create(watcher).Error)
+}
+
+func (r *gormTaskRepository) RemoveWatcher(ctx context.Context, taskID uint, userID string) error {
+	result := r.db.WithContext(ctx).Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) ListWatchers(ctx context.Context, taskID uint) ([]models.TaskWatcher, error) {
+	var watchers []models.TaskWatcher
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at").Find(&watchers).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return watchers, nil
+}
+
+func (r *gormTaskRepository) FindWatchedBy(ctx context.Context, userID string) ([]models.Tasks, error) {
+	var tasks []models.Tasks
+	err := r.tasks(ctx).
+		Where("id IN (?)", r.db.Model(&models.TaskWatcher{}).Select("task_id").Where("user_id = ?", userID)).
+		Order("id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return tasks, nil
+}
+
+// ftsQuery turns free text into a FTS5 query matching every word of it.
+// Each word is quoted, so characters with a meaning in the FTS5 syntax are searched literally.
+func ftsQuery(query string) string {
+	terms := strings.Fields(query)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(terms, " ")
+}
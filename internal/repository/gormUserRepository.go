@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+)
+
+// gormUserRepository is the UserRepository backed by a GORM connection to PostgreSQL.
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository returns a UserRepository that stores users through the given GORM connection.
+func NewGormUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return translateError(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindAll(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Find(&users).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return users, nil
+}
+
+func (r *gormUserRepository) Update(ctx context.Context, user *models.User) error {
+	return translateError(r.db.WithContext(ctx).Save(user).Error)
+}
+
+func (r *gormUserRepository) Delete(ctx context.Context, id string) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var owned []string
+		if err := tx.Model(&models.Workspace{}).Where("owner_id = ?", id).Pluck("id", &owned).Error; err != nil {
+			return err
+		}
+		for _, workspaceID := range owned {
+			if err := deleteWorkspace(tx, workspaceID); err != nil {
+				return err
+			}
+		}
+
+		// The tasks and labels the user created in the other workspaces stay with the teams, handed over to their owners
+		taskOwner := tx.Model(&models.Workspace{}).Select("owner_id").Where("workspaces.id = tasks.workspace_id")
+		if err := tx.Unscoped().Model(&models.Tasks{}).Where("user_id = ? AND workspace_id IS NOT NULL", id).Update("user_id", taskOwner).Error; err != nil {
+			return err
+		}
+		labelOwner := tx.Model(&models.Workspace{}).Select("owner_id").Where("workspaces.id = labels.workspace_id")
+		if err := tx.Model(&models.Label{}).Where("user_id = ? AND workspace_id IS NOT NULL", id).Update("user_id", labelOwner).Error; err != nil {
+			return err
+		}
+
+		// What's left is personal, and goes with the user
+		labels := tx.Model(&models.Label{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Where("label_id IN (?)", labels).Delete(&models.TaskLabel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.Label{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("author_id = ?", id).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("uploader_id = ?", id).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&models.Tasks{}).Error; err != nil {
+			return err
+		}
+
+		// The preferences, calendar feed, memberships, assignments and the like are removed by their foreign keys
+		result := tx.Where("id = ?", id).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
+
+func (r *gormUserRepository) FindPreferences(ctx context.Context, userID string) (*models.Preferences, error) {
+	var preferences models.Preferences
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preferences).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &preferences, nil
+}
+
+func (r *gormUserRepository) SavePreferences(ctx context.Context, preferences *models.Preferences) error {
+	return translateError(r.db.WithContext(ctx).Omit("User").Save(preferences).Error)
+}
+
+func (r *gormUserRepository) FindCalendarFeed(ctx context.Context, userID string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&feed).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &feed, nil
+}
+
+func (r *gormUserRepository) FindCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&feed).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &feed, nil
+}
+
+func (r *gormUserRepository) SaveCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	return translateError(r.db.WithContext(ctx).Omit("User").Save(feed).Error)
+}
+
+func (r *gormUserRepository) DeleteCalendarFeed(ctx context.Context, userID string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.CalendarFeed{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormWorkspaceRepository is the WorkspaceRepository backed by a GORM connection to PostgreSQL or SQLite.
+type gormWorkspaceRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWorkspaceRepository returns a WorkspaceRepository that stores workspaces through the given GORM connection.
+func NewGormWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &gormWorkspaceRepository{db: db}
+}
+
+func (r *gormWorkspaceRepository) Create(ctx context.Context, workspace *models.Workspace) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(workspace).Error; err != nil {
+			return err
+		}
+		owner := models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: workspace.OwnerID, Role: models.RoleOwner}
+		if err := tx.Omit("User").Create(&owner).Error; err != nil {
+			return err
+		}
+		workspace.Members = []models.WorkspaceMember{owner}
+		return nil
+	}))
+}
+
+func (r *gormWorkspaceRepository) FindByID(ctx context.Context, id string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&workspace).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &workspace, nil
+}
+
+func (r *gormWorkspaceRepository) FindByMember(ctx context.Context, userID string) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	err := r.db.WithContext(ctx).
+		Where("id IN (?)", r.db.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)).
+		Order("created_at").
+		Find(&workspaces).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return workspaces, nil
+}
+
+func (r *gormWorkspaceRepository) Update(ctx context.Context, workspace *models.Workspace) error {
+	return translateError(r.db.WithContext(ctx).Omit("Members").Save(workspace).Error)
+}
+
+func (r *gormWorkspaceRepository) Delete(ctx context.Context, id string) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteWorkspace(tx, id)
+	}))
+}
+
+// deleteWorkspace removes the workspace with the given ID, along with everything that belongs to it,
+// within the transaction tx. It returns ErrNotFound when there's no such workspace.
+func deleteWorkspace(tx *gorm.DB, id string) error {
+	labels := tx.Model(&models.Label{}).Select("id").Where("workspace_id = ?", id)
+	if err := tx.Where("label_id IN (?)", labels).Delete(&models.TaskLabel{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.Label{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("workspace_id = ?", id).Delete(&models.Tasks{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.WorkspaceInvitation{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.Workflow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", id).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return err
+	}
+	result := tx.Where("id = ?", id).Delete(&models.Workspace{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormWorkspaceRepository) FindMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	if err := r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &member, nil
+}
+
+func (r *gormWorkspaceRepository) ListMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	var members []models.WorkspaceMember
+	if err := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return members, nil
+}
+
+func (r *gormWorkspaceRepository) SaveMember(ctx context.Context, member *models.WorkspaceMember) error {
+	return translateError(saveMember(r.db.WithContext(ctx), member))
+}
+
+func (r *gormWorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	result := r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.WorkspaceMember{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormWorkspaceRepository) CreateInvitation(ctx context.Context, invitation *models.WorkspaceInvitation) error {
+	return translateError(r.db.WithContext(ctx).Omit("Workspace").Create(invitation).Error)
+}
+
+func (r *gormWorkspaceRepository) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &invitation, nil
+}
+
+func (r *gormWorkspaceRepository) ListInvitations(ctx context.Context, workspaceID string) ([]models.WorkspaceInvitation, error) {
+	var invitations []models.WorkspaceInvitation
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND accepted_at IS NULL", workspaceID).
+		Order("id").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return invitations, nil
+}
+
+func (r *gormWorkspaceRepository) AcceptInvitation(ctx context.Context, invitation *models.WorkspaceInvitation, member *models.WorkspaceMember) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Only one request can flip accepted_at, a concurrent acceptance finds no row left to update
+		result := tx.Model(&models.WorkspaceInvitation{}).
+			Where("id = ? AND accepted_at IS NULL", invitation.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		invitation.AcceptedAt = &now
+
+		return saveMember(tx, member)
+	}))
+}
+
+func (r *gormWorkspaceRepository) DeleteInvitation(ctx context.Context, workspaceID string, id uint) error {
+	result := r.db.WithContext(ctx).Where("workspace_id = ? AND id = ?", workspaceID, id).Delete(&models.WorkspaceInvitation{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// saveMember inserts the membership, or updates its role when the user is already a member.
+func saveMember(tx *gorm.DB, member *models.WorkspaceMember) error {
+	return tx.Omit("User").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role"}),
+	}).Create(member).Error
+}
+
+func (r *gormWorkspaceRepository) FindWorkflow(ctx context.Context, workspaceID string) (*models.Workflow, error) {
+	var workflow models.Workflow
+	if err := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).First(&workflow).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &workflow, nil
+}
+
+func (r *gormWorkspaceRepository) SaveWorkflow(ctx context.Context, workflow *models.Workflow) error {
+	return translateError(r.db.WithContext(ctx).Omit("Workspace").Save(workflow).Error)
+}
+
+func (r *gormWorkspaceRepository) DeleteWorkflow(ctx context.Context, workspaceID string) error {
+	result := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Delete(&models.Workflow{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
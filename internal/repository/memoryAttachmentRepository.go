@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// memoryAttachmentRepository is an AttachmentRepository that keeps attachments in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryAttachmentRepository struct {
+	mu          sync.RWMutex
+	attachments map[uint]models.Attachment
+	lastID      uint
+}
+
+// NewMemoryAttachmentRepository returns an empty, thread-safe, in-memory AttachmentRepository.
+func NewMemoryAttachmentRepository() AttachmentRepository {
+	return &memoryAttachmentRepository{attachments: make(map[uint]models.Attachment)}
+}
+
+func (r *memoryAttachmentRepository) Create(_ context.Context, attachment *models.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(attachment)
+}
+
+func (r *memoryAttachmentRepository) CreateWithinQuota(_ context.Context, attachment *models.Attachment, quota int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage := attachment.Size
+	for _, existing := range r.attachments {
+		if existing.UploaderID == attachment.UploaderID {
+			usage += existing.Size
+		}
+	}
+	if usage > quota {
+		return ErrQuotaExceeded
+	}
+	return r.insert(attachment)
+}
+
+// insert stores a new attachment, with the repository locked.
+func (r *memoryAttachmentRepository) insert(attachment *models.Attachment) error {
+	for _, existing := range r.attachments {
+		if existing.StorageKey == attachment.StorageKey {
+			return ErrDuplicate
+		}
+	}
+
+	r.lastID++
+	attachment.ID = r.lastID
+	attachment.CreatedAt = time.Now()
+	r.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (r *memoryAttachmentRepository) FindByID(_ context.Context, id uint) (*models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachment, ok := r.attachments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &attachment, nil
+}
+
+func (r *memoryAttachmentRepository) FindByTask(_ context.Context, taskID uint) ([]models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachments := make([]models.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.TaskID == taskID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+	return attachments, nil
+}
+
+// FindByOwner returns the attachments the user uploaded, since the tasks and workspaces they're attached through
+// are kept by other repositories.
+func (r *memoryAttachmentRepository) FindByOwner(_ context.Context, userID string) ([]models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachments := make([]models.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.UploaderID == userID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+	return attachments, nil
+}
+
+// FindByWorkspace returns no attachment, since the tasks telling which workspace they're attached to
+// are kept by another repository.
+func (r *memoryAttachmentRepository) FindByWorkspace(_ context.Context, _ string) ([]models.Attachment, error) {
+	return make([]models.Attachment, 0), nil
+}
+
+func (r *memoryAttachmentRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.attachments[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.attachments, id)
+	return nil
+}
+
+func (r *memoryAttachmentRepository) UsageByUser(_ context.Context, userID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var usage int64
+	for _, attachment := range r.attachments {
+		if attachment.UploaderID == userID {
+			usage += attachment.Size
+		}
+	}
+	return usage, nil
+}
+
+func (r *memoryAttachmentRepository) FindByThumbnailStatus(_ context.Context, status models.ThumbnailStatus) ([]models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachments := make([]models.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.ThumbnailStatus == status {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+	return attachments, nil
+}
+
+func (r *memoryAttachmentRepository) SetThumbnailStatus(_ context.Context, id uint, status models.ThumbnailStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attachment, ok := r.attachments[id]
+	if !ok {
+		return ErrNotFound
+	}
+	attachment.ThumbnailStatus = status
+	r.attachments[id] = attachment
+	return nil
+}
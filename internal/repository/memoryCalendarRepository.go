@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// calendarSyncKey identifies a state of a collection in memoryCalendarRepository.
+type calendarSyncKey struct {
+	userID string
+	token  string
+}
+
+// memoryCalendarRepository is a CalendarRepository that keeps the CalDAV state of tasks in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryCalendarRepository struct {
+	mu      sync.RWMutex
+	objects map[uint]models.CalendarObject
+	syncs   map[calendarSyncKey]models.CalendarSync
+}
+
+// NewMemoryCalendarRepository returns an empty, thread-safe, in-memory CalendarRepository.
+func NewMemoryCalendarRepository() CalendarRepository {
+	return &memoryCalendarRepository{
+		objects: make(map[uint]models.CalendarObject),
+		syncs:   make(map[calendarSyncKey]models.CalendarSync),
+	}
+}
+
+func (r *memoryCalendarRepository) FindObjects(_ context.Context, taskIDs []uint) ([]models.CalendarObject, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	objects := []models.CalendarObject{}
+	for _, id := range taskIDs {
+		if object, ok := r.objects[id]; ok {
+			objects = append(objects, copyObject(object))
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].TaskID < objects[j].TaskID })
+	return objects, nil
+}
+
+func (r *memoryCalendarRepository) SaveObject(_ context.Context, object *models.CalendarObject) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	object.UpdatedAt = time.Now()
+	r.objects[object.TaskID] = copyObject(*object)
+	return nil
+}
+
+func (r *memoryCalendarRepository) FindSync(_ context.Context, userID, token string) (*models.CalendarSync, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.syncs[calendarSyncKey{userID, token}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	state.ETags = copyETags(state.ETags)
+	return &state, nil
+}
+
+func (r *memoryCalendarRepository) SaveSync(_ context.Context, state *models.CalendarSync, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.CreatedAt = time.Now()
+	saved := *state
+	saved.ETags = copyETags(state.ETags)
+	r.syncs[calendarSyncKey{state.UserID, state.Token}] = saved
+	for key, existing := range r.syncs {
+		if key.userID == state.UserID && existing.CreatedAt.Before(before) {
+			delete(r.syncs, key)
+		}
+	}
+	return nil
+}
+
+// copyObject returns a copy of the object that shares no slices with it.
+func copyObject(object models.CalendarObject) models.CalendarObject {
+	object.Properties = append([]string(nil), object.Properties...)
+	object.Components = append([]string(nil), object.Components...)
+	return object
+}
+
+// copyETags returns a copy of the ETags of a state.
+func copyETags(etags map[string]string) map[string]string {
+	copied := make(map[string]string, len(etags))
+	for name, etag := range etags {
+		copied[name] = etag
+	}
+	return copied
+}
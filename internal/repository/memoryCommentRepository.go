@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+)
+
+// memoryCommentRepository is a CommentRepository that keeps comments in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryCommentRepository struct {
+	mu             sync.RWMutex
+	comments       map[uint]models.Comment
+	revisions      map[uint][]models.CommentRevision
+	lastID         uint
+	lastRevisionID uint
+}
+
+// NewMemoryCommentRepository returns an empty, thread-safe, in-memory CommentRepository.
+func NewMemoryCommentRepository() CommentRepository {
+	return &memoryCommentRepository{
+		comments:  make(map[uint]models.Comment),
+		revisions: make(map[uint][]models.CommentRevision),
+	}
+}
+
+func (r *memoryCommentRepository) Create(_ context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.lastID++
+	comment.ID = r.lastID
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	stampMentions(comment, now)
+
+	r.comments[comment.ID] = copyComment(*comment)
+	return nil
+}
+
+func (r *memoryCommentRepository) FindByID(_ context.Context, id uint) (*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.comments[id]
+	if !ok || comment.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	comment = copyComment(comment)
+	return &comment, nil
+}
+
+func (r *memoryCommentRepository) FindByTask(_ context.Context, taskID uint) ([]models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := make([]models.Comment, 0)
+	for _, comment := range r.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, copyComment(comment))
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	return comments, nil
+}
+
+func (r *memoryCommentRepository) Update(_ context.Context, comment *models.Comment, revision *models.CommentRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.comments[comment.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	comment.UpdatedAt = now
+	stampMentions(comment, now)
+	r.comments[comment.ID] = copyComment(*comment)
+
+	r.lastRevisionID++
+	revision.ID = r.lastRevisionID
+	revision.CommentID = comment.ID
+	revision.CreatedAt = now
+	r.revisions[comment.ID] = append(r.revisions[comment.ID], *revision)
+	return nil
+}
+
+func (r *memoryCommentRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[id]
+	if !ok || comment.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	comment.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.comments[id] = comment
+	return nil
+}
+
+func (r *memoryCommentRepository) ListRevisions(_ context.Context, commentID uint) ([]models.CommentRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append(make([]models.CommentRevision, 0), r.revisions[commentID]...), nil
+}
+
+// stampMentions points the mentions of the comment to it and fills their creation time.
+func stampMentions(comment *models.Comment, now time.Time) {
+	for i := range comment.Mentions {
+		comment.Mentions[i].CommentID = comment.ID
+		comment.Mentions[i].CreatedAt = now
+	}
+}
+
+// copyComment returns a copy of the comment that doesn't share its mentions, so callers can't change the stored ones.
+func copyComment(comment models.Comment) models.Comment {
+	comment.Mentions = append(make([]models.CommentMention, 0, len(comment.Mentions)), comment.Mentions...)
+	comment.Replies = nil
+	return comment
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// importedKey identifies an imported row in memoryImportRepository.
+type importedKey struct {
+	userID     string
+	source     string
+	externalID string
+}
+
+// memoryImportRepository is an ImportRepository that keeps import jobs in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryImportRepository struct {
+	mu       sync.RWMutex
+	jobs     map[uint]models.ImportJob
+	imported map[importedKey]models.ImportedTask
+	lastID   uint
+}
+
+// NewMemoryImportRepository returns an empty, thread-safe, in-memory ImportRepository.
+func NewMemoryImportRepository() ImportRepository {
+	return &memoryImportRepository{
+		jobs:     make(map[uint]models.ImportJob),
+		imported: make(map[importedKey]models.ImportedTask),
+	}
+}
+
+func (r *memoryImportRepository) Create(_ context.Context, job *models.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	job.ID = r.lastID
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	r.jobs[job.ID] = copyJob(*job)
+	return nil
+}
+
+func (r *memoryImportRepository) FindByID(_ context.Context, id uint) (*models.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job = copyJob(job)
+	return &job, nil
+}
+
+func (r *memoryImportRepository) FindByUser(_ context.Context, userID string) ([]models.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]models.ImportJob, 0)
+	for _, job := range r.jobs {
+		if job.UserID == userID {
+			job = copyJob(job)
+			job.Data = nil
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID > jobs[j].ID })
+	return jobs, nil
+}
+
+func (r *memoryImportRepository) FindByStatus(_ context.Context, status models.ImportStatus) ([]models.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := make([]models.ImportJob, 0)
+	for _, job := range r.jobs {
+		if job.Status == status {
+			jobs = append(jobs, copyJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+	return jobs, nil
+}
+
+func (r *memoryImportRepository) Update(_ context.Context, job *models.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.ID]; !ok {
+		return ErrNotFound
+	}
+	job.UpdatedAt = time.Now()
+	r.jobs[job.ID] = copyJob(*job)
+	return nil
+}
+
+func (r *memoryImportRepository) FindImported(_ context.Context, userID, source string, externalIDs []string) ([]models.ImportedTask, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	imported := []models.ImportedTask{}
+	for _, id := range externalIDs {
+		if task, ok := r.imported[importedKey{userID, source, id}]; ok {
+			imported = append(imported, task)
+		}
+	}
+	sort.Slice(imported, func(i, j int) bool { return imported[i].ExternalID < imported[j].ExternalID })
+	return imported, nil
+}
+
+func (r *memoryImportRepository) SaveImported(_ context.Context, imported *models.ImportedTask) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := importedKey{imported.UserID, imported.Source, imported.ExternalID}
+	if existing, ok := r.imported[key]; ok {
+		imported.CreatedAt = existing.CreatedAt
+	} else {
+		imported.CreatedAt = time.Now()
+	}
+	r.imported[key] = *imported
+	return nil
+}
+
+// copyJob returns a copy of the job that shares no slices nor maps with it.
+func copyJob(job models.ImportJob) models.ImportJob {
+	job.Data = append([]byte(nil), job.Data...)
+	job.Rows = append([]models.ImportRow(nil), job.Rows...)
+	if job.Mapping != nil {
+		mapping := make(map[string]string, len(job.Mapping))
+		for field, column := range job.Mapping {
+			mapping[field] = column
+		}
+		job.Mapping = mapping
+	}
+	return job
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// taskLabelKey identifies a tagging in memoryLabelRepository.
+type taskLabelKey struct {
+	taskID  uint
+	labelID uint
+}
+
+// memoryLabelRepository is a LabelRepository that keeps labels in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryLabelRepository struct {
+	mu     sync.RWMutex
+	labels map[uint]models.Label
+	tags   map[taskLabelKey]models.TaskLabel
+	lastID uint
+}
+
+// NewMemoryLabelRepository returns an empty, thread-safe, in-memory LabelRepository.
+func NewMemoryLabelRepository() LabelRepository {
+	return &memoryLabelRepository{
+		labels: make(map[uint]models.Label),
+		tags:   make(map[taskLabelKey]models.TaskLabel),
+	}
+}
+
+func (r *memoryLabelRepository) Create(_ context.Context, label *models.Label) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.lastID++
+	label.ID = r.lastID
+	label.CreatedAt = now
+	label.UpdatedAt = now
+
+	r.labels[label.ID] = *label
+	return nil
+}
+
+func (r *memoryLabelRepository) FindByID(_ context.Context, id uint) (*models.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	label, ok := r.labels[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &label, nil
+}
+
+func (r *memoryLabelRepository) FindByUser(_ context.Context, userID string) ([]models.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(label models.Label) bool {
+		return label.UserID == userID && label.WorkspaceID == nil
+	}), nil
+}
+
+func (r *memoryLabelRepository) FindByWorkspace(_ context.Context, workspaceID string) ([]models.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(label models.Label) bool {
+		return label.WorkspaceID != nil && *label.WorkspaceID == workspaceID
+	}), nil
+}
+
+func (r *memoryLabelRepository) Update(_ context.Context, label *models.Label) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.labels[label.ID]; !ok {
+		return ErrNotFound
+	}
+	label.UpdatedAt = time.Now()
+	r.labels[label.ID] = *label
+	return nil
+}
+
+func (r *memoryLabelRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.labels[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.labels, id)
+	for key := range r.tags {
+		if key.labelID == id {
+			delete(r.tags, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryLabelRepository) Tag(_ context.Context, labelID uint, taskIDs []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, taskID := range taskIDs {
+		key := taskLabelKey{taskID, labelID}
+		if _, ok := r.tags[key]; !ok {
+			r.tags[key] = models.TaskLabel{TaskID: taskID, LabelID: labelID, CreatedAt: now}
+		}
+	}
+	return nil
+}
+
+func (r *memoryLabelRepository) Untag(_ context.Context, labelID uint, taskIDs []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, taskID := range taskIDs {
+		delete(r.tags, taskLabelKey{taskID, labelID})
+	}
+	return nil
+}
+
+func (r *memoryLabelRepository) FindByTask(_ context.Context, taskID uint) ([]models.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(label models.Label) bool {
+		_, ok := r.tags[taskLabelKey{taskID, label.ID}]
+		return ok
+	}), nil
+}
+
+func (r *memoryLabelRepository) FindTaggedTaskIDs(_ context.Context, labelIDs []uint, all bool) ([]uint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := map[uint]bool{}
+	for _, id := range labelIDs {
+		wanted[id] = true
+	}
+	matches := map[uint]int{}
+	for key := range r.tags {
+		if wanted[key.labelID] {
+			matches[key.taskID]++
+		}
+	}
+
+	ids := make([]uint, 0, len(matches))
+	for taskID, count := range matches {
+		if !all || count == len(wanted) {
+			ids = append(ids, taskID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// filter returns the labels matching the predicate, sorted by name and then by ID. The caller must hold the lock.
+func (r *memoryLabelRepository) filter(match func(models.Label) bool) []models.Label {
+	labels := make([]models.Label, 0)
+	for _, label := range r.labels {
+		if match(label) {
+			labels = append(labels, label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Name != labels[j].Name {
+			return labels[i].Name < labels[j].Name
+		}
+		return labels[i].ID < labels[j].ID
+	})
+	return labels
+}
@@ -0,0 +1,593 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// memoryTaskRepository is a TaskRepository that keeps tasks in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryTaskRepository struct {
+	mu              sync.RWMutex
+	tasks           map[uint]models.Tasks
+	assignees       map[taskUserKey]models.TaskAssignee
+	watchers        map[taskUserKey]models.TaskWatcher
+	checklist       map[uint]models.ChecklistItem
+	dependencies    map[taskDependencyKey]models.TaskDependency
+	lastID          uint
+	lastChecklistID uint
+}
+
+// taskUserKey identifies an assignment or a watch in memoryTaskRepository.
+type taskUserKey struct {
+	taskID uint
+	userID string
+}
+
+// taskDependencyKey identifies a dependency in memoryTaskRepository.
+type taskDependencyKey struct {
+	taskID      uint
+	dependsOnID uint
+}
+
+// NewMemoryTaskRepository returns an empty, thread-safe, in-memory TaskRepository.
+func NewMemoryTaskRepository() TaskRepository {
+	return &memoryTaskRepository{
+		tasks:        make(map[uint]models.Tasks),
+		assignees:    make(map[taskUserKey]models.TaskAssignee),
+		watchers:     make(map[taskUserKey]models.TaskWatcher),
+		checklist:    make(map[uint]models.ChecklistItem),
+		dependencies: make(map[taskDependencyKey]models.TaskDependency),
+	}
+}
+
+func (r *memoryTaskRepository) Create(_ context.Context, task *models.Tasks) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insert(task)
+	return nil
+}
+
+// insert stores a new task, with the repository locked.
+func (r *memoryTaskRepository) insert(task *models.Tasks) {
+	now := time.Now()
+	r.lastID++
+	task.ID = r.lastID
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	if task.Status == "" {
+		task.Status = models.TaskPending
+	}
+
+	r.tasks[task.ID] = *task
+}
+
+func (r *memoryTaskRepository) FindByID(_ context.Context, id uint) (*models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	task.Blocked = r.blocked(id)
+	return &task, nil
+}
+
+func (r *memoryTaskRepository) FindByUser(_ context.Context, userID string) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		return task.UserID == userID && task.WorkspaceID == nil
+	}), nil
+}
+
+func (r *memoryTaskRepository) FindByWorkspace(_ context.Context, workspaceID string) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		return task.WorkspaceID != nil && *task.WorkspaceID == workspaceID
+	}), nil
+}
+
+func (r *memoryTaskRepository) FindColumn(_ context.Context, userID string, workspaceID *string, status string) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := r.filter(func(task models.Tasks) bool {
+		if task.Status != status {
+			return false
+		}
+		if workspaceID != nil {
+			return task.WorkspaceID != nil && *task.WorkspaceID == *workspaceID
+		}
+		return task.UserID == userID && task.WorkspaceID == nil
+	})
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Rank < tasks[j].Rank })
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) Search(ctx context.Context, userID, query string) ([]models.Tasks, error) {
+	tasks, err := r.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	matches := make([]models.Tasks, 0)
+	for _, task := range tasks {
+		text := strings.ToLower(task.Title + " " + deref(task.Description) + " " + deref(task.Notes))
+		found := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, task)
+		}
+	}
+	return matches, nil
+}
+
+func (r *memoryTaskRepository) Update(_ context.Context, task *models.Tasks) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[task.ID]; !ok {
+		return ErrNotFound
+	}
+
+	task.UpdatedAt = time.Now()
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryTaskRepository) CreateInColumn(_ context.Context, task *models.Tasks, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.columnFull(task, limit) {
+		return ErrColumnFull
+	}
+	r.insert(task)
+	return nil
+}
+
+func (r *memoryTaskRepository) UpdateInColumn(_ context.Context, task *models.Tasks, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[task.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.columnFull(task, limit) {
+		return ErrColumnFull
+	}
+	task.UpdatedAt = time.Now()
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+// columnFull reports whether the board column of the task's status holds limit tasks other than it,
+// with the repository locked.
+func (r *memoryTaskRepository) columnFull(task *models.Tasks, limit int) bool {
+	if limit <= 0 {
+		return false
+	}
+	count := 0
+	for _, other := range r.tasks {
+		if other.ID == task.ID || other.Status != task.Status {
+			continue
+		}
+		if task.WorkspaceID != nil {
+			if other.WorkspaceID != nil && *other.WorkspaceID == *task.WorkspaceID {
+				count++
+			}
+		} else if other.UserID == task.UserID && other.WorkspaceID == nil {
+			count++
+		}
+	}
+	return count >= limit
+}
+
+func (r *memoryTaskRepository) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.tasks, id)
+	for key := range r.assignees {
+		if key.taskID == id {
+			delete(r.assignees, key)
+		}
+	}
+	for key := range r.watchers {
+		if key.taskID == id {
+			delete(r.watchers, key)
+		}
+	}
+	for itemID, item := range r.checklist {
+		if item.TaskID == id {
+			delete(r.checklist, itemID)
+		}
+	}
+	for key := range r.dependencies {
+		if key.taskID == id || key.dependsOnID == id {
+			delete(r.dependencies, key)
+		}
+	}
+	for childID, child := range r.tasks {
+		if child.ParentID != nil && *child.ParentID == id {
+			child.ParentID = nil
+			r.tasks[childID] = child
+		}
+	}
+	return nil
+}
+
+// FindDeleted returns no task, since Delete removes the tasks for good rather than moving them to the trash.
+func (r *memoryTaskRepository) FindDeleted(context.Context, time.Time) ([]models.Tasks, error) {
+	return []models.Tasks{}, nil
+}
+
+// Purge always returns ErrNotFound, since the trash is always empty.
+func (r *memoryTaskRepository) Purge(context.Context, uint) error {
+	return ErrNotFound
+}
+
+func (r *memoryTaskRepository) FindChildren(_ context.Context, parentID uint) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		return task.ParentID != nil && *task.ParentID == parentID
+	}), nil
+}
+
+func (r *memoryTaskRepository) CompleteDescendants(_ context.Context, id uint, status string, at time.Time, ranks map[uint]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subtree := map[uint]bool{id: true}
+	queue := []uint{id}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+		for childID, child := range r.tasks {
+			if child.ParentID == nil || *child.ParentID != parentID || subtree[childID] {
+				continue
+			}
+			subtree[childID] = true
+			queue = append(queue, childID)
+			if key, ok := ranks[childID]; ok && child.CompletedAt == nil {
+				child.Status = status
+				child.CompletedAt = &at
+				child.Rank = key
+				child.UpdatedAt = time.Now()
+				r.tasks[childID] = child
+			}
+		}
+	}
+
+	for itemID, item := range r.checklist {
+		if subtree[item.TaskID] && !item.Done {
+			item.Done = true
+			item.UpdatedAt = time.Now()
+			r.checklist[itemID] = item
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) AddChecklistItem(_ context.Context, item *models.ChecklistItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[item.TaskID]; !ok {
+		return ErrNotFound
+	}
+	item.Position = 0
+	for _, other := range r.checklist {
+		if other.TaskID == item.TaskID && other.Position >= item.Position {
+			item.Position = other.Position + 1
+		}
+	}
+
+	now := time.Now()
+	r.lastChecklistID++
+	item.ID = r.lastChecklistID
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	r.checklist[item.ID] = *item
+	return nil
+}
+
+func (r *memoryTaskRepository) FindChecklistItem(_ context.Context, id uint) (*models.ChecklistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	item, ok := r.checklist[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &item, nil
+}
+
+func (r *memoryTaskRepository) ListChecklistItems(_ context.Context, taskID uint) ([]models.ChecklistItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]models.ChecklistItem, 0)
+	for _, item := range r.checklist {
+		if item.TaskID == taskID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Position != items[j].Position {
+			return items[i].Position < items[j].Position
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+func (r *memoryTaskRepository) UpdateChecklistItem(_ context.Context, item *models.ChecklistItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.checklist[item.ID]; !ok {
+		return ErrNotFound
+	}
+	item.UpdatedAt = time.Now()
+	r.checklist[item.ID] = *item
+	return nil
+}
+
+func (r *memoryTaskRepository) DeleteChecklistItem(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.checklist[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.checklist, id)
+	return nil
+}
+
+func (r *memoryTaskRepository) ReorderChecklist(_ context.Context, taskID uint, ids []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for position, id := range ids {
+		if item, ok := r.checklist[id]; ok && item.TaskID == taskID {
+			item.Position = position
+			r.checklist[id] = item
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) AddDependency(_ context.Context, dependency *models.TaskDependency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[dependency.TaskID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.tasks[dependency.DependsOnID]; !ok {
+		return ErrNotFound
+	}
+	key := taskDependencyKey{dependency.TaskID, dependency.DependsOnID}
+	if _, ok := r.dependencies[key]; ok {
+		return ErrDuplicate
+	}
+	dependency.CreatedAt = time.Now()
+	r.dependencies[key] = *dependency
+	return nil
+}
+
+func (r *memoryTaskRepository) RemoveDependency(_ context.Context, taskID, dependsOnID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := taskDependencyKey{taskID, dependsOnID}
+	if _, ok := r.dependencies[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.dependencies, key)
+	return nil
+}
+
+func (r *memoryTaskRepository) FindDependencies(_ context.Context, taskID uint) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		_, ok := r.dependencies[taskDependencyKey{taskID, task.ID}]
+		return ok
+	}), nil
+}
+
+func (r *memoryTaskRepository) FindDependents(_ context.Context, taskID uint) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		_, ok := r.dependencies[taskDependencyKey{task.ID, taskID}]
+		return ok
+	}), nil
+}
+
+func (r *memoryTaskRepository) ListDependenciesAmong(_ context.Context, ids []uint) ([]models.TaskDependency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	among := map[uint]bool{}
+	for _, id := range ids {
+		among[id] = true
+	}
+	dependencies := make([]models.TaskDependency, 0)
+	for key, dependency := range r.dependencies {
+		if among[key.taskID] && among[key.dependsOnID] {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].TaskID != dependencies[j].TaskID {
+			return dependencies[i].TaskID < dependencies[j].TaskID
+		}
+		return dependencies[i].DependsOnID < dependencies[j].DependsOnID
+	})
+	return dependencies, nil
+}
+
+func (r *memoryTaskRepository) AddAssignee(_ context.Context, assignee *models.TaskAssignee) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[assignee.TaskID]; !ok {
+		return ErrNotFound
+	}
+	key := taskUserKey{assignee.TaskID, assignee.UserID}
+	if _, ok := r.assignees[key]; ok {
+		return ErrDuplicate
+	}
+	assignee.CreatedAt = time.Now()
+	r.assignees[key] = *assignee
+	return nil
+}
+
+func (r *memoryTaskRepository) RemoveAssignee(_ context.Context, taskID uint, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := taskUserKey{taskID, userID}
+	if _, ok := r.assignees[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.assignees, key)
+	return nil
+}
+
+func (r *memoryTaskRepository) ListAssignees(_ context.Context, taskID uint) ([]models.TaskAssignee, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	assignees := make([]models.TaskAssignee, 0)
+	for key, assignee := range r.assignees {
+		if key.taskID == taskID {
+			assignees = append(assignees, assignee)
+		}
+	}
+	sort.Slice(assignees, func(i, j int) bool { return assignees[i].CreatedAt.Before(assignees[j].CreatedAt) })
+	return assignees, nil
+}
+
+func (r *memoryTaskRepository) FindAssignedTo(_ context.Context, userID string) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		_, ok := r.assignees[taskUserKey{task.ID, userID}]
+		return ok
+	}), nil
+}
+
+func (r *memoryTaskRepository) AddWatcher(_ context.Context, watcher *models.TaskWatcher) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[watcher.TaskID]; !ok {
+		return ErrNotFound
+	}
+	key := taskUserKey{watcher.TaskID, watcher.UserID}
+	if _, ok := r.watchers[key]; ok {
+		return ErrDuplicate
+	}
+	watcher.CreatedAt = time.Now()
+	r.watchers[key] = *watcher
+	return nil
+}
+
+func (r *memoryTaskRepository) RemoveWatcher(_ context.Context, taskID uint, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := taskUserKey{taskID, userID}
+	if _, ok := r.watchers[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.watchers, key)
+	return nil
+}
+
+func (r *memoryTaskRepository) ListWatchers(_ context.Context, taskID uint) ([]models.TaskWatcher, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	watchers := make([]models.TaskWatcher, 0)
+	for key, watcher := range r.watchers {
+		if key.taskID == taskID {
+			watchers = append(watchers, watcher)
+		}
+	}
+	sort.Slice(watchers, func(i, j int) bool { return watchers[i].CreatedAt.Before(watchers[j].CreatedAt) })
+	return watchers, nil
+}
+
+func (r *memoryTaskRepository) FindWatchedBy(_ context.Context, userID string) ([]models.Tasks, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(task models.Tasks) bool {
+		_, ok := r.watchers[taskUserKey{task.ID, userID}]
+		return ok
+	}), nil
+}
+
+// deref returns the string pointed to by s, or an empty string when s is nil.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// filter returns the tasks matching the predicate, sorted by ID. The caller must hold the lock.
+func (r *memoryTaskRepository) filter(match func(models.Tasks) bool) []models.Tasks {
+	tasks := make([]models.Tasks, 0)
+	for _, task := range r.tasks {
+		if match(task) {
+			task.Blocked = r.blocked(task.ID)
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks
+}
+
+// blocked tells whether any task the task depends on isn't completed yet. The caller must hold the lock.
+func (r *memoryTaskRepository) blocked(id uint) bool {
+	for key := range r.dependencies {
+		if key.taskID != id {
+			continue
+		}
+		if dependsOn, ok := r.tasks[key.dependsOnID]; ok && dependsOn.CompletedAt == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// memoryUserRepository is a UserRepository that keeps users in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryUserRepository struct {
+	mu          sync.RWMutex
+	users       map[string]models.User
+	preferences map[string]models.Preferences
+	feeds       map[string]models.CalendarFeed
+	lastMember  models.Serial
+}
+
+// NewMemoryUserRepository returns an empty, thread-safe, in-memory UserRepository.
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[string]models.User), preferences: make(map[string]models.Preferences),
+		feeds: make(map[string]models.CalendarFeed)}
+}
+
+func (r *memoryUserRepository) Create(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+
+	// Run the same hook GORM runs, so the ID is generated the same way
+	if err := user.BeforeCreate(nil); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	r.lastMember++
+	user.MemberNumber = r.lastMember
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) FindByID(_ context.Context, id string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(_ context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) FindAll(_ context.Context) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].MemberNumber < users[j].MemberNumber })
+	return users, nil
+}
+
+func (r *memoryUserRepository) Update(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	for id, existing := range r.users {
+		if id != user.ID && existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.users, id)
+	delete(r.preferences, id)
+	delete(r.feeds, id)
+	return nil
+}
+
+func (r *memoryUserRepository) FindPreferences(_ context.Context, userID string) (*models.Preferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preferences, ok := r.preferences[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	preferences.NotificationChannels = append([]string(nil), preferences.NotificationChannels...)
+	return &preferences, nil
+}
+
+func (r *memoryUserRepository) SavePreferences(_ context.Context, preferences *models.Preferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[preferences.UserID]; !ok {
+		return ErrNotFound
+	}
+	preferences.UpdatedAt = time.Now()
+	saved := *preferences
+	saved.NotificationChannels = append([]string(nil), preferences.NotificationChannels...)
+	r.preferences[preferences.UserID] = saved
+	return nil
+}
+
+func (r *memoryUserRepository) FindCalendarFeed(_ context.Context, userID string) (*models.CalendarFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	feed, ok := r.feeds[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &feed, nil
+}
+
+func (r *memoryUserRepository) FindCalendarFeedByTokenHash(_ context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, feed := range r.feeds {
+		if feed.TokenHash == tokenHash {
+			return &feed, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) SaveCalendarFeed(_ context.Context, feed *models.CalendarFeed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[feed.UserID]; !ok {
+		return ErrNotFound
+	}
+	for _, existing := range r.feeds {
+		if existing.TokenHash == feed.TokenHash && existing.UserID != feed.UserID {
+			return ErrDuplicate
+		}
+	}
+	if feed.CreatedAt.IsZero() {
+		feed.CreatedAt = time.Now()
+	}
+	r.feeds[feed.UserID] = *feed
+	return nil
+}
+
+func (r *memoryUserRepository) DeleteCalendarFeed(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.feeds[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.feeds, userID)
+	return nil
+}
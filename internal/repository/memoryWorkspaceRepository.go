@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// memberKey identifies a membership in memoryWorkspaceRepository.
+type memberKey struct {
+	workspaceID string
+	userID      string
+}
+
+// memoryWorkspaceRepository is a WorkspaceRepository that keeps workspaces in memory.
+// It is meant for tests and local experiments, nothing survives a restart.
+type memoryWorkspaceRepository struct {
+	mu               sync.RWMutex
+	tasks            TaskRepository
+	workspaces       map[string]models.Workspace
+	members          map[memberKey]models.WorkspaceMember
+	invitations      map[uint]models.WorkspaceInvitation
+	workflows        map[string]models.Workflow
+	lastInvitationID uint
+}
+
+// NewMemoryWorkspaceRepository returns an empty, thread-safe, in-memory WorkspaceRepository.
+// Deleting a workspace also deletes its tasks from the given TaskRepository.
+func NewMemoryWorkspaceRepository(tasks TaskRepository) WorkspaceRepository {
+	return &memoryWorkspaceRepository{
+		tasks:       tasks,
+		workspaces:  make(map[string]models.Workspace),
+		members:     make(map[memberKey]models.WorkspaceMember),
+		invitations: make(map[uint]models.WorkspaceInvitation),
+		workflows:   make(map[string]models.Workflow),
+	}
+}
+
+func (r *memoryWorkspaceRepository) Create(_ context.Context, workspace *models.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := workspace.BeforeCreate(nil); err != nil {
+		return err
+	}
+	now := time.Now()
+	workspace.CreatedAt = now
+	workspace.UpdatedAt = now
+
+	owner := models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: workspace.OwnerID, Role: models.RoleOwner, CreatedAt: now}
+	r.members[memberKey{workspace.ID, workspace.OwnerID}] = owner
+	workspace.Members = []models.WorkspaceMember{owner}
+
+	stored := *workspace
+	stored.Members = nil
+	r.workspaces[workspace.ID] = stored
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) FindByID(_ context.Context, id string) (*models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &workspace, nil
+}
+
+func (r *memoryWorkspaceRepository) FindByMember(_ context.Context, userID string) ([]models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspaces := make([]models.Workspace, 0)
+	for key := range r.members {
+		if key.userID == userID {
+			workspaces = append(workspaces, r.workspaces[key.workspaceID])
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].CreatedAt.Before(workspaces[j].CreatedAt) })
+	return workspaces, nil
+}
+
+func (r *memoryWorkspaceRepository) Update(_ context.Context, workspace *models.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workspaces[workspace.ID]; !ok {
+		return ErrNotFound
+	}
+	workspace.UpdatedAt = time.Now()
+
+	stored := *workspace
+	stored.Members = nil
+	r.workspaces[workspace.ID] = stored
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workspaces[id]; !ok {
+		return ErrNotFound
+	}
+
+	tasks, err := r.tasks.FindByWorkspace(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := r.tasks.Delete(ctx, task.ID); err != nil {
+			return err
+		}
+	}
+
+	for key := range r.members {
+		if key.workspaceID == id {
+			delete(r.members, key)
+		}
+	}
+	for invitationID, invitation := range r.invitations {
+		if invitation.WorkspaceID == id {
+			delete(r.invitations, invitationID)
+		}
+	}
+	delete(r.workflows, id)
+	delete(r.workspaces, id)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) FindMember(_ context.Context, workspaceID, userID string) (*models.WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[memberKey{workspaceID, userID}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+func (r *memoryWorkspaceRepository) ListMembers(_ context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]models.WorkspaceMember, 0)
+	for key, member := range r.members {
+		if key.workspaceID == workspaceID {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].CreatedAt.Before(members[j].CreatedAt) })
+	return members, nil
+}
+
+func (r *memoryWorkspaceRepository) SaveMember(_ context.Context, member *models.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.saveMember(member)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) RemoveMember(_ context.Context, workspaceID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{workspaceID, userID}
+	if _, ok := r.members[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) CreateInvitation(_ context.Context, invitation *models.WorkspaceInvitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.invitations {
+		if existing.TokenHash == invitation.TokenHash {
+			return ErrDuplicate
+		}
+	}
+
+	r.lastInvitationID++
+	invitation.ID = r.lastInvitationID
+	invitation.CreatedAt = time.Now()
+	r.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) FindInvitationByTokenHash(_ context.Context, tokenHash string) (*models.WorkspaceInvitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryWorkspaceRepository) ListInvitations(_ context.Context, workspaceID string) ([]models.WorkspaceInvitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invitations := make([]models.WorkspaceInvitation, 0)
+	for _, invitation := range r.invitations {
+		if invitation.WorkspaceID == workspaceID && invitation.AcceptedAt == nil {
+			invitations = append(invitations, invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool { return invitations[i].ID < invitations[j].ID })
+	return invitations, nil
+}
+
+func (r *memoryWorkspaceRepository) AcceptInvitation(_ context.Context, invitation *models.WorkspaceInvitation, member *models.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.invitations[invitation.ID]
+	if !ok || stored.AcceptedAt != nil {
+		return ErrNotFound
+	}
+
+	now := time.Now()
+	stored.AcceptedAt = &now
+	r.invitations[invitation.ID] = stored
+	invitation.AcceptedAt = &now
+
+	r.saveMember(member)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) DeleteInvitation(_ context.Context, workspaceID string, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || invitation.WorkspaceID != workspaceID {
+		return ErrNotFound
+	}
+	delete(r.invitations, id)
+	return nil
+}
+
+// saveMember inserts or updates the membership. The caller must hold the lock.
+func (r *memoryWorkspaceRepository) FindWorkflow(_ context.Context, workspaceID string) (*models.Workflow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workflow, ok := r.workflows[workspaceID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &workflow, nil
+}
+
+func (r *memoryWorkspaceRepository) SaveWorkflow(_ context.Context, workflow *models.Workflow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workspaces[workflow.WorkspaceID]; !ok {
+		return ErrNotFound
+	}
+	workflow.UpdatedAt = time.Now()
+	r.workflows[workflow.WorkspaceID] = *workflow
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) DeleteWorkflow(_ context.Context, workspaceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workflows[workspaceID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.workflows, workspaceID)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) saveMember(member *models.WorkspaceMember) {
+	key := memberKey{member.WorkspaceID, member.UserID}
+	if existing, ok := r.members[key]; ok {
+		member.CreatedAt = existing.CreatedAt
+	} else {
+		member.CreatedAt = time.Now()
+	}
+	r.members[key] = *member
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when the requested record doesn't exist.
+	ErrNotFound = errors.New("record not found")
+
+	// ErrDuplicate is returned when a record violates a unique constraint, such as a repeated e-mail.
+	ErrDuplicate = errors.New("duplicated record")
+
+	// ErrColumnFull is returned when a task would go over the WIP limit of the board column it enters.
+	ErrColumnFull = errors.New("board column full")
+
+	// ErrQuotaExceeded is returned when an attachment would take its uploader over their storage quota.
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+)
+
+// UserRepository abstracts the storage of models.User records.
+// Implementations must be safe for concurrent use.
+type UserRepository interface {
+	// Create stores a new user, filling its ID and timestamps.
+	Create(ctx context.Context, user *models.User) error
+	// FindByID returns the user with the given ID or ErrNotFound.
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	// FindByEmail returns the user with the given e-mail or ErrNotFound.
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindAll returns every stored user.
+	FindAll(ctx context.Context) ([]models.User, error)
+	// Update saves every field of an existing user.
+	Update(ctx context.Context, user *models.User) error
+	// Delete removes the user with the given ID, along with the workspaces they own and their personal tasks,
+	// labels, comments, attachments, preferences and calendar feed, atomically.
+	// The tasks and labels they created in the workspaces of others are handed over to the owners of the workspaces.
+	Delete(ctx context.Context, id string) error
+
+	// FindPreferences returns the preferences the user saved, or ErrNotFound when they use the default ones.
+	FindPreferences(ctx context.Context, userID string) (*models.Preferences, error)
+	// SavePreferences saves the preferences of their user, replacing the previous ones.
+	SavePreferences(ctx context.Context, preferences *models.Preferences) error
+
+	// FindCalendarFeed returns the calendar feed of the user or ErrNotFound.
+	FindCalendarFeed(ctx context.Context, userID string) (*models.CalendarFeed, error)
+	// FindCalendarFeedByTokenHash returns the calendar feed whose token has the given hash or ErrNotFound.
+	FindCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	// SaveCalendarFeed saves the calendar feed of its user, replacing the previous one.
+	SaveCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error
+	// DeleteCalendarFeed removes the calendar feed of the user, or returns ErrNotFound when they have none.
+	DeleteCalendarFeed(ctx context.Context, userID string) error
+}
+
+// CalendarRepository abstracts the storage of what CalDAV needs beyond the tasks themselves:
+// models.CalendarObject and models.CalendarSync records.
+// Implementations must be safe for concurrent use.
+type CalendarRepository interface {
+	// FindObjects returns the calendar objects of the given tasks, leaving out the tasks without one.
+	FindObjects(ctx context.Context, taskIDs []uint) ([]models.CalendarObject, error)
+	// SaveObject saves the calendar object of its task, replacing the previous one.
+	SaveObject(ctx context.Context, object *models.CalendarObject) error
+	// FindSync returns the state of the user's collection when the sync token was handed out, or ErrNotFound.
+	FindSync(ctx context.Context, userID, token string) (*models.CalendarSync, error)
+	// SaveSync saves the state of the user's collection under its token, refreshing its creation time when
+	// it was already saved, and forgets the states of the user's collection saved before the given time.
+	SaveSync(ctx context.Context, state *models.CalendarSync, before time.Time) error
+}
+
+// ImportRepository abstracts the storage of models.ImportJob records and of the models.ImportedTask records
+// matching the rows of the imported files to the tasks they were imported into.
+// Implementations must be safe for concurrent use.
+type ImportRepository interface {
+	// Create stores a new import job, filling its ID and timestamps.
+	Create(ctx context.Context, job *models.ImportJob) error
+	// FindByID returns the import job with the given ID or ErrNotFound.
+	FindByID(ctx context.Context, id uint) (*models.ImportJob, error)
+	// FindByUser returns the import jobs of the user, newest first.
+	FindByUser(ctx context.Context, userID string) ([]models.ImportJob, error)
+	// FindByStatus returns every import job with the given status, oldest first.
+	FindByStatus(ctx context.Context, status models.ImportStatus) ([]models.ImportJob, error)
+	// Update saves every field of an existing import job.
+	Update(ctx context.Context, job *models.ImportJob) error
+
+	// FindImported returns the tasks the rows with the given external IDs of the user's files of the source
+	// were imported into, leaving out the rows never imported.
+	FindImported(ctx context.Context, userID, source string, externalIDs []string) ([]models.ImportedTask, error)
+	// SaveImported saves the task a row was imported into, replacing the previous one.
+	SaveImported(ctx context.Context, imported *models.ImportedTask) error
+}
+
+// TaskRepository abstracts the storage of models.Tasks records.
+// Every task it returns has its Blocked field filled.
+// Implementations must be safe for concurrent use.
+type TaskRepository interface {
+	// Create stores a new task, filling its ID and timestamps.
+	Create(ctx context.Context, task *models.Tasks) error
+	// FindByID returns the task with the given ID or ErrNotFound.
+	FindByID(ctx context.Context, id uint) (*models.Tasks, error)
+	// FindByUser returns every personal task owned by the given user, leaving out workspace tasks.
+	FindByUser(ctx context.Context, userID string) ([]models.Tasks, error)
+	// FindByWorkspace returns every task of the given workspace.
+	FindByWorkspace(ctx context.Context, workspaceID string) ([]models.Tasks, error)
+	// FindColumn returns the tasks in the given status among the tasks of the workspace or, when workspaceID is nil,
+	// among the personal tasks of the user, ordered by rank and then by ID.
+	FindColumn(ctx context.Context, userID string, workspaceID *string, status string) ([]models.Tasks, error)
+	// Search returns the user's tasks whose title, description or notes contain every word of the query.
+	Search(ctx context.Context, userID, query string) ([]models.Tasks, error)
+	// Update saves every field of an existing task.
+	Update(ctx context.Context, task *models.Tasks) error
+	// CreateInColumn stores a new task as Create does, unless the board column of its status, as FindColumn sees it,
+	// already holds limit tasks, in which case it returns ErrColumnFull. A limit of 0 leaves the column unlimited.
+	// Counting the column and storing the task happen atomically, so concurrent requests can't both take its last slot.
+	CreateInColumn(ctx context.Context, task *models.Tasks, limit int) error
+	// UpdateInColumn saves every field of an existing task as Update does, unless the board column of its status
+	// already holds limit other tasks, in which case it returns ErrColumnFull, atomically as CreateInColumn does.
+	UpdateInColumn(ctx context.Context, task *models.Tasks, limit int) error
+	// Delete removes the task with the given ID. Its subtasks become top-level tasks.
+	Delete(ctx context.Context, id uint) error
+	// FindDeleted returns the tasks of every user in the trash, the deleted tasks, that were deleted before the given time,
+	// oldest first.
+	FindDeleted(ctx context.Context, before time.Time) ([]models.Tasks, error)
+	// Purge permanently removes the deleted task with the given ID, along with its checklist, comments, attachments
+	// and everything else attached to it, or returns ErrNotFound when the task isn't in the trash.
+	Purge(ctx context.Context, id uint) error
+
+	// FindChildren returns the direct subtasks of the task.
+	FindChildren(ctx context.Context, parentID uint) ([]models.Tasks, error)
+	// CompleteDescendants moves the unfinished subtasks of the task in ranks to the given terminal status, completing
+	// them at the given time at the board position ranks holds for them, and checks off the checklist items of the task
+	// and of its subtasks at any depth, atomically.
+	CompleteDescendants(ctx context.Context, id uint, status string, at time.Time, ranks map[uint]string) error
+
+	// AddChecklistItem stores a new checklist item at the end of the task's checklist, filling its ID, position and timestamps.
+	AddChecklistItem(ctx context.Context, item *models.ChecklistItem) error
+	// FindChecklistItem returns the checklist item with the given ID or ErrNotFound.
+	FindChecklistItem(ctx context.Context, id uint) (*models.ChecklistItem, error)
+	// ListChecklistItems returns the checklist of the task, in order.
+	ListChecklistItems(ctx context.Context, taskID uint) ([]models.ChecklistItem, error)
+	// UpdateChecklistItem saves every field of an existing checklist item.
+	UpdateChecklistItem(ctx context.Context, item *models.ChecklistItem) error
+	// DeleteChecklistItem removes the checklist item with the given ID.
+	DeleteChecklistItem(ctx context.Context, id uint) error
+	// ReorderChecklist moves the checklist items of the task to the position of their ID in ids, atomically.
+	ReorderChecklist(ctx context.Context, taskID uint, ids []uint) error
+
+	// AddDependency makes a task depend on another one, or returns ErrDuplicate when it already does.
+	// Preventing cycles is up to the caller.
+	AddDependency(ctx context.Context, dependency *models.TaskDependency) error
+	// RemoveDependency stops the task from depending on the other one.
+	RemoveDependency(ctx context.Context, taskID, dependsOnID uint) error
+	// FindDependencies returns the tasks the task depends on.
+	FindDependencies(ctx context.Context, taskID uint) ([]models.Tasks, error)
+	// FindDependents returns the tasks that depend on the task.
+	FindDependents(ctx context.Context, taskID uint) ([]models.Tasks, error)
+	// ListDependenciesAmong returns the dependencies linking two of the given tasks.
+	ListDependenciesAmong(ctx context.Context, ids []uint) ([]models.TaskDependency, error)
+
+	// AddAssignee assigns the user to the task, or returns ErrDuplicate when they already are.
+	AddAssignee(ctx context.Context, assignee *models.TaskAssignee) error
+	// RemoveAssignee removes the user from the assignees of the task.
+	RemoveAssignee(ctx context.Context, taskID uint, userID string) error
+	// ListAssignees returns the assignees of the task, in the order they were assigned.
+	ListAssignees(ctx context.Context, taskID uint) ([]models.TaskAssignee, error)
+	// FindAssignedTo returns every task the user is assigned to.
+	FindAssignedTo(ctx context.Context, userID string) ([]models.Tasks, error)
+
+	// AddWatcher makes the user watch the task, or returns ErrDuplicate when they already do.
+	AddWatcher(ctx context.Context, watcher *models.TaskWatcher) error
+	// RemoveWatcher stops the user from watching the task.
+	RemoveWatcher(ctx context.Context, taskID uint, userID string) error
+	// ListWatchers returns the watchers of the task, in the order they started watching.
+	ListWatchers(ctx context.Context, taskID uint) ([]models.TaskWatcher, error)
+	// FindWatchedBy returns every task the user watches.
+	FindWatchedBy(ctx context.Context, userID string) ([]models.Tasks, error)
+}
+
+// WorkspaceRepository abstracts the storage of workspaces, their members and invitations.
+// Implementations must be safe for concurrent use.
+type WorkspaceRepository interface {
+	// Create stores a new workspace and makes its owner a member with the owner role.
+	Create(ctx context.Context, workspace *models.Workspace) error
+	// FindByID returns the workspace with the given ID or ErrNotFound.
+	FindByID(ctx context.Context, id string) (*models.Workspace, error)
+	// FindByMember returns every workspace the given user is a member of.
+	FindByMember(ctx context.Context, userID string) ([]models.Workspace, error)
+	// Update saves every field of an existing workspace.
+	Update(ctx context.Context, workspace *models.Workspace) error
+	// Delete removes the workspace with the given ID, along with its members, invitations, workflow, labels and tasks.
+	Delete(ctx context.Context, id string) error
+
+	// FindMember returns the membership of the user in the workspace or ErrNotFound.
+	FindMember(ctx context.Context, workspaceID, userID string) (*models.WorkspaceMember, error)
+	// ListMembers returns every member of the workspace.
+	ListMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error)
+	// SaveMember adds the member to the workspace, or updates its role when it's already a member.
+	SaveMember(ctx context.Context, member *models.WorkspaceMember) error
+	// RemoveMember removes the user from the workspace.
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+
+	// CreateInvitation stores a new invitation.
+	CreateInvitation(ctx context.Context, invitation *models.WorkspaceInvitation) error
+	// FindInvitationByTokenHash returns the invitation whose token has the given hash or ErrNotFound.
+	FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.WorkspaceInvitation, error)
+	// ListInvitations returns the invitations of the workspace that weren't accepted yet.
+	ListInvitations(ctx context.Context, workspaceID string) ([]models.WorkspaceInvitation, error)
+	// AcceptInvitation marks the invitation as accepted and saves the new member, atomically.
+	AcceptInvitation(ctx context.Context, invitation *models.WorkspaceInvitation, member *models.WorkspaceMember) error
+	// DeleteInvitation removes the invitation with the given ID from the workspace.
+	DeleteInvitation(ctx context.Context, workspaceID string, id uint) error
+
+	// FindWorkflow returns the workflow configured for the workspace, or ErrNotFound when it follows the default one.
+	FindWorkflow(ctx context.Context, workspaceID string) (*models.Workflow, error)
+	// SaveWorkflow configures the workflow of its workspace, replacing the previous one.
+	SaveWorkflow(ctx context.Context, workflow *models.Workflow) error
+	// DeleteWorkflow removes the workflow configured for the workspace, which goes back to the default one.
+	DeleteWorkflow(ctx context.Context, workspaceID string) error
+}
+
+// LabelRepository abstracts the storage of labels and of the tasks they tag.
+// Implementations must be safe for concurrent use.
+type LabelRepository interface {
+	// Create stores a new label, filling its ID and timestamps.
+	Create(ctx context.Context, label *models.Label) error
+	// FindByID returns the label with the given ID or ErrNotFound.
+	FindByID(ctx context.Context, id uint) (*models.Label, error)
+	// FindByUser returns the personal labels of the user, leaving out workspace labels, ordered by name.
+	FindByUser(ctx context.Context, userID string) ([]models.Label, error)
+	// FindByWorkspace returns the labels of the workspace, ordered by name.
+	FindByWorkspace(ctx context.Context, workspaceID string) ([]models.Label, error)
+	// Update saves every field of an existing label.
+	Update(ctx context.Context, label *models.Label) error
+	// Delete removes the label with the given ID, untagging the tasks it tagged.
+	Delete(ctx context.Context, id uint) error
+
+	// Tag tags every given task with the label, atomically. Tasks it already tags are left as they are.
+	Tag(ctx context.Context, labelID uint, taskIDs []uint) error
+	// Untag removes the label from every given task, atomically.
+	Untag(ctx context.Context, labelID uint, taskIDs []uint) error
+	// FindByTask returns the labels tagging the task, ordered by name.
+	FindByTask(ctx context.Context, taskID uint) ([]models.Label, error)
+	// FindTaggedTaskIDs returns the IDs of the tasks tagged with every given label when all is true,
+	// or with any of them otherwise.
+	FindTaggedTaskIDs(ctx context.Context, labelIDs []uint, all bool) ([]uint, error)
+}
+
+// CommentRepository abstracts the storage of task comments, their edit history and their mentions.
+// Implementations must be safe for concurrent use.
+type CommentRepository interface {
+	// Create stores a new comment along with its Mentions, filling its ID and timestamps.
+	Create(ctx context.Context, comment *models.Comment) error
+	// FindByID returns the comment with the given ID and its mentions, or ErrNotFound when it doesn't exist or was deleted.
+	FindByID(ctx context.Context, id uint) (*models.Comment, error)
+	// FindByTask returns every comment of the task with its mentions, deleted ones included, in the order they were written.
+	FindByTask(ctx context.Context, taskID uint) ([]models.Comment, error)
+	// Update saves the edited comment, replacing its mentions with its Mentions, and stores the revision
+	// holding the previous body, atomically.
+	Update(ctx context.Context, comment *models.Comment, revision *models.CommentRevision) error
+	// Delete soft deletes the comment with the given ID.
+	Delete(ctx context.Context, id uint) error
+	// ListRevisions returns the previous bodies of the comment, oldest first.
+	ListRevisions(ctx context.Context, commentID uint) ([]models.CommentRevision, error)
+}
+
+// AttachmentRepository abstracts the storage of the metadata of task attachments.
+// Implementations must be safe for concurrent use.
+type AttachmentRepository interface {
+	// Create stores a new attachment, filling its ID and timestamp.
+	Create(ctx context.Context, attachment *models.Attachment) error
+	// CreateWithinQuota stores a new attachment as Create does, unless it takes the bytes its uploader uploaded,
+	// as UsageByUser counts them, over the quota, in which case it returns ErrQuotaExceeded.
+	// Counting the usage and storing the attachment happen atomically, so concurrent uploads can't share the same room.
+	CreateWithinQuota(ctx context.Context, attachment *models.Attachment, quota int64) error
+	// FindByID returns the attachment with the given ID or ErrNotFound.
+	FindByID(ctx context.Context, id uint) (*models.Attachment, error)
+	// FindByTask returns every attachment of the task, in the order they were uploaded.
+	FindByTask(ctx context.Context, taskID uint) ([]models.Attachment, error)
+	// FindByOwner returns the attachments removed along with the user: those they uploaded, and those of their
+	// personal tasks and of the tasks of the workspaces they own, deleted tasks included.
+	FindByOwner(ctx context.Context, userID string) ([]models.Attachment, error)
+	// FindByWorkspace returns the attachments of the tasks of the workspace, deleted tasks included.
+	FindByWorkspace(ctx context.Context, workspaceID string) ([]models.Attachment, error)
+	// Delete removes the attachment with the given ID.
+	Delete(ctx context.Context, id uint) error
+	// UsageByUser returns how many bytes of attachments the user uploaded.
+	UsageByUser(ctx context.Context, userID string) (int64, error)
+	// FindByThumbnailStatus returns every attachment whose thumbnails have the given status.
+	FindByThumbnailStatus(ctx context.Context, status models.ThumbnailStatus) ([]models.Attachment, error)
+	// SetThumbnailStatus changes the thumbnail status of the attachment with the given ID.
+	SetThumbnailStatus(ctx context.Context, id uint, status models.ThumbnailStatus) error
+}
+
+// translateError maps GORM errors into the errors exposed by this package,
+// so callers don't need to import GORM to tell the failures apart.
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
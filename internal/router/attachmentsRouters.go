@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupAttachmentRoutes sets up the attachment related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories, the blob store and the logger.
+// Files are uploaded and listed through the task routes, these routes manage a single attachment.
+//
+// GET /download/id/:id: Downloads the contents of an attachment, honouring Range requests.
+// DELETE /delete/id/:id: Deletes an attachment by its ID.
+// GET /thumbnail/id/:id?size=: Retrieves the thumbnail of an image attachment.
+// GET /get/usage: Retrieves how much of their quota the authenticated user is using.
+func SetupAttachmentRoutes(g *echo.Group, h *handlers.Handler) {
+	g.GET("/download/id/:id", h.DownloadAttachment)
+	g.DELETE("/delete/id/:id", h.DeleteAttachment)
+	g.GET("/thumbnail/id/:id", h.GetAttachmentThumbnail)
+	g.GET("/get/usage", h.GetAttachmentUsage)
+}
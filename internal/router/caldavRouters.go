@@ -0,0 +1,31 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupCalDAVRoutes sets up the routes of the CalDAV server, which exposes the tasks of each user as a VTODO collection
+// calendar and task applications synchronize with.
+// It requires a pointer to the echo.Echo instance, since the discovery route lives at the root of the server,
+// the Handler holding the repositories and the logger, and the middleware authenticating the requests.
+// Every route but OPTIONS and the discovery requires authentication.
+//
+// GET, PROPFIND /.well-known/caldav: Redirects the clients discovering the server to its root.
+// OPTIONS /dav/*: Tells clients which WebDAV and CalDAV features the server supports.
+// PROPFIND /dav/*: Returns the properties of the root, the principal, the calendar home, the collection or its objects.
+// REPORT /dav/*: Queries, fetches or synchronizes the calendar objects of the collection.
+// GET /dav/*: Retrieves a calendar object.
+// PUT /dav/*: Creates or updates a task from a calendar object.
+// DELETE /dav/*: Deletes the task of a calendar object.
+func SetupCalDAVRoutes(e *echo.Echo, h *handlers.Handler, authenticate echo.MiddlewareFunc) {
+	e.GET("/.well-known/caldav", h.CalDAVWellKnown)
+	e.Add(echo.PROPFIND, "/.well-known/caldav", h.CalDAVWellKnown)
+
+	e.OPTIONS("/dav/*", h.CalDAVOptions)
+	e.Add(echo.PROPFIND, "/dav/*", h.CalDAVPropFind, authenticate)
+	e.Add(echo.REPORT, "/dav/*", h.CalDAVReport, authenticate)
+	e.GET("/dav/*", h.CalDAVGet, authenticate)
+	e.PUT("/dav/*", h.CalDAVPut, authenticate)
+	e.DELETE("/dav/*", h.CalDAVDelete, authenticate)
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupCalendarRoutes sets up the calendar feed related routes for the given echo group.
+// It requires a pointer to an echo.Group, the Handler holding the repositories and the logger,
+// and the middleware authenticating the routes that manage the feed.
+// The feed itself is authenticated by the secret token in its path, so calendar applications can subscribe to it.
+//
+// GET /get/feed: Retrieves when the calendar feed of the authenticated user was created (authenticated).
+// POST /create/feed: Creates or regenerates the calendar feed of the authenticated user (authenticated).
+// DELETE /delete/feed: Revokes the calendar feed of the authenticated user (authenticated).
+// GET /feed/:token: Serves the calendar feed with the given token as an iCalendar document.
+func SetupCalendarRoutes(g *echo.Group, h *handlers.Handler, authenticate echo.MiddlewareFunc) {
+	g.GET("/get/feed", h.GetCalendarFeed, authenticate)
+	g.POST("/create/feed", h.CreateCalendarFeed, authenticate)
+	g.DELETE("/delete/feed", h.DeleteCalendarFeed, authenticate)
+	g.GET("/feed/:token", h.GetCalendarFeedEntries)
+}
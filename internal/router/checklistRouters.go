@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupChecklistRoutes sets up the checklist related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories and the logger.
+// Checklist items are added, listed and reordered through the task routes, these routes manage a single item.
+//
+// PUT /update/id/:id: Renames or checks off a checklist item by its ID.
+// DELETE /delete/id/:id: Deletes a checklist item by its ID.
+func SetupChecklistRoutes(g *echo.Group, h *handlers.Handler) {
+	g.PUT("/update/id/:id", h.UpdateChecklistItem)
+	g.DELETE("/delete/id/:id", h.DeleteChecklistItem)
+}
@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupCommentRoutes sets up the comment related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories and the logger.
+// Comments are written and listed through the task routes, these routes manage a single comment.
+//
+// PUT /update/id/:id: Edits a comment by its ID.
+// DELETE /delete/id/:id: Deletes a comment by its ID.
+// GET /get/id/:id/history: Retrieves the previous bodies of a comment.
+func SetupCommentRoutes(g *echo.Group, h *handlers.Handler) {
+	g.PUT("/update/id/:id", h.UpdateComment)
+	g.DELETE("/delete/id/:id", h.DeleteComment)
+	g.GET("/get/id/:id/history", h.GetCommentHistory)
+}
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/docs"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupDocsRoutes sets up the routes documenting the API.
+//
+// GET /openapi.json: The OpenAPI 3.1 document describing every route.
+// GET /docs: Swagger UI rendering that document.
+// GET /docs/swagger-ui/*: The Swagger UI scripts and styles.
+func SetupDocsRoutes(e *echo.Echo) {
+	e.GET("/openapi.json", docs.SpecHandler)
+	e.GET("/docs", docs.UIHandler)
+	e.GET("/docs/swagger-ui/*", docs.AssetHandler)
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupEventRoutes sets up the event related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the event stream and the logger.
+//
+// GET /stream: Follows the events of the authenticated user, such as the changes to their tasks, as server-sent events.
+func SetupEventRoutes(g *echo.Group, h *handlers.Handler) {
+	g.GET("/stream", h.StreamEvents)
+}
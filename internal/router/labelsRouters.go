@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupLabelRoutes sets up the label related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories and the logger.
+// Personal labels are only seen by their owner, and workspace labels by the workspace members.
+//
+// POST /create: Creates a new label, personal or in a workspace.
+// GET /get/all/: Retrieves the personal labels of the authenticated user.
+// GET /get/id/:id: Retrieves a label by its ID.
+// PUT /update/id/:id: Updates a label by its ID.
+// DELETE /delete/id/:id: Deletes a label by its ID.
+// POST /tag/id/:id: Tags several tasks with a label.
+// POST /untag/id/:id: Removes a label from several tasks.
+func SetupLabelRoutes(g *echo.Group, h *handlers.Handler) {
+	g.POST("/create", h.CreateLabel)
+	g.GET("/get/all/", h.GetMyLabels)
+	g.GET("/get/id/:id", h.GetLabelById)
+	g.PUT("/update/id/:id", h.UpdateLabelById)
+	g.DELETE("/delete/id/:id", h.DeleteLabelById)
+	g.POST("/tag/id/:id", h.TagTasks)
+	g.POST("/untag/id/:id", h.UntagTasks)
+}
@@ -2,17 +2,144 @@ package router
 
 import (
 	"github.com/charmbracelet/log"
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/mail"
+	"github.com/devgugga/NullTask/internal/middlewares"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+	"github.com/devgugga/NullTask/internal/transfer"
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 )
 
+// Dependencies holds everything the routes need to serve requests.
+type Dependencies struct {
+	// DB is the database connection the repositories are built on.
+	DB *gorm.DB
+	// Log is the application logger, used when a request has no per-request logger.
+	Log *log.Logger
+	// Tokens issues and verifies the bearer tokens of authenticated routes.
+	Tokens *auth.TokenManager
+	// Events receives what happens to tasks. It may be nil, in which case nothing is published.
+	Events events.Publisher
+	// Stream hands the published events over to the clients of the event stream route. It may be nil, in which case the route is unavailable.
+	Stream *events.Stream
+	// Mailer delivers the e-mails, such as the workspace invitations. It may be nil, in which case they're only logged.
+	Mailer mail.Mailer
+	// Blobs keeps the contents of the attachments.
+	Blobs storage.BlobStore
+	// AttachmentQuota is how many bytes of attachments each user can upload.
+	AttachmentQuota int64
+	// Thumbnails generates the previews of image attachments. It may be nil, in which case there are none.
+	Thumbnails *thumbnails.Worker
+	// Importer imports the files of tasks uploaded to the import routes. It may be nil, in which case the imports stay pending.
+	Importer *transfer.Importer
+}
+
 // SetupRoutes sets up the routes for the application.
-// It takes an Echo instance, a GORM database connection, and a Charmbracelet log instance as parameters.
-// The function creates a group for user routes and sets up the routes using the SetupUserRoutes function.
-func SetupRoutes(e *echo.Echo, db *gorm.DB, log *log.Logger) {
+// It takes an Echo instance and the Dependencies shared by every route as parameters.
+// The function builds the repositories on top of the database connection, creates the Handler shared by
+// every route, and sets up the documentation routes and the user, task, checklist, workspace, label, comment, attachment, calendar, import, export and event groups, along with the CalDAV server.
+// Every route outside of the user and calendar groups requires authentication, while those groups decide route by route.
+// The CalDAV server accepts the e-mail and password of the user as well, since most CalDAV clients can't send bearer tokens.
+func SetupRoutes(e *echo.Echo, deps *Dependencies) {
+	mailer := deps.Mailer
+	if mailer == nil {
+		mailer = mail.LogMailer(deps.Log)
+	}
+	h := &handlers.Handler{
+		Users:           repository.NewGormUserRepository(deps.DB),
+		Tasks:           repository.NewGormTaskRepository(deps.DB),
+		Workspaces:      repository.NewGormWorkspaceRepository(deps.DB),
+		Comments:        repository.NewGormCommentRepository(deps.DB),
+		Labels:          repository.NewGormLabelRepository(deps.DB),
+		Tokens:          deps.Tokens,
+		Log:             deps.Log,
+		Events:          deps.Events,
+		Stream:          deps.Stream,
+		Mailer:          mailer,
+		Attachments:     repository.NewGormAttachmentRepository(deps.DB),
+		Blobs:           deps.Blobs,
+		AttachmentQuota: deps.AttachmentQuota,
+		Thumbnails:      deps.Thumbnails,
+		Calendar:        repository.NewGormCalendarRepository(deps.DB),
+		Imports:         repository.NewGormImportRepository(deps.DB),
+		Importer:        deps.Importer,
+	}
+	authenticate := middlewares.Authenticate(deps.Tokens, h.CheckActive)
+
+	// Set up the OpenAPI document and the Swagger UI
+	SetupDocsRoutes(e)
+
 	// Create a group for user routes
 	userRoutes := e.Group("/users")
 
 	// Set up the routes for user group using the SetupUserRoutes function
-	SetupUserRoutes(userRoutes, db, log)
+	SetupUserRoutes(userRoutes, h, authenticate)
+
+	// Create a group for task routes, all of them require authentication
+	taskRoutes := e.Group("/tasks", authenticate)
+
+	// Set up the routes for task group using the SetupTaskRoutes function
+	SetupTaskRoutes(taskRoutes, h)
+
+	// Create a group for checklist routes, all of them require authentication
+	checklistRoutes := e.Group("/checklist", authenticate)
+
+	// Set up the routes for checklist group using the SetupChecklistRoutes function
+	SetupChecklistRoutes(checklistRoutes, h)
+
+	// Create a group for workspace routes, all of them require authentication
+	workspaceRoutes := e.Group("/workspaces", authenticate)
+
+	// Set up the routes for workspace group using the SetupWorkspaceRoutes function
+	SetupWorkspaceRoutes(workspaceRoutes, h)
+
+	// Create a group for label routes, all of them require authentication
+	labelRoutes := e.Group("/labels", authenticate)
+
+	// Set up the routes for label group using the SetupLabelRoutes function
+	SetupLabelRoutes(labelRoutes, h)
+
+	// Create a group for comment routes, all of them require authentication
+	commentRoutes := e.Group("/comments", authenticate)
+
+	// Set up the routes for comment group using the SetupCommentRoutes function
+	SetupCommentRoutes(commentRoutes, h)
+
+	// Create a group for attachment routes, all of them require authentication
+	attachmentRoutes := e.Group("/attachments", authenticate)
+
+	// Set up the routes for attachment group using the SetupAttachmentRoutes function
+	SetupAttachmentRoutes(attachmentRoutes, h)
+
+	// Create a group for calendar routes
+	calendarRoutes := e.Group("/calendar")
+
+	// Set up the routes for calendar group using the SetupCalendarRoutes function
+	SetupCalendarRoutes(calendarRoutes, h, authenticate)
+
+	// Create a group for import routes, all of them require authentication
+	importRoutes := e.Group("/imports", authenticate)
+
+	// Set up the routes for import group using the SetupImportRoutes function
+	SetupImportRoutes(importRoutes, h)
+
+	// Create a group for export routes, all of them require authentication
+	exportRoutes := e.Group("/exports", authenticate)
+
+	// Set up the routes for export group using the SetupExportRoutes function
+	SetupExportRoutes(exportRoutes, h)
+
+	// Create a group for event routes, all of them require authentication
+	eventRoutes := e.Group("/events", authenticate)
+
+	// Set up the routes for event group using the SetupEventRoutes function
+	SetupEventRoutes(eventRoutes, h)
+
+	// Set up the CalDAV server, which lives outside of any group to answer the discovery of the clients
+	SetupCalDAVRoutes(e, h, middlewares.AuthenticateCredentials(deps.Tokens, h.CheckCredentials, h.CheckActive))
 }
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupTaskRoutes sets up the task related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories and the logger.
+// Every route only sees the personal tasks of the authenticated user and the tasks of their workspaces.
+//
+// POST /create: Creates a new task.
+// POST /quick-add?preview=: Creates a task from a line of text such as "Pay rent tomorrow 9am #finance !high".
+// GET /get/id/:id: Retrieves a task by its ID.
+// GET /get/user/:userId?labels=&match=: Retrieves every task owned by a user, optionally only those with some labels.
+// GET /search/user/:userId?q=&labels=&match=: Searches the text of a user's tasks.
+// PUT /update/id/:id?cascade=&force=: Updates a task by its ID, optionally completing its subtasks along with it
+// or completing it despite unfinished dependencies.
+// DELETE /delete/id/:id: Deletes a task by its ID.
+// POST /assign/id/:id: Assigns a user to a task.
+// DELETE /unassign/id/:id/user/:userId: Removes a user from the assignees of a task.
+// GET /get/id/:id/assignees: Retrieves the assignees of a task.
+// GET /get/assigned: Retrieves every task assigned to the authenticated user.
+// POST /watch/id/:id: Makes the authenticated user watch a task.
+// DELETE /unwatch/id/:id: Stops the authenticated user from watching a task.
+// GET /get/id/:id/watchers: Retrieves the watchers of a task.
+// GET /get/watching: Retrieves every task the authenticated user watches.
+// POST /comment/id/:id: Writes a comment on a task.
+// GET /get/id/:id/comments: Retrieves the comment threads of a task.
+// POST /attach/id/:id: Uploads a file to a task.
+// GET /get/id/:id/attachments: Retrieves the attachments of a task.
+// GET /get/id/:id/subtasks: Retrieves the direct subtasks of a task.
+// GET /get/id/:id/progress: Retrieves how much of a task's subtasks and checklist is done.
+// POST /checklist/id/:id: Adds an item to the checklist of a task.
+// GET /get/id/:id/checklist: Retrieves the checklist of a task.
+// PUT /reorder/id/:id/checklist: Changes the order of the checklist of a task.
+// POST /depend/id/:id: Makes a task depend on another one.
+// DELETE /undepend/id/:id/task/:dependsOnId: Stops a task from depending on another one.
+// GET /get/id/:id/dependencies: Retrieves the tasks a task depends on.
+// GET /get/id/:id/dependents: Retrieves the tasks that depend on a task.
+// GET /get/critical-path?ids=: Orders a set of tasks by their dependencies and finds their critical path.
+// GET /get/id/:id/transitions: Retrieves the statuses a task can move to.
+// GET /get/board: Retrieves the board of the authenticated user's personal tasks.
+// PUT /move/id/:id?force=: Moves a task to another status and/or position of its board.
+// GET /get/id/:id/labels: Retrieves the labels tagging a task.
+// GET /get/next?limit=: Retrieves the open tasks of the authenticated user ranked by what to do next.
+// GET /get/eisenhower: Retrieves the open tasks of the authenticated user on the Eisenhower matrix.
+// GET /get/today: Retrieves the open tasks of the authenticated user due today in their time zone.
+// GET /get/week: Retrieves the open tasks of the authenticated user due this week in their time zone.
+// GET /get/overdue: Retrieves the open tasks of the authenticated user due before today in their time zone.
+func SetupTaskRoutes(g *echo.Group, h *handlers.Handler) {
+	g.POST("/create", h.CreateTask)
+	g.POST("/quick-add", h.QuickAddTask)
+	g.GET("/get/id/:id", h.GetTaskById)
+	g.GET("/get/user/:userId", h.GetTasksByUser)
+	g.GET("/search/user/:userId", h.SearchTasks)
+	g.PUT("/update/id/:id", h.UpdateTaskById)
+	g.DELETE("/delete/id/:id", h.DeleteTaskById)
+	g.POST("/assign/id/:id", h.AssignTask)
+	g.DELETE("/unassign/id/:id/user/:userId", h.UnassignTask)
+	g.GET("/get/id/:id/assignees", h.GetTaskAssignees)
+	g.GET("/get/assigned", h.GetAssignedTasks)
+	g.POST("/watch/id/:id", h.WatchTask)
+	g.DELETE("/unwatch/id/:id", h.UnwatchTask)
+	g.GET("/get/id/:id/watchers", h.GetTaskWatchers)
+	g.GET("/get/watching", h.GetWatchedTasks)
+	g.POST("/comment/id/:id", h.CreateComment)
+	g.GET("/get/id/:id/comments", h.GetTaskComments)
+	g.POST("/attach/id/:id", h.UploadAttachment)
+	g.GET("/get/id/:id/attachments", h.GetTaskAttachments)
+	g.GET("/get/id/:id/subtasks", h.GetSubtasks)
+	g.GET("/get/id/:id/progress", h.GetTaskProgress)
+	g.POST("/checklist/id/:id", h.CreateChecklistItem)
+	g.GET("/get/id/:id/checklist", h.GetTaskChecklist)
+	g.PUT("/reorder/id/:id/checklist", h.ReorderChecklist)
+	g.POST("/depend/id/:id", h.AddDependency)
+	g.DELETE("/undepend/id/:id/task/:dependsOnId", h.RemoveDependency)
+	g.GET("/get/id/:id/dependencies", h.GetTaskDependencies)
+	g.GET("/get/id/:id/dependents", h.GetTaskDependents)
+	g.GET("/get/critical-path", h.GetCriticalPath)
+	g.GET("/get/id/:id/transitions", h.GetTaskTransitions)
+	g.GET("/get/board", h.GetBoard)
+	g.PUT("/move/id/:id", h.MoveTask)
+	g.GET("/get/id/:id/labels", h.GetTaskLabels)
+	g.GET("/get/next", h.GetNextActions)
+	g.GET("/get/eisenhower", h.GetEisenhower)
+	g.GET("/get/today", h.GetTodayTasks)
+	g.GET("/get/week", h.GetWeekTasks)
+	g.GET("/get/overdue", h.GetOverdueTasks)
+}
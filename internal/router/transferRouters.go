@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupImportRoutes sets up the task import related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories, the importer and the logger.
+//
+// POST /create: Uploads a file of tasks and starts importing it in the background.
+// GET /get/all: Retrieves the import jobs of the authenticated user.
+// GET /get/id/:id: Retrieves an import job by its ID, with the outcome of each row.
+func SetupImportRoutes(g *echo.Group, h *handlers.Handler) {
+	g.POST("/create", h.CreateImport)
+	g.GET("/get/all", h.GetImports)
+	g.GET("/get/id/:id", h.GetImport)
+}
+
+// SetupExportRoutes sets up the task export related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories and the logger.
+//
+// GET /get/tasks?format=: Downloads the personal tasks of the authenticated user as a NullTask document or a CSV file.
+func SetupExportRoutes(g *echo.Group, h *handlers.Handler) {
+	g.GET("/get/tasks", h.ExportTasks)
+}
@@ -1,32 +1,36 @@
 package router
 
 import (
-	"github.com/charmbracelet/log"
 	"github.com/devgugga/NullTask/internal/handlers"
 	"github.com/labstack/echo/v4"
-	"gorm.io/gorm"
 )
 
 // SetupUserRoutes sets up the user related routes for the given echo group.
-// It requires a pointer to an echo.Group, a pointer to a gorm.DB instance, and a pointer to a log.Logger instance.
-// The function initializes a Handler instance with the provided DB and Log instances.
-// Then, it registers various HTTP methods and their corresponding handlers for user-related operations.
+// It requires a pointer to an echo.Group, the Handler holding the repositories and the logger,
+// and the middleware authenticating every route but the creation of a user and the login.
+// It registers various HTTP methods and their corresponding handlers for user-related operations.
 //
 // POST /create: Creates a new user.
-// GET /get/email/:email: Retrieves a user by their email.
-// GET /get/id/:id: Retrieves a user by their ID.
-// GET /get/all/: Retrieves all users.
-// PUT /update/email/:email: Updates a user by their email.
-// PUT /update/id/:id: Updates a user by their ID.
-// DELETE /delete/email/:email: Deletes a user by their email.
-func SetupUserRoutes(g *echo.Group, db *gorm.DB, log *log.Logger) {
-	h := &handlers.Handler{DB: db, Log: log}
-
+// POST /login: Exchanges an e-mail and password for a bearer token.
+// GET /get/email/:email: Retrieves a user by their email (authenticated).
+// GET /get/id/:id: Retrieves a user by their ID (authenticated).
+// GET /get/all/: Retrieves all users (authenticated, admins only).
+// PUT /update/email/:email: Updates a user by their email (authenticated).
+// PUT /update/id/:id: Updates a user by their ID (authenticated).
+// DELETE /delete/email/:email: Deletes a user by their email (authenticated).
+// DELETE /delete/id/:id: Deletes a user by their ID (authenticated).
+// GET /get/preferences: Retrieves the preferences of the authenticated user (authenticated).
+// PUT /update/preferences: Updates the preferences of the authenticated user (authenticated).
+func SetupUserRoutes(g *echo.Group, h *handlers.Handler, authenticate echo.MiddlewareFunc) {
 	g.POST("/create", h.CreateUser)
-	g.GET("/get/email/:email", h.GetUserByEmail)
-	g.GET("/get/id/:id", h.GetUserById)
-	g.GET("/get/all/", h.GetAllUsers)
-	g.PUT("/update/email/:email", h.UpdateUserByEmail)
-	g.PUT("/update/id/:id", h.UpdateUserByEmail)
-	g.DELETE("/delete/email/:email", h.DeleteUserByEmail)
+	g.POST("/login", h.Login)
+	g.GET("/get/email/:email", h.GetUserByEmail, authenticate)
+	g.GET("/get/id/:id", h.GetUserById, authenticate)
+	g.GET("/get/all/", h.GetAllUsers, authenticate)
+	g.PUT("/update/email/:email", h.UpdateUserByEmail, authenticate)
+	g.PUT("/update/id/:id", h.UpdateUserById, authenticate)
+	g.DELETE("/delete/email/:email", h.DeleteUserByEmail, authenticate)
+	g.DELETE("/delete/id/:id", h.DeleteUserById, authenticate)
+	g.GET("/get/preferences", h.GetPreferences, authenticate)
+	g.PUT("/update/preferences", h.UpdatePreferences, authenticate)
 }
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/labstack/echo/v4"
+)
+
+// SetupWorkspaceRoutes sets up the workspace related routes for the given echo group.
+// It requires a pointer to an echo.Group, already guarded by the authentication middleware,
+// and the Handler holding the repositories and the logger.
+// Only members see a workspace, and what they can change depends on their role.
+//
+// POST /create: Creates a new workspace owned by the authenticated user.
+// GET /get/all/: Retrieves every workspace the authenticated user is a member of.
+// GET /get/id/:id: Retrieves a workspace by its ID.
+// PUT /update/id/:id: Updates a workspace by its ID.
+// DELETE /delete/id/:id: Deletes a workspace by its ID.
+// GET /get/id/:id/members: Retrieves the members of a workspace.
+// PUT /update/id/:id/members/:userId: Changes the role of a member.
+// DELETE /delete/id/:id/members/:userId: Removes a member from a workspace.
+// POST /invite/id/:id: Invites an e-mail address to a workspace.
+// GET /get/id/:id/invitations: Retrieves the pending invitations of a workspace.
+// DELETE /delete/id/:id/invitations/:invitationId: Revokes an invitation.
+// POST /accept/:token: Accepts an invitation.
+// GET /get/id/:id/tasks?labels=&match=: Retrieves the tasks of a workspace, optionally only those with some labels.
+// GET /get/id/:id/workflow: Retrieves the status workflow the tasks of a workspace follow.
+// PUT /update/id/:id/workflow: Configures the status workflow of a workspace.
+// DELETE /delete/id/:id/workflow: Resets the status workflow of a workspace to the default one.
+// GET /get/id/:id/board: Retrieves the board of a workspace, with a column per status.
+// GET /get/id/:id/labels: Retrieves the labels of a workspace.
+func SetupWorkspaceRoutes(g *echo.Group, h *handlers.Handler) {
+	g.POST("/create", h.CreateWorkspace)
+	g.GET("/get/all/", h.GetMyWorkspaces)
+	g.GET("/get/id/:id", h.GetWorkspaceById)
+	g.PUT("/update/id/:id", h.UpdateWorkspaceById)
+	g.DELETE("/delete/id/:id", h.DeleteWorkspaceById)
+	g.GET("/get/id/:id/members", h.GetWorkspaceMembers)
+	g.PUT("/update/id/:id/members/:userId", h.UpdateWorkspaceMember)
+	g.DELETE("/delete/id/:id/members/:userId", h.RemoveWorkspaceMember)
+	g.POST("/invite/id/:id", h.InviteToWorkspace)
+	g.GET("/get/id/:id/invitations", h.GetWorkspaceInvitations)
+	g.DELETE("/delete/id/:id/invitations/:invitationId", h.DeleteWorkspaceInvitation)
+	g.POST("/accept/:token", h.AcceptWorkspaceInvitation)
+	g.GET("/get/id/:id/tasks", h.GetWorkspaceTasks)
+	g.GET("/get/id/:id/workflow", h.GetWorkspaceWorkflow)
+	g.PUT("/update/id/:id/workflow", h.UpdateWorkspaceWorkflow)
+	g.DELETE("/delete/id/:id/workflow", h.DeleteWorkspaceWorkflow)
+	g.GET("/get/id/:id/board", h.GetWorkspaceBoard)
+	g.GET("/get/id/:id/labels", h.GetWorkspaceLabels)
+}
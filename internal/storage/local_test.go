@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put(ctx, "tasks/1/report.pdf", strings.NewReader("hello, world"), 12, "application/pdf"); err != nil {
+		t.Fatalf("putting the blob: %v", err)
+	}
+
+	blob, err := store.Open(ctx, "tasks/1/report.pdf")
+	if err != nil {
+		t.Fatalf("opening the blob: %v", err)
+	}
+	if _, err := blob.Seek(7, io.SeekStart); err != nil {
+		t.Fatalf("seeking the blob: %v", err)
+	}
+	content, err := io.ReadAll(blob)
+	blob.Close()
+	if err != nil || string(content) != "world" {
+		t.Fatalf("expected to read %q from the offset, got %q (%v)", "world", content, err)
+	}
+
+	if err := store.Delete(ctx, "tasks/1/report.pdf"); err != nil {
+		t.Fatalf("deleting the blob: %v", err)
+	}
+	if _, err := store.Open(ctx, "tasks/1/report.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after deleting, got %v", err)
+	}
+	if err := store.Delete(ctx, "tasks/1/report.pdf"); err != nil {
+		t.Fatalf("expected deleting a missing blob to succeed, got %v", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideTheRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../b"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+func TestLocalStoreChecksTheSize(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put(context.Background(), "short", strings.NewReader("abc"), 10, ""); err == nil {
+		t.Fatal("expected a short blob to be rejected")
+	}
+	if _, err := store.Open(context.Background(), "short"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the rejected blob not to be stored, got %v", err)
+	}
+}
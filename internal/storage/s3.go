@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config configures the connection of a S3Store.
+type S3Config struct {
+	// Endpoint is the host, and port, of the S3 API, such as s3.amazonaws.com or localhost:9000 for a local MinIO.
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	// Bucket is where the blobs are stored, it's created when it doesn't exist.
+	Bucket string
+	Region string
+	// UseSSL connects through HTTPS.
+	UseSSL bool
+}
+
+// S3Store is a BlobStore keeping each blob as an object of a bucket in an S3-compatible service,
+// such as Amazon S3 or MinIO.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store connects to the S3-compatible service and makes sure the bucket exists.
+func NewS3Store(ctx context.Context, conf S3Config) (*S3Store, error) {
+	client, err := minio.New(conf.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(conf.AccessKey, conf.SecretKey, ""),
+		Secure: conf.UseSSL,
+		Region: conf.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, conf.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, conf.Bucket, minio.MakeBucketOptions{Region: conf.Region}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3Store{client: client, bucket: conf.Bucket}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Open stats the object first, so missing blobs are reported as ErrNotFound right away
+// instead of on the first read.
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, translateS3Error(err)
+	}
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, translateS3Error(err)
+	}
+	return object, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// translateS3Error maps the "no such key" error of the S3 API into ErrNotFound.
+func translateS3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+// Package storage keeps the contents of uploaded files, such as task attachments, outside of the database.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when there's no blob with the requested key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores blobs, opaque byte sequences, under string keys.
+// Implementations must be safe for concurrent use.
+type BlobStore interface {
+	// Put stores the size bytes read from r under the key, replacing any blob already stored there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the blob stored under the key, or ErrNotFound. The blob can be read from any offset,
+	// which is what serving Range requests needs, and must be closed by the caller.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the blob stored under the key. Deleting a missing blob isn't an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,128 @@
+// Package taskgraph orders tasks linked by dependencies.
+//
+// The graph is a set of task IDs and edges saying that a task can't be done before another one.
+// It's kept free of storage concerns: the caller loads the tasks and their dependencies and asks
+// for a topological order or for the critical path through them.
+package taskgraph
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrCycle is returned when the dependencies loop back on themselves, so the tasks have no valid order.
+var ErrCycle = errors.New("the dependencies form a cycle")
+
+// Edge says that Task can't be done before DependsOn.
+type Edge struct {
+	Task      uint
+	DependsOn uint
+}
+
+// Graph is a set of tasks and the dependencies between them.
+type Graph struct {
+	nodes []uint
+	preds map[uint][]uint
+	succs map[uint][]uint
+}
+
+// New builds the graph of the given tasks. Edges reaching tasks outside of the set are ignored,
+// as are repeated tasks and edges.
+func New(nodes []uint, edges []Edge) *Graph {
+	g := &Graph{preds: map[uint][]uint{}, succs: map[uint][]uint{}}
+	known := map[uint]bool{}
+	for _, node := range nodes {
+		if !known[node] {
+			known[node] = true
+			g.nodes = append(g.nodes, node)
+		}
+	}
+	sort.Slice(g.nodes, func(i, j int) bool { return g.nodes[i] < g.nodes[j] })
+
+	seen := map[Edge]bool{}
+	for _, edge := range edges {
+		if !known[edge.Task] || !known[edge.DependsOn] || seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		g.preds[edge.Task] = append(g.preds[edge.Task], edge.DependsOn)
+		g.succs[edge.DependsOn] = append(g.succs[edge.DependsOn], edge.Task)
+	}
+	return g
+}
+
+// Sort returns the tasks in an order where every task comes after the tasks it depends on.
+// Among the tasks that are ready at the same time, lower IDs come first, so the order is stable.
+// It returns ErrCycle when there is no such order.
+func (g *Graph) Sort() ([]uint, error) {
+	pending := map[uint]int{}
+	var ready []uint
+	for _, node := range g.nodes {
+		pending[node] = len(g.preds[node])
+		if pending[node] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	order := make([]uint, 0, len(g.nodes))
+	for len(ready) > 0 {
+		node := ready[0]
+		ready = ready[1:]
+		order = append(order, node)
+
+		for _, next := range g.succs[node] {
+			pending[next]--
+			if pending[next] == 0 {
+				i := sort.Search(len(ready), func(i int) bool { return ready[i] > next })
+				ready = append(ready[:i], append([]uint{next}, ready[i:]...)...)
+			}
+		}
+	}
+
+	if len(order) != len(g.nodes) {
+		return nil, ErrCycle
+	}
+	return order, nil
+}
+
+// CriticalPath returns the heaviest chain of dependencies, from the first task to do to the last one,
+// where the weight of a chain is the sum of the weights of its tasks.
+// Ties are broken in favour of the chain found first in the topological order.
+// It returns an empty path when no chain weighs anything, and ErrCycle when the tasks have no valid order.
+func (g *Graph) CriticalPath(weight func(uint) int) ([]uint, error) {
+	order, err := g.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	// best is the weight of the heaviest chain ending at each task, and via the previous task of that chain
+	best := map[uint]int{}
+	via := map[uint]uint{}
+	var last uint
+	found := false
+	for _, node := range order {
+		best[node] = weight(node)
+		for _, pred := range g.preds[node] {
+			if candidate := best[pred] + weight(node); candidate > best[node] {
+				best[node] = candidate
+				via[node] = pred
+			}
+		}
+		if !found || best[node] > best[last] {
+			last, found = node, true
+		}
+	}
+	if !found || best[last] <= 0 {
+		return []uint{}, nil
+	}
+
+	path := []uint{last}
+	for {
+		pred, ok := via[path[0]]
+		if !ok {
+			break
+		}
+		path = append([]uint{pred}, path...)
+	}
+	return path, nil
+}
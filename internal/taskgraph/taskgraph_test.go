@@ -0,0 +1,67 @@
+package taskgraph
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	// 1 <- 3 <- 4, 2 <- 4, and 5 on its own; edges to 9 are outside the graph
+	g := New([]uint{5, 4, 3, 2, 1, 4}, []Edge{{3, 1}, {4, 3}, {4, 2}, {4, 9}, {3, 1}})
+
+	order, err := g.Sort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{1, 2, 3, 4, 5}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("Sort() = %v, want %v", order, want)
+	}
+}
+
+func TestSortCycle(t *testing.T) {
+	g := New([]uint{1, 2, 3}, []Edge{{1, 2}, {2, 3}, {3, 1}})
+	if _, err := g.Sort(); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle, got %v", err)
+	}
+	if _, err := g.CriticalPath(func(uint) int { return 1 }); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle, got %v", err)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	// Two chains lead to 5: 1 -> 2 -> 5 and 3 -> 4 -> 6 -> 5
+	g := New([]uint{1, 2, 3, 4, 5, 6}, []Edge{{2, 1}, {5, 2}, {4, 3}, {6, 4}, {5, 6}})
+
+	tests := []struct {
+		name   string
+		weight func(uint) int
+		want   []uint
+	}{
+		{"unit weights", func(uint) int { return 1 }, []uint{3, 4, 6, 5}},
+		{"finished tasks weigh nothing", func(id uint) int {
+			if id == 3 || id == 4 || id == 6 {
+				return 0
+			}
+			return 1
+		}, []uint{1, 2, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := g.CriticalPath(tt.weight)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(path, tt.want) {
+				t.Fatalf("CriticalPath() = %v, want %v", path, tt.want)
+			}
+		})
+	}
+
+	if path, err := New(nil, nil).CriticalPath(func(uint) int { return 1 }); err != nil || len(path) != 0 {
+		t.Fatalf("expected an empty path for an empty graph, got %v, %v", path, err)
+	}
+	if path, err := g.CriticalPath(func(uint) int { return 0 }); err != nil || len(path) != 0 {
+		t.Fatalf("expected an empty path when nothing is left to do, got %v, %v", path, err)
+	}
+}
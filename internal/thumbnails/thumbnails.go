@@ -0,0 +1,167 @@
+// Package thumbnails generates the previews of image attachments in the background.
+//
+// JPEG, PNG and GIF images are decoded with the standard library, turned upright according to their
+// EXIF orientation and scaled down to fit each configured size. The thumbnails are re-encoded from
+// their pixels alone, so they never carry the original's metadata, such as the location a photo was taken at.
+package thumbnails
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+)
+
+// DefaultSizes are the sizes, in pixels, of the squares thumbnails are fitted into when none are configured.
+var DefaultSizes = []int{128, 512}
+
+// queueSize is how many attachments can wait for their thumbnails before Enqueue stops accepting them.
+const queueSize = 256
+
+// Worker generates the thumbnails of the attachments handed to Enqueue, in background goroutines.
+// Attachments that couldn't be queued stay pending and are picked up the next time the worker starts.
+type Worker struct {
+	attachments repository.AttachmentRepository
+	blobs       storage.BlobStore
+	log         *log.Logger
+	sizes       []int
+
+	queue   chan models.Attachment
+	pending sync.WaitGroup
+}
+
+// NewWorker returns a Worker storing the thumbnails of each size next to the originals in the blob store.
+// Without sizes, DefaultSizes are used.
+func NewWorker(attachments repository.AttachmentRepository, blobs storage.BlobStore, logger *log.Logger, sizes []int) *Worker {
+	if len(sizes) == 0 {
+		sizes = DefaultSizes
+	}
+	sizes = append([]int(nil), sizes...)
+	sort.Ints(sizes)
+
+	return &Worker{
+		attachments: attachments,
+		blobs:       blobs,
+		log:         logger,
+		sizes:       sizes,
+		queue:       make(chan models.Attachment, queueSize),
+	}
+}
+
+// Sizes returns the sizes thumbnails are generated at, smallest first.
+func (w *Worker) Sizes() []int {
+	return append([]int(nil), w.sizes...)
+}
+
+// Start runs the given number of goroutines generating thumbnails until the context is cancelled,
+// and queues the attachments left pending by a previous run.
+func (w *Worker) Start(ctx context.Context, workers int) {
+	for i := 0; i < max(1, workers); i++ {
+		go w.run(ctx)
+	}
+
+	pending, err := w.attachments.FindByThumbnailStatus(ctx, models.ThumbnailPending)
+	if err != nil {
+		w.log.Error("Erro ao buscar anexos sem miniaturas", "error", err)
+		return
+	}
+	for _, attachment := range pending {
+		w.Enqueue(attachment)
+	}
+}
+
+// Enqueue schedules the generation of the thumbnails of the attachment, without waiting for it.
+// It reports false when the queue is full, in which case the attachment stays pending.
+func (w *Worker) Enqueue(attachment models.Attachment) bool {
+	w.pending.Add(1)
+	select {
+	case w.queue <- attachment:
+		return true
+	default:
+		w.pending.Done()
+		w.log.Warn("Fila de miniaturas cheia", "attachment_id", attachment.ID)
+		return false
+	}
+}
+
+// Wait blocks until every queued attachment was processed.
+func (w *Worker) Wait() {
+	w.pending.Wait()
+}
+
+func (w *Worker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case attachment := <-w.queue:
+			w.process(ctx, attachment)
+			w.pending.Done()
+		}
+	}
+}
+
+// process generates the thumbnails of the attachment and records the outcome in its ThumbnailStatus.
+func (w *Worker) process(ctx context.Context, attachment models.Attachment) {
+	logger := w.log.With("attachment_id", attachment.ID)
+
+	status := models.ThumbnailReady
+	if err := w.Generate(ctx, attachment); err != nil {
+		logger.Error("Erro ao gerar miniaturas", "error", err)
+		status = models.ThumbnailFailed
+	}
+
+	if err := w.attachments.SetThumbnailStatus(ctx, attachment.ID, status); err != nil {
+		logger.Error("Erro ao salvar o estado das miniaturas", "error", err)
+	}
+}
+
+// Generate decodes the attachment and stores its thumbnail at every size, synchronously.
+func (w *Worker) Generate(ctx context.Context, attachment models.Attachment) error {
+	if !Supported(attachment.ContentType) {
+		return ErrUnsupported
+	}
+
+	blob, err := w.blobs.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(blob)
+	blob.Close()
+	if err != nil {
+		return err
+	}
+
+	img, err := decode(data, attachment.ContentType)
+	if err != nil {
+		return err
+	}
+
+	contentType := ContentType(attachment.ContentType)
+	for _, size := range w.sizes {
+		var buf bytes.Buffer
+		if err := encode(&buf, resize(img, size), attachment.ContentType); err != nil {
+			return err
+		}
+		if err := w.blobs.Put(ctx, attachment.ThumbnailKey(size), &buf, int64(buf.Len()), contentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the thumbnails of the attachment from the blob store.
+func (w *Worker) Delete(ctx context.Context, attachment models.Attachment) error {
+	for _, size := range w.sizes {
+		if err := w.blobs.Delete(ctx, attachment.ThumbnailKey(size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package thumbnails
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+)
+
+// halfRed returns a width×height image whose left half is red and right half is blue.
+func halfRed(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{B: 255, A: 255}
+			if x < width/2 {
+				c = color.NRGBA{R: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment holding the orientation right after the start of the JPEG.
+func withOrientation(jpegData []byte, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientationTag, 3})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var out bytes.Buffer
+	out.Write(jpegData[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(&out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+	out.Write(jpegData[2:])
+	return out.Bytes()
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, halfRed(8, 4), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := jpegOrientation(bytes.NewReader(buf.Bytes())); got != 1 {
+		t.Fatalf("expected JPEGs without EXIF to be upright, got %d", got)
+	}
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		if got := jpegOrientation(bytes.NewReader(withOrientation(buf.Bytes(), orientation))); got != int(orientation) {
+			t.Errorf("expected orientation %d, got %d", orientation, got)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	img := halfRed(4, 2)
+
+	rotated := orient(img, 6)
+	if rotated.Bounds().Dx() != 2 || rotated.Bounds().Dy() != 4 {
+		t.Fatalf("expected a 2x4 image, got %v", rotated.Bounds())
+	}
+	// Turning the image clockwise brings its left half to the top
+	if r, _, _, _ := rotated.At(0, 0).RGBA(); r == 0 {
+		t.Fatal("expected the top of the rotated image to be red")
+	}
+	if _, _, b, _ := rotated.At(0, 3).RGBA(); b == 0 {
+		t.Fatal("expected the bottom of the rotated image to be blue")
+	}
+
+	mirrored := orient(img, 2)
+	if _, _, b, _ := mirrored.At(0, 0).RGBA(); b == 0 {
+		t.Fatal("expected the left of the mirrored image to be blue")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachments := repository.NewMemoryAttachmentRepository()
+	worker := NewWorker(attachments, blobs, log.New(io.Discard), []int{16, 64})
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, halfRed(100, 50), nil); err != nil {
+		t.Fatal(err)
+	}
+	photo := withOrientation(buf.Bytes(), 6)
+
+	attachment := models.Attachment{UploaderID: "u1", TaskID: 1, FileName: "photo.jpg", ContentType: "image/jpeg", Size: int64(len(photo)), StorageKey: "photo", ThumbnailStatus: models.ThumbnailPending}
+	if err := attachments.Create(ctx, &attachment); err != nil {
+		t.Fatal(err)
+	}
+	if err := blobs.Put(ctx, attachment.StorageKey, bytes.NewReader(photo), int64(len(photo)), attachment.ContentType); err != nil {
+		t.Fatal(err)
+	}
+
+	worker.Start(ctx, 1)
+	worker.Wait()
+
+	stored, err := attachments.FindByID(ctx, attachment.ID)
+	if err != nil || stored.ThumbnailStatus != models.ThumbnailReady {
+		t.Fatalf("expected the thumbnails to be ready, got %+v (%v)", stored, err)
+	}
+
+	for size, want := range map[int]image.Point{16: {8, 16}, 64: {32, 64}} {
+		blob, err := blobs.Open(ctx, attachment.ThumbnailKey(size))
+		if err != nil {
+			t.Fatalf("opening the %d thumbnail: %v", size, err)
+		}
+		data, _ := io.ReadAll(blob)
+		blob.Close()
+
+		if bytes.Contains(data, []byte("Exif")) {
+			t.Errorf("expected the %d thumbnail to carry no EXIF data", size)
+		}
+		thumb, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("decoding the %d thumbnail: %v", size, err)
+		}
+		if got := thumb.Bounds().Size(); got != want {
+			t.Errorf("expected the %d thumbnail to be %v, got %v", size, want, got)
+		}
+	}
+}
+
+func TestGenerateRejectsInvalidImages(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachments := repository.NewMemoryAttachmentRepository()
+	worker := NewWorker(attachments, blobs, log.New(io.Discard), nil)
+
+	var buf bytes.Buffer
+	png.Encode(&buf, halfRed(4, 4))
+	broken := buf.Bytes()[:20]
+
+	attachment := models.Attachment{UploaderID: "u1", TaskID: 1, FileName: "broken.png", ContentType: "image/png", Size: int64(len(broken)), StorageKey: "broken", ThumbnailStatus: models.ThumbnailPending}
+	attachments.Create(ctx, &attachment)
+	blobs.Put(ctx, attachment.StorageKey, bytes.NewReader(broken), int64(len(broken)), attachment.ContentType)
+
+	worker.Start(ctx, 1)
+	worker.Wait()
+
+	stored, _ := attachments.FindByID(ctx, attachment.ID)
+	if stored.ThumbnailStatus != models.ThumbnailFailed {
+		t.Fatalf("expected the thumbnails to have failed, got %q", stored.ThumbnailStatus)
+	}
+}
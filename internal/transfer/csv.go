@@ -0,0 +1,211 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// Columns are the fields of the tasks CSV columns can be mapped onto, in the order Export writes them.
+// Without a mapping, each field is read from the column with its name. Labels are separated by commas.
+var Columns = []string{"id", "parent_id", "title", "description", "status", "priority", "due_date", "reminder", "recurrence", "completed_at", "labels", "notes"}
+
+// CheckMapping returns an error when the mapping maps a field that isn't one of the Columns, or maps none to title
+// while mapping others.
+func CheckMapping(mapping map[string]string) error {
+	for field, column := range mapping {
+		known := false
+		for _, name := range Columns {
+			known = known || name == field
+		}
+		if !known {
+			return errors.New("Campo desconhecido no mapeamento: " + field)
+		}
+		if strings.TrimSpace(column) == "" {
+			return errors.New("Coluna vazia no mapeamento do campo " + field)
+		}
+	}
+	if _, ok := mapping["title"]; len(mapping) > 0 && !ok {
+		return errors.New("O mapeamento deve incluir o campo title")
+	}
+	return nil
+}
+
+// parseCSV reads the rows of a CSV file. Rows without an id column get a Ref derived from their contents,
+// so importing the same file again matches the rows that didn't change.
+func parseCSV(data []byte, mapping map[string]string, loc *time.Location) ([]Row, error) {
+	if err := CheckMapping(mapping); err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("Arquivo CSV vazio")
+		}
+		return nil, fmt.Errorf("Arquivo CSV inválido: %v", err)
+	}
+
+	// index finds the column of each field, by the name it's mapped to, or by its own name
+	index := map[string]int{}
+	for _, field := range Columns {
+		name, mapped := mapping[field]
+		if !mapped {
+			if len(mapping) > 0 {
+				continue
+			}
+			name = field
+		}
+		for i, column := range header {
+			if columnName(column) == columnName(name) {
+				index[field] = i
+				break
+			}
+		}
+		if _, ok := index[field]; !ok && mapped {
+			return nil, errors.New("Coluna não encontrada: " + name)
+		}
+	}
+	if _, ok := index["title"]; !ok {
+		return nil, errors.New("Coluna title não encontrada, mapeie o título das tarefas")
+	}
+
+	var rows []Row
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, Row{Line: parseErr.StartLine, Err: fmt.Errorf("Linha inválida: %v", parseErr.Err)})
+				continue
+			}
+			return nil, fmt.Errorf("Arquivo CSV inválido: %v", err)
+		}
+		if strings.TrimSpace(strings.Join(values, "")) == "" {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		value := func(field string) string {
+			if i, ok := index[field]; ok && i < len(values) {
+				return strings.TrimSpace(values[i])
+			}
+			return ""
+		}
+		record, err := csvRecord(value, loc)
+		if record.Ref == "" {
+			record.Ref = contentRef([]byte(strings.Join(values, "\x1f")))
+		}
+		rows = append(rows, Row{Line: line, Record: record, Err: err})
+	}
+	return rows, nil
+}
+
+// columnName returns the name of a column as it's compared, ignoring case and taking spaces and hyphens for underscores,
+// so "Due Date" is the column of due_date.
+func columnName(name string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// contentRef returns the Ref of a row without an ID, derived from its contents.
+func contentRef(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return "row-" + hex.EncodeToString(sum[:12])
+}
+
+// csvRecord reads the record of a row, given the value of each of its fields.
+func csvRecord(value func(field string) string, loc *time.Location) (Record, error) {
+	record := Record{
+		Ref:         value("id"),
+		ParentRef:   value("parent_id"),
+		Title:       value("title"),
+		Description: optional(value("description")),
+		Notes:       optional(value("notes")),
+		Recurrence:  optional(value("recurrence")),
+		Status:      strings.ToLower(value("status")),
+	}
+	for _, label := range strings.Split(value("labels"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			record.Labels = append(record.Labels, label)
+		}
+	}
+
+	var err error
+	if record.Priority, err = parsePriority(value("priority")); err != nil {
+		return record, err
+	}
+	if record.DueDate, err = parseTime(value("due_date"), loc); err != nil {
+		return record, err
+	}
+	if record.Reminder, err = parseTime(value("reminder"), loc); err != nil {
+		return record, err
+	}
+	if record.CompletedAt, err = parseTime(value("completed_at"), loc); err != nil {
+		return record, err
+	}
+	if record.Status == "" && record.CompletedAt != nil {
+		record.Status = models.TaskDone
+	}
+	return record, nil
+}
+
+// WriteCSV writes the tasks of the document as a CSV file with a column for each of the Columns.
+func WriteCSV(w io.Writer, document *Document) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(Columns); err != nil {
+		return err
+	}
+
+	text := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	stamp := func(value *time.Time) string {
+		if value == nil {
+			return ""
+		}
+		return value.UTC().Format(time.RFC3339)
+	}
+	for _, task := range document.Tasks {
+		parent := ""
+		if task.ParentID != nil {
+			parent = strconv.FormatUint(uint64(*task.ParentID), 10)
+		}
+		err := writer.Write([]string{
+			strconv.FormatUint(uint64(task.ID), 10),
+			parent,
+			task.Title,
+			text(task.Description),
+			task.Status,
+			strconv.Itoa(task.Priority),
+			stamp(task.DueDate),
+			stamp(task.Reminder),
+			text(task.Recurrence),
+			stamp(task.CompletedAt),
+			strings.Join(task.Labels, ", "),
+			text(task.Notes),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
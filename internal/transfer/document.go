@@ -0,0 +1,111 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// DocumentVersion is the version of the NullTask JSON documents written by Export.
+const DocumentVersion = 1
+
+// Document is the NullTask JSON document of the tasks of a user.
+type Document struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Tasks      []DocumentTask `json:"tasks"`
+}
+
+// DocumentTask is a task in a Document, along with the names of its labels and its checklist.
+type DocumentTask struct {
+	ID          uint             `json:"id"`
+	ParentID    *uint            `json:"parent_id,omitempty"`
+	Title       string           `json:"title"`
+	Description *string          `json:"description,omitempty"`
+	Notes       *string          `json:"notes,omitempty"`
+	Status      string           `json:"status"`
+	Priority    int              `json:"priority"`
+	DueDate     *time.Time       `json:"due_date,omitempty"`
+	Reminder    *time.Time       `json:"reminder,omitempty"`
+	Recurrence  *string          `json:"recurrence,omitempty"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Labels      []string         `json:"labels"`
+	Checklist   []ChecklistEntry `json:"checklist"`
+}
+
+// Export returns the document of the tasks, in the given order, with the labels and the checklist of each one, by task ID.
+func Export(tasks []models.Tasks, labels map[uint][]models.Label, checklists map[uint][]models.ChecklistItem) *Document {
+	document := &Document{Version: DocumentVersion, ExportedAt: time.Now().UTC(), Tasks: make([]DocumentTask, 0, len(tasks))}
+	for _, task := range tasks {
+		entry := DocumentTask{
+			ID:          task.ID,
+			ParentID:    task.ParentID,
+			Title:       task.Title,
+			Description: task.Description,
+			Notes:       task.Notes,
+			Status:      task.Status,
+			Priority:    task.Priority,
+			DueDate:     task.DueDate,
+			Reminder:    task.Reminder,
+			Recurrence:  task.Recurrence,
+			CompletedAt: task.CompletedAt,
+			CreatedAt:   task.CreatedAt,
+			Labels:      []string{},
+			Checklist:   []ChecklistEntry{},
+		}
+		for _, label := range labels[task.ID] {
+			entry.Labels = append(entry.Labels, label.Name)
+		}
+		for _, item := range checklists[task.ID] {
+			entry.Checklist = append(entry.Checklist, ChecklistEntry{Title: item.Title, Done: item.Done})
+		}
+		document.Tasks = append(document.Tasks, entry)
+	}
+	return document
+}
+
+// parseDocument reads the rows of a NullTask JSON document. Its tasks keep their IDs as their Ref.
+func parseDocument(data []byte, _ *time.Location) ([]Row, error) {
+	var document Document
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("Documento JSON inválido: %v", err)
+	}
+	if document.Version > DocumentVersion {
+		return nil, fmt.Errorf("Versão do documento não suportada: %d", document.Version)
+	}
+
+	rows := make([]Row, 0, len(document.Tasks))
+	for i, task := range document.Tasks {
+		record := Record{
+			Title:       task.Title,
+			Description: task.Description,
+			Notes:       task.Notes,
+			Status:      task.Status,
+			Priority:    task.Priority,
+			DueDate:     task.DueDate,
+			Reminder:    task.Reminder,
+			Recurrence:  task.Recurrence,
+			CompletedAt: task.CompletedAt,
+			Labels:      task.Labels,
+			Checklist:   task.Checklist,
+		}
+		if task.ID != 0 {
+			record.Ref = strconv.FormatUint(uint64(task.ID), 10)
+		} else {
+			// Tasks written by hand may have no ID, they are told apart by their contents as the rows of CSV files are
+			encoded, _ := json.Marshal(task)
+			record.Ref = contentRef(encoded)
+		}
+		if task.ParentID != nil {
+			record.ParentRef = strconv.FormatUint(uint64(*task.ParentID), 10)
+		}
+		rows = append(rows, Row{Line: i + 1, Record: record})
+	}
+	return rows, nil
+}
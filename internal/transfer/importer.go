@@ -0,0 +1,499 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/rank"
+	"github.com/devgugga/NullTask/internal/repository"
+)
+
+// queueSize is how many jobs can wait for the importer before Enqueue stops accepting them.
+const queueSize = 64
+
+// maxChecklistTitle is the longest title, in characters, of a checklist item.
+const maxChecklistTitle = 500
+
+// Importer imports the jobs handed to Enqueue into personal tasks of their users, in background goroutines.
+// Each row is matched with the task it was imported into before, which it updates rather than creating another one,
+// so importing a file again is safe. Jobs that couldn't be queued stay pending and are picked up the next time
+// the importer starts, along with the ones a restart interrupted.
+type Importer struct {
+	imports repository.ImportRepository
+	tasks   repository.TaskRepository
+	labels  repository.LabelRepository
+	users   repository.UserRepository
+	log     *log.Logger
+
+	queue   chan uint
+	pending sync.WaitGroup
+}
+
+// NewImporter returns an Importer saving the tasks, their labels and checklists through the repositories.
+func NewImporter(imports repository.ImportRepository, tasks repository.TaskRepository, labels repository.LabelRepository, users repository.UserRepository, logger *log.Logger) *Importer {
+	return &Importer{
+		imports: imports,
+		tasks:   tasks,
+		labels:  labels,
+		users:   users,
+		log:     logger,
+		queue:   make(chan uint, queueSize),
+	}
+}
+
+// Start runs the given number of goroutines importing jobs until the context is cancelled,
+// and queues the jobs left pending or interrupted by a previous run.
+func (i *Importer) Start(ctx context.Context, workers int) {
+	for n := 0; n < max(1, workers); n++ {
+		go i.run(ctx)
+	}
+
+	for _, status := range []models.ImportStatus{models.ImportRunning, models.ImportPending} {
+		jobs, err := i.imports.FindByStatus(ctx, status)
+		if err != nil {
+			i.log.Error("Erro ao buscar importações pendentes", "error", err)
+			return
+		}
+		for _, job := range jobs {
+			i.Enqueue(job)
+		}
+	}
+}
+
+// Enqueue schedules the import of the job, without waiting for it.
+// It reports false when the queue is full, in which case the job stays pending.
+func (i *Importer) Enqueue(job models.ImportJob) bool {
+	i.pending.Add(1)
+	select {
+	case i.queue <- job.ID:
+		return true
+	default:
+		i.pending.Done()
+		i.log.Warn("Fila de importações cheia", "import_id", job.ID)
+		return false
+	}
+}
+
+// Wait blocks until every queued job was imported.
+func (i *Importer) Wait() {
+	i.pending.Wait()
+}
+
+func (i *Importer) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-i.queue:
+			job, err := i.imports.FindByID(ctx, id)
+			if err == nil {
+				err = i.Run(ctx, job)
+			}
+			if err != nil {
+				i.log.Error("Erro ao importar tarefas", "import_id", id, "error", err)
+			}
+			i.pending.Done()
+		}
+	}
+}
+
+// Run imports the job synchronously, recording what happened to each of its rows in the job.
+// Dry runs go through the same steps without saving anything but the job.
+// The returned error means the job itself couldn't be saved, the failures of its rows are recorded in it.
+func (i *Importer) Run(ctx context.Context, job *models.ImportJob) error {
+	job.Status = models.ImportRunning
+	job.Rows, job.Error = nil, ""
+	job.Total, job.Created, job.Updated, job.Skipped, job.Failed = 0, 0, 0, 0, 0
+	if err := i.imports.Update(ctx, job); err != nil {
+		return err
+	}
+
+	preferences, err := i.users.FindPreferences(ctx, job.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		preferences, err = models.DefaultPreferences(job.UserID), nil
+	}
+	if err == nil {
+		var rows []Row
+		if rows, err = Parse(Format(job.Format), job.Data, job.Mapping, preferences.Location()); err != nil {
+			job.Status, job.Error = models.ImportFailed, err.Error()
+		} else {
+			run := &importRun{Importer: i, ctx: ctx, job: job, preferences: preferences, refs: map[string]uint{}, ranks: map[string]string{}}
+			err = run.rows(rows)
+		}
+	}
+	if err != nil {
+		i.log.Error("Erro ao importar tarefas", "import_id", job.ID, "error", err)
+		job.Status, job.Error = models.ImportFailed, "Erro ao importar tarefas"
+	} else if job.Status == models.ImportRunning {
+		job.Status = models.ImportCompleted
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Data = nil
+	return i.imports.Update(ctx, job)
+}
+
+// importRun is the import of a job.
+type importRun struct {
+	*Importer
+	ctx         context.Context
+	job         *models.ImportJob
+	preferences *models.Preferences
+
+	// refs are the tasks the rows were imported into, by Ref
+	refs map[string]uint
+	// labels are the personal labels of the user, by lowercase name, loaded on first use
+	labels map[string]models.Label
+	// ranks are the ranks of the last tasks of the board columns, by status, loaded on first use
+	ranks map[string]string
+}
+
+// rows imports the rows and then links the subtasks to their parents, which may come later in the file.
+// The returned error means the import can't go on.
+func (r *importRun) rows(rows []Row) error {
+	refs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Err == nil && row.Skip == "" {
+			refs = append(refs, row.Record.Ref)
+		}
+	}
+	previous, err := r.imported(refs)
+	if err != nil {
+		return err
+	}
+
+	results := make([]models.ImportRow, len(rows))
+	for n, row := range rows {
+		result := &results[n]
+		*result = models.ImportRow{Row: row.Line, Ref: row.Record.Ref, Title: row.Record.Title}
+		_, repeated := r.refs[row.Record.Ref]
+		switch {
+		case row.Err != nil:
+			result.Action, result.Error = models.ImportError, row.Err.Error()
+		case row.Skip != "":
+			result.Action, result.Note = models.ImportSkip, row.Skip
+		case repeated:
+			result.Action, result.Error = models.ImportError, "Identificador repetido no arquivo: "+row.Record.Ref
+		default:
+			r.row(&row.Record, previous[row.Record.Ref], result)
+		}
+	}
+
+	parents := map[string]bool{}
+	for n, row := range rows {
+		if row.Record.ParentRef != "" && results[n].TaskID != nil {
+			if _, ok := r.refs[row.Record.ParentRef]; !ok {
+				parents[row.Record.ParentRef] = true
+			}
+		}
+	}
+	others := make([]string, 0, len(parents))
+	for ref := range parents {
+		others = append(others, ref)
+	}
+	earlier, err := r.imported(others)
+	if err != nil {
+		return err
+	}
+	for ref, task := range earlier {
+		r.refs[ref] = task.ID
+	}
+	for n, row := range rows {
+		if row.Record.ParentRef != "" && (results[n].Action == models.ImportCreate || results[n].Action == models.ImportUpdate) {
+			r.parent(&results[n], row.Record.ParentRef)
+		}
+	}
+
+	for _, result := range results {
+		switch result.Action {
+		case models.ImportCreate:
+			r.job.Created++
+		case models.ImportUpdate:
+			r.job.Updated++
+		case models.ImportSkip:
+			r.job.Skipped++
+		case models.ImportError:
+			r.job.Failed++
+		}
+	}
+	r.job.Total, r.job.Rows = len(rows), results
+	return nil
+}
+
+// imported returns the tasks of the user the rows with the given refs were imported into before, by ref.
+// Rows of the files NullTask wrote are also matched with the tasks of the user they were exported from,
+// so importing a file where it came from updates its tasks.
+func (r *importRun) imported(refs []string) (map[string]*models.Tasks, error) {
+	tasks := map[string]*models.Tasks{}
+	imported, err := r.imports.FindImported(r.ctx, r.job.UserID, r.job.Format, refs)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]uint{}
+	for _, entry := range imported {
+		ids[entry.ExternalID] = entry.TaskID
+	}
+	if Format(r.job.Format).keepsIDs() {
+		for _, ref := range refs {
+			if _, ok := ids[ref]; !ok {
+				if id, err := strconv.ParseUint(ref, 10, 0); err == nil {
+					ids[ref] = uint(id)
+				}
+			}
+		}
+	}
+
+	for ref, id := range ids {
+		task, err := r.tasks.FindByID(r.ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Tasks moved to a workspace or deleted since then are imported again
+		if task.UserID == r.job.UserID && task.WorkspaceID == nil {
+			tasks[ref] = task
+		}
+	}
+	return tasks, nil
+}
+
+// row imports a record into a new task, or into the task it was imported into before, recording the outcome in result.
+func (r *importRun) row(record *Record, task *models.Tasks, result *models.ImportRow) {
+	result.Action = models.ImportCreate
+	if task != nil {
+		result.Action = models.ImportUpdate
+		id := task.ID
+		result.TaskID = &id
+	}
+	if r.job.DryRun {
+		// Every row the dry run went through is taken, as the real import would, for the checks of the rows after it
+		r.refs[record.Ref] = 0
+		if task != nil {
+			r.refs[record.Ref] = task.ID
+		}
+		return
+	}
+
+	var err error
+	if task == nil {
+		task, err = r.create(record)
+	} else {
+		err = r.update(task, record)
+	}
+	if err != nil {
+		r.log.Error("Erro ao importar a tarefa", "import_id", r.job.ID, "ref", record.Ref, "error", err)
+		result.Action, result.Error, result.TaskID = models.ImportError, "Erro ao salvar a tarefa", nil
+		return
+	}
+	id := task.ID
+	result.TaskID = &id
+	r.refs[record.Ref] = task.ID
+
+	imported := &models.ImportedTask{UserID: r.job.UserID, Source: r.job.Format, ExternalID: record.Ref, TaskID: task.ID}
+	if err := r.imports.SaveImported(r.ctx, imported); err != nil {
+		r.log.Error("Erro ao salvar a tarefa importada", "import_id", r.job.ID, "ref", record.Ref, "error", err)
+	}
+	if err := r.tag(task, record.Labels); err != nil {
+		r.log.Error("Erro ao etiquetar a tarefa importada", "import_id", r.job.ID, "ref", record.Ref, "error", err)
+		result.Note = "Etiquetas não importadas"
+	}
+}
+
+// create saves a new personal task for the record, with its checklist.
+func (r *importRun) create(record *Record) (*models.Tasks, error) {
+	task := &models.Tasks{UserID: r.job.UserID}
+	apply(task, record)
+
+	// Remind the task as the owner prefers when it's due without a reminder of its own
+	if task.DueDate != nil && task.Reminder == nil && r.preferences.DefaultReminder != nil {
+		reminder := task.DueDate.Add(-time.Duration(*r.preferences.DefaultReminder) * time.Minute)
+		task.Reminder = &reminder
+	}
+	if err := r.place(task, record); err != nil {
+		return nil, err
+	}
+	if err := r.tasks.Create(r.ctx, task); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range record.Checklist {
+		title := strings.TrimSpace(entry.Title)
+		if runes := []rune(title); len(runes) > maxChecklistTitle {
+			title = string(runes[:maxChecklistTitle])
+		}
+		if title == "" {
+			continue
+		}
+		if err := r.tasks.AddChecklistItem(r.ctx, &models.ChecklistItem{TaskID: task.ID, Title: title, Done: entry.Done}); err != nil {
+			return task, err
+		}
+	}
+	return task, nil
+}
+
+// update saves the fields of the record into the task it was imported into before. The checklist is left as it is,
+// since the user may have changed it since then.
+func (r *importRun) update(task *models.Tasks, record *Record) error {
+	apply(task, record)
+	if err := r.place(task, record); err != nil {
+		return err
+	}
+	return r.tasks.Update(r.ctx, task)
+}
+
+// apply copies the fields of the record into the task. Reminders are only replaced by the ones of the record,
+// and so are the description, notes and recurrence of partial records.
+func apply(task *models.Tasks, record *Record) {
+	task.Title = record.Title
+	task.Priority = record.Priority
+	task.DueDate = record.DueDate
+	if !record.Partial || record.Description != nil {
+		task.Description = record.Description
+	}
+	if !record.Partial || record.Notes != nil {
+		task.Notes = record.Notes
+	}
+	if !record.Partial || record.Recurrence != nil {
+		task.Recurrence = record.Recurrence
+	}
+	if record.Reminder != nil {
+		task.Reminder = record.Reminder
+	}
+}
+
+// place moves the task to the status of the record, at the end of its board column, keeping CompletedAt in step.
+// Records without a status leave the status of existing tasks as it is, unless they tell whether the task is Done,
+// and put new ones in the initial status.
+func (r *importRun) place(task *models.Tasks, record *Record) error {
+	workflow := models.DefaultWorkflow()
+	status := record.Status
+	if status == "" {
+		status = task.Status
+	}
+	if status == "" {
+		status = workflow.Initial
+	}
+	if current, _ := workflow.Status(status); record.Status == "" && record.Done != nil && *record.Done != current.Terminal {
+		status = workflow.Initial
+		if *record.Done {
+			status = models.TaskDone
+		}
+	}
+
+	if current, _ := workflow.Status(status); current.Terminal {
+		if task.CompletedAt == nil {
+			completed := time.Now()
+			if record.CompletedAt != nil {
+				completed = *record.CompletedAt
+			}
+			task.CompletedAt = &completed
+		}
+	} else {
+		task.CompletedAt = nil
+	}
+	if status == task.Status {
+		return nil
+	}
+
+	last, ok := r.ranks[status]
+	if !ok {
+		column, err := r.tasks.FindColumn(r.ctx, r.job.UserID, nil, status)
+		if err != nil {
+			return err
+		}
+		if len(column) > 0 {
+			last = column[len(column)-1].Rank
+		}
+	}
+	key, err := rank.Between(last, "")
+	if err != nil {
+		return err
+	}
+	task.Status, task.Rank, r.ranks[status] = status, key, key
+	return nil
+}
+
+// tag tags the task with the personal labels of the user with the given names, creating the ones they don't have.
+func (r *importRun) tag(task *models.Tasks, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if r.labels == nil {
+		labels, err := r.Importer.labels.FindByUser(r.ctx, r.job.UserID)
+		if err != nil {
+			return err
+		}
+		r.labels = map[string]models.Label{}
+		for _, label := range labels {
+			r.labels[strings.ToLower(label.Name)] = label
+		}
+	}
+
+	for _, name := range names {
+		label, ok := r.labels[strings.ToLower(name)]
+		if !ok {
+			label = models.Label{Name: name, Color: models.DefaultLabelColor, UserID: r.job.UserID}
+			if err := r.Importer.labels.Create(r.ctx, &label); err != nil {
+				return err
+			}
+			r.labels[strings.ToLower(name)] = label
+		}
+		if err := r.Importer.labels.Tag(r.ctx, label.ID, []uint{task.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parent makes the task of the result a subtask of the task of the row with the given ref,
+// noting in the result why it couldn't.
+func (r *importRun) parent(result *models.ImportRow, ref string) {
+	parentID, ok := r.refs[ref]
+	if !ok {
+		result.Note = "Tarefa pai não encontrada: " + ref
+		return
+	}
+	if r.job.DryRun {
+		return
+	}
+
+	task, err := r.tasks.FindByID(r.ctx, *result.TaskID)
+	if err != nil {
+		r.log.Error("Erro ao buscar a tarefa importada", "import_id", r.job.ID, "error", err)
+		result.Note = "Tarefa pai não importada"
+		return
+	}
+	if task.ParentID != nil && *task.ParentID == parentID {
+		return
+	}
+
+	// Walk up from the parent, the task must not be one of its ancestors
+	seen := map[uint]bool{}
+	for id := parentID; !seen[id]; {
+		if id == task.ID {
+			result.Note = "A tarefa não pode ser subtarefa de si mesma ou de suas subtarefas"
+			return
+		}
+		seen[id] = true
+		ancestor, err := r.tasks.FindByID(r.ctx, id)
+		if err != nil || ancestor.ParentID == nil {
+			break
+		}
+		id = *ancestor.ParentID
+	}
+
+	task.ParentID = &parentID
+	if err := r.tasks.Update(r.ctx, task); err != nil {
+		r.log.Error("Erro ao salvar a tarefa importada", "import_id", r.job.ID, "error", err)
+		result.Note = "Tarefa pai não importada"
+	}
+}
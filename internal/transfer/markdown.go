@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// markdownItem matches the items of GitHub-style checklists, such as "- [x] Buy milk", along with their indentation.
+	markdownItem = regexp.MustCompile(`^([ \t]*)[-*+] \[([ xX])\](?:[ \t]+(.*))?$`)
+	// markdownComment matches the HTML comments holding the tags of checklist items, such as <!-- nt:12 -->.
+	markdownComment = regexp.MustCompile(`<!--(.*?)-->`)
+)
+
+// parseMarkdown reads the rows of a Markdown file, one per checklist item, leaving out every other line.
+// Checked items are done tasks, and items nested under another one are its subtasks. Items carry the labels and
+// tags of todo.txt, the ID of their task in an <!-- nt: --> comment, and items without one, written by hand,
+// are told apart by their title and the titles of the items they're nested under.
+func parseMarkdown(data []byte, loc *time.Location) ([]Row, error) {
+	// parents are the items the next ones may be nested under, from the outermost
+	type parent struct {
+		indent int
+		ref    string
+	}
+	var parents []parent
+	var rows []Row
+	fenced := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	scanner.Buffer(nil, MaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		match := markdownItem.FindStringSubmatch(text)
+		if fenced || match == nil {
+			continue
+		}
+
+		indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		done := match[2] != " "
+		record := Record{Done: &done, Partial: true}
+
+		// The tags in comments are read along with the visible ones
+		body := match[3]
+		var hidden []string
+		for _, comment := range markdownComment.FindAllStringSubmatch(body, -1) {
+			hidden = append(hidden, strings.Fields(comment[1])...)
+		}
+		words := append(strings.Fields(markdownComment.ReplaceAllString(body, " ")), hidden...)
+		err := todoTags(&record, words, loc)
+
+		if len(parents) > 0 && record.ParentRef == "" {
+			record.ParentRef = parents[len(parents)-1].ref
+		}
+		if record.Ref == "" {
+			record.Ref = contentRef([]byte(record.ParentRef + "\x1f" + record.Title))
+		}
+		parents = append(parents, parent{indent: indent, ref: record.Ref})
+		rows = append(rows, Row{Line: line, Record: record, Err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Arquivo Markdown inválido: %v", err)
+	}
+	return rows, nil
+}
+
+// WriteMarkdown writes the tasks of the document as a GitHub-style Markdown checklist, with the subtasks nested
+// under their tasks and the dates in loc. Each item carries the ID of its task in an HTML comment, so importing
+// the file back updates the tasks. Descriptions, notes, reminders and checklists are left out.
+func WriteMarkdown(w io.Writer, document *Document, loc *time.Location) error {
+	ids := map[uint]bool{}
+	children := map[uint][]DocumentTask{}
+	for _, task := range document.Tasks {
+		ids[task.ID] = true
+	}
+	var roots []DocumentTask
+	for _, task := range document.Tasks {
+		if task.ParentID != nil && ids[*task.ParentID] {
+			children[*task.ParentID] = append(children[*task.ParentID], task)
+		} else {
+			roots = append(roots, task)
+		}
+	}
+
+	buffered := bufio.NewWriter(w)
+	buffered.WriteString("# NullTask\n\n")
+	var write func(tasks []DocumentTask, depth int)
+	write = func(tasks []DocumentTask, depth int) {
+		for _, task := range tasks {
+			box := "[ ]"
+			if task.CompletedAt != nil {
+				box = "[x]"
+			}
+			words := []string{strings.Repeat("  ", depth) + "-", box}
+			words = append(words, strings.Fields(task.Title)...)
+			if letter := priorityLetter(task.Priority); letter != "" {
+				words = append(words, priorityTag+":"+letter)
+			}
+			words = append(words, todoTagsOf(task, loc)...)
+
+			// The IDs are kept out of sight, along with the parents the nesting doesn't tell
+			hidden := []string{idTag + ":" + strconv.FormatUint(uint64(task.ID), 10)}
+			if task.ParentID != nil && !ids[*task.ParentID] {
+				hidden = append(hidden, parentTag+":"+strconv.FormatUint(uint64(*task.ParentID), 10))
+			}
+			buffered.WriteString(strings.Join(words, " ") + " <!-- " + strings.Join(hidden, " ") + " -->\n")
+			write(children[task.ID], depth+1)
+		}
+	}
+	write(roots, 0)
+	return buffered.Flush()
+}
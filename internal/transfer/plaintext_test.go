@@ -0,0 +1,145 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestParseTodoTxt(t *testing.T) {
+	data := "(A) 2024-06-01 Call +Mom about the trip @phone due:2024-07-05 nt:12\n" +
+		"\n" +
+		"x 2024-07-01 2024-06-20 File taxes +money pri:B parent:12 rec:+2w\n" +
+		"Water plants due:2024-07-05T08:30 rec:1b see https://example.com\n" +
+		"Broken due:tomorrow\n"
+	rows := parse(t, TodoTxt, data, nil)
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %+v", rows)
+	}
+
+	call := rows[0]
+	if call.Err != nil || call.Line != 1 || call.Record.Ref != "12" || call.Record.Title != "Call about the trip" {
+		t.Fatalf("unexpected first row %+v", call)
+	}
+	if call.Record.Priority != models.PriorityHigh || call.Record.Done == nil || *call.Record.Done || !call.Record.Partial {
+		t.Fatalf("expected an open task of high priority, got %+v", call.Record)
+	}
+	if strings.Join(call.Record.Labels, "|") != "Mom|phone" {
+		t.Fatalf("expected the labels Mom and phone, got %v", call.Record.Labels)
+	}
+	if due := time.Date(2024, 7, 5, 23, 59, 0, 0, saoPaulo); !call.Record.DueDate.Equal(due) {
+		t.Fatalf("expected the task to be due at %v, got %v", due, call.Record.DueDate)
+	}
+
+	taxes := rows[1].Record
+	if rows[1].Err != nil || rows[1].Line != 3 || taxes.Done == nil || !*taxes.Done || taxes.CompletedAt == nil {
+		t.Fatalf("expected a done task, got %+v", rows[1])
+	}
+	if taxes.Priority != models.PriorityMedium || taxes.ParentRef != "12" || *taxes.Recurrence != "FREQ=WEEKLY;INTERVAL=2" {
+		t.Fatalf("unexpected tags %+v", taxes)
+	}
+	if !strings.HasPrefix(taxes.Ref, "row-") {
+		t.Fatalf("expected a Ref derived from the title, got %q", taxes.Ref)
+	}
+
+	water := rows[2].Record
+	if water.Title != "Water plants see https://example.com" || *water.Recurrence != weekdays {
+		t.Fatalf("unexpected record %+v", water)
+	}
+	if due := time.Date(2024, 7, 5, 8, 30, 0, 0, saoPaulo); !water.DueDate.Equal(due) {
+		t.Fatalf("expected the task to be due at %v, got %v", due, water.DueDate)
+	}
+	if rows[3].Err == nil {
+		t.Fatalf("expected the invalid due date to fail, got %+v", rows[3])
+	}
+
+	// Marking a task done keeps the Ref of lines without an ID
+	again := parse(t, TodoTxt, "x Water plants see https://example.com\n", nil)
+	if again[0].Record.Ref != water.Ref {
+		t.Fatalf("expected the Ref %q, got %q", water.Ref, again[0].Record.Ref)
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	data := "# Trip\n" +
+		"\n" +
+		"Some notes, and a list that isn't a checklist:\n" +
+		"- passport\n" +
+		"- [ ] Book flights +travel due:2024-07-05\n" +
+		"  - [x] Compare prices <!-- nt:7 -->\n" +
+		"    - [ ] Ask Ana\n" +
+		"  - [ ] Pick seats\n" +
+		"```\n" +
+		"- [ ] Not a task\n" +
+		"```\n" +
+		"* [X] Pack <!-- nt:9 parent:3 -->\n" +
+		"- [ ]\n"
+	rows := parse(t, Markdown, data, nil)
+	if len(rows) != 6 {
+		t.Fatalf("expected 6 rows, got %+v", rows)
+	}
+
+	flights, prices, ana, seats, pack := rows[0].Record, rows[1].Record, rows[2].Record, rows[3].Record, rows[4].Record
+	if rows[0].Line != 5 || flights.Title != "Book flights" || *flights.Done || flights.DueDate == nil || flights.Labels[0] != "travel" {
+		t.Fatalf("unexpected first record %+v", rows[0])
+	}
+	if prices.Ref != "7" || !*prices.Done || prices.Title != "Compare prices" || prices.ParentRef != flights.Ref {
+		t.Fatalf("expected a done subtask of the flights, got %+v", prices)
+	}
+	if ana.ParentRef != "7" || seats.ParentRef != flights.Ref || ana.Ref == seats.Ref {
+		t.Fatalf("expected the nesting to give the parents, got %+v and %+v", ana, seats)
+	}
+	if pack.Ref != "9" || pack.ParentRef != "3" || !*pack.Done {
+		t.Fatalf("unexpected record %+v", pack)
+	}
+	if rows[5].Err == nil {
+		t.Fatalf("expected the item without a title to fail, got %+v", rows[5])
+	}
+}
+
+func TestPlainTextRoundTrip(t *testing.T) {
+	due := time.Date(2024, 7, 5, 23, 59, 0, 0, saoPaulo)
+	meeting := time.Date(2024, 7, 5, 14, 30, 0, 0, saoPaulo)
+	completed := time.Date(2024, 7, 1, 10, 0, 0, 0, saoPaulo)
+	weekly, monthly := "FREQ=WEEKLY", "FREQ=MONTHLY;BYMONTHDAY=1"
+	parent := uint(1)
+	tasks := []models.Tasks{
+		{ID: 1, Title: "Plan trip", Status: models.TaskInProgress, Priority: models.PriorityHigh, DueDate: &due, Recurrence: &weekly},
+		{ID: 2, Title: "Book hotel", Status: models.TaskDone, Priority: models.PriorityLow, CompletedAt: &completed, ParentID: &parent, DueDate: &meeting},
+		{ID: 3, Title: "Pay rent", Status: models.TaskPending, Recurrence: &monthly},
+	}
+	labels := map[uint][]models.Label{1: {{Name: "road trip"}}}
+	document := Export(tasks, labels, nil)
+
+	for _, format := range []Format{TodoTxt, Markdown} {
+		var buffer bytes.Buffer
+		write := WriteTodoTxt
+		if format == Markdown {
+			write = WriteMarkdown
+		}
+		if err := write(&buffer, document, saoPaulo); err != nil {
+			t.Fatalf("writing the %s file: %v", format, err)
+		}
+
+		rows := parse(t, format, buffer.String(), nil)
+		if len(rows) != 3 {
+			t.Fatalf("%s: expected 3 rows, got %+v in %q", format, rows, buffer.String())
+		}
+		trip, hotel, rent := rows[0].Record, rows[1].Record, rows[2].Record
+		if trip.Ref != "1" || trip.Title != "Plan trip" || trip.Priority != models.PriorityHigh || *trip.Done || !trip.DueDate.Equal(due) {
+			t.Fatalf("%s: unexpected first record %+v", format, trip)
+		}
+		if *trip.Recurrence != weekly || strings.Join(trip.Labels, "|") != "road_trip" {
+			t.Fatalf("%s: unexpected first record %+v", format, trip)
+		}
+		if hotel.Ref != "2" || hotel.ParentRef != "1" || !*hotel.Done || hotel.Priority != models.PriorityLow || !hotel.DueDate.Equal(meeting) {
+			t.Fatalf("%s: unexpected second record %+v", format, hotel)
+		}
+		if rent.Ref != "3" || rent.Recurrence != nil || rows[2].Err != nil {
+			t.Fatalf("%s: expected the recurrence todo.txt can't tell to be left out, got %+v", format, rent)
+		}
+	}
+}
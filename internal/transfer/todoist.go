@@ -0,0 +1,186 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// flexID is an ID that Todoist writes as a string in its newer APIs and as a number in the older ones.
+type flexID string
+
+func (id *flexID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*id = flexID(text)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*id = flexID(number.String())
+	return nil
+}
+
+// flexBool is a flag that Todoist writes as a boolean in its newer APIs and as 0 or 1 in the older ones.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid flag %s", data)
+	}
+	return nil
+}
+
+// todoistTask is a task of Todoist, as returned by its REST and Sync APIs.
+type todoistTask struct {
+	ID          flexID      `json:"id"`
+	Content     string      `json:"content"`
+	Description string      `json:"description"`
+	Priority    int         `json:"priority"`
+	Labels      []flexID    `json:"labels"`
+	ProjectID   flexID      `json:"project_id"`
+	ParentID    flexID      `json:"parent_id"`
+	Completed   flexBool    `json:"is_completed"`
+	Checked     flexBool    `json:"checked"`
+	CompletedAt string      `json:"completed_at"`
+	Due         *todoistDue `json:"due"`
+}
+
+// todoistDue is the due date of a task of Todoist. Recurring tasks describe how they repeat in String, in natural language.
+type todoistDue struct {
+	Date        string   `json:"date"`
+	Datetime    string   `json:"datetime"`
+	String      string   `json:"string"`
+	IsRecurring flexBool `json:"is_recurring"`
+}
+
+// todoistExport is what the APIs of Todoist return: a list of tasks, or an object holding them
+// under results, items or tasks, along with the projects and labels the Sync API returns.
+type todoistExport struct {
+	Results  []todoistTask `json:"results"`
+	Items    []todoistTask `json:"items"`
+	Tasks    []todoistTask `json:"tasks"`
+	Projects []struct {
+		ID           flexID   `json:"id"`
+		Name         string   `json:"name"`
+		InboxProject flexBool `json:"inbox_project"`
+	} `json:"projects"`
+	Labels []struct {
+		ID   flexID `json:"id"`
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// todoistRules map the simplest recurrences of Todoist onto recurrence rules.
+// Tasks repeating any other way keep the recurrence of Todoist in their notes.
+var todoistRules = map[string]string{
+	"every day": "FREQ=DAILY", "daily": "FREQ=DAILY", "todo dia": "FREQ=DAILY", "todos os dias": "FREQ=DAILY",
+	"every week": "FREQ=WEEKLY", "weekly": "FREQ=WEEKLY", "toda semana": "FREQ=WEEKLY",
+	"every month": "FREQ=MONTHLY", "monthly": "FREQ=MONTHLY", "todo mês": "FREQ=MONTHLY",
+	"every year": "FREQ=YEARLY", "yearly": "FREQ=YEARLY", "todo ano": "FREQ=YEARLY",
+	"every weekday": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "every workday": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+}
+
+// parseTodoist reads the rows of the tasks of Todoist. The project of each task, other than the inbox, becomes a label.
+func parseTodoist(data []byte, loc *time.Location) ([]Row, error) {
+	var export todoistExport
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &export.Tasks); err != nil {
+			return nil, fmt.Errorf("JSON do Todoist inválido: %v", err)
+		}
+	} else if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("JSON do Todoist inválido: %v", err)
+	}
+
+	projects := map[flexID]string{}
+	for _, project := range export.Projects {
+		if !bool(project.InboxProject) && !strings.EqualFold(project.Name, "Inbox") {
+			projects[project.ID] = project.Name
+		}
+	}
+	labels := map[flexID]string{}
+	for _, label := range export.Labels {
+		labels[label.ID] = label.Name
+	}
+
+	tasks := append(append(append([]todoistTask{}, export.Results...), export.Items...), export.Tasks...)
+	rows := make([]Row, 0, len(tasks))
+	for i, task := range tasks {
+		record := Record{
+			Ref:         string(task.ID),
+			ParentRef:   string(task.ParentID),
+			Title:       task.Content,
+			Description: optional(task.Description),
+			Priority:    todoistPriority(task.Priority),
+		}
+		if project, ok := projects[task.ProjectID]; ok {
+			record.Labels = append(record.Labels, project)
+		}
+		for _, label := range task.Labels {
+			// The Sync API refers to labels by their ID, the REST API by their name
+			if name, ok := labels[label]; ok {
+				record.Labels = append(record.Labels, name)
+			} else {
+				record.Labels = append(record.Labels, string(label))
+			}
+		}
+
+		row := Row{Line: i + 1}
+		if task.Due != nil {
+			due := task.Due.Datetime
+			if due == "" {
+				due = task.Due.Date
+			}
+			var err error
+			if record.DueDate, err = parseTime(due, loc); err != nil {
+				row.Err = err
+			}
+			if task.Due.IsRecurring {
+				if rule, ok := todoistRules[strings.ToLower(strings.TrimSpace(task.Due.String))]; ok {
+					record.Recurrence = &rule
+				} else {
+					note := "Recorrência no Todoist: " + task.Due.String
+					record.Notes = &note
+				}
+			}
+		}
+		if task.Completed || task.Checked {
+			record.Status = models.TaskDone
+			if completed, err := parseTime(task.CompletedAt, loc); err == nil {
+				record.CompletedAt = completed
+			}
+		}
+		row.Record = record
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// todoistPriority returns the priority of a task for a priority of Todoist, where 4 is the most urgent and 1 the default.
+func todoistPriority(priority int) int {
+	switch priority {
+	case 4:
+		return models.PriorityHigh
+	case 3:
+		return models.PriorityMedium
+	case 2:
+		return models.PriorityLow
+	}
+	return models.PriorityNone
+}
@@ -0,0 +1,278 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// The tags todo.txt lines and Markdown checklist items carry, besides the labels written as +label or @label.
+const (
+	// idTag holds the ID of the task the line was exported from, which a re-import updates.
+	idTag = "nt"
+	// parentTag holds the ID of the task the task of the line is a subtask of.
+	parentTag = "parent"
+	dueTag    = "due"
+	// priorityTag holds the priority of done tasks, which lose their (A) prefix in todo.txt.
+	priorityTag   = "pri"
+	recurrenceTag = "rec"
+)
+
+var (
+	// todoDate matches the completion and creation dates of todo.txt lines.
+	todoDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	// todoPriority matches the priority of todo.txt lines, such as (A).
+	todoPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+	// todoTag matches the key:value tags of todo.txt, leaving out links, whose values start with //.
+	todoTag = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):([^/\s][^\s]*)$`)
+	// todoRecurrence matches the recurrences of todo.txt, such as rec:1w or rec:+2m.
+	todoRecurrence = regexp.MustCompile(`^\+?(\d*)([dwmyb])$`)
+)
+
+// MaxLineSize is the length, in bytes, of the longest line of the todo.txt and Markdown files.
+const MaxLineSize = 64 << 10
+
+// todoFrequencies map the units of the recurrences of todo.txt onto the frequencies of recurrence rules.
+var todoFrequencies = map[string]string{"d": "DAILY", "w": "WEEKLY", "m": "MONTHLY", "y": "YEARLY"}
+
+// weekdays is the recurrence rule of the tasks repeating every business day.
+const weekdays = "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+
+// parseTodoTxt reads the rows of a todo.txt file, one per line. Completed lines, starting with x, are done tasks,
+// their projects and contexts become labels, and the due:, rec:, pri:, parent: and nt: tags fill the rest of the record.
+// Lines without an nt: tag, written by hand, are told apart by their title.
+func parseTodoTxt(data []byte, loc *time.Location) ([]Row, error) {
+	var rows []Row
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	scanner.Buffer(nil, MaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		record, err := todoRecord(text, loc)
+		rows = append(rows, Row{Line: line, Record: record, Err: err})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Arquivo todo.txt inválido: %v", err)
+	}
+	return rows, nil
+}
+
+// todoRecord reads the record of a todo.txt line.
+func todoRecord(text string, loc *time.Location) (Record, error) {
+	record := Record{Partial: true}
+	words := strings.Fields(text)
+
+	var completed string
+	done := len(words) > 0 && words[0] == "x"
+	record.Done = &done
+	if done {
+		words = words[1:]
+		if len(words) > 0 && todoDate.MatchString(words[0]) {
+			completed, words = words[0], words[1:]
+		}
+	} else if len(words) > 0 {
+		if match := todoPriority.FindStringSubmatch(words[0]); match != nil {
+			record.Priority, words = letterPriority(match[1]), words[1:]
+		}
+	}
+	// The creation date is left out, tasks get the time they were imported at
+	if len(words) > 0 && todoDate.MatchString(words[0]) {
+		words = words[1:]
+	}
+
+	if err := todoTags(&record, words, loc); err != nil {
+		return record, err
+	}
+	var err error
+	if record.CompletedAt, err = parseTime(completed, loc); err != nil {
+		return record, err
+	}
+	if record.Ref == "" {
+		record.Ref = contentRef([]byte(record.Title))
+	}
+	return record, nil
+}
+
+// todoTags fills the record with the title, labels and tags of the words of a todo.txt line or a Markdown checklist item,
+// past its completion, priority and dates. Tags the record has no field for are kept in the title.
+func todoTags(record *Record, words []string, loc *time.Location) error {
+	var title []string
+	var err error
+	for _, word := range words {
+		if len(word) > 1 && (word[0] == '+' || word[0] == '@') {
+			record.Labels = append(record.Labels, word[1:])
+			continue
+		}
+		match := todoTag.FindStringSubmatch(word)
+		if match == nil {
+			title = append(title, word)
+			continue
+		}
+		switch value := match[2]; strings.ToLower(match[1]) {
+		case idTag:
+			record.Ref = value
+		case parentTag:
+			record.ParentRef = value
+		case dueTag:
+			if record.DueDate, err = parseTime(value, loc); err != nil {
+				return err
+			}
+		case priorityTag:
+			record.Priority = letterPriority(strings.ToUpper(value))
+		case recurrenceTag:
+			if rule := todoRule(value); rule != "" {
+				record.Recurrence = &rule
+			} else {
+				note := "Recorrência no todo.txt: " + value
+				record.Notes = &note
+			}
+		default:
+			title = append(title, word)
+		}
+	}
+	record.Title = strings.Join(title, " ")
+	return nil
+}
+
+// letterPriority returns the priority of a task for a priority of todo.txt, where A is the most urgent.
+func letterPriority(letter string) int {
+	switch letter {
+	case "A":
+		return models.PriorityHigh
+	case "B":
+		return models.PriorityMedium
+	case "C":
+		return models.PriorityLow
+	}
+	return models.PriorityNone
+}
+
+// priorityLetter returns the priority of todo.txt for the priority of a task, or an empty string for none.
+func priorityLetter(priority int) string {
+	switch priority {
+	case models.PriorityHigh:
+		return "A"
+	case models.PriorityMedium:
+		return "B"
+	case models.PriorityLow:
+		return "C"
+	}
+	return ""
+}
+
+// todoRule returns the recurrence rule of a recurrence of todo.txt, or an empty string when it has none.
+func todoRule(value string) string {
+	match := todoRecurrence.FindStringSubmatch(value)
+	if match == nil {
+		return ""
+	}
+	if match[2] == "b" {
+		return weekdays
+	}
+	rule := "FREQ=" + todoFrequencies[match[2]]
+	if interval, _ := strconv.Atoi(match[1]); interval > 1 {
+		rule += ";INTERVAL=" + match[1]
+	}
+	return rule
+}
+
+// todoRecurrenceOf returns the recurrence of todo.txt of a recurrence rule, or an empty string for the rules
+// todo.txt can't tell, which are left out.
+func todoRecurrenceOf(rule string) string {
+	if rule == weekdays {
+		return "1b"
+	}
+	interval, unit := "1", ""
+	for _, part := range strings.Split(rule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ":
+			for letter, frequency := range todoFrequencies {
+				if frequency == value {
+					unit = letter
+				}
+			}
+		case "INTERVAL":
+			interval = value
+		default:
+			return ""
+		}
+	}
+	if unit == "" {
+		return ""
+	}
+	return interval + unit
+}
+
+// todoLabel returns how a label is written in todo.txt, with its spaces, which would end it, turned into underscores.
+func todoLabel(name string) string {
+	return "+" + strings.Join(strings.Fields(name), "_")
+}
+
+// todoDay returns how a time is written in todo.txt: its date in loc, along with its time of day when it isn't
+// at 23:59, the time of the tasks due on a day.
+func todoDay(t time.Time, loc *time.Location) string {
+	t = t.In(loc)
+	if t.Hour() == 23 && t.Minute() == 59 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02T15:04")
+}
+
+// todoTagsOf returns the labels, due date and recurrence written after the title of a task in todo.txt and Markdown checklists.
+func todoTagsOf(task DocumentTask, loc *time.Location) []string {
+	var words []string
+	for _, label := range task.Labels {
+		words = append(words, todoLabel(label))
+	}
+	if task.DueDate != nil {
+		words = append(words, dueTag+":"+todoDay(*task.DueDate, loc))
+	}
+	if task.Recurrence != nil {
+		if recurrence := todoRecurrenceOf(*task.Recurrence); recurrence != "" {
+			words = append(words, recurrenceTag+":"+recurrence)
+		}
+	}
+	return words
+}
+
+// WriteTodoTxt writes the tasks of the document as a todo.txt file, one line per task, with its dates in loc.
+// Each line carries the ID of its task in an nt: tag, so importing the file back updates the tasks.
+// Descriptions, notes, reminders and checklists have no place in todo.txt and are left out.
+func WriteTodoTxt(w io.Writer, document *Document, loc *time.Location) error {
+	buffered := bufio.NewWriter(w)
+	for _, task := range document.Tasks {
+		var words []string
+		letter := priorityLetter(task.Priority)
+		if task.CompletedAt != nil {
+			words = append(words, "x", task.CompletedAt.In(loc).Format("2006-01-02"))
+		} else if letter != "" {
+			words = append(words, "("+letter+")")
+		}
+		if !task.CreatedAt.IsZero() {
+			words = append(words, task.CreatedAt.In(loc).Format("2006-01-02"))
+		}
+		words = append(words, strings.Fields(task.Title)...)
+		if task.CompletedAt != nil && letter != "" {
+			words = append(words, priorityTag+":"+letter)
+		}
+		words = append(words, todoTagsOf(task, loc)...)
+		if task.ParentID != nil {
+			words = append(words, parentTag+":"+strconv.FormatUint(uint64(*task.ParentID), 10))
+		}
+		words = append(words, idTag+":"+strconv.FormatUint(uint64(task.ID), 10))
+		if _, err := buffered.WriteString(strings.Join(words, " ") + "\n"); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
@@ -0,0 +1,231 @@
+// Package transfer moves tasks in and out of NullTask.
+//
+// Exports write the personal tasks of a user as a NullTask JSON document, which imports read back as they were,
+// as a CSV file, as a todo.txt file or as a Markdown checklist. Imports read those formats, with the columns of
+// CSV files mapped onto the fields of the tasks, and the JSON exports of Todoist and Trello. Every format is read into Records first, one per row,
+// which the Importer then saves in the background, matching each row with the task it was imported into before.
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// Format is the format of a file of tasks.
+type Format string
+
+const (
+	// NullTask is the JSON document written by Export, which imports back as it was.
+	NullTask Format = "nulltask"
+	// CSV is a CSV file with a header row, whose columns are mapped onto the fields of the tasks.
+	CSV Format = "csv"
+	// Todoist is the JSON of the tasks of Todoist, as returned by its REST and Sync APIs.
+	Todoist Format = "todoist"
+	// Trello is the JSON export of a Trello board.
+	Trello Format = "trello"
+	// TodoTxt is a todo.txt file, one task per line.
+	TodoTxt Format = "todotxt"
+	// Markdown is a Markdown file whose GitHub-style checklist items are tasks.
+	Markdown Format = "markdown"
+)
+
+// Formats are the formats files can be imported from.
+var Formats = []Format{NullTask, CSV, Todoist, Trello, TodoTxt, Markdown}
+
+// keepsIDs reports whether the files of the format written by NullTask keep the IDs of their tasks as the Refs
+// of their rows, so importing them where they came from updates the tasks.
+func (f Format) keepsIDs() bool {
+	return f == NullTask || f == TodoTxt || f == Markdown
+}
+
+// ErrFormat is returned for formats files can't be imported from.
+var ErrFormat = errors.New("Formato de importação inválido")
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(strings.TrimSpace(name)) {
+			return format, nil
+		}
+	}
+	return "", ErrFormat
+}
+
+// Record is a task read from a row of a file, ready to be imported.
+type Record struct {
+	// Ref identifies the row in the file it comes from: the ID the task has there, or, for the files without IDs,
+	// one derived from the contents of the row. Importing the same row again updates the task it was imported into.
+	Ref         string
+	Title       string
+	Description *string
+	Notes       *string
+	// Status is a status of models.DefaultWorkflow, or empty for its initial one.
+	Status string
+	// Done tells, for the formats that only know whether tasks are done, whether the task is, with an empty Status.
+	// Done tasks are moved to the done status unless they're in a terminal status already, and the others are taken
+	// out of terminal statuses.
+	Done        *bool
+	Priority    int
+	DueDate     *time.Time
+	Reminder    *time.Time
+	Recurrence  *string
+	CompletedAt *time.Time
+	// Labels are the names of the labels tagging the task.
+	Labels []string
+	// Checklist is the checklist of the task, in order.
+	Checklist []ChecklistEntry
+	// ParentRef is the Ref of the row of the task this one is a subtask of, or empty.
+	ParentRef string
+	// Partial records come from formats that only carry some fields of the tasks, such as todo.txt:
+	// the tasks they update keep their description, notes and recurrence unless the record has some.
+	Partial bool
+}
+
+// ChecklistEntry is an item of the checklist of a task.
+type ChecklistEntry struct {
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+// Row is a row read from a file: its Record, or why it can't be imported.
+type Row struct {
+	// Line is where the row is in the file: its line in CSV files, and its position, from 1, in the others.
+	Line   int
+	Record Record
+	// Skip tells why the row has nothing to import, such as an archived card, when it's left out on purpose.
+	Skip string
+	// Err tells why the row can't be imported.
+	Err error
+}
+
+// Parse reads the rows of a file in the given format. The mapping maps the fields of the tasks to the columns
+// of CSV files, as Columns lists them, and is ignored by the other formats.
+// Times without a time zone are taken to be in loc, and dates without a time of day are due at 23:59 of that day.
+// Errors in a row are reported in its Row, while the returned error means the file couldn't be read at all.
+func Parse(format Format, data []byte, mapping map[string]string, loc *time.Location) ([]Row, error) {
+	var rows []Row
+	var err error
+	switch format {
+	case NullTask:
+		rows, err = parseDocument(data, loc)
+	case CSV:
+		rows, err = parseCSV(data, mapping, loc)
+	case Todoist:
+		rows, err = parseTodoist(data, loc)
+	case Trello:
+		rows, err = parseTrello(data, loc)
+	case TodoTxt:
+		rows, err = parseTodoTxt(data, loc)
+	case Markdown:
+		rows, err = parseMarkdown(data, loc)
+	default:
+		return nil, ErrFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if rows[i].Err == nil && rows[i].Skip == "" {
+			rows[i].Err = check(&rows[i].Record)
+		}
+	}
+	return rows, nil
+}
+
+// check validates a record and tidies up its fields.
+func check(record *Record) error {
+	record.Title = strings.TrimSpace(record.Title)
+	if record.Title == "" {
+		return errors.New("Título obrigatório")
+	}
+	if record.Priority < models.PriorityNone || record.Priority > models.PriorityHigh {
+		return fmt.Errorf("Prioridade inválida: %d", record.Priority)
+	}
+	if record.Status != "" {
+		if _, ok := models.DefaultWorkflow().Status(record.Status); !ok {
+			return errors.New("Status inválido: " + record.Status)
+		}
+	}
+	if record.Recurrence != nil && len(*record.Recurrence) > 255 {
+		return errors.New("Recorrência longa demais")
+	}
+	if record.Ref == "" {
+		return errors.New("Linha sem identificador")
+	}
+
+	labels := make([]string, 0, len(record.Labels))
+	seen := map[string]bool{}
+	for _, label := range record.Labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[strings.ToLower(label)] {
+			continue
+		}
+		if len([]rune(label)) > 50 {
+			return errors.New("Nome de etiqueta longo demais: " + label)
+		}
+		seen[strings.ToLower(label)] = true
+		labels = append(labels, label)
+	}
+	record.Labels = labels
+	return nil
+}
+
+// parsePriority reads a priority, given by its number or its name, in English or in Portuguese.
+func parsePriority(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "", "none", "nenhuma":
+		return models.PriorityNone, nil
+	case "low", "baixa":
+		return models.PriorityLow, nil
+	case "medium", "média", "media":
+		return models.PriorityMedium, nil
+	case "high", "alta":
+		return models.PriorityHigh, nil
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New("Prioridade inválida: " + value)
+	}
+	return priority, nil
+}
+
+// dateLayouts are the layouts times are read with, besides RFC 3339.
+var dateLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// parseTime reads a time, which is nil when the value is empty. Dates without a time of day are read as 23:59 in loc,
+// and times without a time zone as times in loc.
+func parseTime(value string, loc *time.Location) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		t := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 0, 0, loc)
+		return &t, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.New("Data inválida: " + value)
+}
+
+// optional returns a pointer to the trimmed text, or nil when it's empty.
+func optional(text string) *string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	return &text
+}
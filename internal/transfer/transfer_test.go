@@ -0,0 +1,236 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+var saoPaulo = time.FixedZone("BRT", -3*60*60)
+
+func parse(t *testing.T, format Format, data string, mapping map[string]string) []Row {
+	t.Helper()
+	rows, err := Parse(format, []byte(data), mapping, saoPaulo)
+	if err != nil {
+		t.Fatalf("parsing the %s file: %v", format, err)
+	}
+	return rows
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"nulltask", "CSV", " todoist ", "trello"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Fatalf("expected %q to be a format, got %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("xlsx"); err != ErrFormat {
+		t.Fatalf("expected ErrFormat, got %v", err)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffTitle,Priority,Due Date,Labels,Status\n" +
+		"Pay rent,high,2024-07-05,\"home, bills\",\n" +
+		"\n" +
+		"Call mom,baixa,,,done\n" +
+		",low,,,\n" +
+		"Broken date,,05/07/2024,,\n" +
+		"Unknown status,,,,archived\n"
+	rows := parse(t, CSV, data, nil)
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %+v", rows)
+	}
+
+	rent := rows[0]
+	if rent.Err != nil || rent.Line != 2 || rent.Record.Title != "Pay rent" || rent.Record.Priority != models.PriorityHigh {
+		t.Fatalf("unexpected first row %+v", rent)
+	}
+	due := time.Date(2024, 7, 5, 23, 59, 0, 0, saoPaulo)
+	if rent.Record.DueDate == nil || !rent.Record.DueDate.Equal(due) {
+		t.Fatalf("expected the first row to be due at %v, got %v", due, rent.Record.DueDate)
+	}
+	if strings.Join(rent.Record.Labels, "|") != "home|bills" {
+		t.Fatalf("expected the labels home and bills, got %v", rent.Record.Labels)
+	}
+	if !strings.HasPrefix(rent.Record.Ref, "row-") {
+		t.Fatalf("expected a Ref derived from the contents, got %q", rent.Record.Ref)
+	}
+
+	if call := rows[1]; call.Err != nil || call.Line != 4 || call.Record.Status != models.TaskDone || call.Record.Priority != models.PriorityLow {
+		t.Fatalf("unexpected second row %+v", call)
+	}
+	for _, row := range rows[2:] {
+		if row.Err == nil {
+			t.Fatalf("expected row %d to fail, got %+v", row.Line, row.Record)
+		}
+	}
+
+	// The same file gives the same refs, so importing it again matches the rows
+	again := parse(t, CSV, data, nil)
+	if again[0].Record.Ref != rent.Record.Ref || again[1].Record.Ref == rent.Record.Ref {
+		t.Fatalf("expected stable and distinct refs, got %q, %q and %q", rent.Record.Ref, again[0].Record.Ref, again[1].Record.Ref)
+	}
+}
+
+func TestParseCSVMapping(t *testing.T) {
+	data := "Task Name;Prazo;Ref\nWrite report;2024-07-05 14:30;42\n"
+	data = strings.ReplaceAll(data, ";", ",")
+	rows := parse(t, CSV, data, map[string]string{"title": "task name", "due_date": "Prazo", "id": "Ref"})
+	if len(rows) != 1 || rows[0].Err != nil {
+		t.Fatalf("expected one valid row, got %+v", rows)
+	}
+	record := rows[0].Record
+	due := time.Date(2024, 7, 5, 14, 30, 0, 0, saoPaulo)
+	if record.Title != "Write report" || record.Ref != "42" || record.DueDate == nil || !record.DueDate.Equal(due) {
+		t.Fatalf("unexpected record %+v", record)
+	}
+
+	for _, mapping := range []map[string]string{
+		{"title": "Task Name", "color": "Cor"},
+		{"due_date": "Prazo"},
+		{"title": " "},
+	} {
+		if err := CheckMapping(mapping); err == nil {
+			t.Fatalf("expected the mapping %v to be refused", mapping)
+		}
+	}
+	if _, err := Parse(CSV, []byte(data), map[string]string{"title": "Nome"}, saoPaulo); err == nil {
+		t.Fatal("expected a mapping onto a missing column to fail")
+	}
+	if _, err := Parse(CSV, []byte("name\nfoo\n"), nil, saoPaulo); err == nil {
+		t.Fatal("expected a file without a title column to fail")
+	}
+}
+
+func TestParseTodoist(t *testing.T) {
+	data := `{
+		"projects": [{"id": "1", "name": "Inbox", "inbox_project": true}, {"id": "2", "name": "Work"}],
+		"labels": [{"id": 7, "name": "urgent"}],
+		"items": [
+			{"id": 100, "content": "Ship release", "priority": 4, "project_id": "2", "labels": [7],
+				"due": {"date": "2024-07-05", "string": "every week", "is_recurring": true}},
+			{"id": "101", "content": "Write notes", "parent_id": 100, "project_id": "1", "checked": 1,
+				"completed_at": "2024-07-01T10:00:00Z", "due": {"date": "2024-07-01", "string": "every other tuesday", "is_recurring": true}}
+		]
+	}`
+	rows := parse(t, Todoist, data, nil)
+	if len(rows) != 2 || rows[0].Err != nil || rows[1].Err != nil {
+		t.Fatalf("expected two valid rows, got %+v", rows)
+	}
+
+	ship := rows[0].Record
+	if ship.Ref != "100" || ship.Priority != models.PriorityHigh || ship.Recurrence == nil || *ship.Recurrence != "FREQ=WEEKLY" {
+		t.Fatalf("unexpected first record %+v", ship)
+	}
+	if strings.Join(ship.Labels, "|") != "Work|urgent" {
+		t.Fatalf("expected the labels Work and urgent, got %v", ship.Labels)
+	}
+
+	notes := rows[1].Record
+	if notes.ParentRef != "100" || notes.Status != models.TaskDone || notes.CompletedAt == nil || len(notes.Labels) != 0 {
+		t.Fatalf("unexpected second record %+v", notes)
+	}
+	if notes.Recurrence != nil || notes.Notes == nil || !strings.Contains(*notes.Notes, "every other tuesday") {
+		t.Fatalf("expected the recurrence to be kept in the notes, got %+v", notes)
+	}
+
+	// The REST API returns a plain list of tasks
+	rows = parse(t, Todoist, `[{"id": "5", "content": "Plain", "priority": 1, "labels": ["errand"]}]`, nil)
+	if len(rows) != 1 || rows[0].Err != nil || rows[0].Record.Labels[0] != "errand" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if _, err := Parse(Todoist, []byte(`{"items": 3}`), nil, saoPaulo); err == nil {
+		t.Fatal("expected invalid JSON to fail")
+	}
+}
+
+func TestParseTrello(t *testing.T) {
+	data := `{
+		"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Old", "closed": true}],
+		"labels": [{"id": "b1", "name": "Bug", "color": "red"}, {"id": "b2", "name": "", "color": "green"}],
+		"cards": [
+			{"id": "c1", "name": "Fix login", "desc": "Steps", "idList": "l1", "idLabels": ["b1", "b2"],
+				"due": "2024-07-05T15:00:00.000Z", "dueComplete": true},
+			{"id": "c2", "name": "Archived", "idList": "l1", "closed": true},
+			{"id": "c3", "name": "In an archived list", "idList": "l2"}
+		],
+		"checklists": [
+			{"idCard": "c1", "pos": 2, "checkItems": [{"name": "Deploy", "state": "incomplete", "pos": 1}]},
+			{"idCard": "c1", "pos": 1, "checkItems": [
+				{"name": "Test", "state": "complete", "pos": 2},
+				{"name": "Reproduce", "state": "complete", "pos": 1}
+			]}
+		]
+	}`
+	rows := parse(t, Trello, data, nil)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", rows)
+	}
+
+	card := rows[0]
+	if card.Err != nil || card.Skip != "" || card.Record.Status != models.TaskDone || *card.Record.Description != "Steps" {
+		t.Fatalf("unexpected first row %+v", card)
+	}
+	if strings.Join(card.Record.Labels, "|") != "Doing|Bug|green" {
+		t.Fatalf("expected the labels Doing, Bug and green, got %v", card.Record.Labels)
+	}
+	var checklist []string
+	for _, item := range card.Record.Checklist {
+		checklist = append(checklist, item.Title)
+	}
+	if strings.Join(checklist, "|") != "Reproduce|Test|Deploy" || !card.Record.Checklist[0].Done || card.Record.Checklist[2].Done {
+		t.Fatalf("unexpected checklist %+v", card.Record.Checklist)
+	}
+	if rows[1].Skip == "" || rows[2].Skip == "" {
+		t.Fatalf("expected the archived cards to be skipped, got %+v", rows[1:])
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	due := time.Date(2024, 7, 5, 15, 0, 0, 0, time.UTC)
+	description := "Quarterly"
+	parent := uint(1)
+	tasks := []models.Tasks{
+		{ID: 1, Title: "Taxes", Description: &description, Status: models.TaskInProgress, Priority: models.PriorityHigh, DueDate: &due},
+		{ID: 2, Title: "Gather receipts", Status: models.TaskPending, ParentID: &parent},
+	}
+	labels := map[uint][]models.Label{1: {{Name: "home"}, {Name: "money"}}}
+	checklists := map[uint][]models.ChecklistItem{1: {{Title: "Form", Done: true}}}
+
+	var encoded bytes.Buffer
+	if err := json.NewEncoder(&encoded).Encode(Export(tasks, labels, checklists)); err != nil {
+		t.Fatalf("encoding the document: %v", err)
+	}
+	rows := parse(t, NullTask, encoded.String(), nil)
+	if len(rows) != 2 || rows[0].Err != nil || rows[1].Err != nil {
+		t.Fatalf("expected two valid rows, got %+v", rows)
+	}
+	taxes := rows[0].Record
+	if taxes.Ref != "1" || taxes.Title != "Taxes" || *taxes.Description != description || taxes.Status != models.TaskInProgress ||
+		taxes.Priority != models.PriorityHigh || !taxes.DueDate.Equal(due) || len(taxes.Labels) != 2 || len(taxes.Checklist) != 1 {
+		t.Fatalf("unexpected first record %+v", taxes)
+	}
+	if rows[1].Record.ParentRef != "1" {
+		t.Fatalf("expected the second record to be a subtask of the first, got %+v", rows[1].Record)
+	}
+
+	var csv bytes.Buffer
+	if err := WriteCSV(&csv, Export(tasks, labels, checklists)); err != nil {
+		t.Fatalf("writing the CSV file: %v", err)
+	}
+	rows = parse(t, CSV, csv.String(), nil)
+	if len(rows) != 2 || rows[0].Err != nil || rows[0].Record.Ref != "1" || rows[1].Record.ParentRef != "1" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if strings.Join(rows[0].Record.Labels, "|") != "home|money" || !rows[0].Record.DueDate.Equal(due) {
+		t.Fatalf("unexpected first record %+v", rows[0].Record)
+	}
+
+	if _, err := Parse(NullTask, []byte(`{"version": 99, "tasks": []}`), nil, saoPaulo); err == nil {
+		t.Fatal("expected a newer document version to fail")
+	}
+}
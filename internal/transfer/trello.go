@@ -0,0 +1,107 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// trelloBoard is the JSON export of a Trello board, reduced to what tasks are made of.
+type trelloBoard struct {
+	Cards []struct {
+		ID          string   `json:"id"`
+		Name        string   `json:"name"`
+		Desc        string   `json:"desc"`
+		Closed      bool     `json:"closed"`
+		Due         string   `json:"due"`
+		DueComplete bool     `json:"dueComplete"`
+		IDList      string   `json:"idList"`
+		IDLabels    []string `json:"idLabels"`
+	} `json:"cards"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Labels []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// parseTrello reads the rows of the export of a Trello board, one per card. The list of each card and its labels
+// become labels of the task, and its checklists its checklist. Archived cards, and the cards of archived lists, are skipped.
+func parseTrello(data []byte, loc *time.Location) ([]Row, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("JSON do Trello inválido: %v", err)
+	}
+
+	lists := map[string]string{}
+	archived := map[string]bool{}
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		archived[list.ID] = list.Closed
+	}
+	labels := map[string]string{}
+	for _, label := range board.Labels {
+		// Labels without a name are only told apart by their color
+		name := label.Name
+		if name == "" {
+			name = label.Color
+		}
+		labels[label.ID] = name
+	}
+
+	checklists := board.Checklists
+	sort.SliceStable(checklists, func(i, j int) bool { return checklists[i].Pos < checklists[j].Pos })
+	items := map[string][]ChecklistEntry{}
+	for _, checklist := range checklists {
+		entries := checklist.CheckItems
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Pos < entries[j].Pos })
+		for _, item := range entries {
+			items[checklist.IDCard] = append(items[checklist.IDCard], ChecklistEntry{Title: item.Name, Done: item.State == "complete"})
+		}
+	}
+
+	rows := make([]Row, 0, len(board.Cards))
+	for i, card := range board.Cards {
+		row := Row{Line: i + 1}
+		if card.Closed || archived[card.IDList] {
+			row.Skip = "Cartão arquivado"
+		}
+
+		record := Record{Ref: card.ID, Title: card.Name, Description: optional(card.Desc), Checklist: items[card.ID]}
+		if list := lists[card.IDList]; list != "" {
+			record.Labels = append(record.Labels, list)
+		}
+		for _, id := range card.IDLabels {
+			if name := labels[id]; name != "" {
+				record.Labels = append(record.Labels, name)
+			}
+		}
+		var err error
+		if record.DueDate, err = parseTime(card.Due, loc); err != nil {
+			row.Err = err
+		}
+		if card.DueComplete {
+			record.Status = models.TaskDone
+		}
+		row.Record = record
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
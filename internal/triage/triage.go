@@ -0,0 +1,163 @@
+// Package triage decides which open tasks deserve attention first.
+//
+// Score ranks tasks for the "next actions" list by adding up the points of independent factors,
+// each reported with an explanation so users can see why a task is where it is:
+//
+//   - priority: 10 points per priority level, from 0 for no priority to 30 for a high priority.
+//   - important / urgent: 15 points for tasks flagged as important and 10 for those flagged as urgent.
+//   - overdue: 40 points, plus 2 per full day past the due date, up to 60 points.
+//   - due soon: for tasks due within DueSoon, from 0 points a week ahead up to 35 points at the due time,
+//     growing linearly.
+//   - blocked: -50 points for tasks waiting on unfinished dependencies, which can't be acted on yet.
+//   - age: 1 point per full week since the task was created, up to 10 points, so old tasks slowly surface.
+//
+// QuadrantOf places tasks on the Eisenhower matrix. Tasks that weren't explicitly flagged are urgent when they
+// are overdue or due within UrgentWithin, and important when their priority is at least models.PriorityHigh.
+package triage
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+const (
+	// DueSoon is how far ahead a due date starts adding points to the score.
+	DueSoon = 7 * 24 * time.Hour
+	// UrgentWithin is how close a due date makes an unflagged task urgent.
+	UrgentWithin = 48 * time.Hour
+)
+
+// The points of each factor of the score.
+const (
+	pointsPerPriority   = 10
+	pointsImportant     = 15
+	pointsUrgent        = 10
+	pointsOverdue       = 40
+	pointsPerOverdueDay = 2
+	maxPointsOverdue    = 60
+	maxPointsDueSoon    = 35
+	pointsBlocked       = -50
+	maxPointsAge        = 10
+)
+
+// Factor is one of the contributions to a task's score.
+type Factor struct {
+	// Name identifies the factor: priority, important, urgent, overdue, due_soon, blocked or age.
+	Name string `json:"name"`
+	// Points is what the factor adds to the score, negative when it lowers it.
+	Points float64 `json:"points"`
+	// Explanation tells, in Portuguese, why the factor applies to the task.
+	Explanation string `json:"explanation"`
+}
+
+// Score returns the score of the task at the given time, the higher the sooner it should be done,
+// along with the factors that make it up. Factors that don't apply to the task are left out.
+func Score(task models.Tasks, now time.Time) (float64, []Factor) {
+	factors := []Factor{}
+
+	if task.Priority > models.PriorityNone {
+		factors = append(factors, Factor{"priority", float64(task.Priority * pointsPerPriority), "Prioridade " + models.PriorityName(task.Priority)})
+	}
+	if task.Important != nil && *task.Important {
+		factors = append(factors, Factor{"important", pointsImportant, "Marcada como importante"})
+	}
+	if task.Urgent != nil && *task.Urgent {
+		factors = append(factors, Factor{"urgent", pointsUrgent, "Marcada como urgente"})
+	}
+
+	if task.DueDate != nil {
+		left := task.DueDate.Sub(now)
+		switch {
+		case left < 0:
+			days := int(-left / (24 * time.Hour))
+			points := math.Min(pointsOverdue+float64(days*pointsPerOverdueDay), maxPointsOverdue)
+			factors = append(factors, Factor{"overdue", points, overdueExplanation(days)})
+		case left <= DueSoon:
+			points := round(maxPointsDueSoon * float64(DueSoon-left) / float64(DueSoon))
+			factors = append(factors, Factor{"due_soon", points, dueSoonExplanation(left)})
+		}
+	}
+
+	if task.Blocked {
+		factors = append(factors, Factor{"blocked", pointsBlocked, "Bloqueada por dependências não concluídas"})
+	}
+
+	if weeks := int(now.Sub(task.CreatedAt) / (7 * 24 * time.Hour)); weeks > 0 {
+		points := math.Min(float64(weeks), maxPointsAge)
+		factors = append(factors, Factor{"age", points, fmt.Sprintf("Criada há %d semana(s)", weeks)})
+	}
+
+	total := 0.0
+	for _, factor := range factors {
+		total += factor.Points
+	}
+	return round(total), factors
+}
+
+// Quadrant is a quadrant of the Eisenhower matrix.
+type Quadrant string
+
+const (
+	// QuadrantDo holds the urgent and important tasks.
+	QuadrantDo Quadrant = "do"
+	// QuadrantSchedule holds the important tasks that aren't urgent.
+	QuadrantSchedule Quadrant = "schedule"
+	// QuadrantDelegate holds the urgent tasks that aren't important.
+	QuadrantDelegate Quadrant = "delegate"
+	// QuadrantEliminate holds the tasks that are neither urgent nor important.
+	QuadrantEliminate Quadrant = "eliminate"
+)
+
+// QuadrantOf returns the quadrant of the Eisenhower matrix the task falls in at the given time.
+func QuadrantOf(task models.Tasks, now time.Time) Quadrant {
+	urgent, important := Urgent(task, now), Important(task)
+	switch {
+	case urgent && important:
+		return QuadrantDo
+	case important:
+		return QuadrantSchedule
+	case urgent:
+		return QuadrantDelegate
+	}
+	return QuadrantEliminate
+}
+
+// Urgent tells whether the task is urgent: as flagged or, when it isn't, whether it's overdue or due within UrgentWithin.
+func Urgent(task models.Tasks, now time.Time) bool {
+	if task.Urgent != nil {
+		return *task.Urgent
+	}
+	return task.DueDate != nil && task.DueDate.Sub(now) <= UrgentWithin
+}
+
+// Important tells whether the task is important: as flagged or, when it isn't, whether its priority is high.
+func Important(task models.Tasks) bool {
+	if task.Important != nil {
+		return *task.Important
+	}
+	return task.Priority >= models.PriorityHigh
+}
+
+// overdueExplanation explains the overdue factor of a task overdue for the given number of full days.
+func overdueExplanation(days int) string {
+	if days == 0 {
+		return "Atrasada desde hoje"
+	}
+	return fmt.Sprintf("Atrasada há %d dia(s)", days)
+}
+
+// dueSoonExplanation explains the due soon factor of a task due in the given time.
+func dueSoonExplanation(left time.Duration) string {
+	if left < 24*time.Hour {
+		return fmt.Sprintf("Vence em %d hora(s)", int(left/time.Hour))
+	}
+	return fmt.Sprintf("Vence em %d dia(s)", int(left/(24*time.Hour)))
+}
+
+// round rounds the points to two decimal places.
+func round(points float64) float64 {
+	return math.Round(points*100) / 100
+}
@@ -0,0 +1,77 @@
+package triage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+var now = time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+func at(d time.Duration) *time.Time {
+	t := now.Add(d)
+	return &t
+}
+
+func flag(b bool) *bool {
+	return &b
+}
+
+func TestScore(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name    string
+		task    models.Tasks
+		want    float64
+		factors []string
+	}{
+		{"nothing", models.Tasks{CreatedAt: now}, 0, nil},
+		{"high priority", models.Tasks{Priority: models.PriorityHigh, CreatedAt: now}, 30, []string{"priority"}},
+		{"flagged", models.Tasks{Important: flag(true), Urgent: flag(true), CreatedAt: now}, 25, []string{"important", "urgent"}},
+		{"flagged false", models.Tasks{Important: flag(false), Urgent: flag(false), CreatedAt: now}, 0, nil},
+		{"overdue today", models.Tasks{DueDate: at(-time.Hour), CreatedAt: now}, 40, []string{"overdue"}},
+		{"overdue three days", models.Tasks{DueDate: at(-3*day - time.Hour), CreatedAt: now}, 46, []string{"overdue"}},
+		{"overdue for long", models.Tasks{DueDate: at(-30 * day), CreatedAt: now}, 60, []string{"overdue"}},
+		{"due now", models.Tasks{DueDate: at(0), CreatedAt: now}, 35, []string{"due_soon"}},
+		{"due in half a week", models.Tasks{DueDate: at(DueSoon / 2), CreatedAt: now}, 17.5, []string{"due_soon"}},
+		{"due later", models.Tasks{DueDate: at(DueSoon + time.Hour), CreatedAt: now}, 0, nil},
+		{"blocked", models.Tasks{Priority: models.PriorityLow, Blocked: true, CreatedAt: now}, -40, []string{"priority", "blocked"}},
+		{"three weeks old", models.Tasks{CreatedAt: now.Add(-21*day - time.Hour)}, 3, []string{"age"}},
+		{"a year old", models.Tasks{CreatedAt: now.Add(-365 * day)}, 10, []string{"age"}},
+	}
+	for _, tt := range tests {
+		score, factors := Score(tt.task, now)
+		if score != tt.want {
+			t.Fatalf("%s: expected a score of %v, got %v (%+v)", tt.name, tt.want, score, factors)
+		}
+		if len(factors) != len(tt.factors) {
+			t.Fatalf("%s: expected the factors %v, got %+v", tt.name, tt.factors, factors)
+		}
+		for i, factor := range factors {
+			if factor.Name != tt.factors[i] || factor.Explanation == "" {
+				t.Fatalf("%s: expected the factors %v, got %+v", tt.name, tt.factors, factors)
+			}
+		}
+	}
+}
+
+func TestQuadrantOf(t *testing.T) {
+	tests := []struct {
+		name string
+		task models.Tasks
+		want Quadrant
+	}{
+		{"high priority due tomorrow", models.Tasks{Priority: models.PriorityHigh, DueDate: at(24 * time.Hour)}, QuadrantDo},
+		{"high priority due next month", models.Tasks{Priority: models.PriorityHigh, DueDate: at(30 * 24 * time.Hour)}, QuadrantSchedule},
+		{"low priority overdue", models.Tasks{Priority: models.PriorityLow, DueDate: at(-time.Hour)}, QuadrantDelegate},
+		{"no due date", models.Tasks{Priority: models.PriorityMedium}, QuadrantEliminate},
+		{"flagged over derived", models.Tasks{Priority: models.PriorityHigh, DueDate: at(-time.Hour), Urgent: flag(false), Important: flag(false)}, QuadrantEliminate},
+		{"flagged without due date", models.Tasks{Urgent: flag(true), Important: flag(true)}, QuadrantDo},
+	}
+	for _, tt := range tests {
+		if got := QuadrantOf(tt.task, now); got != tt.want {
+			t.Fatalf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
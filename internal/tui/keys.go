@@ -0,0 +1,62 @@
+package tui
+
+import "github.com/charmbracelet/bubbles/key"
+
+// keyMap holds the key bindings of the TUI, which the help at the bottom of the screen lists.
+type keyMap struct {
+	Up, Down, Left, Right          key.Binding
+	MoveLeft, MoveRight            key.Binding
+	MoveUp, MoveDown               key.Binding
+	Top, Bottom                    key.Binding
+	Toggle, Start, Priority        key.Binding
+	Add, Edit, Delete              key.Binding
+	Filter, Closed, Group, Kanban  key.Binding
+	Reload, Help, Quit             key.Binding
+	Confirm, Cancel, ConfirmDelete key.Binding
+}
+
+func defaultKeys() keyMap {
+	return keyMap{
+		Up:            key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:          key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		Left:          key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "previous column")),
+		Right:         key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("→/l", "next column")),
+		MoveLeft:      key.NewBinding(key.WithKeys("shift+left", "H"), key.WithHelp("H", "move to the previous column")),
+		MoveRight:     key.NewBinding(key.WithKeys("shift+right", "L"), key.WithHelp("L", "move to the next column")),
+		MoveUp:        key.NewBinding(key.WithKeys("shift+up", "K"), key.WithHelp("K", "move up")),
+		MoveDown:      key.NewBinding(key.WithKeys("shift+down", "J"), key.WithHelp("J", "move down")),
+		Top:           key.NewBinding(key.WithKeys("home", "g"), key.WithHelp("g", "first")),
+		Bottom:        key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("G", "last")),
+		Toggle:        key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("x", "done/reopen")),
+		Start:         key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start/stop")),
+		Priority:      key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "priority")),
+		Add:           key.NewBinding(key.WithKeys("a", "n"), key.WithHelp("a", "add")),
+		Edit:          key.NewBinding(key.WithKeys("e", "enter"), key.WithHelp("e", "edit")),
+		Delete:        key.NewBinding(key.WithKeys("d", "delete"), key.WithHelp("d", "delete")),
+		Filter:        key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		Closed:        key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "show/hide closed")),
+		Group:         key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "group by status/label")),
+		Kanban:        key.NewBinding(key.WithKeys("tab", "v"), key.WithHelp("tab", "list/kanban")),
+		Reload:        key.NewBinding(key.WithKeys("r", "ctrl+r"), key.WithHelp("r", "reload")),
+		Help:          key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Quit:          key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+		Confirm:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save")),
+		Cancel:        key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		ConfirmDelete: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "confirm")),
+	}
+}
+
+// ShortHelp returns the bindings shown at the bottom of the screen.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Add, k.Edit, k.Toggle, k.Filter, k.Kanban, k.Help, k.Quit}
+}
+
+// FullHelp returns every binding, in columns, shown when help is toggled.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Left, k.Right, k.Top, k.Bottom},
+		{k.MoveLeft, k.MoveRight, k.MoveUp, k.MoveDown},
+		{k.Add, k.Edit, k.Delete, k.Toggle, k.Start, k.Priority},
+		{k.Filter, k.Closed, k.Group, k.Kanban, k.Reload, k.Quit},
+	}
+}
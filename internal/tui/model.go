@@ -0,0 +1,652 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// RetryDelay is how long the TUI waits before connecting again to the event stream, once it ended or failed.
+// Each attempt reloads the tasks, so they stay fresh even while the stream is unavailable.
+const RetryDelay = 5 * time.Second
+
+type layout int
+
+const (
+	listLayout layout = iota
+	kanbanLayout
+)
+
+type grouping int
+
+const (
+	byStatus grouping = iota
+	byLabel
+)
+
+// inputKind is what the input line is being typed for.
+type inputKind int
+
+const (
+	noInput inputKind = iota
+	filterInput
+	addInput
+	editInput
+)
+
+// Model is the state of the TUI, a tea.Model.
+type Model struct {
+	ctx    context.Context
+	source Source
+	keys   keyMap
+	help   help.Model
+	input  textinput.Model
+
+	board  []handlers.BoardColumn
+	labels []models.Label
+	// tagged holds the IDs of the tasks tagged with each label, only loaded while grouping by label.
+	tagged map[uint][]uint
+
+	layout     layout
+	grouping   grouping
+	showClosed bool
+	filter     string
+
+	// cursor is the index of the selected task in the list, column and row its place on the kanban board.
+	// selected is its ID, which keeps it selected when the tasks are reloaded or laid out differently.
+	cursor      int
+	column, row int
+	selected    uint
+
+	inputKind inputKind
+	deleting  *models.Tasks
+
+	stream      Events
+	live        bool
+	loading     bool
+	stale       bool
+	loaded      bool
+	status      string
+	err         error
+	width       int
+	height      int
+	retryDelay  time.Duration
+	lastEventAt time.Time
+}
+
+// NewModel returns the TUI showing the tasks of the source, until the context is cancelled.
+func NewModel(ctx context.Context, source Source) Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = 255
+	return Model{
+		ctx:        ctx,
+		source:     source,
+		keys:       defaultKeys(),
+		help:       help.New(),
+		input:      input,
+		retryDelay: RetryDelay,
+		width:      80,
+		height:     24,
+	}
+}
+
+type (
+	// loadedMsg carries the tasks and labels of the user, or why they couldn't be loaded.
+	loadedMsg struct {
+		board  []handlers.BoardColumn
+		labels []models.Label
+		tagged map[uint][]uint
+		err    error
+	}
+	// savedMsg reports a change made to the tasks, and the task to select afterwards, if any.
+	savedMsg struct {
+		status string
+		task   *models.Tasks
+		err    error
+	}
+	connectedMsg struct {
+		stream Events
+		err    error
+	}
+	eventMsg struct {
+		event events.Event
+	}
+	disconnectedMsg struct {
+		err error
+	}
+	reconnectMsg struct{}
+)
+
+// Init connects to the event stream, which then loads the tasks.
+func (m Model) Init() tea.Cmd {
+	return m.connect()
+}
+
+// connect subscribes to the events of the user.
+func (m Model) connect() tea.Cmd {
+	ctx, source := m.ctx, m.source
+	return func() tea.Msg {
+		stream, err := source.Subscribe(ctx)
+		return connectedMsg{stream: stream, err: err}
+	}
+}
+
+// listen waits for the next event of the stream.
+func listen(stream Events) tea.Cmd {
+	return func() tea.Msg {
+		event, err := stream.Next()
+		if err != nil {
+			return disconnectedMsg{err: err}
+		}
+		return eventMsg{event: event}
+	}
+}
+
+// reload loads the tasks again, once the load in progress, if any, is over.
+func (m *Model) reload() tea.Cmd {
+	if m.loading {
+		m.stale = true
+		return nil
+	}
+	m.loading = true
+	ctx, source, withTags := m.ctx, m.source, m.grouping == byLabel
+	return func() tea.Msg {
+		board, err := source.Board(ctx)
+		if err != nil {
+			return loadedMsg{err: err}
+		}
+		labels, err := source.Labels(ctx)
+		if err != nil {
+			return loadedMsg{err: err}
+		}
+		var tagged map[uint][]uint
+		if withTags {
+			tagged = make(map[uint][]uint, len(labels))
+			for _, label := range labels {
+				if tagged[label.ID], err = source.Tagged(ctx, label.ID); err != nil {
+					return loadedMsg{err: err}
+				}
+			}
+		}
+		return loadedMsg{board: board, labels: labels, tagged: tagged}
+	}
+}
+
+// save runs a change to the tasks in the background, reporting it with the status when it succeeds.
+func (m Model) save(status string, change func(ctx context.Context, source Source) (*models.Tasks, error)) tea.Cmd {
+	ctx, source := m.ctx, m.source
+	return func() tea.Msg {
+		task, err := change(ctx, source)
+		return savedMsg{status: status, task: task, err: err}
+	}
+}
+
+// Update handles the keys pressed and the outcome of the requests to the source.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.help.Width = msg.Width
+		return m, nil
+
+	case connectedMsg:
+		if msg.err != nil {
+			m.live = false
+			return m, tea.Batch(m.reload(), m.reconnect())
+		}
+		m.stream, m.live = msg.stream, true
+		return m, tea.Batch(m.reload(), listen(msg.stream))
+
+	case eventMsg:
+		m.lastEventAt = msg.event.OccurredAt
+		return m, tea.Batch(m.reload(), listen(m.stream))
+
+	case disconnectedMsg:
+		if m.stream != nil {
+			m.stream.Close()
+		}
+		m.stream, m.live = nil, false
+		if m.ctx.Err() != nil {
+			return m, nil
+		}
+		return m, m.reconnect()
+
+	case reconnectMsg:
+		return m, m.connect()
+
+	case loadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.board, m.labels, m.tagged, m.loaded, m.err = msg.board, msg.labels, msg.tagged, true, nil
+			m.restoreSelection()
+		}
+		if m.stale {
+			m.stale = false
+			return m, m.reload()
+		}
+		return m, nil
+
+	case savedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.status, m.err = msg.status, nil
+		if msg.task != nil {
+			m.selected = msg.task.ID
+		}
+		return m, m.reload()
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	if m.inputKind != noInput {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// reconnect connects to the event stream again after a while.
+func (m Model) reconnect() tea.Cmd {
+	return tea.Tick(m.retryDelay, func(time.Time) tea.Msg { return reconnectMsg{} })
+}
+
+func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		return m, tea.Quit
+	}
+	if m.deleting != nil {
+		task := m.deleting
+		m.deleting, m.status = nil, ""
+		if !key.Matches(msg, m.keys.ConfirmDelete) {
+			return m, nil
+		}
+		return m, m.save(fmt.Sprintf("Deleted %q", task.Title), func(ctx context.Context, source Source) (*models.Tasks, error) {
+			return nil, source.DeleteTask(ctx, task.ID)
+		})
+	}
+	if m.inputKind != noInput {
+		return m.handleInput(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Help):
+		m.help.ShowAll = !m.help.ShowAll
+		return m, nil
+	case key.Matches(msg, m.keys.Kanban):
+		m.layout = 1 - m.layout
+		m.restoreSelection()
+		return m, nil
+	case key.Matches(msg, m.keys.Group):
+		if m.layout != listLayout {
+			return m, nil
+		}
+		m.grouping = 1 - m.grouping
+		if m.grouping == byLabel && m.tagged == nil {
+			// The tags are only loaded while grouping by label
+			return m, m.reload()
+		}
+		m.restoreSelection()
+		return m, nil
+	case key.Matches(msg, m.keys.Closed):
+		m.showClosed = !m.showClosed
+		m.restoreSelection()
+		return m, nil
+	case key.Matches(msg, m.keys.Filter):
+		return m, m.startInput(filterInput, m.filter)
+	case key.Matches(msg, m.keys.Add):
+		return m, m.startInput(addInput, "")
+	case key.Matches(msg, m.keys.Reload):
+		m.status = ""
+		return m, m.reload()
+	case key.Matches(msg, m.keys.Up):
+		m.moveCursor(-1)
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		m.moveCursor(1)
+		return m, nil
+	case key.Matches(msg, m.keys.Top):
+		m.moveCursor(-len(m.tasks()))
+		return m, nil
+	case key.Matches(msg, m.keys.Bottom):
+		m.moveCursor(len(m.tasks()))
+		return m, nil
+	case key.Matches(msg, m.keys.Left):
+		m.moveColumn(-1)
+		return m, nil
+	case key.Matches(msg, m.keys.Right):
+		m.moveColumn(1)
+		return m, nil
+	}
+
+	task := m.current()
+	if task == nil {
+		return m, nil
+	}
+	switch {
+	case key.Matches(msg, m.keys.Edit):
+		return m, m.startInput(editInput, task.Title)
+	case key.Matches(msg, m.keys.Delete):
+		m.deleting = task
+		return m, nil
+	case key.Matches(msg, m.keys.Toggle):
+		status, verb := models.TaskDone, "Completed"
+		if task.CompletedAt != nil {
+			status, verb = models.TaskPending, "Reopened"
+		}
+		return m, m.update(task, verb, map[string]interface{}{"status": status})
+	case key.Matches(msg, m.keys.Start):
+		switch task.Status {
+		case models.TaskPending:
+			return m, m.update(task, "Started", map[string]interface{}{"status": models.TaskInProgress})
+		case models.TaskInProgress:
+			return m, m.update(task, "Stopped", map[string]interface{}{"status": models.TaskPending})
+		}
+		m.status = "Reopen the task before starting it"
+		return m, nil
+	case key.Matches(msg, m.keys.Priority):
+		priority := (task.Priority + 1) % len(priorityNames)
+		return m, m.update(task, "Set the priority of", map[string]interface{}{"priority": priority})
+	case key.Matches(msg, m.keys.MoveLeft):
+		return m, m.moveTo(task, -1)
+	case key.Matches(msg, m.keys.MoveRight):
+		return m, m.moveTo(task, 1)
+	case key.Matches(msg, m.keys.MoveUp):
+		return m, m.reorder(task, -1)
+	case key.Matches(msg, m.keys.MoveDown):
+		return m, m.reorder(task, 1)
+	}
+	return m, nil
+}
+
+// handleInput handles the keys pressed while typing in the input line.
+func (m Model) handleInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		if m.inputKind == filterInput {
+			m.filter = ""
+			m.restoreSelection()
+		}
+		m.stopInput()
+		return m, nil
+	case key.Matches(msg, m.keys.Confirm):
+		kind, value := m.inputKind, strings.TrimSpace(m.input.Value())
+		m.stopInput()
+		switch kind {
+		case addInput:
+			if value == "" {
+				return m, nil
+			}
+			return m, m.save(fmt.Sprintf("Added %q", value), func(ctx context.Context, source Source) (*models.Tasks, error) {
+				return source.QuickAdd(ctx, value)
+			})
+		case editInput:
+			task := m.current()
+			if task == nil || value == "" || value == task.Title {
+				return m, nil
+			}
+			return m, m.update(task, "Renamed", map[string]interface{}{"title": value})
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.inputKind == filterInput {
+		m.filter = m.input.Value()
+		m.restoreSelection()
+	}
+	return m, cmd
+}
+
+func (m *Model) startInput(kind inputKind, value string) tea.Cmd {
+	m.inputKind, m.status = kind, ""
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+func (m *Model) stopInput() {
+	m.inputKind = noInput
+	m.input.Blur()
+	m.input.SetValue("")
+}
+
+// update changes the fields of the task, reporting it with the verb.
+func (m Model) update(task *models.Tasks, verb string, fields map[string]interface{}) tea.Cmd {
+	id := task.ID
+	return m.save(fmt.Sprintf("%s %q", verb, task.Title), func(ctx context.Context, source Source) (*models.Tasks, error) {
+		return source.UpdateTask(ctx, id, fields)
+	})
+}
+
+// moveTo moves the task to the end of the previous or next column of the kanban board.
+func (m Model) moveTo(task *models.Tasks, delta int) tea.Cmd {
+	groups := m.groups()
+	target := m.column + delta
+	if m.layout != kanbanLayout || target < 0 || target >= len(groups) {
+		return nil
+	}
+	id, status := task.ID, groups[target].status
+	return m.save(fmt.Sprintf("Moved %q to %s", task.Title, statusTitle(status)), func(ctx context.Context, source Source) (*models.Tasks, error) {
+		return source.MoveTask(ctx, id, handlers.MoveRequest{Status: status})
+	})
+}
+
+// reorder moves the task above or below its neighbour in its column of the kanban board.
+func (m Model) reorder(task *models.Tasks, delta int) tea.Cmd {
+	if m.layout != kanbanLayout {
+		return nil
+	}
+	tasks := m.groups()[m.column].tasks
+	target := m.row + delta
+	if target < 0 || target >= len(tasks) {
+		return nil
+	}
+
+	// The task lands between the neighbour it passes and the task beyond it
+	move := handlers.MoveRequest{}
+	passed := tasks[target].ID
+	if delta < 0 {
+		move.BeforeID = &passed
+		if target > 0 {
+			move.AfterID = &tasks[target-1].ID
+		}
+	} else {
+		move.AfterID = &passed
+		if target+1 < len(tasks) {
+			move.BeforeID = &tasks[target+1].ID
+		}
+	}
+	id := task.ID
+	return m.save(fmt.Sprintf("Moved %q", task.Title), func(ctx context.Context, source Source) (*models.Tasks, error) {
+		return source.MoveTask(ctx, id, move)
+	})
+}
+
+// group is a heading of the list, or a column of the kanban board, and its tasks.
+type group struct {
+	title  string
+	status string
+	tasks  []models.Tasks
+}
+
+// groups returns the groups of tasks shown, with the tasks matching the filter:
+// the columns of the board on the kanban board, and the statuses or labels with tasks on the list.
+func (m Model) groups() []group {
+	var groups []group
+	if m.layout == kanbanLayout || m.grouping == byStatus {
+		for _, column := range m.board {
+			tasks := m.matching(column.Tasks)
+			if m.layout == listLayout && len(tasks) == 0 {
+				continue
+			}
+			groups = append(groups, group{title: statusTitle(column.Status), status: column.Status, tasks: tasks})
+		}
+		return groups
+	}
+
+	all := m.matching(m.tasks())
+	labelled := map[uint]bool{}
+	for _, label := range m.labels {
+		tagged := map[uint]bool{}
+		for _, id := range m.tagged[label.ID] {
+			tagged[id] = true
+		}
+		var tasks []models.Tasks
+		for _, task := range all {
+			if tagged[task.ID] {
+				tasks = append(tasks, task)
+				labelled[task.ID] = true
+			}
+		}
+		if len(tasks) > 0 {
+			groups = append(groups, group{title: label.Name, tasks: tasks})
+		}
+	}
+	var unlabelled []models.Tasks
+	for _, task := range all {
+		if !labelled[task.ID] {
+			unlabelled = append(unlabelled, task)
+		}
+	}
+	if len(unlabelled) > 0 {
+		groups = append(groups, group{title: "No label", tasks: unlabelled})
+	}
+	return groups
+}
+
+// tasks returns every task of the board.
+func (m Model) tasks() []models.Tasks {
+	var tasks []models.Tasks
+	for _, column := range m.board {
+		tasks = append(tasks, column.Tasks...)
+	}
+	return tasks
+}
+
+// matching keeps the tasks containing the filter, and the open ones on the list unless the closed ones are shown.
+func (m Model) matching(tasks []models.Tasks) []models.Tasks {
+	filter := strings.ToLower(strings.TrimSpace(m.filter))
+	kept := []models.Tasks{}
+	for _, task := range tasks {
+		if m.layout == listLayout && !m.showClosed && task.CompletedAt != nil {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(task.Title), filter) &&
+			(task.Description == nil || !strings.Contains(strings.ToLower(*task.Description), filter)) {
+			continue
+		}
+		kept = append(kept, task)
+	}
+	return kept
+}
+
+// entries returns the tasks of the list, in order. Grouped by label, a task is listed under each of its labels.
+func (m Model) entries() []models.Tasks {
+	var entries []models.Tasks
+	for _, g := range m.groups() {
+		entries = append(entries, g.tasks...)
+	}
+	return entries
+}
+
+// current returns the selected task, nil when there is none.
+func (m Model) current() *models.Tasks {
+	if m.layout == listLayout {
+		entries := m.entries()
+		if m.cursor < 0 || m.cursor >= len(entries) {
+			return nil
+		}
+		return &entries[m.cursor]
+	}
+	groups := m.groups()
+	if m.column < 0 || m.column >= len(groups) || m.row < 0 || m.row >= len(groups[m.column].tasks) {
+		return nil
+	}
+	return &groups[m.column].tasks[m.row]
+}
+
+// moveCursor selects the task delta places below the selected one, or above it when delta is negative.
+func (m *Model) moveCursor(delta int) {
+	if m.layout == listLayout {
+		m.cursor = clamp(m.cursor+delta, 0, len(m.entries())-1)
+	} else if groups := m.groups(); m.column < len(groups) {
+		m.row = clamp(m.row+delta, 0, len(groups[m.column].tasks)-1)
+	}
+	if task := m.current(); task != nil {
+		m.selected = task.ID
+	}
+}
+
+// moveColumn selects a task of the column delta places to the right of the selected one, or to the left when delta is negative.
+func (m *Model) moveColumn(delta int) {
+	groups := m.groups()
+	if m.layout != kanbanLayout || len(groups) == 0 {
+		return
+	}
+	m.column = clamp(m.column+delta, 0, len(groups)-1)
+	m.row = clamp(m.row, 0, len(groups[m.column].tasks)-1)
+	if task := m.current(); task != nil {
+		m.selected = task.ID
+	}
+}
+
+// restoreSelection selects the task that was selected before the tasks or the way they are shown changed,
+// or the one in its place when it's gone.
+func (m *Model) restoreSelection() {
+	groups := m.groups()
+	if m.layout == listLayout {
+		entries := m.entries()
+		if m.cursor >= len(entries) || m.cursor < 0 || entries[m.cursor].ID != m.selected {
+			m.cursor = clamp(m.cursor, 0, len(entries)-1)
+			for i, task := range entries {
+				if task.ID == m.selected {
+					m.cursor = i
+					break
+				}
+			}
+		}
+	} else {
+		found := false
+		for c, g := range groups {
+			for r, task := range g.tasks {
+				if task.ID == m.selected && !found {
+					m.column, m.row, found = c, r, true
+				}
+			}
+		}
+		if !found {
+			m.column = clamp(m.column, 0, len(groups)-1)
+			if m.column < len(groups) {
+				m.row = clamp(m.row, 0, len(groups[m.column].tasks)-1)
+			}
+		}
+	}
+	if task := m.current(); task != nil {
+		m.selected = task.ID
+	}
+}
+
+// clamp keeps the value between low and high, or at low when high is below it.
+func clamp(value, low, high int) int {
+	return max(low, min(value, high))
+}
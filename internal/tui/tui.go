@@ -0,0 +1,72 @@
+// Package tui is the full-screen terminal interface of NullTask: the tasks of the user grouped by status or label,
+// or laid out as a kanban board, edited in place and kept up to date by the event stream of the API.
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/devgugga/NullTask/internal/client"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// Source is where the TUI reads and changes the tasks of the user.
+type Source interface {
+	// Board returns the personal tasks of the user, a column per status.
+	Board(ctx context.Context) ([]handlers.BoardColumn, error)
+	// Labels returns the personal labels of the user.
+	Labels(ctx context.Context) ([]models.Label, error)
+	// Tagged returns the IDs of the tasks tagged with the label.
+	Tagged(ctx context.Context, labelID uint) ([]uint, error)
+	QuickAdd(ctx context.Context, text string) (*models.Tasks, error)
+	UpdateTask(ctx context.Context, id uint, fields map[string]interface{}) (*models.Tasks, error)
+	MoveTask(ctx context.Context, id uint, move handlers.MoveRequest) (*models.Tasks, error)
+	DeleteTask(ctx context.Context, id uint) error
+	// Subscribe connects to the events of the user, returning once connected.
+	Subscribe(ctx context.Context) (Events, error)
+}
+
+// Events follows the events of the user, as client.EventStream does.
+type Events interface {
+	Next() (events.Event, error)
+	Close() error
+}
+
+// ClientSource returns the Source of the tasks of the user with the given ID, read and changed through the API.
+func ClientSource(api *client.Client, userID string) Source {
+	return &clientSource{Client: api, userID: userID}
+}
+
+type clientSource struct {
+	*client.Client
+	userID string
+}
+
+func (s *clientSource) Tagged(ctx context.Context, labelID uint) ([]uint, error) {
+	tasks, err := s.Tasks(ctx, s.userID, client.TaskFilter{LabelIDs: []uint{labelID}})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids, nil
+}
+
+func (s *clientSource) Subscribe(ctx context.Context) (Events, error) {
+	return s.Client.Subscribe(ctx)
+}
+
+// Run shows the TUI until the user quits or the context is cancelled.
+func Run(ctx context.Context, source Source) error {
+	program := tea.NewProgram(NewModel(ctx, source), tea.WithAltScreen(), tea.WithContext(ctx))
+	final, err := program.Run()
+	if m, ok := final.(Model); ok && m.stream != nil {
+		m.stream.Close()
+	}
+	return err
+}
@@ -0,0 +1,381 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// fakeSource keeps the tasks in memory and records the changes asked of it.
+type fakeSource struct {
+	mu      sync.Mutex
+	tasks   []models.Tasks
+	labels  []models.Label
+	tagged  map[uint][]uint
+	loads   int
+	changes []string
+	moves   []handlers.MoveRequest
+	events  chan events.Event
+}
+
+func newFakeSource() *fakeSource {
+	completed := time.Now()
+	return &fakeSource{
+		tasks: []models.Tasks{
+			{ID: 1, Title: "Pay rent", Status: models.TaskPending, Priority: models.PriorityHigh},
+			{ID: 2, Title: "Water plants", Status: models.TaskPending},
+			{ID: 3, Title: "Write report", Status: models.TaskInProgress},
+			{ID: 4, Title: "Call mom", Status: models.TaskDone, CompletedAt: &completed},
+		},
+		labels: []models.Label{{ID: 7, Name: "home"}},
+		tagged: map[uint][]uint{7: {2}},
+		events: make(chan events.Event, 8),
+	}
+}
+
+func (s *fakeSource) record(change string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, change)
+}
+
+func (s *fakeSource) Board(context.Context) ([]handlers.BoardColumn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	columns := []handlers.BoardColumn{}
+	for _, status := range models.DefaultWorkflow().Statuses {
+		column := handlers.BoardColumn{Status: status.Name, Tasks: []models.Tasks{}}
+		for _, task := range s.tasks {
+			if task.Status == status.Name {
+				column.Tasks = append(column.Tasks, task)
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func (s *fakeSource) Labels(context.Context) ([]models.Label, error) {
+	return s.labels, nil
+}
+
+func (s *fakeSource) Tagged(_ context.Context, labelID uint) ([]uint, error) {
+	s.record("tagged")
+	return s.tagged[labelID], nil
+}
+
+func (s *fakeSource) QuickAdd(_ context.Context, text string) (*models.Tasks, error) {
+	s.record("add " + text)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task := models.Tasks{ID: 5, Title: text, Status: models.TaskPending}
+	s.tasks = append(s.tasks, task)
+	return &task, nil
+}
+
+func (s *fakeSource) UpdateTask(_ context.Context, id uint, fields map[string]interface{}) (*models.Tasks, error) {
+	for field, value := range fields {
+		s.record(field + " " + strings.TrimSpace(strings.Trim(strings.ReplaceAll(toString(value), "\n", " "), `"`)))
+	}
+	return s.task(id), nil
+}
+
+func (s *fakeSource) MoveTask(_ context.Context, id uint, move handlers.MoveRequest) (*models.Tasks, error) {
+	s.mu.Lock()
+	s.moves = append(s.moves, move)
+	for i := range s.tasks {
+		if s.tasks[i].ID == id && move.Status != "" {
+			s.tasks[i].Status = move.Status
+		}
+	}
+	s.mu.Unlock()
+	return s.task(id), nil
+}
+
+func (s *fakeSource) DeleteTask(_ context.Context, id uint) error {
+	s.record("delete")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tasks {
+		if s.tasks[i].ID == id {
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *fakeSource) Subscribe(context.Context) (Events, error) {
+	return fakeEvents(s.events), nil
+}
+
+func (s *fakeSource) task(id uint) *models.Tasks {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, task := range s.tasks {
+		if task.ID == id {
+			return &task
+		}
+	}
+	return nil
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return priorityNames[v]
+	}
+	return ""
+}
+
+// fakeEvents delivers the events sent on the channel, and ends the stream when it's closed.
+type fakeEvents chan events.Event
+
+func (e fakeEvents) Next() (events.Event, error) {
+	event, ok := <-e
+	if !ok {
+		return events.Event{}, io.EOF
+	}
+	return event, nil
+}
+
+func (e fakeEvents) Close() error {
+	return nil
+}
+
+// harness drives a model as the program would, leaving aside the commands still running after a moment,
+// such as the one waiting for the next event, until the test waits for them.
+type harness struct {
+	t       *testing.T
+	m       Model
+	waiting []chan tea.Msg
+}
+
+// start returns the harness of a model connected and loaded.
+func start(t *testing.T, source *fakeSource) *harness {
+	t.Helper()
+	h := &harness{t: t, m: NewModel(context.Background(), source)}
+	h.m.retryDelay = time.Hour
+	h.feed(tea.WindowSizeMsg{Width: 100, Height: 30})
+	h.feed(h.run(h.m.Init())...)
+	if !h.m.loaded || !h.m.live {
+		t.Fatalf("expected the model to be loaded and live, got %+v", h.m)
+	}
+	return h
+}
+
+// feed updates the model with the messages, and then with the messages of the commands it returns.
+func (h *harness) feed(msgs ...tea.Msg) {
+	queue := msgs
+	for len(queue) > 0 {
+		msg := queue[0]
+		queue = queue[1:]
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, cmd := range batch {
+				queue = append(queue, h.run(cmd)...)
+			}
+			continue
+		}
+		next, cmd := h.m.Update(msg)
+		h.m = next.(Model)
+		queue = append(queue, h.run(cmd)...)
+	}
+}
+
+// run runs the command, returning its message unless it's still running after a moment.
+func (h *harness) run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		if msg == nil {
+			return nil
+		}
+		return []tea.Msg{msg}
+	case <-time.After(50 * time.Millisecond):
+		h.waiting = append(h.waiting, done)
+		return nil
+	}
+}
+
+// wait feeds the model the message of the first command left running to end.
+func (h *harness) wait() {
+	h.t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		for i, done := range h.waiting {
+			select {
+			case msg := <-done:
+				h.waiting = append(h.waiting[:i], h.waiting[i+1:]...)
+				if msg != nil {
+					h.feed(msg)
+				}
+				return
+			default:
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.t.Fatal("expected a command to end")
+}
+
+// press feeds the keys to the model, each character being a key, except for the named keys between angle brackets.
+func (h *harness) press(keys ...string) {
+	for _, k := range keys {
+		switch k {
+		case "<enter>":
+			h.feed(tea.KeyMsg{Type: tea.KeyEnter})
+		case "<esc>":
+			h.feed(tea.KeyMsg{Type: tea.KeyEsc})
+		case "<tab>":
+			h.feed(tea.KeyMsg{Type: tea.KeyTab})
+		default:
+			for _, r := range k {
+				h.feed(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+		}
+	}
+}
+
+func titles(groups []group) []string {
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.title)
+	}
+	return names
+}
+
+func TestListGroupsAndFilters(t *testing.T) {
+	h := start(t, newFakeSource())
+
+	if got := strings.Join(titles(h.m.groups()), "|"); got != "Pending|In progress" {
+		t.Fatalf("expected the open tasks by status, got %q", got)
+	}
+	if task := h.m.current(); task == nil || task.ID != 1 {
+		t.Fatalf("expected the first task to be selected, got %+v", task)
+	}
+	view := h.m.View()
+	if !strings.Contains(view, "Pay rent") || strings.Contains(view, "Call mom") || !strings.Contains(view, "live") {
+		t.Fatalf("unexpected view:\n%s", view)
+	}
+
+	h.press("c")
+	if got := strings.Join(titles(h.m.groups()), "|"); got != "Pending|In progress|Done" {
+		t.Fatalf("expected the closed tasks to be shown, got %q", got)
+	}
+
+	h.press("jj", "/", "rep")
+	if h.m.filter != "rep" || len(h.m.entries()) != 1 || h.m.current().ID != 3 {
+		t.Fatalf("expected the filter to keep the report, got %q and %+v", h.m.filter, h.m.entries())
+	}
+	h.press("<esc>")
+	if h.m.filter != "" || h.m.current().ID != 3 {
+		t.Fatalf("expected the filter to be cleared keeping the selection, got %q and %+v", h.m.filter, h.m.current())
+	}
+
+	h.press("o")
+	if got := strings.Join(titles(h.m.groups()), "|"); got != "home|No label" {
+		t.Fatalf("expected the tasks by label, got %q", got)
+	}
+	if h.m.current().ID != 3 {
+		t.Fatalf("expected the selection to follow the task, got %+v", h.m.current())
+	}
+}
+
+func TestKeysChangeTasks(t *testing.T) {
+	source := newFakeSource()
+	h := start(t, source)
+
+	h.press("j", "x", "p")
+	h.press("e", "!", "<enter>")
+	h.press("a", "Buy milk tomorrow", "<enter>")
+	if h.m.current() == nil || h.m.current().ID != 5 {
+		t.Fatalf("expected the added task to be selected, got %+v", h.m.current())
+	}
+	h.press("d", "n")
+	h.press("d")
+	if !strings.Contains(h.m.View(), `Delete "Buy milk tomorrow"? (y/n)`) {
+		t.Fatalf("expected to be asked to confirm, got:\n%s", h.m.View())
+	}
+	h.press("y")
+
+	want := []string{"status done", "priority low", "title Water plants!", "add Buy milk tomorrow", "delete"}
+	if strings.Join(source.changes, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected the changes %q, got %q", want, source.changes)
+	}
+	if h.m.status != `Deleted "Buy milk tomorrow"` || source.task(5) != nil {
+		t.Fatalf("expected the task to be deleted, got %q", h.m.status)
+	}
+}
+
+func TestKanban(t *testing.T) {
+	source := newFakeSource()
+	h := start(t, source)
+
+	h.press("<tab>")
+	if got := strings.Join(titles(h.m.groups()), "|"); got != "Pending|In progress|Done|Cancelled" {
+		t.Fatalf("expected a column per status, got %q", got)
+	}
+	if !strings.Contains(h.m.View(), "Call mom") {
+		t.Fatalf("expected the kanban board to show the closed tasks, got:\n%s", h.m.View())
+	}
+
+	// Moving to the next column follows the task there
+	h.press("L")
+	if len(source.moves) != 1 || source.moves[0].Status != models.TaskInProgress {
+		t.Fatalf("expected the task to move to in progress, got %+v", source.moves)
+	}
+	if h.m.column != 1 || h.m.current().ID != 1 {
+		t.Fatalf("expected the moved task to stay selected, got column %d and %+v", h.m.column, h.m.current())
+	}
+
+	h.press("K")
+	if len(source.moves) != 1 {
+		t.Fatalf("expected nothing to move above the first task, got %+v", source.moves)
+	}
+	h.press("J")
+	if len(source.moves) != 2 {
+		t.Fatalf("expected the task to move down, got %+v", source.moves)
+	}
+	move := source.moves[1]
+	if move.Status != "" || move.AfterID == nil || *move.AfterID != 3 || move.BeforeID != nil {
+		t.Fatalf("expected the rent to move after the report, got %+v", move)
+	}
+	if h.m.current().ID != 1 {
+		t.Fatalf("expected the moved task to stay selected, got %+v", h.m.current())
+	}
+}
+
+func TestLiveUpdates(t *testing.T) {
+	source := newFakeSource()
+	h := start(t, source)
+	loads := source.loads
+
+	source.tasks = append(source.tasks, models.Tasks{ID: 9, Title: "Added elsewhere", Status: models.TaskPending})
+	source.events <- events.Event{Type: events.TaskCreated, TaskID: 9, OccurredAt: time.Now()}
+	h.wait()
+	if source.loads != loads+1 || len(h.m.entries()) != 4 || h.m.lastEventAt.IsZero() {
+		t.Fatalf("expected the event to reload the tasks, got %d loads and %+v", source.loads, h.m.entries())
+	}
+
+	close(source.events)
+	h.wait()
+	if h.m.live || !strings.Contains(h.m.View(), "offline") {
+		t.Fatalf("expected the ended stream to leave the model offline, got:\n%s", h.m.View())
+	}
+}
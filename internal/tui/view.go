@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// priorityNames are the names of the priorities, indexed by their level.
+var priorityNames = []string{"none", "low", "medium", "high"}
+
+// minColumnWidth is the narrowest a column of the kanban board gets; fewer columns are shown on narrow terminals.
+const minColumnWidth = 24
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("5"))
+	faintStyle    = lipgloss.NewStyle().Faint(true)
+	headingStyle  = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	overdueStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	liveStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	columnStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).Padding(0, 1)
+	focusedStyle  = columnStyle.BorderForeground(lipgloss.Color("5"))
+
+	statusColors = map[string]lipgloss.Color{
+		models.TaskPending:    lipgloss.Color("4"),
+		models.TaskInProgress: lipgloss.Color("3"),
+		models.TaskDone:       lipgloss.Color("2"),
+		models.TaskCancelled:  lipgloss.Color("8"),
+	}
+	priorityColors = []lipgloss.Color{"8", "4", "3", "1"}
+)
+
+// View renders the header, the list or the kanban board, and the input line, status and help at the bottom.
+func (m Model) View() string {
+	header := m.headerView()
+	footer := m.footerView()
+	height := m.height - lipgloss.Height(header) - lipgloss.Height(footer)
+
+	var body string
+	switch {
+	case !m.loaded && m.err == nil:
+		body = faintStyle.Render("Loading…")
+	case m.layout == kanbanLayout:
+		body = m.kanbanView(height)
+	default:
+		body = m.listView(height)
+	}
+	body = lipgloss.NewStyle().Height(max(height, 0)).MaxHeight(max(height, 0)).Render(body)
+	return lipgloss.JoinVertical(lipgloss.Left, header, body, footer)
+}
+
+func (m Model) headerView() string {
+	view := "list by status"
+	switch {
+	case m.layout == kanbanLayout:
+		view = "kanban"
+	case m.grouping == byLabel:
+		view = "list by label"
+	}
+	parts := []string{titleStyle.Render("NullTask"), view}
+	if m.filter != "" {
+		parts = append(parts, fmt.Sprintf("filter %q", m.filter))
+	}
+	if m.layout == listLayout && m.showClosed {
+		parts = append(parts, "with closed")
+	}
+	if m.live {
+		live := liveStyle.Render("● live")
+		if !m.lastEventAt.IsZero() {
+			live += faintStyle.Render(", changed at " + m.lastEventAt.Local().Format("15:04"))
+		}
+		parts = append(parts, live)
+	} else {
+		parts = append(parts, faintStyle.Render("○ offline"))
+	}
+	return strings.Join(parts, faintStyle.Render(" · ")) + "\n"
+}
+
+func (m Model) footerView() string {
+	var line string
+	switch {
+	case m.deleting != nil:
+		line = fmt.Sprintf("Delete %q? (y/n)", m.deleting.Title)
+	case m.inputKind == filterInput:
+		line = "Filter: " + m.input.View()
+	case m.inputKind == addInput:
+		line = "Add: " + m.input.View() + faintStyle.Render("  e.g. Pay rent tomorrow 9am #finance !high")
+	case m.err != nil:
+		line = errorStyle.Render("Error: " + m.err.Error())
+	default:
+		line = faintStyle.Render(m.status)
+	}
+	return "\n" + lipgloss.NewStyle().MaxWidth(m.width).Render(line) + "\n" + m.help.View(m.keys)
+}
+
+// listView renders the groups of tasks one under the other, scrolled to show the selected task.
+func (m Model) listView(height int) string {
+	groups := m.groups()
+	if len(groups) == 0 {
+		return faintStyle.Render(m.emptyMessage())
+	}
+
+	var lines []string
+	selectedLine, index := 0, 0
+	for _, g := range groups {
+		heading := headingStyle.Foreground(statusColors[g.status]).Render(g.title)
+		lines = append(lines, heading+faintStyle.Render(fmt.Sprintf(" %d", len(g.tasks))))
+		for _, task := range g.tasks {
+			selected := index == m.cursor
+			if selected {
+				selectedLine = len(lines)
+			}
+			lines = append(lines, m.taskLine(task, selected, m.width))
+			index++
+		}
+		lines = append(lines, "")
+	}
+
+	offset := 0
+	if height > 0 && selectedLine >= height {
+		offset = selectedLine - height + 1
+	}
+	return strings.Join(lines[offset:], "\n")
+}
+
+// kanbanView renders the columns of the board side by side, as many as fit, around the selected one.
+func (m Model) kanbanView(height int) string {
+	groups := m.groups()
+	if len(groups) == 0 {
+		return faintStyle.Render(m.emptyMessage())
+	}
+
+	shown := clamp(m.width/minColumnWidth, 1, len(groups))
+	first := clamp(m.column-shown/2, 0, len(groups)-shown)
+	width := m.width/shown - columnStyle.GetHorizontalFrameSize()
+	// The border and the heading of the column take 4 lines
+	rows := max(height-4, 1)
+
+	columns := make([]string, 0, shown)
+	for c := first; c < first+shown; c++ {
+		g := groups[c]
+		heading := headingStyle.Foreground(statusColors[g.status]).Render(g.title) + faintStyle.Render(fmt.Sprintf(" %d", len(g.tasks)))
+		lines := []string{heading}
+
+		offset := 0
+		if c == m.column && m.row >= rows {
+			offset = m.row - rows + 1
+		}
+		for r := offset; r < len(g.tasks) && r < offset+rows; r++ {
+			lines = append(lines, m.taskLine(g.tasks[r], c == m.column && r == m.row, width))
+		}
+		if len(g.tasks) == 0 {
+			lines = append(lines, faintStyle.Render("empty"))
+		}
+
+		style := columnStyle
+		if c == m.column {
+			style = focusedStyle
+		}
+		columns = append(columns, style.Width(width+style.GetHorizontalPadding()).Height(rows+1).Render(strings.Join(lines, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// taskLine renders a task in the given width: its checkbox, title, priority and due date.
+// The selected task is highlighted, or replaced by the input line while its title is edited.
+func (m Model) taskLine(task models.Tasks, selected bool, width int) string {
+	box := "[ ]"
+	switch {
+	case task.Status == models.TaskInProgress:
+		box = "[>]"
+	case task.Status == models.TaskCancelled:
+		box = "[-]"
+	case task.CompletedAt != nil:
+		box = "[x]"
+	}
+
+	var meta []string
+	if task.Blocked {
+		meta = append(meta, faintStyle.Render("blocked"))
+	}
+	if task.Priority > 0 && task.Priority < len(priorityNames) {
+		meta = append(meta, lipgloss.NewStyle().Foreground(priorityColors[task.Priority]).Render(strings.Repeat("!", task.Priority)))
+	}
+	if task.DueDate != nil {
+		due := formatDue(*task.DueDate, time.Now())
+		if task.CompletedAt == nil && task.DueDate.Before(time.Now()) {
+			due = overdueStyle.Render(due)
+		}
+		meta = append(meta, due)
+	}
+	suffix := ""
+	if len(meta) > 0 {
+		suffix = " " + strings.Join(meta, " ")
+	}
+
+	if selected && m.inputKind == editInput {
+		return "› " + box + " " + m.input.View()
+	}
+	titleWidth := max(width-lipgloss.Width(box)-lipgloss.Width(suffix)-3, 1)
+	title := truncate(task.Title, titleWidth)
+	if task.CompletedAt != nil {
+		title = faintStyle.Render(title)
+	}
+	if selected {
+		return "› " + selectedStyle.Render(box+" "+title) + suffix
+	}
+	return "  " + box + " " + title + suffix
+}
+
+// emptyMessage explains why there's no task to show.
+func (m Model) emptyMessage() string {
+	switch {
+	case m.err != nil:
+		return "Couldn't load the tasks."
+	case m.filter != "":
+		return "No task matches the filter. Press esc on it to clear it."
+	default:
+		return "No tasks. Press a to add one."
+	}
+}
+
+// statusTitle turns a status into a heading, as "in_progress" into "In progress".
+func statusTitle(status string) string {
+	title := strings.ReplaceAll(status, "_", " ")
+	if title == "" {
+		return title
+	}
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// formatDue formats a due date in the local time zone, shorter when it's this year,
+// and without the time when it's due at the end of the day.
+func formatDue(due, now time.Time) string {
+	due = due.Local()
+	layout := "Jan 2"
+	if due.Year() != now.Year() {
+		layout = "Jan 2 2006"
+	}
+	if due.Hour() != 23 || due.Minute() != 59 {
+		layout += " 15:04"
+	}
+	return due.Format(layout)
+}
+
+// truncate cuts the text to the given width, ending it with an ellipsis when it's cut.
+func truncate(text string, width int) string {
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
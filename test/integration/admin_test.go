@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/config"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+)
+
+// setDeactivated deactivates the user, or reactivates them, as the deactivate-user command of the server does.
+func (a *testApp) setDeactivated(userID string, deactivated bool) {
+	a.t.Helper()
+
+	users := repository.NewGormUserRepository(a.db)
+	user, err := users.FindByID(context.Background(), userID)
+	if err != nil {
+		a.t.Fatalf("finding the user: %v", err)
+	}
+	user.DeactivatedAt = nil
+	if deactivated {
+		now := time.Now()
+		user.DeactivatedAt = &now
+	}
+	if err := users.Update(context.Background(), user); err != nil {
+		a.t.Fatalf("updating the user: %v", err)
+	}
+}
+
+func TestDeactivatedUser(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	feed := app.createCalendarFeed(token)
+
+	app.setDeactivated(user.ID, true)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/board", nil, token), http.StatusUnauthorized)
+	expectStatus(t, app.request(http.MethodPost, "/users/login", handlers.LoginRequest{Email: user.Email, Password: testPassword}, ""), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPost, "/users/login", handlers.LoginRequest{Email: user.Email, Password: "wrong-password"}, ""), http.StatusUnauthorized)
+	expectStatus(t, app.dav("PROPFIND", "/dav/", "", user, map[string]string{"Depth": "0"}), http.StatusUnauthorized)
+	expectStatus(t, app.calendar("/calendar/feed/"+feed.Token+".ics", ""), http.StatusUnauthorized)
+
+	app.setDeactivated(user.ID, false)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/board", nil, token), http.StatusOK)
+	app.login(user.Email, testPassword)
+	expectStatus(t, app.dav("PROPFIND", "/dav/", "", user, map[string]string{"Depth": "0"}), http.StatusMultiStatus)
+	expectStatus(t, app.calendar("/calendar/feed/"+feed.Token+".ics", ""), http.StatusOK)
+}
+
+func TestUsersCantChangeTheirStanding(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.request(http.MethodPost, "/users/create", map[string]interface{}{
+		"name": "Eve", "email": "eve@nulltask.test", "password": testPassword, "age": 30, "admin": true, "deactivated_at": time.Now(),
+	}, "")
+	expectStatus(t, rec, http.StatusCreated)
+	var user models.User
+	decode(t, rec, &user)
+	if user.Admin || user.DeactivatedAt != nil {
+		t.Fatalf("expected a regular active user, got %+v", user)
+	}
+
+	token := app.login(user.Email, testPassword)
+	rec = app.request(http.MethodPut, "/users/update/id/"+user.ID, map[string]interface{}{
+		"name": "Eve", "email": user.Email, "password": testPassword, "age": 30, "admin": true,
+	}, token)
+	expectStatus(t, rec, http.StatusOK)
+	stored, err := repository.NewGormUserRepository(app.db).FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Admin {
+		t.Fatalf("expected the user not to become an admin, got %+v", stored)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	ctx := context.Background()
+	tasks := repository.NewGormTaskRepository(app.db)
+
+	parent := app.createTask(token, map[string]interface{}{"title": "Old project"})
+	child := app.createTask(token, map[string]interface{}{"title": "Old step", "parent_id": parent.ID})
+	kept := app.createTask(token, map[string]interface{}{"title": "Current project"})
+	if err := tasks.AddChecklistItem(ctx, &models.ChecklistItem{TaskID: parent.ID, Title: "Plan"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint{child.ID, parent.ID} {
+		expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", id), nil, token), http.StatusOK)
+	}
+
+	if deleted, err := tasks.FindDeleted(ctx, time.Now().Add(-time.Hour)); err != nil || len(deleted) != 0 {
+		t.Fatalf("expected no task deleted an hour ago, got %+v and %v", deleted, err)
+	}
+	deleted, err := tasks.FindDeleted(ctx, time.Now().Add(time.Second))
+	if err != nil || len(deleted) != 2 || deleted[0].ID != child.ID || deleted[1].ID != parent.ID {
+		t.Fatalf("expected the deleted tasks, oldest first, got %+v and %v", deleted, err)
+	}
+
+	if err := tasks.Purge(ctx, kept.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected a task outside of the trash not to be purged, got %v", err)
+	}
+	if err := tasks.Purge(ctx, parent.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tasks.Purge(ctx, parent.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected the purged task to be gone, got %v", err)
+	}
+
+	var count int64
+	app.db.Unscoped().Model(&models.Tasks{}).Where("id = ?", parent.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected the task to be removed from the database")
+	}
+	app.db.Model(&models.ChecklistItem{}).Where("task_id = ?", parent.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected the checklist of the task to be removed, %d items left", count)
+	}
+	var orphan models.Tasks
+	app.db.Unscoped().First(&orphan, child.ID)
+	if orphan.ParentID != nil {
+		t.Fatalf("expected the deleted subtask to stop pointing at the purged task, got %+v", orphan)
+	}
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", kept.ID), nil, token), http.StatusOK)
+}
+
+func TestReindexSearch(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Renew passport"})
+	path := "/tasks/search/user/" + user.ID + "?q=passport"
+
+	// Empty the index, as if it had drifted apart from the tasks
+	if err := app.db.Exec(`INSERT INTO tasks_fts(tasks_fts) VALUES ('delete-all')`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ids := app.taskIDsOf(token, path); len(ids) != 0 {
+		t.Fatalf("expected the emptied index to find nothing, got %v", ids)
+	}
+
+	if err := config.ReindexSearch(app.db); err != nil {
+		t.Fatal(err)
+	}
+	if ids := app.taskIDsOf(token, path); len(ids) != 1 || ids[0] != task.ID {
+		t.Fatalf("expected the reindexed search to find the task, got %v", ids)
+	}
+}
@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestAssignTask(t *testing.T) {
+	app := newTestApp(t)
+	owner, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	stranger, _ := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+	task := app.createTask(ownerToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+
+	assignPath := fmt.Sprintf("/tasks/assign/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/tasks/watch/id/%d", task.ID), nil, ownerToken), http.StatusCreated)
+
+	rec := app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": viewer.ID}, editorToken)
+	expectStatus(t, rec, http.StatusCreated)
+	var assignee models.TaskAssignee
+	decode(t, rec, &assignee)
+	if assignee.TaskID != task.ID || assignee.UserID != viewer.ID || assignee.AssignedBy != editor.ID {
+		t.Fatalf("unexpected assignment: %+v", assignee)
+	}
+
+	assignments := app.eventsOf(events.TaskAssigned)
+	if len(assignments) != 1 {
+		t.Fatalf("expected one event, got %+v", app.events)
+	}
+	event := assignments[0]
+	if event.Type != events.TaskAssigned || event.TaskID != task.ID || event.UserID != viewer.ID || event.ActorID != editor.ID {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	if len(event.Recipients) != 2 || event.Recipients[0] != viewer.ID || event.Recipients[1] != owner.ID {
+		t.Fatalf("expected the assignee and the watcher to be notified, got %v", event.Recipients)
+	}
+
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": viewer.ID}, editorToken), http.StatusConflict)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": editor.ID}, viewerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": stranger.ID}, editorToken), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": "not-a-uuid"}, editorToken), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": "3f2a1c1e-8d6b-4a44-9a8e-2b0d4b7f1c11"}, editorToken), http.StatusNotFound)
+
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/assignees", task.ID), nil, viewerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var assignees []models.TaskAssignee
+	decode(t, rec, &assignees)
+	if len(assignees) != 1 || assignees[0].UserID != viewer.ID {
+		t.Fatalf("expected %s to be the only assignee, got %+v", viewer.ID, assignees)
+	}
+
+	rec = app.request(http.MethodGet, "/tasks/get/assigned", nil, viewerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected task %d to be assigned to the viewer, got %+v", task.ID, tasks)
+	}
+
+	// Assigned users who leave the workspace no longer see its tasks
+	expectStatus(t, app.request(http.MethodDelete, "/workspaces/delete/id/"+workspace.ID+"/members/"+viewer.ID, nil, viewerToken), http.StatusOK)
+	rec = app.request(http.MethodGet, "/tasks/get/assigned", nil, viewerToken)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &tasks)
+	if len(tasks) != 0 {
+		t.Fatalf("expected no visible assigned task, got %+v", tasks)
+	}
+}
+
+func TestAssignPersonalTask(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	other, _ := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+
+	assignPath := fmt.Sprintf("/tasks/assign/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": other.ID}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": user.ID}, token), http.StatusCreated)
+
+	if assignments := app.eventsOf(events.TaskAssigned); len(assignments) != 1 || len(assignments[0].Recipients) != 0 {
+		t.Fatalf("expected one event without recipients for a self assignment, got %+v", app.events)
+	}
+}
+
+func TestUnassignTask(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+	task := app.createTask(ownerToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+
+	assignPath := fmt.Sprintf("/tasks/assign/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": editor.ID}, ownerToken), http.StatusCreated)
+	expectStatus(t, app.request(http.MethodPost, assignPath, map[string]interface{}{"user_id": viewer.ID}, ownerToken), http.StatusCreated)
+
+	unassignPath := fmt.Sprintf("/tasks/unassign/id/%d/user/", task.ID)
+	expectStatus(t, app.request(http.MethodDelete, unassignPath+editor.ID, nil, viewerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodDelete, unassignPath+viewer.ID, nil, viewerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, unassignPath+editor.ID, nil, ownerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, unassignPath+editor.ID, nil, ownerToken), http.StatusNotFound)
+
+	last := app.events[len(app.events)-1]
+	if last.Type != events.TaskUnassigned || last.UserID != editor.ID || len(last.Recipients) != 1 || last.Recipients[0] != editor.ID {
+		t.Fatalf("unexpected event: %+v", last)
+	}
+}
+
+func TestWatchTask(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	_, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+
+	watchPath := fmt.Sprintf("/tasks/watch/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodPost, watchPath, nil, otherToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodPost, watchPath, nil, token), http.StatusCreated)
+	expectStatus(t, app.request(http.MethodPost, watchPath, nil, token), http.StatusConflict)
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/watchers", task.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var watchers []models.TaskWatcher
+	decode(t, rec, &watchers)
+	if len(watchers) != 1 || watchers[0].UserID != user.ID {
+		t.Fatalf("expected %s to be the only watcher, got %+v", user.ID, watchers)
+	}
+
+	rec = app.request(http.MethodGet, "/tasks/get/watching", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Fatalf("expected task %d to be watched, got %+v", task.ID, tasks)
+	}
+
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/unwatch/id/%d", task.ID), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/unwatch/id/%d", task.ID), nil, token), http.StatusNotFound)
+
+	// Deleting the task also stops every watch
+	expectStatus(t, app.request(http.MethodPost, watchPath, nil, token), http.StatusCreated)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", task.ID), nil, token), http.StatusOK)
+	rec = app.request(http.MethodGet, "/tasks/get/watching", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &tasks)
+	if len(tasks) != 0 {
+		t.Fatalf("expected deleted tasks to be left out, got %+v", tasks)
+	}
+}
@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// pngHeader is the signature every PNG file starts with, enough for the content type to be detected.
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0, 0, 0, 0x0d, 'I', 'H', 'D', 'R'}
+
+// upload sends the content as the "file" field of a multipart request to the task's upload endpoint.
+func (a *testApp) upload(token string, taskID uint, fileName string, content []byte) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		a.t.Fatalf("creating the multipart body: %v", err)
+	}
+	part.Write(content)
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tasks/attach/id/%d", taskID), &body)
+	req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+	rec := httptest.NewRecorder()
+	a.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUploadAttachment(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	_, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Fix the layout"})
+
+	content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 100)...)
+	rec := app.upload(token, task.ID, `C:\Users\ana\screenshot.txt`, content)
+	expectStatus(t, rec, http.StatusCreated)
+	var attachment models.Attachment
+	decode(t, rec, &attachment)
+	if attachment.ContentType != "image/png" {
+		t.Fatalf("expected the content type to be sniffed as image/png, got %q", attachment.ContentType)
+	}
+	if attachment.FileName != "screenshot.txt" || attachment.Size != int64(len(content)) || attachment.UploaderID != user.ID {
+		t.Fatalf("unexpected attachment: %+v", attachment)
+	}
+
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/attachments", task.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var attachments []models.Attachment
+	decode(t, rec, &attachments)
+	if len(attachments) != 1 || attachments[0].ID != attachment.ID {
+		t.Fatalf("expected attachment %d to be listed, got %+v", attachment.ID, attachments)
+	}
+
+	expectStatus(t, app.upload(otherToken, task.ID, "x.txt", []byte("hi")), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/attachments/download/id/%d", attachment.ID), nil, otherToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/tasks/attach/id/%d", task.ID), map[string]interface{}{"file": "x"}, token), http.StatusBadRequest)
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Read the report"})
+
+	rec := app.upload(token, task.ID, "report.txt", []byte("hello, world"))
+	expectStatus(t, rec, http.StatusCreated)
+	var attachment models.Attachment
+	decode(t, rec, &attachment)
+	if !strings.HasPrefix(attachment.ContentType, "text/plain") {
+		t.Fatalf("expected a text/plain attachment, got %q", attachment.ContentType)
+	}
+
+	path := fmt.Sprintf("/attachments/download/id/%d", attachment.ID)
+	rec = app.request(http.MethodGet, path, nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Body.String() != "hello, world" {
+		t.Fatalf("unexpected downloaded content %q", rec.Body.String())
+	}
+	if disposition := rec.Header().Get(echo.HeaderContentDisposition); disposition != `attachment; filename=report.txt` {
+		t.Fatalf("unexpected Content-Disposition %q", disposition)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	req.Header.Set("Range", "bytes=7-")
+	rec = httptest.NewRecorder()
+	app.e.ServeHTTP(rec, req)
+	expectStatus(t, rec, http.StatusPartialContent)
+	if body, _ := io.ReadAll(rec.Body); string(body) != "world" || rec.Header().Get("Content-Range") != "bytes 7-11/12" {
+		t.Fatalf("unexpected partial content %q with range %q", body, rec.Header().Get("Content-Range"))
+	}
+}
+
+func TestAttachmentQuota(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Collect the invoices"})
+
+	half := bytes.Repeat([]byte("a"), testAttachmentQuota/2)
+	expectStatus(t, app.upload(token, task.ID, "first.txt", half), http.StatusCreated)
+	expectStatus(t, app.upload(token, task.ID, "second.txt", half), http.StatusCreated)
+	expectStatus(t, app.upload(token, task.ID, "third.txt", []byte("a")), http.StatusRequestEntityTooLarge)
+
+	rec := app.request(http.MethodGet, "/attachments/get/usage", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var usage handlers.AttachmentUsageResponse
+	decode(t, rec, &usage)
+	if usage.Used != testAttachmentQuota || usage.Quota != testAttachmentQuota {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+
+	// Deleting an attachment frees its space
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/attachments", task.ID), nil, token)
+	var attachments []models.Attachment
+	decode(t, rec, &attachments)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/attachments/delete/id/%d", attachments[0].ID), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/attachments/download/id/%d", attachments[0].ID), nil, token), http.StatusNotFound)
+	expectStatus(t, app.upload(token, task.ID, "third.txt", []byte("a")), http.StatusCreated)
+
+	big := bytes.Repeat([]byte("a"), testAttachmentQuota)
+	expectStatus(t, app.upload(token, task.ID, "big.txt", big), http.StatusRequestEntityTooLarge)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+	task := app.createTask(ownerToken, map[string]interface{}{"title": "Plan the trip", "workspace_id": workspace.ID})
+
+	expectStatus(t, app.upload(viewerToken, task.ID, "map.txt", []byte("north")), http.StatusForbidden)
+
+	rec := app.upload(ownerToken, task.ID, "map.txt", []byte("north"))
+	expectStatus(t, rec, http.StatusCreated)
+	var attachment models.Attachment
+	decode(t, rec, &attachment)
+
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/attachments/download/id/%d", attachment.ID), nil, viewerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/attachments/delete/id/%d", attachment.ID), nil, viewerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/attachments/delete/id/%d", attachment.ID), nil, editorToken), http.StatusOK)
+}
@@ -0,0 +1,240 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/config"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// moveTask moves the task on its board, expecting the given status code.
+func (a *testApp) moveTask(token string, taskID uint, move map[string]interface{}, code int) {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPut, fmt.Sprintf("/tasks/move/id/%d", taskID), move, token)
+	expectStatus(a.t, rec, code)
+}
+
+// board fetches a board and returns the IDs of the tasks of each column, by status.
+func (a *testApp) board(token, path string) ([]handlers.BoardColumn, map[string][]uint) {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, path, nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+	var columns []handlers.BoardColumn
+	decode(a.t, rec, &columns)
+
+	ids := map[string][]uint{}
+	for _, column := range columns {
+		ids[column.Status] = []uint{}
+		for _, task := range column.Tasks {
+			ids[column.Status] = append(ids[column.Status], task.ID)
+		}
+	}
+	return columns, ids
+}
+
+// expectColumn fails the test unless the column holds exactly the given tasks, in order.
+func expectColumn(t *testing.T, ids map[string][]uint, status string, want ...uint) {
+	t.Helper()
+
+	got := ids[status]
+	if len(got) != len(want) {
+		t.Fatalf("expected the %s column to be %v, got %v", status, want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected the %s column to be %v, got %v", status, want, got)
+		}
+	}
+}
+
+func TestBoard(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	first := app.createTask(token, map[string]interface{}{"title": "First"})
+	second := app.createTask(token, map[string]interface{}{"title": "Second"})
+	third := app.createTask(token, map[string]interface{}{"title": "Third"})
+
+	columns, ids := app.board(token, "/tasks/get/board")
+	if len(columns) != 4 || columns[0].Status != models.TaskPending {
+		t.Fatalf("expected a column per status of the default workflow, got %+v", columns)
+	}
+	expectColumn(t, ids, models.TaskPending, first.ID, second.ID, third.ID)
+
+	// Reordering within the column only touches the moved task
+	app.moveTask(token, third.ID, map[string]interface{}{"before_id": first.ID}, http.StatusOK)
+	app.moveTask(token, first.ID, map[string]interface{}{"after_id": third.ID, "before_id": second.ID}, http.StatusOK)
+	_, ids = app.board(token, "/tasks/get/board")
+	expectColumn(t, ids, models.TaskPending, third.ID, first.ID, second.ID)
+	if task := app.getTask(token, second.ID); task.Rank != second.Rank {
+		t.Fatalf("expected the second task to keep its rank %q, got %q", second.Rank, task.Rank)
+	}
+
+	// Changing status and position at once, and back to the end of a column without neighbours
+	app.moveTask(token, second.ID, map[string]interface{}{"status": models.TaskInProgress}, http.StatusOK)
+	app.moveTask(token, first.ID, map[string]interface{}{"status": models.TaskInProgress, "before_id": second.ID}, http.StatusOK)
+	_, ids = app.board(token, "/tasks/get/board")
+	expectColumn(t, ids, models.TaskPending, third.ID)
+	expectColumn(t, ids, models.TaskInProgress, first.ID, second.ID)
+
+	// Updating the status puts the task at the end of the new column
+	app.updateStatus(token, third.ID, models.TaskInProgress, http.StatusOK)
+	_, ids = app.board(token, "/tasks/get/board")
+	expectColumn(t, ids, models.TaskInProgress, first.ID, second.ID, third.ID)
+
+	if task := app.getTask(token, first.ID); task.CompletedAt != nil {
+		t.Fatalf("expected the task not to be completed, got %+v", task)
+	}
+	app.moveTask(token, first.ID, map[string]interface{}{"status": models.TaskDone}, http.StatusOK)
+	if task := app.getTask(token, first.ID); task.CompletedAt == nil {
+		t.Fatalf("expected the task to be completed by the move, got %+v", task)
+	}
+}
+
+func TestInvalidMove(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	first := app.createTask(token, map[string]interface{}{"title": "First"})
+	second := app.createTask(token, map[string]interface{}{"title": "Second"})
+	third := app.createTask(token, map[string]interface{}{"title": "Third"})
+	done := app.createTask(token, map[string]interface{}{"title": "Done", "status": models.TaskDone})
+
+	// Neighbours must be in the column the task lands in, and in order
+	app.moveTask(token, first.ID, map[string]interface{}{"after_id": done.ID}, http.StatusBadRequest)
+	app.moveTask(token, first.ID, map[string]interface{}{"before_id": 999}, http.StatusBadRequest)
+	app.moveTask(token, first.ID, map[string]interface{}{"after_id": third.ID, "before_id": second.ID}, http.StatusBadRequest)
+	app.moveTask(token, first.ID, map[string]interface{}{"status": "archived"}, http.StatusBadRequest)
+	app.moveTask(otherToken, first.ID, map[string]interface{}{"after_id": third.ID}, http.StatusNotFound)
+
+	app.addDependency(token, second.ID, first.ID, http.StatusCreated)
+	app.moveTask(token, second.ID, map[string]interface{}{"status": models.TaskDone}, http.StatusConflict)
+	rec := app.request(http.MethodPut, fmt.Sprintf("/tasks/move/id/%d?force=true", second.ID), map[string]interface{}{"status": models.TaskDone}, token)
+	expectStatus(t, rec, http.StatusOK)
+}
+
+func TestWorkspaceBoardWIPLimit(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	workspace := app.createWorkspace(token, "Standup")
+	workflow := map[string]interface{}{
+		"initial": "todo",
+		"statuses": []map[string]interface{}{
+			{"name": "todo"},
+			{"name": "doing", "wip_limit": 2},
+			{"name": "done", "terminal": true},
+		},
+		"transitions": []map[string]interface{}{
+			{"from": "todo", "to": "doing"},
+			{"from": "doing", "to": "todo"},
+			{"from": "doing", "to": "done"},
+		},
+	}
+	expectStatus(t, app.request(http.MethodPut, "/workspaces/update/id/"+workspace.ID+"/workflow", workflow, token), http.StatusOK)
+
+	var tasks []models.Tasks
+	for i := 0; i < 3; i++ {
+		tasks = append(tasks, app.createTask(token, map[string]interface{}{"title": fmt.Sprintf("Task %d", i), "workspace_id": workspace.ID}))
+	}
+	app.moveTask(token, tasks[0].ID, map[string]interface{}{"status": "doing"}, http.StatusOK)
+	app.moveTask(token, tasks[1].ID, map[string]interface{}{"status": "doing"}, http.StatusOK)
+	app.moveTask(token, tasks[2].ID, map[string]interface{}{"status": "doing"}, http.StatusConflict)
+	app.updateStatus(token, tasks[2].ID, "doing", http.StatusConflict)
+	rec := app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Over the limit", "workspace_id": workspace.ID, "status": "doing"}, token)
+	expectStatus(t, rec, http.StatusConflict)
+
+	// Reordering within a full column is still allowed
+	app.moveTask(token, tasks[1].ID, map[string]interface{}{"before_id": tasks[0].ID}, http.StatusOK)
+
+	columns, ids := app.board(token, "/workspaces/get/id/"+workspace.ID+"/board")
+	if len(columns) != 3 || columns[1].WIPLimit != 2 {
+		t.Fatalf("expected the board to follow the workflow, got %+v", columns)
+	}
+	expectColumn(t, ids, "todo", tasks[2].ID)
+	expectColumn(t, ids, "doing", tasks[1].ID, tasks[0].ID)
+
+	// Freeing a slot lets the next task in
+	app.moveTask(token, tasks[0].ID, map[string]interface{}{"status": "done"}, http.StatusOK)
+	app.moveTask(token, tasks[2].ID, map[string]interface{}{"status": "doing"}, http.StatusOK)
+}
+
+// TestConcurrentMovesKeepTheWIPLimit moves several tasks at once into a column with a single free slot,
+// which only one of them must get.
+func TestConcurrentMovesKeepTheWIPLimit(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	workspace := app.createWorkspace(token, "Standup")
+	workflow := map[string]interface{}{
+		"initial": "todo",
+		"statuses": []map[string]interface{}{
+			{"name": "todo"},
+			{"name": "doing", "wip_limit": 1},
+			{"name": "done", "terminal": true},
+		},
+		"transitions": []map[string]interface{}{
+			{"from": "todo", "to": "doing"},
+			{"from": "doing", "to": "done"},
+		},
+	}
+	expectStatus(t, app.request(http.MethodPut, "/workspaces/update/id/"+workspace.ID+"/workflow", workflow, token), http.StatusOK)
+
+	const count = 8
+	var tasks []models.Tasks
+	for i := 0; i < count; i++ {
+		tasks = append(tasks, app.createTask(token, map[string]interface{}{"title": fmt.Sprintf("Task %d", i), "workspace_id": workspace.ID}))
+	}
+
+	codes := make(chan int, count)
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			codes <- app.request(http.MethodPut, fmt.Sprintf("/tasks/move/id/%d", id), map[string]interface{}{"status": "doing"}, token).Code
+		}(task.ID)
+	}
+	wg.Wait()
+	close(codes)
+
+	moved := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			moved++
+		case http.StatusConflict:
+		default:
+			t.Fatalf("expected the moves to succeed or conflict, got %d", code)
+		}
+	}
+	_, ids := app.board(token, "/workspaces/get/id/"+workspace.ID+"/board")
+	if moved != 1 || len(ids["doing"]) != 1 {
+		t.Fatalf("expected a single task to get the free slot, %d moves succeeded and the column holds %v", moved, ids["doing"])
+	}
+}
+
+func TestMigrateRanksOldTasks(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	first := app.createTask(token, map[string]interface{}{"title": "First"})
+	second := app.createTask(token, map[string]interface{}{"title": "Second"})
+	third := app.createTask(token, map[string]interface{}{"title": "Third"})
+
+	// Tasks created before the boards existed have no rank
+	if err := app.db.Model(&models.Tasks{}).Where("id IN ?", []uint{first.ID, third.ID}).UpdateColumn("rank", "").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Migrate(app.db); err != nil {
+		t.Fatalf("migrating the database: %v", err)
+	}
+
+	_, ids := app.board(token, "/tasks/get/board")
+	expectColumn(t, ids, models.TaskPending, second.ID, first.ID, third.ID)
+	if task := app.getTask(token, first.ID); task.Rank == "" {
+		t.Fatalf("expected the task to be ranked, got %+v", task)
+	}
+}
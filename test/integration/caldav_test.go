@@ -0,0 +1,250 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// dav sends a CalDAV request authenticated with the e-mail and the test password of the user, along with the headers.
+func (a *testApp) dav(method, path, body string, user models.User, headers map[string]string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if user.Email != "" {
+		req.SetBasicAuth(user.Email, testPassword)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	a.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// etagPattern finds the ETags in a multistatus response.
+var etagPattern = regexp.MustCompile(`<d:getetag>(.*?)</d:getetag>`)
+
+// davTodo is a VTODO as a CalDAV client sends it, with properties NullTask has no field for.
+func davTodo(uid, summary, status string) string {
+	return "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//Example//Client//EN\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:" + uid + "\r\n" +
+		"DTSTAMP:20300101T000000Z\r\n" +
+		"SUMMARY:" + summary + "\r\n" +
+		"DUE:20300304T150000Z\r\n" +
+		"STATUS:" + status + "\r\n" +
+		"PRIORITY:1\r\n" +
+		"CATEGORIES:Home\r\n" +
+		"X-CLIENT-COLOR:#ff0000\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"TRIGGER;RELATED=END:-PT15M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+}
+
+func TestCalDAVDiscovery(t *testing.T) {
+	app := newTestApp(t)
+	user, _ := app.newUser()
+
+	rec := app.dav(http.MethodOptions, "/dav/", "", models.User{}, nil)
+	expectStatus(t, rec, http.StatusOK)
+	if dav := rec.Header().Get("DAV"); !strings.Contains(dav, "calendar-access") {
+		t.Fatalf("expected the DAV header to announce calendar-access, got %q", dav)
+	}
+
+	rec = app.dav(echo.PROPFIND, "/.well-known/caldav", "", models.User{}, nil)
+	expectStatus(t, rec, http.StatusMovedPermanently)
+	if location := rec.Header().Get("Location"); location != "/dav/" {
+		t.Fatalf("expected a redirect to /dav/, got %q", location)
+	}
+
+	rec = app.dav(echo.PROPFIND, "/dav/", "", models.User{}, nil)
+	expectStatus(t, rec, http.StatusUnauthorized)
+	if challenge := rec.Header().Get("WWW-Authenticate"); !strings.HasPrefix(challenge, "Basic") {
+		t.Fatalf("expected a Basic challenge, got %q", challenge)
+	}
+	wrong := user
+	wrong.Email = "nobody@nulltask.test"
+	expectStatus(t, app.dav(echo.PROPFIND, "/dav/", "", wrong, nil), http.StatusUnauthorized)
+
+	rec = app.dav(echo.PROPFIND, "/dav/", `<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:current-user-principal/><c:calendar-home-set/><d:getcolor/></d:prop>
+</d:propfind>`, user, map[string]string{"Depth": "0"})
+	expectStatus(t, rec, http.StatusMultiStatus)
+	body := rec.Body.String()
+	for _, want := range []string{"<d:current-user-principal><d:href>/dav/principal/</d:href>", "<c:calendar-home-set><d:href>/dav/calendars/</d:href>", "<d:getcolor/></d:prop><d:status>HTTP/1.1 404 Not Found"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in %s", want, body)
+		}
+	}
+
+	rec = app.dav(echo.PROPFIND, "/dav/calendars/", "", user, map[string]string{"Depth": "1"})
+	expectStatus(t, rec, http.StatusMultiStatus)
+	body = rec.Body.String()
+	for _, want := range []string{"<d:href>/dav/calendars/tasks/</d:href>", "<c:calendar/>", `<c:comp name="VTODO"/>`, "<cs:getctag>", "<d:sync-token>urn:nulltask:sync:"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in %s", want, body)
+		}
+	}
+
+	expectStatus(t, app.dav(echo.PROPFIND, "/dav/elsewhere/", "", user, nil), http.StatusNotFound)
+	expectStatus(t, app.dav(echo.PROPFIND, "/dav/", "<d:propfind", user, nil), http.StatusBadRequest)
+}
+
+func TestCalDAVSync(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	other, otherToken := app.newUser()
+
+	existing := app.createTask(token, map[string]interface{}{"title": "Existing"})
+	app.createTask(otherToken, map[string]interface{}{"title": "Not mine"})
+
+	sync := func(syncToken string) (string, string) {
+		t.Helper()
+		rec := app.dav(echo.REPORT, "/dav/calendars/tasks/", `<d:sync-collection xmlns:d="DAV:"><d:sync-token>`+syncToken+`</d:sync-token>
+  <d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`, user, nil)
+		expectStatus(t, rec, http.StatusMultiStatus)
+		body := rec.Body.String()
+		match := regexp.MustCompile(`<d:sync-token>(.*?)</d:sync-token>`).FindStringSubmatch(body)
+		if match == nil {
+			t.Fatalf("expected a sync token in %s", body)
+		}
+		return body, match[1]
+	}
+	body, syncToken := sync("")
+	if !strings.Contains(body, fmt.Sprintf("/dav/calendars/tasks/task-%d.ics", existing.ID)) || strings.Contains(body, "Not mine") {
+		t.Fatalf("unexpected first synchronization %s", body)
+	}
+
+	// A new to-do becomes a personal task, keeping what it has no field for
+	path := "/dav/calendars/tasks/groceries.ics"
+	rec := app.dav(http.MethodPut, path, davTodo("groceries-uid", "Groceries", "NEEDS-ACTION"), user, map[string]string{"If-None-Match": "*"})
+	expectStatus(t, rec, http.StatusCreated)
+	expectStatus(t, app.dav(http.MethodPut, path, davTodo("groceries-uid", "Groceries", "NEEDS-ACTION"), user, map[string]string{"If-None-Match": "*"}), http.StatusPreconditionFailed)
+	expectStatus(t, app.dav(http.MethodPut, "/dav/calendars/tasks/copy.ics", davTodo("groceries-uid", "Copy", "NEEDS-ACTION"), user, nil), http.StatusForbidden)
+
+	rec = app.request(http.MethodGet, "/tasks/get/user/"+user.ID, nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	var created *models.Tasks
+	for i := range tasks {
+		if tasks[i].Title == "Groceries" {
+			created = &tasks[i]
+		}
+	}
+	due := time.Date(2030, 3, 4, 15, 0, 0, 0, time.UTC)
+	if created == nil || created.UserID != user.ID || created.DueDate == nil || !created.DueDate.Equal(due) ||
+		created.Priority != models.PriorityHigh || created.Reminder == nil || !created.Reminder.Equal(due.Add(-15*time.Minute)) {
+		t.Fatalf("unexpected task %+v", created)
+	}
+
+	rec = app.dav(http.MethodGet, path, "", user, nil)
+	expectStatus(t, rec, http.StatusOK)
+	etag := rec.Header().Get("ETag")
+	for _, want := range []string{"UID:groceries-uid\r\n", "SUMMARY:Groceries\r\n", "CATEGORIES:Home\r\n", "X-CLIENT-COLOR:#ff0000\r\n", "TRIGGER;RELATED=END:-PT15M\r\n"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("expected %q in %s", want, rec.Body.String())
+		}
+	}
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	// Completing the to-do completes the task, as long as the client saw its latest version
+	expectStatus(t, app.dav(http.MethodPut, path, davTodo("groceries-uid", "Groceries", "COMPLETED"), user, map[string]string{"If-Match": `"stale"`}), http.StatusPreconditionFailed)
+	rec = app.dav(http.MethodPut, path, davTodo("groceries-uid", "Groceries and bread", "COMPLETED"), user, map[string]string{"If-Match": etag})
+	expectStatus(t, rec, http.StatusNoContent)
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", created.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.Tasks
+	decode(t, rec, &updated)
+	if updated.Title != "Groceries and bread" || updated.Status != models.TaskDone || updated.CompletedAt == nil {
+		t.Fatalf("unexpected task %+v", updated)
+	}
+
+	// Changes made through the API show up as well
+	app.updateStatus(token, existing.ID, models.TaskInProgress, http.StatusOK)
+
+	body, next := sync(syncToken)
+	if next == syncToken || !strings.Contains(body, path) || !strings.Contains(body, fmt.Sprintf("task-%d.ics", existing.ID)) {
+		t.Fatalf("expected both tasks to have changed, got %s", body)
+	}
+	body, again := sync(next)
+	if again != next || strings.Contains(body, "<d:response>") {
+		t.Fatalf("expected nothing to have changed, got %s", body)
+	}
+
+	// The collection can be queried and fetched
+	rec = app.dav(echo.REPORT, "/dav/calendars/tasks/", `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">
+    <c:prop-filter name="COMPLETED"><c:is-not-defined/></c:prop-filter>
+  </c:comp-filter></c:comp-filter></c:filter>
+</c:calendar-query>`, user, nil)
+	expectStatus(t, rec, http.StatusMultiStatus)
+	if body := rec.Body.String(); strings.Contains(body, path) || len(etagPattern.FindAllString(body, -1)) != 1 {
+		t.Fatalf("expected only the open task, got %s", body)
+	}
+	rec = app.dav(echo.REPORT, "/dav/calendars/tasks/", `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-data/></d:prop><d:href>`+path+`</d:href><d:href>/dav/calendars/tasks/missing.ics</d:href>
+</c:calendar-multiget>`, user, nil)
+	expectStatus(t, rec, http.StatusMultiStatus)
+	if body := rec.Body.String(); !strings.Contains(body, "X-CLIENT-COLOR") || !strings.Contains(body, "<d:href>/dav/calendars/tasks/missing.ics</d:href><d:status>HTTP/1.1 404 Not Found") {
+		t.Fatalf("unexpected multiget %s", body)
+	}
+	rec = app.dav(echo.REPORT, "/dav/calendars/tasks/", `<d:expand-property xmlns:d="DAV:"/>`, user, nil)
+	expectStatus(t, rec, http.StatusForbidden)
+
+	// Deleted tasks are reported as removed
+	expectStatus(t, app.dav(http.MethodDelete, path, "", user, map[string]string{"If-Match": etag}), http.StatusPreconditionFailed)
+	expectStatus(t, app.dav(http.MethodDelete, path, "", other, nil), http.StatusNotFound)
+	expectStatus(t, app.dav(http.MethodDelete, path, "", user, nil), http.StatusNoContent)
+	expectStatus(t, app.dav(http.MethodGet, path, "", user, nil), http.StatusNotFound)
+	body, _ = sync(again)
+	if !strings.Contains(body, "<d:href>"+path+"</d:href><d:status>HTTP/1.1 404 Not Found") {
+		t.Fatalf("expected the task to be reported as removed, got %s", body)
+	}
+
+	rec = app.dav(echo.REPORT, "/dav/calendars/tasks/", `<d:sync-collection xmlns:d="DAV:"><d:sync-token>urn:nulltask:sync:unknown</d:sync-token></d:sync-collection>`, user, nil)
+	expectStatus(t, rec, http.StatusForbidden)
+	if !strings.Contains(rec.Body.String(), "valid-sync-token") {
+		t.Fatalf("expected the valid-sync-token precondition, got %s", rec.Body.String())
+	}
+}
+
+func TestCalDAVRejectsInvalidObjects(t *testing.T) {
+	app := newTestApp(t)
+	user, _ := app.newUser()
+	path := "/dav/calendars/tasks/bad.ics"
+
+	event := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:1\r\nSUMMARY:Meeting\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	rec := app.dav(http.MethodPut, path, event, user, nil)
+	expectStatus(t, rec, http.StatusForbidden)
+	if !strings.Contains(rec.Body.String(), "supported-calendar-component") {
+		t.Fatalf("expected the supported-calendar-component precondition, got %s", rec.Body.String())
+	}
+	expectStatus(t, app.dav(http.MethodPut, path, "not a calendar", user, nil), http.StatusBadRequest)
+	expectStatus(t, app.dav(http.MethodPut, path, davTodo("empty", "", "NEEDS-ACTION"), user, nil), http.StatusBadRequest)
+	expectStatus(t, app.dav(http.MethodPut, "/dav/calendars/tasks/", davTodo("1", "Collection", "NEEDS-ACTION"), user, nil), http.StatusMethodNotAllowed)
+
+	// The names of the tasks no client wrote can't be taken by others, whether the task exists yet or not
+	_, otherToken := app.newUser()
+	other := app.createTask(otherToken, map[string]interface{}{"title": "Not mine"})
+	for _, id := range []uint{other.ID, other.ID + 100} {
+		name := fmt.Sprintf("/dav/calendars/tasks/task-%d.ics", id)
+		expectStatus(t, app.dav(http.MethodPut, name, davTodo(name, "Squatter", "NEEDS-ACTION"), user, nil), http.StatusConflict)
+	}
+}
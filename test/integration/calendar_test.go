@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// createCalendarFeed creates or regenerates the calendar feed of the user and returns it.
+func (a *testApp) createCalendarFeed(token string) handlers.CalendarFeedResponse {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/calendar/create/feed", nil, token)
+	expectStatus(a.t, rec, http.StatusCreated)
+	var feed handlers.CalendarFeedResponse
+	decode(a.t, rec, &feed)
+	return feed
+}
+
+// calendar fetches the calendar feed at path, sending the ETag as If-None-Match when it isn't empty.
+func (a *testApp) calendar(path, etag string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rec := httptest.NewRecorder()
+	a.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCalendarFeed(t *testing.T) {
+	app := newTestApp(t)
+	owner, ownerToken := app.newUser()
+	_, memberToken := app.newUser()
+	_, strangerToken := app.newUser()
+
+	expectStatus(t, app.request(http.MethodGet, "/calendar/get/feed", nil, ownerToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodPost, "/calendar/create/feed", nil, ""), http.StatusUnauthorized)
+
+	feed := app.createCalendarFeed(ownerToken)
+	if feed.UserID != owner.ID || feed.Token == "" || !strings.HasSuffix(feed.URL, "/calendar/feed/"+feed.Token+".ics") {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+	rec := app.request(http.MethodGet, "/calendar/get/feed", nil, ownerToken)
+	expectStatus(t, rec, http.StatusOK)
+	if strings.Contains(rec.Body.String(), feed.Token) {
+		t.Fatalf("expected the token not to be shown again, got %s", rec.Body.String())
+	}
+
+	due := time.Date(2030, 3, 4, 15, 0, 0, 0, time.UTC)
+	app.createTask(ownerToken, map[string]interface{}{
+		"title": "Pay rent", "due_date": due, "reminder": due.Add(-time.Hour), "recurrence": "FREQ=MONTHLY", "priority": models.PriorityHigh,
+	})
+	app.createTask(ownerToken, map[string]interface{}{"title": "Someday"})
+	done := app.createTask(ownerToken, map[string]interface{}{"title": "Call the bank", "due_date": due})
+	app.updateStatus(ownerToken, done.ID, models.TaskDone, http.StatusOK)
+
+	// Tasks assigned to the owner show up in their feed, other workspace tasks don't
+	workspace := app.createWorkspace(memberToken, "Team")
+	app.joinWorkspace(memberToken, workspace, owner, ownerToken, models.RoleEditor)
+	assigned := app.createTask(memberToken, map[string]interface{}{"title": "Review budget", "due_date": due, "workspace_id": workspace.ID})
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/tasks/assign/id/%d", assigned.ID), map[string]interface{}{"user_id": owner.ID}, memberToken), http.StatusCreated)
+	app.createTask(memberToken, map[string]interface{}{"title": "Not mine", "due_date": due, "workspace_id": workspace.ID})
+
+	rec = app.calendar("/calendar/feed/"+feed.Token+".ics", "")
+	expectStatus(t, rec, http.StatusOK)
+	body := rec.Body.String()
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Fatalf("unexpected Content-Type %q", contentType)
+	}
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "BEGIN:VEVENT\r\n", "SUMMARY:Pay rent\r\n", "DTSTART:20300304T150000Z\r\n",
+		"RRULE:FREQ=MONTHLY\r\n", "PRIORITY:1\r\n", "TRIGGER:-PT1H\r\n", "SUMMARY:Call the bank\r\n", "SUMMARY:Review budget\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected the feed to contain %q, got\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Someday") || strings.Contains(body, "Not mine") {
+		t.Fatalf("expected only the owner's tasks with a due date, got\n%s", body)
+	}
+
+	rec = app.calendar("/calendar/feed/"+feed.Token+"?kind=todo", "")
+	expectStatus(t, rec, http.StatusOK)
+	body = rec.Body.String()
+	for _, want := range []string{"BEGIN:VTODO\r\n", "DUE:20300304T150000Z\r\n", "TRIGGER;RELATED=END:-PT1H\r\n", "STATUS:COMPLETED\r\n", "STATUS:NEEDS-ACTION\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected the feed to contain %q, got\n%s", want, body)
+		}
+	}
+	expectStatus(t, app.calendar("/calendar/feed/"+feed.Token+"?kind=journal", ""), http.StatusBadRequest)
+
+	// The ETag changes along with the tasks
+	path := "/calendar/feed/" + feed.Token + ".ics"
+	etag := app.calendar(path, "").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected the feed to have an ETag")
+	}
+	expectStatus(t, app.calendar(path, etag), http.StatusNotModified)
+	app.createTask(ownerToken, map[string]interface{}{"title": "Dentist", "due_date": due})
+	rec = app.calendar(path, etag)
+	expectStatus(t, rec, http.StatusOK)
+	if rec.Header().Get("ETag") == etag {
+		t.Fatal("expected the ETag to change")
+	}
+
+	// Regenerating the token revokes the previous link, and deleting the feed revokes every link
+	regenerated := app.createCalendarFeed(ownerToken)
+	if regenerated.Token == feed.Token {
+		t.Fatal("expected a new token")
+	}
+	expectStatus(t, app.calendar(path, ""), http.StatusNotFound)
+	expectStatus(t, app.calendar("/calendar/feed/"+regenerated.Token+".ics", ""), http.StatusOK)
+
+	expectStatus(t, app.request(http.MethodDelete, "/calendar/delete/feed", nil, strangerToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodDelete, "/calendar/delete/feed", nil, ownerToken), http.StatusOK)
+	expectStatus(t, app.calendar("/calendar/feed/"+regenerated.Token+".ics", ""), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, "/calendar/get/feed", nil, ownerToken), http.StatusNotFound)
+}
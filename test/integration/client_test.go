@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/client"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestClientTasks(t *testing.T) {
+	app := newTestApp(t)
+	user, _ := app.newUser()
+	server := httptest.NewServer(app.e)
+	t.Cleanup(server.Close)
+	api := client.New(server.URL, "")
+	ctx := context.Background()
+
+	if _, err := api.Login(ctx, user.Email, "wrong password"); !client.IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected the wrong password to be refused, got %v", err)
+	}
+	session, err := api.Login(ctx, user.Email, testPassword)
+	if err != nil {
+		t.Fatalf("logging in: %v", err)
+	}
+	if session.UserID != user.ID || session.Token == "" || api.Token != session.Token || !session.ExpiresAt.After(time.Now()) {
+		t.Fatalf("unexpected session %+v", session)
+	}
+
+	due := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	rent, err := api.CreateTask(ctx, map[string]interface{}{"title": "Pay rent", "priority": models.PriorityHigh, "due_date": due})
+	if err != nil {
+		t.Fatalf("creating a task: %v", err)
+	}
+	if rent.UserID != user.ID || rent.Priority != models.PriorityHigh || !rent.DueDate.Equal(due) {
+		t.Fatalf("unexpected task %+v", rent)
+	}
+	plants, err := api.QuickAdd(ctx, "Water plants #home")
+	if err != nil {
+		t.Fatalf("quick adding a task: %v", err)
+	}
+	if plants.Title != "Water plants" {
+		t.Fatalf("expected the label to be parsed out of the title, got %q", plants.Title)
+	}
+
+	// Only the fields given change
+	rent, err = api.UpdateTask(ctx, rent.ID, map[string]interface{}{"status": models.TaskDone, "due_date": nil})
+	if err != nil {
+		t.Fatalf("updating the task: %v", err)
+	}
+	if rent.Status != models.TaskDone || rent.CompletedAt == nil || rent.DueDate != nil || rent.Title != "Pay rent" || rent.Priority != models.PriorityHigh {
+		t.Fatalf("unexpected task %+v", rent)
+	}
+
+	tasks, err := api.Tasks(ctx, user.ID, client.TaskFilter{})
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("expected both tasks, got %+v, %v", tasks, err)
+	}
+	labels, err := api.Labels(ctx)
+	if err != nil || len(labels) != 1 || labels[0].Name != "home" {
+		t.Fatalf("expected the label quick add created, got %+v, %v", labels, err)
+	}
+	tasks, err = api.Tasks(ctx, user.ID, client.TaskFilter{LabelIDs: []uint{labels[0].ID}})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != plants.ID {
+		t.Fatalf("expected the task labelled home, got %+v, %v", tasks, err)
+	}
+	tasks, err = api.SearchTasks(ctx, user.ID, "rent", client.TaskFilter{})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != rent.ID {
+		t.Fatalf("expected the rent to be found, got %+v, %v", tasks, err)
+	}
+
+	if err := api.DeleteTask(ctx, plants.ID); err != nil {
+		t.Fatalf("deleting the task: %v", err)
+	}
+	if _, err := api.Task(ctx, plants.ID); !client.IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("expected the deleted task to be gone, got %v", err)
+	}
+	if _, err := client.New(server.URL, "").Tasks(ctx, user.ID, client.TaskFilter{}); !client.IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected a client without a token to be refused, got %v", err)
+	}
+}
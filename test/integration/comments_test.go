@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// createComment writes a comment on the task and returns it.
+func (a *testApp) createComment(token string, taskID uint, comment map[string]interface{}) models.Comment {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, fmt.Sprintf("/tasks/comment/id/%d", taskID), comment, token)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var created models.Comment
+	decode(a.t, rec, &created)
+	return created
+}
+
+func TestCreateComment(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	stranger, _ := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+	task := app.createTask(ownerToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/tasks/watch/id/%d", task.ID), nil, viewerToken), http.StatusCreated)
+
+	body := fmt.Sprintf("**Milk** <script>alert(1)</script> for @%s and @%s", viewer.Email, stranger.Email)
+	comment := app.createComment(editorToken, task.ID, map[string]interface{}{"body": body})
+	if comment.ID == 0 || comment.AuthorID != editor.ID || comment.Body != body {
+		t.Fatalf("unexpected created comment: %+v", comment)
+	}
+	if !strings.Contains(comment.BodyHTML, "<strong>Milk</strong>") || strings.Contains(comment.BodyHTML, "<script") {
+		t.Fatalf("expected sanitized HTML, got %s", comment.BodyHTML)
+	}
+	if len(comment.Mentions) != 1 || comment.Mentions[0].UserID != viewer.ID {
+		t.Fatalf("expected only the member to be mentioned, got %+v", comment.Mentions)
+	}
+
+	published := app.eventsOf(events.CommentCreated, events.UserMentioned)
+	if len(published) != 2 || published[0].Type != events.CommentCreated || published[1].Type != events.UserMentioned {
+		t.Fatalf("expected a comment and a mention event, got %+v", app.events)
+	}
+	if published[1].UserID != viewer.ID || published[1].CommentID != comment.ID {
+		t.Fatalf("unexpected mention event: %+v", published[1])
+	}
+
+	commentPath := fmt.Sprintf("/tasks/comment/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodPost, commentPath, map[string]interface{}{"body": "Me too"}, viewerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPost, commentPath, map[string]interface{}{"body": ""}, editorToken), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, commentPath, map[string]interface{}{"body": "Reply", "parent_id": 9999}, editorToken), http.StatusBadRequest)
+}
+
+func TestCommentThreads(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+	other := app.createTask(token, map[string]interface{}{"title": "Buy milk"})
+
+	first := app.createComment(token, task.ID, map[string]interface{}{"body": "First"})
+	reply := app.createComment(token, task.ID, map[string]interface{}{"body": "Reply", "parent_id": first.ID})
+	app.createComment(token, task.ID, map[string]interface{}{"body": "Nested", "parent_id": reply.ID})
+	app.createComment(token, task.ID, map[string]interface{}{"body": "Second"})
+
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/tasks/comment/id/%d", other.ID), map[string]interface{}{"body": "Wrong task", "parent_id": first.ID}, token), http.StatusBadRequest)
+
+	// Deleting a comment keeps its replies reachable
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/comments/delete/id/%d", first.ID), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/comments/delete/id/%d", first.ID), nil, token), http.StatusNotFound)
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/comments", task.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var threads []models.Comment
+	decode(t, rec, &threads)
+	if len(threads) != 2 || threads[1].Body != "Second" {
+		t.Fatalf("expected two threads, got %+v", threads)
+	}
+	if !threads[0].DeletedAt.Valid || threads[0].Body != "" || threads[0].BodyHTML != "" {
+		t.Fatalf("expected the first comment to be deleted without its body, got %+v", threads[0])
+	}
+	if len(threads[0].Replies) != 1 || threads[0].Replies[0].Body != "Reply" ||
+		len(threads[0].Replies[0].Replies) != 1 || threads[0].Replies[0].Replies[0].Body != "Nested" {
+		t.Fatalf("expected the replies to be nested, got %+v", threads[0].Replies)
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+	task := app.createTask(ownerToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+	comment := app.createComment(editorToken, task.ID, map[string]interface{}{"body": "First draft"})
+	eventsBefore := len(app.events)
+
+	updatePath := fmt.Sprintf("/comments/update/id/%d", comment.ID)
+	expectStatus(t, app.request(http.MethodPut, updatePath, map[string]interface{}{"body": "Hijacked"}, ownerToken), http.StatusForbidden)
+
+	rec := app.request(http.MethodPut, updatePath, map[string]interface{}{"body": "Second draft, cc @" + viewer.Email}, editorToken)
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.Comment
+	decode(t, rec, &updated)
+	if updated.EditedAt == nil || len(updated.Mentions) != 1 {
+		t.Fatalf("unexpected edited comment: %+v", updated)
+	}
+	if len(app.events) != eventsBefore+1 || app.events[eventsBefore].Type != events.UserMentioned {
+		t.Fatalf("expected the new mention to be notified, got %+v", app.events[eventsBefore:])
+	}
+
+	expectStatus(t, app.request(http.MethodPut, updatePath, map[string]interface{}{"body": "Final, cc @" + viewer.Email}, editorToken), http.StatusOK)
+	if len(app.events) != eventsBefore+1 {
+		t.Fatalf("expected users already mentioned not to be notified again, got %+v", app.events[eventsBefore:])
+	}
+
+	rec = app.request(http.MethodGet, fmt.Sprintf("/comments/get/id/%d/history", comment.ID), nil, viewerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var revisions []models.CommentRevision
+	decode(t, rec, &revisions)
+	if len(revisions) != 2 || revisions[0].Body != "First draft" || revisions[1].Body != "Second draft, cc @"+viewer.Email {
+		t.Fatalf("unexpected history: %+v", revisions)
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	_, strangerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	task := app.createTask(ownerToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+	byOwner := app.createComment(ownerToken, task.ID, map[string]interface{}{"body": "From the owner"})
+	byEditor := app.createComment(editorToken, task.ID, map[string]interface{}{"body": "From the editor"})
+
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/comments/delete/id/%d", byEditor.ID), nil, strangerToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/comments/delete/id/%d", byOwner.ID), nil, editorToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/comments/delete/id/%d", byEditor.ID), nil, ownerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/comments/update/id/%d", byEditor.ID), map[string]interface{}{"body": "Undelete"}, editorToken), http.StatusNotFound)
+}
@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// addDependency makes the task depend on the other one, expecting the given status.
+func (a *testApp) addDependency(token string, taskID, dependsOnID uint, status int) {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, fmt.Sprintf("/tasks/depend/id/%d", taskID), map[string]interface{}{"depends_on_id": dependsOnID}, token)
+	expectStatus(a.t, rec, status)
+}
+
+// getTask fetches the task through the API.
+func (a *testApp) getTask(token string, id uint) models.Tasks {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", id), nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+
+	var task models.Tasks
+	decode(a.t, rec, &task)
+	return task
+}
+
+func TestTaskDependencies(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	buy := app.createTask(token, map[string]interface{}{"title": "Buy paint"})
+	paint := app.createTask(token, map[string]interface{}{"title": "Paint the wall"})
+	hang := app.createTask(token, map[string]interface{}{"title": "Hang the pictures"})
+
+	app.addDependency(token, paint.ID, buy.ID, http.StatusCreated)
+	app.addDependency(token, hang.ID, paint.ID, http.StatusCreated)
+	app.addDependency(token, hang.ID, paint.ID, http.StatusConflict)
+
+	// Closing the loop in any way is refused
+	app.addDependency(token, buy.ID, hang.ID, http.StatusConflict)
+	app.addDependency(token, buy.ID, buy.ID, http.StatusConflict)
+
+	foreign := app.createTask(otherToken, map[string]interface{}{"title": "Not mine"})
+	app.addDependency(token, paint.ID, foreign.ID, http.StatusBadRequest)
+	workspace := app.createWorkspace(token, "Family")
+	shared := app.createTask(token, map[string]interface{}{"title": "Shared", "workspace_id": workspace.ID})
+	app.addDependency(token, paint.ID, shared.ID, http.StatusBadRequest)
+
+	if task := app.getTask(token, paint.ID); !task.Blocked {
+		t.Fatalf("expected the painting to be blocked by the paint, got %+v", task)
+	}
+	if task := app.getTask(token, buy.ID); task.Blocked {
+		t.Fatalf("expected buying the paint not to be blocked, got %+v", task)
+	}
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/dependencies", hang.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != paint.ID || !tasks[0].Blocked {
+		t.Fatalf("expected hanging the pictures to depend on the blocked painting, got %+v", tasks)
+	}
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/dependents", buy.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &tasks)
+	if len(tasks) != 1 || tasks[0].ID != paint.ID {
+		t.Fatalf("expected the painting to depend on buying the paint, got %+v", tasks)
+	}
+
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/undepend/id/%d/task/%d", paint.ID, buy.ID), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/undepend/id/%d/task/%d", paint.ID, buy.ID), nil, token), http.StatusNotFound)
+	if task := app.getTask(token, paint.ID); task.Blocked {
+		t.Fatalf("expected the painting to be unblocked, got %+v", task)
+	}
+}
+
+func TestCompleteBlockedTask(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	buy := app.createTask(token, map[string]interface{}{"title": "Buy paint"})
+	paint := app.createTask(token, map[string]interface{}{"title": "Paint the wall"})
+	app.addDependency(token, paint.ID, buy.ID, http.StatusCreated)
+
+	path := fmt.Sprintf("/tasks/update/id/%d", paint.ID)
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"status": models.TaskDone}, token), http.StatusConflict)
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"title": "Paint the whole wall", "blocked": false}, token), http.StatusOK)
+
+	rec := app.request(http.MethodPut, path+"?force=true", map[string]interface{}{"status": models.TaskDone}, token)
+	expectStatus(t, rec, http.StatusOK)
+	var forced models.Tasks
+	decode(t, rec, &forced)
+	if forced.Status != models.TaskDone || !forced.Blocked {
+		t.Fatalf("expected the task to be completed while still blocked, got %+v", forced)
+	}
+
+	// Once its dependencies are done, the task can be completed as usual
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"status": models.TaskPending}, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", buy.ID), map[string]interface{}{"status": models.TaskDone}, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"status": models.TaskDone}, token), http.StatusOK)
+}
+
+func TestCriticalPath(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	design := app.createTask(token, map[string]interface{}{"title": "Design"})
+	build := app.createTask(token, map[string]interface{}{"title": "Build"})
+	test := app.createTask(token, map[string]interface{}{"title": "Test"})
+	docs := app.createTask(token, map[string]interface{}{"title": "Write the docs"})
+	release := app.createTask(token, map[string]interface{}{"title": "Release"})
+	app.addDependency(token, build.ID, design.ID, http.StatusCreated)
+	app.addDependency(token, test.ID, build.ID, http.StatusCreated)
+	app.addDependency(token, release.ID, test.ID, http.StatusCreated)
+	app.addDependency(token, release.ID, docs.ID, http.StatusCreated)
+
+	ids := fmt.Sprintf("%d,%d,%d,%d,%d", release.ID, docs.ID, test.ID, build.ID, design.ID)
+	rec := app.request(http.MethodGet, "/tasks/get/critical-path?ids="+ids, nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var res handlers.CriticalPathResponse
+	decode(t, rec, &res)
+
+	position := map[uint]int{}
+	for i, task := range res.Order {
+		position[task.ID] = i
+	}
+	if len(res.Order) != 5 || position[design.ID] > position[build.ID] || position[test.ID] > position[release.ID] || position[docs.ID] > position[release.ID] {
+		t.Fatalf("expected every task after its dependencies, got %+v", res.Order)
+	}
+	want := []uint{design.ID, build.ID, test.ID, release.ID}
+	if len(res.CriticalPath) != len(want) {
+		t.Fatalf("expected the critical path %v, got %+v", want, res.CriticalPath)
+	}
+	for i, task := range res.CriticalPath {
+		if task.ID != want[i] {
+			t.Fatalf("expected the critical path %v, got %+v", want, res.CriticalPath)
+		}
+	}
+
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/critical-path?ids=", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/critical-path?ids=1,x", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/critical-path?ids="+ids, nil, otherToken), http.StatusNotFound)
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/client"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestTaskEvents(t *testing.T) {
+	app := newTestApp(t)
+	owner, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+
+	task := app.createTask(editorToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+	app.updateStatus(ownerToken, task.ID, models.TaskDone, http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", task.ID), nil, editorToken), http.StatusOK)
+
+	published := app.eventsOf(events.TaskCreated, events.TaskUpdated, events.TaskDeleted)
+	if len(published) != 3 {
+		t.Fatalf("expected three task events, got %+v", published)
+	}
+	created, updated, deleted := published[0], published[1], published[2]
+	if created.Type != events.TaskCreated || created.ActorID != editor.ID || created.UserID != editor.ID || created.TaskID != task.ID {
+		t.Fatalf("unexpected event %+v", created)
+	}
+	if len(created.Recipients) != 2 || contains(created.Recipients, editor.ID) || !contains(created.Recipients, owner.ID) || !contains(created.Recipients, viewer.ID) {
+		t.Fatalf("expected the other members to be the recipients, got %v", created.Recipients)
+	}
+	if updated.Type != events.TaskUpdated || updated.ActorID != owner.ID || contains(updated.Recipients, owner.ID) {
+		t.Fatalf("unexpected event %+v", updated)
+	}
+	if deleted.Type != events.TaskDeleted || deleted.TaskID != task.ID || *deleted.WorkspaceID != workspace.ID {
+		t.Fatalf("unexpected event %+v", deleted)
+	}
+
+	// Nobody else hears about personal tasks
+	app.createTask(ownerToken, map[string]interface{}{"title": "Call mom"})
+	if last := app.events[len(app.events)-1]; last.Type != events.TaskCreated || len(last.Recipients) != 0 {
+		t.Fatalf("expected a personal task event without recipients, got %+v", last)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	app := newTestApp(t)
+	owner, ownerToken := app.newUser()
+	member, memberToken := app.newUser()
+	_, strangerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, member, memberToken, models.RoleEditor)
+
+	server := httptest.NewServer(app.e)
+	t.Cleanup(server.Close)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := client.New(server.URL, "").Subscribe(ctx); !client.IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected a client without a token to be refused, got %v", err)
+	}
+	stream, err := client.New(server.URL, memberToken).Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	defer stream.Close()
+
+	// The stream carries the changes the member makes and those of the tasks they can see, not the others
+	app.createTask(strangerToken, map[string]interface{}{"title": "Someone else's"})
+	personal := app.createTask(memberToken, map[string]interface{}{"title": "Call mom"})
+	shared := app.createTask(ownerToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+
+	for _, want := range []uint{personal.ID, shared.ID} {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatalf("waiting for an event: %v", err)
+		}
+		if event.Type != events.TaskCreated || event.TaskID != want {
+			t.Fatalf("expected task %d to be created, got %+v", want, event)
+		}
+	}
+	app.updateStatus(ownerToken, shared.ID, models.TaskInProgress, http.StatusOK)
+	if event, err := stream.Next(); err != nil || event.Type != events.TaskUpdated || event.ActorID != owner.ID {
+		t.Fatalf("expected the owner's update, got %+v, %v", event, err)
+	}
+}
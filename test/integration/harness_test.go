@@ -0,0 +1,240 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/devgugga/NullTask/internal/auth"
+	"github.com/devgugga/NullTask/internal/config"
+	"github.com/devgugga/NullTask/internal/events"
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/mail"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/router"
+	"github.com/devgugga/NullTask/internal/storage"
+	"github.com/devgugga/NullTask/internal/thumbnails"
+	"github.com/devgugga/NullTask/internal/transfer"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// testPassword is the password of every user created by the harness.
+	testPassword = "secret123"
+	// testAttachmentQuota is how many bytes of attachments each user can upload, small enough to be reached by tests.
+	testAttachmentQuota = 64 << 10
+	// testThumbnailSize is the only size thumbnails are generated at.
+	testThumbnailSize = 32
+)
+
+// userCounter makes the e-mails generated by newUser unique across tests.
+var userCounter atomic.Int64
+
+// testApp is the whole API, built by config.NewServer on top of router.SetupRoutes,
+// running against a private in-memory SQLite database.
+// Every event the API publishes is recorded in events, in order, and every e-mail it sends in mails,
+// unless mailErr is set, in which case sending fails with it.
+type testApp struct {
+	t          *testing.T
+	e          *echo.Echo
+	db         *gorm.DB
+	events     []events.Event
+	mails      []mail.Message
+	mailErr    error
+	stream     *events.Stream
+	blobs      storage.BlobStore
+	thumbnails *thumbnails.Worker
+	importer   *transfer.Importer
+}
+
+// newTestApp boots a fresh application whose database only lives for the duration of the test.
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+
+	db, err := config.ConnectDB(&config.DBConfig{Driver: config.DriverSQLite, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("connecting to the database: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+
+	if err := config.Migrate(db); err != nil {
+		t.Fatalf("migrating the database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("creating the blob store: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	thumbs := thumbnails.NewWorker(repository.NewGormAttachmentRepository(db), blobs, log.New(io.Discard), []int{testThumbnailSize})
+	thumbs.Start(ctx, 1)
+
+	importer := transfer.NewImporter(repository.NewGormImportRepository(db), repository.NewGormTaskRepository(db),
+		repository.NewGormLabelRepository(db), repository.NewGormUserRepository(db), log.New(io.Discard))
+	importer.Start(ctx, 1)
+
+	app := &testApp{t: t, db: db, blobs: blobs, thumbnails: thumbs, importer: importer}
+	bus := events.NewBus()
+	bus.Subscribe(func(_ context.Context, event events.Event) {
+		app.events = append(app.events, event)
+	})
+	app.stream = events.NewStream()
+	bus.Subscribe(app.stream.Subscriber())
+
+	app.e = config.NewServer(&router.Dependencies{
+		DB:     db,
+		Log:    log.New(io.Discard),
+		Tokens: auth.NewTokenManager("integration-test-secret", time.Hour),
+		Events: bus,
+		Stream: app.stream,
+		Mailer: mail.MailerFunc(func(_ context.Context, message mail.Message) error {
+			if app.mailErr != nil {
+				return app.mailErr
+			}
+			app.mails = append(app.mails, message)
+			return nil
+		}),
+		Blobs:           blobs,
+		AttachmentQuota: testAttachmentQuota,
+		Thumbnails:      thumbs,
+		Importer:        importer,
+	})
+	return app
+}
+
+// eventsOf returns the published events of the given types, in order.
+func (a *testApp) eventsOf(types ...events.Type) []events.Event {
+	var matching []events.Event
+	for _, event := range a.events {
+		for _, eventType := range types {
+			if event.Type == eventType {
+				matching = append(matching, event)
+			}
+		}
+	}
+	return matching
+}
+
+// request sends a request to the application and returns the recorded response.
+// A non-nil body is encoded as JSON, unless it's already a string, and a non-empty token
+// is sent as a bearer token.
+func (a *testApp) request(method, path string, body interface{}, token string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatalf("encoding the request body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	a.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// createUser registers a user through the API and returns it.
+func (a *testApp) createUser(name, email, password string) models.User {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/users/create", map[string]interface{}{
+		"name":     name,
+		"email":    email,
+		"password": password,
+		"age":      30,
+	}, "")
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var user models.User
+	decode(a.t, rec, &user)
+	return user
+}
+
+// login authenticates through the API and returns the bearer token.
+func (a *testApp) login(email, password string) string {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/users/login", handlers.LoginRequest{Email: email, Password: password}, "")
+	expectStatus(a.t, rec, http.StatusOK)
+
+	var res handlers.LoginResponse
+	decode(a.t, rec, &res)
+	return res.Token
+}
+
+// newUser registers a user with a unique e-mail and logs them in.
+func (a *testApp) newUser() (models.User, string) {
+	a.t.Helper()
+
+	n := userCounter.Add(1)
+	email := fmt.Sprintf("user%d@nulltask.test", n)
+	user := a.createUser(fmt.Sprintf("User %d", n), email, testPassword)
+	return user, a.login(email, testPassword)
+}
+
+// createTask creates a task owned by the user the token belongs to and returns it.
+func (a *testApp) createTask(token string, task map[string]interface{}) models.Tasks {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/tasks/create", task, token)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var created models.Tasks
+	decode(a.t, rec, &created)
+	return created
+}
+
+// expectStatus fails the test when the response doesn't have the expected status code.
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+}
+
+// decode unmarshals the JSON body of the response into v.
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding the response body %q: %v", rec.Body.String(), err)
+	}
+}
+
+// urlEncode escapes s to be used in a query string.
+func urlEncode(s string) string {
+	return url.QueryEscape(s)
+}
@@ -0,0 +1,186 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// createLabel creates a label through the API and returns it.
+func (a *testApp) createLabel(token string, label map[string]interface{}) models.Label {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/labels/create", label, token)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var created models.Label
+	decode(a.t, rec, &created)
+	return created
+}
+
+// tag tags the tasks with the label, expecting the given status.
+func (a *testApp) tag(token string, labelID uint, status int, taskIDs ...uint) {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, fmt.Sprintf("/labels/tag/id/%d", labelID), map[string]interface{}{"task_ids": taskIDs}, token)
+	expectStatus(a.t, rec, status)
+}
+
+// taskIDsOf fetches a task list and returns the IDs of its tasks.
+func (a *testApp) taskIDsOf(token, path string) []uint {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, path, nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(a.t, rec, &tasks)
+
+	ids := []uint{}
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestCreateLabel(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+
+	home := app.createLabel(token, map[string]interface{}{"name": "@home", "color": "#ff8800"})
+	office := app.createLabel(token, map[string]interface{}{"name": "@office"})
+	if office.Color != models.DefaultLabelColor {
+		t.Fatalf("expected the default color, got %+v", office)
+	}
+	expectStatus(t, app.request(http.MethodPost, "/labels/create", map[string]interface{}{"name": "@HOME"}, token), http.StatusConflict)
+	expectStatus(t, app.request(http.MethodPost, "/labels/create", map[string]interface{}{"name": "@car", "color": "orange"}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, "/labels/create", map[string]interface{}{"name": ""}, token), http.StatusBadRequest)
+
+	// Names are only unique within the labels of the same user or workspace
+	app.createLabel(otherToken, map[string]interface{}{"name": "@home"})
+	workspace := app.createWorkspace(token, "Family")
+	shared := app.createLabel(token, map[string]interface{}{"name": "@home", "workspace_id": workspace.ID})
+	expectStatus(t, app.request(http.MethodPost, "/labels/create", map[string]interface{}{"name": "Garden", "workspace_id": workspace.ID}, otherToken), http.StatusNotFound)
+
+	rec := app.request(http.MethodGet, "/labels/get/all/", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var labels []models.Label
+	decode(t, rec, &labels)
+	if len(labels) != 2 || labels[0].ID != home.ID || labels[1].ID != office.ID {
+		t.Fatalf("expected the personal labels by name, got %+v", labels)
+	}
+	rec = app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID+"/labels", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &labels)
+	if len(labels) != 1 || labels[0].ID != shared.ID {
+		t.Fatalf("expected the workspace label, got %+v", labels)
+	}
+
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/labels/get/id/%d", home.ID), nil, otherToken), http.StatusNotFound)
+	rec = app.request(http.MethodPut, fmt.Sprintf("/labels/update/id/%d", office.ID), map[string]interface{}{"name": "@work", "color": "#0000ff", "created_at": "2000-01-01T00:00:00Z"}, token)
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.Label
+	decode(t, rec, &updated)
+	if updated.Name != "@work" || updated.Color != "#0000ff" || !updated.CreatedAt.Equal(office.CreatedAt) {
+		t.Fatalf("expected the label to be renamed and recolored, got %+v", updated)
+	}
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/labels/update/id/%d", office.ID), map[string]interface{}{"name": "@home"}, token), http.StatusConflict)
+}
+
+func TestTagTasks(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	home := app.createLabel(token, map[string]interface{}{"name": "@home"})
+	garden := app.createLabel(token, map[string]interface{}{"name": "Garden"})
+	mow := app.createTask(token, map[string]interface{}{"title": "Mow the lawn"})
+	water := app.createTask(token, map[string]interface{}{"title": "Water the plants"})
+	report := app.createTask(token, map[string]interface{}{"title": "Write the report"})
+
+	app.tag(token, home.ID, http.StatusOK, mow.ID, water.ID)
+	app.tag(token, home.ID, http.StatusOK, mow.ID)
+	app.tag(token, garden.ID, http.StatusOK, mow.ID)
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/labels", mow.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var labels []models.Label
+	decode(t, rec, &labels)
+	if len(labels) != 2 || labels[0].ID != home.ID || labels[1].ID != garden.ID {
+		t.Fatalf("expected the task to be tagged with both labels, got %+v", labels)
+	}
+
+	// Tagging is all or nothing
+	foreign := app.createTask(otherToken, map[string]interface{}{"title": "Not mine"})
+	app.tag(token, garden.ID, http.StatusBadRequest, water.ID, foreign.ID)
+	workspace := app.createWorkspace(token, "Family")
+	shared := app.createTask(token, map[string]interface{}{"title": "Shared", "workspace_id": workspace.ID})
+	app.tag(token, garden.ID, http.StatusBadRequest, water.ID, shared.ID)
+	app.tag(otherToken, garden.ID, http.StatusNotFound, foreign.ID)
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/labels/tag/id/%d", garden.ID), map[string]interface{}{"task_ids": []uint{}}, token), http.StatusBadRequest)
+	user := mow.UserID
+	if ids := app.taskIDsOf(token, fmt.Sprintf("/tasks/get/user/%s?labels=%d", user, garden.ID)); len(ids) != 1 || ids[0] != mow.ID {
+		t.Fatalf("expected only the lawn to be in the garden, got %v", ids)
+	}
+
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		{fmt.Sprintf("labels=%d,%d", home.ID, garden.ID), []uint{mow.ID}},
+		{fmt.Sprintf("labels=%d,%d&match=all", home.ID, garden.ID), []uint{mow.ID}},
+		{fmt.Sprintf("labels=%d,%d&match=any", home.ID, garden.ID), []uint{mow.ID, water.ID}},
+		{"", []uint{mow.ID, water.ID, report.ID}},
+	}
+	for _, tt := range tests {
+		ids := app.taskIDsOf(token, "/tasks/get/user/"+user+"?"+tt.query)
+		if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Fatalf("expected %q to list %v, got %v", tt.query, tt.want, ids)
+		}
+	}
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/user/"+user+"?labels=x", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/user/%s?labels=%d&match=some", user, home.ID), nil, token), http.StatusBadRequest)
+	if ids := app.taskIDsOf(token, fmt.Sprintf("/tasks/search/user/%s?q=lawn&labels=%d", user, home.ID)); len(ids) != 1 || ids[0] != mow.ID {
+		t.Fatalf("expected the search to find the lawn, got %v", ids)
+	}
+
+	rec = app.request(http.MethodPost, fmt.Sprintf("/labels/untag/id/%d", home.ID), map[string]interface{}{"task_ids": []uint{mow.ID, water.ID}}, token)
+	expectStatus(t, rec, http.StatusOK)
+	if ids := app.taskIDsOf(token, fmt.Sprintf("/tasks/get/user/%s?labels=%d", user, home.ID)); len(ids) != 0 {
+		t.Fatalf("expected no task tagged @home, got %v", ids)
+	}
+
+	// Deleting a label untags its tasks
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/labels/delete/id/%d", garden.ID), nil, token), http.StatusOK)
+	if ids := app.taskIDsOf(token, fmt.Sprintf("/tasks/get/user/%s?labels=%d", user, garden.ID)); len(ids) != 0 {
+		t.Fatalf("expected no task tagged with the deleted label, got %v", ids)
+	}
+}
+
+func TestWorkspaceLabels(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(token, "Release")
+	app.joinWorkspace(token, workspace, viewer, viewerToken, models.RoleViewer)
+	backend := app.createLabel(token, map[string]interface{}{"name": "Backend", "workspace_id": workspace.ID})
+	api := app.createTask(token, map[string]interface{}{"title": "Build the API", "workspace_id": workspace.ID})
+	app.createTask(token, map[string]interface{}{"title": "Design the UI", "workspace_id": workspace.ID})
+
+	app.tag(viewerToken, backend.ID, http.StatusForbidden, api.ID)
+	app.tag(token, backend.ID, http.StatusOK, api.ID)
+	personal := app.createTask(token, map[string]interface{}{"title": "Personal"})
+	app.tag(token, backend.ID, http.StatusBadRequest, personal.ID)
+
+	ids := app.taskIDsOf(viewerToken, fmt.Sprintf("/workspaces/get/id/%s/tasks?labels=%d", workspace.ID, backend.ID))
+	if len(ids) != 1 || ids[0] != api.ID {
+		t.Fatalf("expected the viewer to filter the workspace tasks by label, got %v", ids)
+	}
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/labels/get/id/%d", backend.ID), nil, viewerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/labels/delete/id/%d", backend.ID), nil, viewerToken), http.StatusForbidden)
+
+	// Deleting the workspace deletes its labels
+	expectStatus(t, app.request(http.MethodDelete, "/workspaces/delete/id/"+workspace.ID, nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/labels/get/id/%d", backend.ID), nil, token), http.StatusNotFound)
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/docs"
+	"github.com/labstack/echo/v4"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	app := newTestApp(t)
+	spec := docs.Spec()
+
+	registered := map[string]bool{}
+	for _, route := range app.e.Routes() {
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+		registered[route.Method+" "+route.Path] = true
+
+		if !spec.HasOperation(route.Method, route.Path) {
+			t.Errorf("%s %s is registered but missing from the OpenAPI document, add it to docs.Routes", route.Method, route.Path)
+		}
+	}
+
+	for _, route := range docs.Routes() {
+		if !registered[route.Method+" "+route.Path] {
+			t.Errorf("%s %s is documented but not registered", route.Method, route.Path)
+		}
+	}
+}
+
+func TestServeOpenAPIDocument(t *testing.T) {
+	app := newTestApp(t)
+
+	rec := app.request(http.MethodGet, "/openapi.json", nil, "")
+	expectStatus(t, rec, http.StatusOK)
+
+	var doc docs.Document
+	decode(t, rec, &doc)
+	if doc.OpenAPI != "3.1.0" {
+		t.Fatalf("expected an OpenAPI 3.1 document, got %q", doc.OpenAPI)
+	}
+
+	if user := doc.Components.Schemas["User"]; user == nil || user.Properties["password"] != nil {
+		t.Fatalf("expected the User schema in the components, without the password, got %+v", user)
+	}
+	user := doc.Components.Schemas["UserRequest"]
+	if user == nil {
+		t.Fatal("expected the UserRequest schema in the components")
+	}
+	for _, field := range []string{"name", "email", "password", "age"} {
+		if !contains(user.Required, field) {
+			t.Errorf("expected %q to be required, required fields are %v", field, user.Required)
+		}
+	}
+	if format := user.Properties["email"].Format; format != "email" {
+		t.Errorf("expected the e-mail format, got %q", format)
+	}
+	if min := user.Properties["password"].MinLength; min == nil || *min != 6 {
+		t.Errorf("expected the password to have a minimum length of 6, got %v", min)
+	}
+	if min := user.Properties["age"].Minimum; min == nil || *min != 18 {
+		t.Errorf("expected the age to have a minimum of 18, got %v", min)
+	}
+
+	rec = app.request(http.MethodGet, "/docs", nil, "")
+	expectStatus(t, rec, http.StatusOK)
+	if !strings.Contains(rec.Body.String(), "swagger-ui") {
+		t.Fatal("expected the Swagger UI page")
+	}
+	if strings.Contains(rec.Body.String(), "https://") {
+		t.Fatal("expected the Swagger UI page not to load anything from other sites")
+	}
+	for _, asset := range []string{"swagger-ui-bundle.js", "swagger-ui.css"} {
+		rec = app.request(http.MethodGet, "/docs/swagger-ui/"+asset, nil, "")
+		expectStatus(t, rec, http.StatusOK)
+		if rec.Body.Len() == 0 {
+			t.Fatalf("expected the content of %s", asset)
+		}
+	}
+	expectStatus(t, app.request(http.MethodGet, "/docs/swagger-ui/missing.js", nil, ""), http.StatusNotFound)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
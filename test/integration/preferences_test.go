@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// preferences fetches the preferences of the user.
+func (a *testApp) preferences(token string) models.Preferences {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, "/users/get/preferences", nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+	var preferences models.Preferences
+	decode(a.t, rec, &preferences)
+	return preferences
+}
+
+func TestPreferences(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+
+	preferences := app.preferences(token)
+	if preferences.UserID != user.ID || preferences.Timezone != "UTC" || preferences.Locale != "pt-BR" || preferences.WeekStart != "sunday" ||
+		preferences.DefaultReminder != nil || fmt.Sprint(preferences.NotificationChannels) != "[in_app]" {
+		t.Fatalf("expected the default preferences, got %+v", preferences)
+	}
+
+	update := map[string]interface{}{"timezone": "America/Sao_Paulo", "locale": "en-US", "default_reminder": 30, "notification_channels": []string{"email", "push"}}
+	expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", update, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", map[string]interface{}{"week_start": "monday"}, token), http.StatusOK)
+	preferences = app.preferences(token)
+	if preferences.Timezone != "America/Sao_Paulo" || preferences.Locale != "en-US" || preferences.WeekStart != "monday" ||
+		preferences.DefaultReminder == nil || *preferences.DefaultReminder != 30 || fmt.Sprint(preferences.NotificationChannels) != "[email push]" {
+		t.Fatalf("expected the preferences to be updated, got %+v", preferences)
+	}
+
+	invalid := []map[string]interface{}{
+		{"timezone": "Mars/Olympus_Mons"},
+		{"timezone": ""},
+		{"locale": "not a locale"},
+		{"week_start": "someday"},
+		{"default_reminder": -5},
+		{"notification_channels": []string{"pigeon"}},
+		{"notification_channels": []string{"email", "email"}},
+	}
+	for _, body := range invalid {
+		expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", body, token), http.StatusBadRequest)
+	}
+
+	// The default reminder applies to tasks due without a reminder of their own
+	due := time.Date(2030, 1, 10, 15, 0, 0, 0, time.UTC)
+	task := app.createTask(token, map[string]interface{}{"title": "Dentist", "due_date": due})
+	if task.Reminder == nil || !task.Reminder.Equal(due.Add(-30*time.Minute)) {
+		t.Fatalf("expected the task to be reminded 30 minutes before, got %+v", task)
+	}
+	task = app.createTask(token, map[string]interface{}{"title": "Doctor", "due_date": due, "reminder": due.Add(-time.Hour)})
+	if task.Reminder == nil || !task.Reminder.Equal(due.Add(-time.Hour)) {
+		t.Fatalf("expected the task to keep its reminder, got %+v", task)
+	}
+	expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", map[string]interface{}{"default_reminder": nil}, token), http.StatusOK)
+	if task = app.createTask(token, map[string]interface{}{"title": "Barber", "due_date": due}); task.Reminder != nil {
+		t.Fatalf("expected no default reminder, got %+v", task)
+	}
+}
+
+func TestAgendaViews(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+
+	// Kiritimati is 14 hours ahead of UTC, so its days rarely line up with the server's
+	loc, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	weekStart := strings.ToLower(now.Weekday().String())
+	expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", map[string]interface{}{"timezone": loc.String(), "week_start": weekStart}, token), http.StatusOK)
+
+	at := func(title string, due time.Time) models.Tasks {
+		return app.createTask(token, map[string]interface{}{"title": title, "due_date": due})
+	}
+	yesterday := at("Yesterday", today.Add(-time.Minute))
+	earlyToday := at("Early today", today)
+	lateToday := at("Late today", today.Add(24*time.Hour-time.Minute))
+	tomorrow := at("Tomorrow", today.Add(24*time.Hour))
+	endOfWeek := at("End of the week", today.AddDate(0, 0, 7).Add(-time.Minute))
+	at("Next week", today.AddDate(0, 0, 7))
+	app.createTask(token, map[string]interface{}{"title": "Done today", "due_date": today.Add(time.Hour), "status": models.TaskDone})
+	app.createTask(token, map[string]interface{}{"title": "No due date"})
+
+	tests := []struct {
+		path string
+		want []uint
+	}{
+		{"/tasks/get/today", []uint{earlyToday.ID, lateToday.ID}},
+		{"/tasks/get/overdue", []uint{yesterday.ID}},
+		{"/tasks/get/week", []uint{earlyToday.ID, lateToday.ID, tomorrow.ID, endOfWeek.ID}},
+	}
+	for _, tt := range tests {
+		if ids := app.taskIDsOf(token, tt.path); fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Fatalf("expected %s to list %v, got %v", tt.path, tt.want, ids)
+		}
+	}
+
+	// A week starting tomorrow started six days ago
+	weekStart = strings.ToLower(now.AddDate(0, 0, 1).Weekday().String())
+	expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", map[string]interface{}{"week_start": weekStart}, token), http.StatusOK)
+	if ids := app.taskIDsOf(token, "/tasks/get/week"); fmt.Sprint(ids) != fmt.Sprint([]uint{yesterday.ID, earlyToday.ID, lateToday.ID}) {
+		t.Fatalf("expected the week to end today, got %v", ids)
+	}
+}
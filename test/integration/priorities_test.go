@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// nextActions fetches the next actions of the user and returns the IDs of their tasks, in order.
+func (a *testApp) nextActions(token, query string) ([]handlers.NextActionResponse, []uint) {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, "/tasks/get/next"+query, nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+	var actions []handlers.NextActionResponse
+	decode(a.t, rec, &actions)
+
+	ids := []uint{}
+	for _, action := range actions {
+		ids = append(ids, action.Task.ID)
+	}
+	return actions, ids
+}
+
+func TestTaskPriority(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+
+	task := app.createTask(token, map[string]interface{}{"title": "Pay the rent", "priority": models.PriorityHigh, "important": true})
+	if task.Priority != models.PriorityHigh || task.Important == nil || !*task.Important || task.Urgent != nil {
+		t.Fatalf("expected a high priority important task, got %+v", task)
+	}
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Too high", "priority": 4}, token), http.StatusBadRequest)
+
+	rec := app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", task.ID), map[string]interface{}{"priority": models.PriorityLow, "urgent": false}, token)
+	expectStatus(t, rec, http.StatusOK)
+	if task = app.getTask(token, task.ID); task.Priority != models.PriorityLow || task.Urgent == nil || *task.Urgent {
+		t.Fatalf("expected the priority and urgency to be updated, got %+v", task)
+	}
+}
+
+func TestNextActions(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	_, ownerToken := app.newUser()
+	now := time.Now()
+
+	overdue := app.createTask(token, map[string]interface{}{"title": "Overdue", "due_date": now.Add(-50 * time.Hour)})
+	high := app.createTask(token, map[string]interface{}{"title": "High", "priority": models.PriorityHigh})
+	soon := app.createTask(token, map[string]interface{}{"title": "Due soon", "due_date": now.Add(24 * time.Hour)})
+	plain := app.createTask(token, map[string]interface{}{"title": "Plain"})
+	blocked := app.createTask(token, map[string]interface{}{"title": "Blocked", "priority": models.PriorityHigh})
+	app.addDependency(token, blocked.ID, plain.ID, http.StatusCreated)
+	app.createTask(token, map[string]interface{}{"title": "Done", "priority": models.PriorityHigh, "status": models.TaskDone})
+
+	// Tasks assigned to the user count too, but only once and while they can see them
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, user, token, models.RoleEditor)
+	shared := app.createTask(ownerToken, map[string]interface{}{"title": "Shared", "workspace_id": workspace.ID, "priority": models.PriorityMedium})
+	rec := app.request(http.MethodPost, fmt.Sprintf("/tasks/assign/id/%d", shared.ID), map[string]interface{}{"user_id": user.ID}, ownerToken)
+	expectStatus(t, rec, http.StatusCreated)
+	mine := app.createTask(token, map[string]interface{}{"title": "Mine", "workspace_id": workspace.ID})
+	rec = app.request(http.MethodPost, fmt.Sprintf("/tasks/assign/id/%d", mine.ID), map[string]interface{}{"user_id": user.ID}, token)
+	expectStatus(t, rec, http.StatusCreated)
+
+	actions, ids := app.nextActions(token, "")
+	// The task due soon scores as much as the high priority one, and goes first for having a due date
+	want := []uint{overdue.ID, soon.ID, high.ID, shared.ID, plain.ID, mine.ID, blocked.ID}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Fatalf("expected the next actions %v, got %v", want, ids)
+	}
+	if actions[0].Score != 44 || len(actions[0].Factors) != 1 || actions[0].Factors[0].Name != "overdue" || actions[0].Factors[0].Explanation == "" {
+		t.Fatalf("expected the overdue task to be explained, got %+v", actions[0])
+	}
+	if last := actions[len(actions)-1]; last.Score != -20 || len(last.Factors) != 2 {
+		t.Fatalf("expected the blocked task to be pushed down, got %+v", last)
+	}
+
+	if _, ids = app.nextActions(token, "?limit=2"); fmt.Sprint(ids) != fmt.Sprint(want[:2]) {
+		t.Fatalf("expected the first two next actions, got %v", ids)
+	}
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/next?limit=0", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/next?limit=101", nil, token), http.StatusBadRequest)
+
+	expectStatus(t, app.request(http.MethodDelete, "/workspaces/delete/id/"+workspace.ID, nil, ownerToken), http.StatusOK)
+	if _, ids = app.nextActions(token, ""); len(ids) != 5 {
+		t.Fatalf("expected the workspace task to be gone, got %v", ids)
+	}
+}
+
+func TestEisenhower(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	now := time.Now()
+
+	do := app.createTask(token, map[string]interface{}{"title": "Do", "priority": models.PriorityHigh, "due_date": now.Add(time.Hour)})
+	schedule := app.createTask(token, map[string]interface{}{"title": "Schedule", "important": true})
+	delegate := app.createTask(token, map[string]interface{}{"title": "Delegate", "urgent": true})
+	eliminate := app.createTask(token, map[string]interface{}{"title": "Eliminate", "priority": models.PriorityHigh, "important": false, "due_date": now.Add(30 * 24 * time.Hour)})
+
+	rec := app.request(http.MethodGet, "/tasks/get/eisenhower", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var matrix handlers.EisenhowerResponse
+	decode(t, rec, &matrix)
+	quadrants := map[string][]models.Tasks{"do": matrix.Do, "schedule": matrix.Schedule, "delegate": matrix.Delegate, "eliminate": matrix.Eliminate}
+	for name, want := range map[string]uint{"do": do.ID, "schedule": schedule.ID, "delegate": delegate.ID, "eliminate": eliminate.ID} {
+		if got := quadrants[name]; len(got) != 1 || got[0].ID != want {
+			t.Fatalf("expected task %d alone in the %s quadrant, got %+v", want, name, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// quickAdd creates a task from a line of text through the API, expecting the given status code.
+func (a *testApp) quickAdd(token, path string, body map[string]interface{}, code int) handlers.QuickAddResponse {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, path, body, token)
+	expectStatus(a.t, rec, code)
+	var added handlers.QuickAddResponse
+	if code < http.StatusBadRequest {
+		decode(a.t, rec, &added)
+	}
+	return added
+}
+
+func TestQuickAdd(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	expectStatus(t, app.request(http.MethodPut, "/users/update/preferences", map[string]interface{}{"timezone": "America/Sao_Paulo"}, token), http.StatusOK)
+	finance := app.createLabel(token, map[string]interface{}{"name": "Finance"})
+
+	app.quickAdd(token, "/tasks/quick-add", map[string]interface{}{"text": "Pay rent tomorrow", "timezone": "Mars/Olympus_Mons"}, http.StatusBadRequest)
+	app.quickAdd(token, "/tasks/quick-add", map[string]interface{}{"text": "Water the plants every 0 days"}, http.StatusBadRequest)
+	added := app.quickAdd(token, "/tasks/quick-add", map[string]interface{}{"text": "Pay rent tomorrow 9am #finance #home !high every month !urgent"}, http.StatusCreated)
+	task := app.getTask(token, added.Task.ID)
+	loc, _ := time.LoadLocation("America/Sao_Paulo")
+	now := time.Now().In(loc)
+	due := time.Date(now.Year(), now.Month(), now.Day()+1, 9, 0, 0, 0, loc)
+	if task.Title != "Pay rent !urgent" || task.DueDate == nil || !task.DueDate.Equal(due) || task.Reminder == nil || !task.Reminder.Equal(due) ||
+		task.Priority != models.PriorityHigh || task.Recurrence == nil || *task.Recurrence != "FREQ=MONTHLY" {
+		t.Fatalf("expected the text to be parsed in the user's time zone, got %+v", task)
+	}
+	if len(added.Unparsed) != 1 || added.Unparsed[0].Text != "!urgent" || added.Unparsed[0].Start != 55 {
+		t.Fatalf("expected !urgent to be unparsed, got %+v", added.Unparsed)
+	}
+
+	// The time zone of the request wins over the preferences
+	preview := app.quickAdd(token, "/tasks/quick-add?preview=true", map[string]interface{}{"text": "Pay rent tomorrow 9am", "timezone": "Asia/Tokyo"}, http.StatusOK)
+	if preview.Task.DueDate == nil || preview.Task.DueDate.In(time.UTC).Hour() != 0 {
+		t.Fatalf("expected the text to be parsed in the time zone of the request, got %+v", preview.Task)
+	}
+
+	// The existing label is reused and the missing one created
+	if len(added.Labels) != 2 || added.Labels[0].ID != finance.ID || added.Labels[1].Name != "home" || added.Labels[1].ID == 0 {
+		t.Fatalf("expected the task to be tagged with finance and a new home label, got %+v", added.Labels)
+	}
+	rec := app.request(http.MethodGet, "/labels/get/all/", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var labels []models.Label
+	decode(t, rec, &labels)
+	if len(labels) != 2 {
+		t.Fatalf("expected two personal labels, got %+v", labels)
+	}
+
+	expectStatus(t, app.request(http.MethodPost, "/tasks/quick-add", map[string]interface{}{"text": "tomorrow #finance !high"}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, "/tasks/quick-add", map[string]interface{}{"text": ""}, token), http.StatusBadRequest)
+}
+
+func TestQuickAddPreview(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+
+	added := app.quickAdd(token, "/tasks/quick-add?preview=true", map[string]interface{}{"text": "Pagar a fatura toda segunda #contas"}, http.StatusOK)
+	if added.Task.ID != 0 || added.Task.Title != "Pagar a fatura" || added.Task.DueDate == nil || added.Task.DueDate.Weekday() != time.Monday {
+		t.Fatalf("expected an unsaved task due on a Monday, got %+v", added.Task)
+	}
+	if len(added.Labels) != 1 || added.Labels[0].ID != 0 || added.Labels[0].Name != "contas" {
+		t.Fatalf("expected an unsaved label, got %+v", added.Labels)
+	}
+	if ids := app.taskIDsOf(token, "/tasks/get/user/"+added.Task.UserID); len(ids) != 0 {
+		t.Fatalf("expected the preview not to create the task, got %v", ids)
+	}
+}
+
+func TestQuickAddWorkspace(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(token, "Release")
+	app.joinWorkspace(token, workspace, viewer, viewerToken, models.RoleViewer)
+	backend := app.createLabel(token, map[string]interface{}{"name": "backend", "workspace_id": workspace.ID})
+
+	body := map[string]interface{}{"text": "Ship the API #Backend", "workspace_id": workspace.ID}
+	app.quickAdd(viewerToken, "/tasks/quick-add", body, http.StatusForbidden)
+	added := app.quickAdd(token, "/tasks/quick-add", body, http.StatusCreated)
+	if added.Task.WorkspaceID == nil || *added.Task.WorkspaceID != workspace.ID || len(added.Labels) != 1 || added.Labels[0].ID != backend.ID {
+		t.Fatalf("expected the workspace task to be tagged with the workspace label, got %+v", added)
+	}
+}
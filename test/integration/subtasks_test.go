@@ -0,0 +1,238 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/handlers"
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// addChecklistItem adds an item to the checklist of the task and returns it.
+func (a *testApp) addChecklistItem(token string, taskID uint, title string) models.ChecklistItem {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, fmt.Sprintf("/tasks/checklist/id/%d", taskID), map[string]interface{}{"title": title}, token)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var item models.ChecklistItem
+	decode(a.t, rec, &item)
+	return item
+}
+
+// progress returns the progress of the task.
+func (a *testApp) progress(token string, taskID uint) handlers.TaskProgressResponse {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/progress", taskID), nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+
+	var progress handlers.TaskProgressResponse
+	decode(a.t, rec, &progress)
+	return progress
+}
+
+func TestSubtasks(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	project := app.createTask(token, map[string]interface{}{"title": "Move house"})
+	packing := app.createTask(token, map[string]interface{}{"title": "Pack", "parent_id": project.ID})
+	app.createTask(token, map[string]interface{}{"title": "Pack the kitchen", "parent_id": packing.ID})
+	app.createTask(token, map[string]interface{}{"title": "Hire a truck", "parent_id": project.ID})
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/subtasks", project.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var children []models.Tasks
+	decode(t, rec, &children)
+	if len(children) != 2 || children[0].ID != packing.ID || *children[0].ParentID != project.ID {
+		t.Fatalf("expected the two direct subtasks of the project, got %+v", children)
+	}
+
+	// Tasks of other users, or of another workspace, can't be parents
+	foreign := app.createTask(otherToken, map[string]interface{}{"title": "Not mine"})
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Sneaky", "parent_id": foreign.ID}, token), http.StatusBadRequest)
+	workspace := app.createWorkspace(token, "Family")
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Shared", "workspace_id": workspace.ID, "parent_id": project.ID}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/subtasks", project.ID), nil, otherToken), http.StatusNotFound)
+}
+
+func TestSubtaskCycles(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	a := app.createTask(token, map[string]interface{}{"title": "A"})
+	b := app.createTask(token, map[string]interface{}{"title": "B", "parent_id": a.ID})
+	c := app.createTask(token, map[string]interface{}{"title": "C", "parent_id": b.ID})
+
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", a.ID), map[string]interface{}{"parent_id": a.ID}, token), http.StatusConflict)
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", a.ID), map[string]interface{}{"parent_id": c.ID}, token), http.StatusConflict)
+
+	// Moving C directly under A is fine, and so is making it a top-level task again
+	rec := app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", c.ID), map[string]interface{}{"parent_id": a.ID}, token)
+	expectStatus(t, rec, http.StatusOK)
+	var moved models.Tasks
+	decode(t, rec, &moved)
+	if moved.ParentID == nil || *moved.ParentID != a.ID {
+		t.Fatalf("expected C to be a subtask of A, got %+v", moved)
+	}
+	rec = app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", c.ID), map[string]interface{}{"parent_id": nil}, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &moved)
+	if moved.ParentID != nil {
+		t.Fatalf("expected C to be a top-level task, got %+v", moved)
+	}
+
+	// Deleting a task turns its subtasks into top-level tasks
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", a.ID), nil, token), http.StatusOK)
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", b.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var orphan models.Tasks
+	decode(t, rec, &orphan)
+	if orphan.ParentID != nil {
+		t.Fatalf("expected B to lose its deleted parent, got %+v", orphan)
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Trip"})
+	passport := app.addChecklistItem(token, task.ID, "Passport")
+	tickets := app.addChecklistItem(token, task.ID, "Tickets")
+	charger := app.addChecklistItem(token, task.ID, "Charger")
+	if passport.Position != 0 || charger.Position != 2 {
+		t.Fatalf("expected the items to be appended in order, got positions %d and %d", passport.Position, charger.Position)
+	}
+
+	path := fmt.Sprintf("/tasks/reorder/id/%d/checklist", task.ID)
+	rec := app.request(http.MethodPut, path, map[string]interface{}{"ids": []uint{charger.ID, passport.ID, tickets.ID}}, token)
+	expectStatus(t, rec, http.StatusOK)
+	var items []models.ChecklistItem
+	decode(t, rec, &items)
+	if len(items) != 3 || items[0].ID != charger.ID || items[1].ID != passport.ID || items[2].ID != tickets.ID {
+		t.Fatalf("expected the checklist in the new order, got %+v", items)
+	}
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"ids": []uint{charger.ID, passport.ID}}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"ids": []uint{charger.ID, passport.ID, passport.ID}}, token), http.StatusBadRequest)
+
+	rec = app.request(http.MethodPut, fmt.Sprintf("/checklist/update/id/%d", passport.ID), map[string]interface{}{"done": true, "position": 9, "created_at": "2000-01-01T00:00:00Z"}, token)
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.ChecklistItem
+	decode(t, rec, &updated)
+	if !updated.Done || updated.Title != "Passport" || updated.Position != 1 || !updated.CreatedAt.Equal(passport.CreatedAt) {
+		t.Fatalf("expected the passport to be checked off in place, got %+v", updated)
+	}
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/checklist/update/id/%d", passport.ID), map[string]interface{}{"title": ""}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/checklist/update/id/%d", passport.ID), map[string]interface{}{"done": false}, otherToken), http.StatusNotFound)
+
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/checklist/delete/id/%d", tickets.ID), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/checklist/delete/id/%d", tickets.ID), nil, token), http.StatusNotFound)
+
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/checklist", task.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &items)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 checklist items left, got %+v", items)
+	}
+}
+
+func TestTaskProgress(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	project := app.createTask(token, map[string]interface{}{"title": "Move house"})
+
+	if progress := app.progress(token, project.ID); progress.Percent != 0 {
+		t.Fatalf("expected an empty pending task to be 0%% done, got %+v", progress)
+	}
+
+	packing := app.createTask(token, map[string]interface{}{"title": "Pack", "parent_id": project.ID})
+	kitchen := app.createTask(token, map[string]interface{}{"title": "Pack the kitchen", "parent_id": packing.ID})
+	app.createTask(token, map[string]interface{}{"title": "Hire a truck", "parent_id": project.ID, "status": models.TaskDone})
+	app.addChecklistItem(token, project.ID, "Change the address")
+	boxes := app.addChecklistItem(token, packing.ID, "Buy boxes")
+
+	progress := app.progress(token, project.ID)
+	want := handlers.TaskProgressResponse{Subtasks: 2, SubtasksDone: 1, ChecklistItems: 1, ChecklistItemsDone: 0, Percent: 33}
+	if progress != want {
+		t.Fatalf("expected progress %+v, got %+v", want, progress)
+	}
+
+	// Completing without cascading leaves the subtasks alone
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", packing.ID), map[string]interface{}{"status": models.TaskDone}, token), http.StatusOK)
+	if progress := app.progress(token, packing.ID); progress.Percent != 0 {
+		t.Fatalf("expected the subtasks of Pack to be untouched, got %+v", progress)
+	}
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", packing.ID), map[string]interface{}{"status": models.TaskPending}, token), http.StatusOK)
+
+	expectStatus(t, app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d?cascade=true", project.ID), map[string]interface{}{"status": models.TaskDone}, token), http.StatusOK)
+	if progress := app.progress(token, project.ID); progress.Percent != 100 {
+		t.Fatalf("expected the project to be fully done, got %+v", progress)
+	}
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", kitchen.ID), nil, token)
+	var grandchild models.Tasks
+	decode(t, rec, &grandchild)
+	if grandchild.Status != models.TaskDone || grandchild.CompletedAt == nil {
+		t.Fatalf("expected the cascade to reach every depth, got %+v", grandchild)
+	}
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/checklist", packing.ID), nil, token)
+	var items []models.ChecklistItem
+	decode(t, rec, &items)
+	if len(items) != 1 || items[0].ID != boxes.ID || !items[0].Done {
+		t.Fatalf("expected the checklist of the subtasks to be checked off, got %+v", items)
+	}
+}
+
+func TestCascadeFollowsTheWorkflow(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	workspace := app.createWorkspace(token, "Release")
+	workflow := map[string]interface{}{
+		"initial": "todo",
+		"statuses": []map[string]interface{}{
+			{"name": "todo"},
+			{"name": "review"},
+			{"name": "shipped", "terminal": true, "wip_limit": 2},
+		},
+		"transitions": reviewWorkflow["transitions"],
+	}
+	expectStatus(t, app.request(http.MethodPut, "/workspaces/update/id/"+workspace.ID+"/workflow", workflow, token), http.StatusOK)
+
+	project := app.createTask(token, map[string]interface{}{"title": "Release 2.0", "workspace_id": workspace.ID})
+	step := app.createTask(token, map[string]interface{}{"title": "Changelog", "workspace_id": workspace.ID, "parent_id": project.ID})
+	app.updateStatus(token, project.ID, "review", http.StatusOK)
+	ship := func(query string, code int) {
+		t.Helper()
+		rec := app.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d?cascade=true%s", project.ID, query), map[string]interface{}{"status": "shipped"}, token)
+		expectStatus(t, rec, code)
+	}
+
+	// A subtask that can't take the status on its own stops the cascade, and the task with it
+	ship("", http.StatusConflict)
+	if task := app.getTask(token, project.ID); task.Status != "review" {
+		t.Fatalf("expected the task to stay in review, got %+v", task)
+	}
+
+	// So does going over the WIP limit of the column, counting the task
+	app.updateStatus(token, step.ID, "review", http.StatusOK)
+	extra := app.createTask(token, map[string]interface{}{"title": "Docs", "workspace_id": workspace.ID, "parent_id": step.ID})
+	app.updateStatus(token, extra.ID, "review", http.StatusOK)
+	ship("", http.StatusConflict)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", extra.ID), nil, token), http.StatusOK)
+
+	// As does a blocked subtask, unless forced
+	blocker := app.createTask(token, map[string]interface{}{"title": "Sign off", "workspace_id": workspace.ID})
+	app.addDependency(token, step.ID, blocker.ID, http.StatusCreated)
+	ship("", http.StatusConflict)
+	ship("&force=true", http.StatusOK)
+
+	shipped, moved := app.getTask(token, project.ID), app.getTask(token, step.ID)
+	if moved.Status != "shipped" || moved.CompletedAt == nil {
+		t.Fatalf("expected the subtask to be shipped, got %+v", moved)
+	}
+	if moved.Rank <= shipped.Rank {
+		t.Fatalf("expected the subtask to follow the task in the column, got the ranks %q and %q", shipped.Rank, moved.Rank)
+	}
+}
@@ -0,0 +1,153 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestCreateTask(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	other, _ := app.newUser()
+
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent", "notes": "before the 5th", "user_id": other.ID})
+	if task.ID == 0 || task.Title != "Pay rent" || task.Status != "pending" {
+		t.Fatalf("unexpected created task: %+v", task)
+	}
+	if task.UserID != user.ID {
+		t.Fatalf("expected the task to be owned by the authenticated user %s, got %s", user.ID, task.UserID)
+	}
+
+	forged := app.createTask(token, map[string]interface{}{
+		"title": "Forged", "id": 999, "created_at": "2000-01-01T00:00:00Z", "deleted_at": "2000-01-01T00:00:00Z",
+	})
+	if forged.ID == 999 || forged.ID == 0 || forged.CreatedAt.Year() == 2000 {
+		t.Fatalf("expected the database to number and date the task, got %+v", forged)
+	}
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", forged.ID), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Same ID", "id": task.ID}, token), http.StatusCreated)
+
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"notes": "no title"}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", `{"title": `, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Pay rent"}, ""), http.StatusUnauthorized)
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Pay rent"}, "not-a-token"), http.StatusUnauthorized)
+}
+
+func TestGetTask(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", task.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var got models.Tasks
+	decode(t, rec, &got)
+	if got.ID != task.ID || got.Title != task.Title {
+		t.Fatalf("expected task %+v, got %+v", task, got)
+	}
+
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", task.ID), nil, otherToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/id/9999", nil, token), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/id/not-a-number", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", task.ID), nil, ""), http.StatusUnauthorized)
+}
+
+func TestGetTasksByUser(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	other, otherToken := app.newUser()
+	app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+	app.createTask(token, map[string]interface{}{"title": "Buy milk"})
+	app.createTask(otherToken, map[string]interface{}{"title": "Walk the dog"})
+
+	rec := app.request(http.MethodGet, "/tasks/get/user/"+user.ID, nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+
+	expectStatus(t, app.request(http.MethodGet, "/tasks/get/user/"+other.ID, nil, token), http.StatusForbidden)
+}
+
+func TestSearchTasks(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	other, otherToken := app.newUser()
+	app.createTask(token, map[string]interface{}{"title": "Groceries", "description": "buy milk and eggs"})
+	app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+	app.createTask(otherToken, map[string]interface{}{"title": "Milk the cow"})
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"milk", 1},
+		{"milk eggs", 1},
+		{"milk rent", 0},
+		{"rent", 1},
+		{`"quoted`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := app.request(http.MethodGet, "/tasks/search/user/"+user.ID+"?q="+urlEncode(tt.query), nil, token)
+			expectStatus(t, rec, http.StatusOK)
+			var tasks []models.Tasks
+			decode(t, rec, &tasks)
+			if len(tasks) != tt.want {
+				t.Fatalf("expected %d tasks, got %d", tt.want, len(tasks))
+			}
+		})
+	}
+
+	expectStatus(t, app.request(http.MethodGet, "/tasks/search/user/"+user.ID+"?q=", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, "/tasks/search/user/"+other.ID+"?q=milk", nil, token), http.StatusForbidden)
+}
+
+func TestUpdateTask(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	other, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+	path := fmt.Sprintf("/tasks/update/id/%d", task.ID)
+
+	rec := app.request(http.MethodPut, path, map[string]interface{}{"title": "Pay rent and bills", "status": "done", "user_id": other.ID, "id": 9999}, token)
+	expectStatus(t, rec, http.StatusOK)
+	var updated models.Tasks
+	decode(t, rec, &updated)
+	if updated.ID != task.ID || updated.UserID != user.ID || updated.Title != "Pay rent and bills" || updated.Status != "done" {
+		t.Fatalf("unexpected updated task: %+v", updated)
+	}
+
+	rec = app.request(http.MethodPut, path, map[string]interface{}{
+		"title": "Pay rent", "status": "done", "created_at": "2000-01-01T00:00:00Z", "deleted_at": "2000-01-01T00:00:00Z",
+	}, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &updated)
+	if !updated.CreatedAt.Equal(task.CreatedAt) || updated.DeletedAt.Valid {
+		t.Fatalf("expected the dates of the task to be kept, got %+v", updated)
+	}
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", task.ID), nil, token), http.StatusOK)
+
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"title": ""}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPut, path, map[string]interface{}{"title": "Mine now"}, otherToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodPut, "/tasks/update/id/9999", map[string]interface{}{"title": "Ghost"}, token), http.StatusNotFound)
+}
+
+func TestDeleteTask(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Pay rent"})
+	path := fmt.Sprintf("/tasks/delete/id/%d", task.ID)
+
+	expectStatus(t, app.request(http.MethodDelete, path, nil, otherToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodDelete, path, nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, path, nil, token), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", task.ID), nil, token), http.StatusNotFound)
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+func TestAttachmentThumbnail(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Review the mockup"})
+
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := app.upload(token, task.ID, "mockup.png", buf.Bytes())
+	expectStatus(t, rec, http.StatusCreated)
+	var attachment models.Attachment
+	decode(t, rec, &attachment)
+	if attachment.ThumbnailStatus != models.ThumbnailPending {
+		t.Fatalf("expected the thumbnail to be pending, got %q", attachment.ThumbnailStatus)
+	}
+
+	app.thumbnails.Wait()
+
+	path := fmt.Sprintf("/attachments/thumbnail/id/%d", attachment.ID)
+	rec = app.request(http.MethodGet, path, nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	if contentType := rec.Header().Get("Content-Type"); contentType != "image/png" {
+		t.Fatalf("expected a PNG thumbnail, got %q", contentType)
+	}
+	thumb, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("decoding the thumbnail: %v", err)
+	}
+	if size := thumb.Bounds().Size(); size != image.Pt(testThumbnailSize, testThumbnailSize/2) {
+		t.Fatalf("expected the thumbnail to fit %d pixels, got %v", testThumbnailSize, size)
+	}
+
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/attachments", task.ID), nil, token)
+	var attachments []models.Attachment
+	decode(t, rec, &attachments)
+	if attachments[0].ThumbnailStatus != models.ThumbnailReady {
+		t.Fatalf("expected the thumbnail to be ready, got %q", attachments[0].ThumbnailStatus)
+	}
+
+	expectStatus(t, app.request(http.MethodGet, path+"?size=999", nil, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("%s?size=%d", path, testThumbnailSize), nil, token), http.StatusOK)
+	expectStatus(t, app.request(http.MethodGet, path, nil, otherToken), http.StatusNotFound)
+}
+
+func TestAttachmentWithoutThumbnail(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	task := app.createTask(token, map[string]interface{}{"title": "Read the notes"})
+
+	rec := app.upload(token, task.ID, "notes.txt", []byte("just text"))
+	expectStatus(t, rec, http.StatusCreated)
+	var attachment models.Attachment
+	decode(t, rec, &attachment)
+	if attachment.ThumbnailStatus != models.ThumbnailNone {
+		t.Fatalf("expected text files to get no thumbnail, got %q", attachment.ThumbnailStatus)
+	}
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/attachments/thumbnail/id/%d", attachment.ID), nil, token), http.StatusNotFound)
+
+	// Files claiming to be images but that can't be decoded end up failed
+	rec = app.upload(token, task.ID, "fake.png", append([]byte("\x89PNG\r\n\x1a\n"), []byte("garbage")...))
+	expectStatus(t, rec, http.StatusCreated)
+	decode(t, rec, &attachment)
+	app.thumbnails.Wait()
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/attachments/thumbnail/id/%d", attachment.ID), nil, token), http.StatusNotFound)
+}
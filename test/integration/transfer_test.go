@@ -0,0 +1,340 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devgugga/NullTask/internal/client"
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/transfer"
+	"github.com/labstack/echo/v4"
+)
+
+// importFile uploads a file of tasks to be imported with the given form fields, besides the file itself.
+func (a *testApp) importFile(token string, fields map[string]string, content string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	part, err := form.CreateFormFile("file", "tasks")
+	if err != nil {
+		a.t.Fatalf("creating the multipart body: %v", err)
+	}
+	part.Write([]byte(content))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/imports/create", &body)
+	req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+	rec := httptest.NewRecorder()
+	a.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// runImport imports a file of tasks, waits for the importer to finish and returns the job.
+func (a *testApp) runImport(token string, fields map[string]string, content string) models.ImportJob {
+	a.t.Helper()
+
+	rec := a.importFile(token, fields, content)
+	expectStatus(a.t, rec, http.StatusAccepted)
+	var job models.ImportJob
+	decode(a.t, rec, &job)
+	if job.Status != models.ImportPending {
+		a.t.Fatalf("expected a pending job, got %+v", job)
+	}
+	a.importer.Wait()
+
+	rec = a.request(http.MethodGet, fmt.Sprintf("/imports/get/id/%d", job.ID), nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+	decode(a.t, rec, &job)
+	return job
+}
+
+// tasksByTitle returns the personal tasks of the user, by title.
+func (a *testApp) tasksByTitle(token, userID string) map[string]models.Tasks {
+	a.t.Helper()
+
+	rec := a.request(http.MethodGet, "/tasks/get/user/"+userID, nil, token)
+	expectStatus(a.t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(a.t, rec, &tasks)
+	byTitle := map[string]models.Tasks{}
+	for _, task := range tasks {
+		byTitle[task.Title] = task
+	}
+	return byTitle
+}
+
+const todoistExport = `{
+	"projects": [{"id": "2", "name": "Work"}],
+	"items": [
+		{"id": "100", "content": "Ship release", "priority": 4, "project_id": "2", "due": {"date": "2024-07-05"}},
+		{"id": "101", "content": "Write changelog", "parent_id": "100", "checked": true},
+		{"id": "102", "content": "   "}
+	]
+}`
+
+func TestImportTodoist(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+
+	job := app.runImport(token, map[string]string{"format": "todoist"}, todoistExport)
+	if job.Status != models.ImportCompleted || job.Total != 3 || job.Created != 2 || job.Failed != 1 || job.FinishedAt == nil {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	if row := job.Rows[2]; row.Action != models.ImportError || row.Row != 3 || row.Ref != "102" || row.Error == "" {
+		t.Fatalf("expected the third row to fail, got %+v", row)
+	}
+
+	tasks := app.tasksByTitle(token, user.ID)
+	ship, changelog := tasks["Ship release"], tasks["Write changelog"]
+	if ship.ID == 0 || ship.Priority != models.PriorityHigh || ship.DueDate == nil || ship.Status != models.TaskPending {
+		t.Fatalf("unexpected imported task %+v", ship)
+	}
+	if changelog.ParentID == nil || *changelog.ParentID != ship.ID || changelog.Status != models.TaskDone || changelog.CompletedAt == nil {
+		t.Fatalf("expected a done subtask of the release, got %+v", changelog)
+	}
+
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/labels", ship.ID), nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var labels []models.Label
+	decode(t, rec, &labels)
+	if len(labels) != 1 || labels[0].Name != "Work" {
+		t.Fatalf("expected the task to be tagged with its project, got %+v", labels)
+	}
+
+	// Importing the same file again updates the tasks instead of creating others
+	again := app.runImport(token, map[string]string{"format": "todoist"}, strings.Replace(todoistExport, `"priority": 4`, `"priority": 2`, 1))
+	if again.Created != 0 || again.Updated != 2 || again.Failed != 1 {
+		t.Fatalf("expected the rows to update their tasks, got %+v", again)
+	}
+	if row := again.Rows[0]; row.TaskID == nil || *row.TaskID != ship.ID {
+		t.Fatalf("expected the first row to update the release, got %+v", row)
+	}
+	tasks = app.tasksByTitle(token, user.ID)
+	if len(tasks) != 2 || tasks["Ship release"].Priority != models.PriorityLow {
+		t.Fatalf("expected the two tasks to be updated, got %+v", tasks)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+
+	csv := "Nome,Prazo,Etiquetas\nPay rent,2024-07-05,\"home, bills\"\nCall mom,amanhã,\n"
+	fields := map[string]string{
+		"format":  "csv",
+		"mapping": `{"title": "Nome", "due_date": "Prazo", "labels": "Etiquetas"}`,
+		"dry_run": "true",
+	}
+	job := app.runImport(token, fields, csv)
+	if job.Status != models.ImportCompleted || !job.DryRun || job.Created != 1 || job.Failed != 1 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	if row := job.Rows[1]; row.Row != 3 || row.Action != models.ImportError || row.Error == "" {
+		t.Fatalf("expected the second row to fail, got %+v", row)
+	}
+	if tasks := app.tasksByTitle(token, user.ID); len(tasks) != 0 {
+		t.Fatalf("expected the dry run to create nothing, got %+v", tasks)
+	}
+
+	delete(fields, "dry_run")
+	job = app.runImport(token, fields, csv)
+	if job.Created != 1 || job.Rows[0].TaskID == nil {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	fields["dry_run"] = "true"
+	job = app.runImport(token, fields, csv)
+	if job.Created != 0 || job.Updated != 1 || job.Rows[0].TaskID == nil {
+		t.Fatalf("expected the dry run to report an update, got %+v", job)
+	}
+}
+
+func TestImportValidation(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	_, otherToken := app.newUser()
+
+	expectStatus(t, app.importFile(token, map[string]string{"format": "xlsx"}, "a"), http.StatusBadRequest)
+	expectStatus(t, app.importFile(token, map[string]string{"format": "csv", "mapping": "{"}, "a"), http.StatusBadRequest)
+	expectStatus(t, app.importFile(token, map[string]string{"format": "csv", "mapping": `{"color": "Cor"}`}, "a"), http.StatusBadRequest)
+	expectStatus(t, app.importFile(token, map[string]string{"format": "csv", "dry_run": "maybe"}, "a"), http.StatusBadRequest)
+
+	// Files that can't be read fail as a whole
+	job := app.runImport(token, map[string]string{"format": "trello"}, "not json")
+	if job.Status != models.ImportFailed || job.Error == "" || job.Total != 0 {
+		t.Fatalf("expected the job to fail, got %+v", job)
+	}
+
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/imports/get/id/%d", job.ID), nil, otherToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, "/imports/get/id/abc", nil, token), http.StatusBadRequest)
+
+	rec := app.request(http.MethodGet, "/imports/get/all", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var jobs []models.ImportJob
+	decode(t, rec, &jobs)
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Fatalf("expected the user's job, got %+v", jobs)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	parent := app.createTask(token, map[string]interface{}{"title": "Taxes", "description": "Quarterly", "priority": models.PriorityHigh})
+	app.createTask(token, map[string]interface{}{"title": "Gather receipts", "parent_id": parent.ID})
+	expectStatus(t, app.request(http.MethodPost, fmt.Sprintf("/tasks/checklist/id/%d", parent.ID), map[string]interface{}{"title": "Form"}, token), http.StatusCreated)
+
+	rec := app.request(http.MethodGet, "/exports/get/tasks", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	if !strings.Contains(rec.Header().Get(echo.HeaderContentDisposition), ".json") {
+		t.Fatalf("expected a JSON download, got %q", rec.Header().Get(echo.HeaderContentDisposition))
+	}
+	var document transfer.Document
+	decode(t, rec, &document)
+	if document.Version != transfer.DocumentVersion || len(document.Tasks) != 2 {
+		t.Fatalf("unexpected document %+v", document)
+	}
+
+	// Importing the document where it came from updates its tasks
+	job := app.runImport(token, map[string]string{"format": "nulltask"}, rec.Body.String())
+	if job.Created != 0 || job.Updated != 2 || job.Failed != 0 {
+		t.Fatalf("expected the tasks to be updated, got %+v", job)
+	}
+
+	// Somewhere else, it creates them again, with their subtasks and checklists
+	other, otherToken := app.newUser()
+	job = app.runImport(otherToken, map[string]string{"format": "nulltask"}, rec.Body.String())
+	if job.Created != 2 || job.Updated != 0 {
+		t.Fatalf("expected the tasks to be created, got %+v", job)
+	}
+	tasks := app.tasksByTitle(otherToken, other.ID)
+	taxes, receipts := tasks["Taxes"], tasks["Gather receipts"]
+	if taxes.ID == 0 || taxes.ID == parent.ID || taxes.Description == nil || *taxes.Description != "Quarterly" || taxes.Priority != models.PriorityHigh {
+		t.Fatalf("unexpected imported task %+v", taxes)
+	}
+	if receipts.ParentID == nil || *receipts.ParentID != taxes.ID {
+		t.Fatalf("expected the subtask to be kept, got %+v", receipts)
+	}
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/checklist", taxes.ID), nil, otherToken)
+	expectStatus(t, rec, http.StatusOK)
+	var checklist []models.ChecklistItem
+	decode(t, rec, &checklist)
+	if len(checklist) != 1 || checklist[0].Title != "Form" {
+		t.Fatalf("expected the checklist to be imported, got %+v", checklist)
+	}
+	if tasks := app.tasksByTitle(token, user.ID); len(tasks) != 2 {
+		t.Fatalf("expected the tasks of the first user to be left alone, got %+v", tasks)
+	}
+
+	rec = app.request(http.MethodGet, "/exports/get/tasks?format=csv", nil, otherToken)
+	expectStatus(t, rec, http.StatusOK)
+	if !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), "text/csv") || !strings.Contains(rec.Body.String(), "Taxes") {
+		t.Fatalf("unexpected CSV export %q", rec.Body.String())
+	}
+	expectStatus(t, app.request(http.MethodGet, "/exports/get/tasks?format=xml", nil, token), http.StatusBadRequest)
+}
+
+func TestImportClient(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	server := httptest.NewServer(app.e)
+	t.Cleanup(server.Close)
+	api := client.New(server.URL, token)
+	ctx := context.Background()
+
+	csv := "title,priority\nPay rent,high\n"
+	job, err := api.Import(ctx, client.ImportOptions{Format: "csv", Mapping: map[string]string{"title": "title", "priority": "priority"}}, "tasks.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("importing: %v", err)
+	}
+	if job, err = api.WaitImport(ctx, job.ID, 10*time.Millisecond); err != nil {
+		t.Fatalf("waiting for the import: %v", err)
+	}
+	if job.Status != models.ImportCompleted || job.Created != 1 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+
+	var exported bytes.Buffer
+	if err := api.Export(ctx, "csv", &exported); err != nil {
+		t.Fatalf("exporting: %v", err)
+	}
+	if !strings.Contains(exported.String(), "Pay rent") {
+		t.Fatalf("expected the imported task to be exported, got %q", exported.String())
+	}
+
+	if _, err := api.Import(ctx, client.ImportOptions{Format: "xlsx"}, "tasks.xlsx", strings.NewReader("")); !client.IsStatus(err, http.StatusBadRequest) {
+		t.Fatalf("expected a bad request, got %v", err)
+	}
+}
+
+func TestPlainTextRoundTrip(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	trip := app.createTask(token, map[string]interface{}{"title": "Plan trip", "description": "Summer", "priority": models.PriorityHigh})
+	hotel := app.createTask(token, map[string]interface{}{"title": "Book hotel", "parent_id": trip.ID})
+	app.updateStatus(token, trip.ID, models.TaskInProgress, http.StatusOK)
+
+	rec := app.request(http.MethodGet, "/exports/get/tasks?format=todotxt", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	if !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), "text/plain") {
+		t.Fatalf("expected a plain text file, got %q", rec.Header().Get(echo.HeaderContentType))
+	}
+	todo := rec.Body.String()
+	if !strings.Contains(todo, "(A) ") || !strings.Contains(todo, fmt.Sprintf("nt:%d", hotel.ID)) {
+		t.Fatalf("unexpected todo.txt file %q", todo)
+	}
+
+	// Completing the hotel in the file and adding a task updates the tasks and creates the new one
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(todo), "\n") {
+		if strings.Contains(line, "Book hotel") {
+			line = "x " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "Rent a car +travel")
+	job := app.runImport(token, map[string]string{"format": "todotxt"}, strings.Join(lines, "\n"))
+	if job.Created != 1 || job.Updated != 2 || job.Failed != 0 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	tasks := app.tasksByTitle(token, user.ID)
+	if tasks["Book hotel"].Status != models.TaskDone || tasks["Book hotel"].CompletedAt == nil {
+		t.Fatalf("expected the hotel to be done, got %+v", tasks["Book hotel"])
+	}
+	if planned := tasks["Plan trip"]; planned.Status != models.TaskInProgress || planned.Description == nil || *planned.Description != "Summer" {
+		t.Fatalf("expected the status and description of the trip to be kept, got %+v", planned)
+	}
+
+	rec = app.request(http.MethodGet, "/exports/get/tasks?format=markdown", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	markdown := rec.Body.String()
+	if !strings.Contains(markdown, "  - [x] Book hotel") || !strings.Contains(markdown, "- [ ] Rent a car +travel") {
+		t.Fatalf("unexpected Markdown file %q", markdown)
+	}
+
+	// Unchecking the hotel reopens it, and nothing is duplicated
+	markdown = strings.Replace(markdown, "[x] Book hotel", "[ ] Book hotel", 1)
+	job = app.runImport(token, map[string]string{"format": "markdown"}, markdown)
+	if job.Created != 0 || job.Updated != 3 || job.Failed != 0 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	tasks = app.tasksByTitle(token, user.ID)
+	if len(tasks) != 3 || tasks["Book hotel"].Status != models.TaskPending || tasks["Book hotel"].CompletedAt != nil {
+		t.Fatalf("expected the hotel to be reopened, got %+v", tasks)
+	}
+	if parent := tasks["Book hotel"].ParentID; parent == nil || *parent != trip.ID {
+		t.Fatalf("expected the hotel to stay a subtask of the trip, got %+v", tasks["Book hotel"])
+	}
+}
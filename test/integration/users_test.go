@@ -0,0 +1,298 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+)
+
+func TestCreateUser(t *testing.T) {
+	app := newTestApp(t)
+
+	user := app.createUser("Ada", "ada@nulltask.test", testPassword)
+	if user.ID == "" {
+		t.Fatal("expected the created user to have an ID")
+	}
+	if user.Password != "" {
+		t.Fatal("expected the password not to be returned")
+	}
+	stored, err := repository.NewGormUserRepository(app.db).FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Password == "" || stored.Password == testPassword {
+		t.Fatal("expected the password to be stored hashed")
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+	}{
+		{"duplicate email", map[string]interface{}{"name": "Ada", "email": "ada@nulltask.test", "password": testPassword, "age": 30}, http.StatusConflict},
+		{"missing name", map[string]interface{}{"email": "new@nulltask.test", "password": testPassword, "age": 30}, http.StatusBadRequest},
+		{"invalid email", map[string]interface{}{"name": "Ada", "email": "not-an-email", "password": testPassword, "age": 30}, http.StatusBadRequest},
+		{"short password", map[string]interface{}{"name": "Ada", "email": "new@nulltask.test", "password": "123", "age": 30}, http.StatusBadRequest},
+		{"underage", map[string]interface{}{"name": "Ada", "email": "new@nulltask.test", "password": testPassword, "age": 17}, http.StatusBadRequest},
+		{"malformed json", `{"name": `, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := app.request(http.MethodPost, "/users/create", tt.body, "")
+			expectStatus(t, rec, tt.status)
+		})
+	}
+}
+
+func TestMemberNumbers(t *testing.T) {
+	app := newTestApp(t)
+
+	first, _ := app.newUser()
+	second, token := app.newUser()
+	if first.MemberNumber != 1 || second.MemberNumber != 2 {
+		t.Fatalf("expected the member numbers 1 and 2, got %d and %d", first.MemberNumber, second.MemberNumber)
+	}
+
+	// The number of a deleted user isn't handed out again
+	expectStatus(t, app.request(http.MethodDelete, "/users/delete/id/"+second.ID, nil, token), http.StatusOK)
+	if third, _ := app.newUser(); third.MemberNumber != 3 {
+		t.Fatalf("expected the member number 3, got %d", third.MemberNumber)
+	}
+
+	// Databases migrated before the sequences existed start after the largest number
+	if err := app.db.Where("1 = 1").Delete(&models.Sequence{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if fourth, _ := app.newUser(); fourth.MemberNumber != 4 {
+		t.Fatalf("expected the member number 4, got %d", fourth.MemberNumber)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	app := newTestApp(t)
+	app.createUser("Ada", "ada@nulltask.test", testPassword)
+
+	if token := app.login("ada@nulltask.test", testPassword); token == "" {
+		t.Fatal("expected a token")
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+	}{
+		{"wrong password", map[string]string{"email": "ada@nulltask.test", "password": "wrong-password"}, http.StatusUnauthorized},
+		{"unknown email", map[string]string{"email": "nobody@nulltask.test", "password": testPassword}, http.StatusUnauthorized},
+		{"missing password", map[string]string{"email": "ada@nulltask.test"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := app.request(http.MethodPost, "/users/login", tt.body, "")
+			expectStatus(t, rec, tt.status)
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	app := newTestApp(t)
+	user, _ := app.newUser()
+	_, token := app.newUser()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"by email", "/users/get/email/" + user.Email, http.StatusOK},
+		{"by id", "/users/get/id/" + user.ID, http.StatusOK},
+		{"unknown email", "/users/get/email/nobody@nulltask.test", http.StatusNotFound},
+		{"unknown id", "/users/get/id/00000000-0000-0000-0000-000000000000", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, app.request(http.MethodGet, tt.path, nil, ""), http.StatusUnauthorized)
+
+			rec := app.request(http.MethodGet, tt.path, nil, token)
+			expectStatus(t, rec, tt.status)
+
+			if tt.status == http.StatusOK {
+				if strings.Contains(rec.Body.String(), "password") {
+					t.Fatalf("expected the password not to be returned, got %s", rec.Body.String())
+				}
+				var got models.User
+				decode(t, rec, &got)
+				if got.ID != user.ID {
+					t.Fatalf("expected user %s, got %s", user.ID, got.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAllUsers(t *testing.T) {
+	app := newTestApp(t)
+	expectStatus(t, app.request(http.MethodGet, "/users/get/all/", nil, ""), http.StatusUnauthorized)
+
+	admin, token := app.newUser()
+	expectStatus(t, app.request(http.MethodGet, "/users/get/all/", nil, token), http.StatusForbidden)
+	if err := app.db.Model(&models.User{}).Where("id = ?", admin.ID).Update("admin", true).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	rec := app.request(http.MethodGet, "/users/get/all/", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var users []models.User
+	decode(t, rec, &users)
+	if len(users) != 1 {
+		t.Fatalf("expected 1 user, got %d", len(users))
+	}
+
+	_, otherToken := app.newUser()
+	expectStatus(t, app.request(http.MethodGet, "/users/get/all/", nil, otherToken), http.StatusForbidden)
+
+	rec = app.request(http.MethodGet, "/users/get/all/", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &users)
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	for _, by := range []string{"id", "email"} {
+		t.Run("by "+by, func(t *testing.T) {
+			app := newTestApp(t)
+			user, token := app.newUser()
+			other, otherToken := app.newUser()
+
+			path := func(u models.User) string {
+				if by == "id" {
+					return "/users/update/id/" + u.ID
+				}
+				return "/users/update/email/" + u.Email
+			}
+
+			body := map[string]interface{}{"name": "Renamed", "email": user.Email, "password": "new-secret", "age": 40, "id": other.ID}
+			rec := app.request(http.MethodPut, path(user), body, token)
+			expectStatus(t, rec, http.StatusOK)
+
+			var updated models.User
+			decode(t, rec, &updated)
+			if updated.ID != user.ID || updated.Name != "Renamed" || updated.Age != 40 {
+				t.Fatalf("unexpected updated user: %+v", updated)
+			}
+
+			// The new password must have been hashed, so logging in with it works
+			app.login(user.Email, "new-secret")
+
+			// Leaving the password out keeps it
+			expectStatus(t, app.request(http.MethodPut, path(user), map[string]interface{}{"name": "Renamed again"}, token), http.StatusOK)
+			app.login(user.Email, "new-secret")
+
+			expectStatus(t, app.request(http.MethodPut, path(user), body, ""), http.StatusUnauthorized)
+			expectStatus(t, app.request(http.MethodPut, path(user), body, "not-a-token"), http.StatusUnauthorized)
+			expectStatus(t, app.request(http.MethodPut, path(other), body, token), http.StatusForbidden)
+			expectStatus(t, app.request(http.MethodPut, path(other), map[string]interface{}{"name": ""}, otherToken), http.StatusBadRequest)
+			expectStatus(t, app.request(http.MethodPut, path(other), map[string]interface{}{"email": user.Email}, otherToken), http.StatusConflict)
+			expectStatus(t, app.request(http.MethodPut, path(models.User{ID: "00000000-0000-0000-0000-000000000000", Email: "nobody@nulltask.test"}), body, token), http.StatusNotFound)
+		})
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	for _, by := range []string{"id", "email"} {
+		t.Run("by "+by, func(t *testing.T) {
+			app := newTestApp(t)
+			user, token := app.newUser()
+			other, otherToken := app.newUser()
+
+			path := func(u models.User) string {
+				if by == "id" {
+					return "/users/delete/id/" + u.ID
+				}
+				return "/users/delete/email/" + u.Email
+			}
+
+			expectStatus(t, app.request(http.MethodDelete, path(user), nil, ""), http.StatusUnauthorized)
+			expectStatus(t, app.request(http.MethodDelete, path(other), nil, token), http.StatusForbidden)
+			expectStatus(t, app.request(http.MethodDelete, path(user), nil, token), http.StatusOK)
+			expectStatus(t, app.request(http.MethodDelete, path(user), nil, token), http.StatusUnauthorized)
+			expectStatus(t, app.request(http.MethodGet, "/users/get/id/"+user.ID, nil, otherToken), http.StatusNotFound)
+		})
+	}
+}
+
+func TestDeleteUserWithData(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+	teammate, teammateToken := app.newUser()
+	ctx := context.Background()
+	attachments := repository.NewGormAttachmentRepository(app.db)
+
+	// Personal data, and a workspace of the user
+	personal := app.createTask(token, map[string]interface{}{"title": "Renew passport"})
+	app.createTask(token, map[string]interface{}{"title": "Book the appointment", "parent_id": personal.ID})
+	label := app.createLabel(token, map[string]interface{}{"name": "errands"})
+	app.tag(token, label.ID, http.StatusOK, personal.ID)
+	app.createComment(token, personal.ID, map[string]interface{}{"body": "Bring the old one"})
+	expectStatus(t, app.upload(token, personal.ID, "photo.png", pngHeader), http.StatusCreated)
+	owned := app.createWorkspace(token, "Side project")
+	app.joinWorkspace(token, owned, teammate, teammateToken, models.RoleEditor)
+	ownedTask := app.createTask(teammateToken, map[string]interface{}{"title": "Landing page", "workspace_id": owned.ID})
+
+	// Work in the workspace of a teammate
+	team := app.createWorkspace(teammateToken, "Team")
+	app.joinWorkspace(teammateToken, team, user, token, models.RoleEditor)
+	shared := app.createTask(token, map[string]interface{}{"title": "Release notes", "workspace_id": team.ID})
+	sharedLabel := app.createLabel(token, map[string]interface{}{"name": "docs", "workspace_id": team.ID})
+	comment := app.createComment(token, shared.ID, map[string]interface{}{"body": "Draft in the wiki"})
+	app.createComment(teammateToken, shared.ID, map[string]interface{}{"body": "Thanks", "parent_id": comment.ID})
+	expectStatus(t, app.upload(token, shared.ID, "draft.txt", []byte("draft")), http.StatusCreated)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", personal.ID), nil, token), http.StatusOK)
+
+	removed, err := attachments.FindByOwner(ctx, user.ID)
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("expected both attachments of the user to go with them, got %+v and %v", removed, err)
+	}
+
+	expectStatus(t, app.request(http.MethodDelete, "/users/delete/id/"+user.ID, nil, token), http.StatusOK)
+
+	for _, attachment := range removed {
+		if _, err := app.blobs.Open(ctx, attachment.StorageKey); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected the contents of %s to be removed, got %v", attachment.FileName, err)
+		}
+	}
+	var count int64
+	app.db.Unscoped().Model(&models.Tasks{}).Where("user_id = ? OR workspace_id = ?", user.ID, owned.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected the personal tasks and the workspace of the user to be removed, %d tasks left", count)
+	}
+	app.db.Model(&models.Workspace{}).Where("id = ?", owned.ID).Count(&count)
+	if count != 0 {
+		t.Fatal("expected the workspace of the user to be removed")
+	}
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", ownedTask.ID), nil, teammateToken), http.StatusNotFound)
+
+	// The team keeps the work of the user
+	if got := app.getTask(teammateToken, shared.ID); got.UserID != teammate.ID {
+		t.Fatalf("expected the task to be handed over to the owner of the workspace, got %+v", got)
+	}
+	var kept models.Label
+	if err := app.db.First(&kept, sharedLabel.ID).Error; err != nil || kept.UserID != teammate.ID {
+		t.Fatalf("expected the label to be handed over to the owner of the workspace, got %+v and %v", kept, err)
+	}
+	rec := app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/comments", shared.ID), nil, teammateToken)
+	expectStatus(t, rec, http.StatusOK)
+	var thread []models.Comment
+	decode(t, rec, &thread)
+	if len(thread) != 1 || thread[0].Body != "Thanks" {
+		t.Fatalf("expected only the reply of the teammate to be left, got %+v", thread)
+	}
+}
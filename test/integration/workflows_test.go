@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/models"
+)
+
+// reviewWorkflow is a workflow where tasks go through a review before being shipped.
+var reviewWorkflow = map[string]interface{}{
+	"initial": "todo",
+	"statuses": []map[string]interface{}{
+		{"name": "todo"},
+		{"name": "review"},
+		{"name": "shipped", "terminal": true},
+	},
+	"transitions": []map[string]interface{}{
+		{"from": "todo", "to": "review"},
+		{"from": "review", "to": "todo"},
+		{"from": "review", "to": "shipped"},
+	},
+}
+
+// updateStatus moves the task to the status, expecting the given status code, and returns the task.
+func (a *testApp) updateStatus(token string, taskID uint, status string, code int) models.Tasks {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPut, fmt.Sprintf("/tasks/update/id/%d", taskID), map[string]interface{}{"status": status}, token)
+	expectStatus(a.t, rec, code)
+
+	var task models.Tasks
+	if code == http.StatusOK {
+		decode(a.t, rec, &task)
+	}
+	return task
+}
+
+func TestDefaultWorkflow(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	workspace := app.createWorkspace(token, "Home")
+
+	rec := app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID+"/workflow", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var workflow models.Workflow
+	decode(t, rec, &workflow)
+	if workflow.Initial != models.TaskPending || len(workflow.Statuses) != 4 {
+		t.Fatalf("expected the default workflow, got %+v", workflow)
+	}
+
+	task := app.createTask(token, map[string]interface{}{"title": "Water the plants"})
+	if task.Status != models.TaskPending || task.CompletedAt != nil {
+		t.Fatalf("expected a new pending task, got %+v", task)
+	}
+	app.updateStatus(token, task.ID, "archived", http.StatusBadRequest)
+
+	task = app.updateStatus(token, task.ID, models.TaskCancelled, http.StatusOK)
+	if task.CompletedAt == nil {
+		t.Fatalf("expected a cancelled task to be completed, got %+v", task)
+	}
+	app.updateStatus(token, task.ID, models.TaskDone, http.StatusConflict)
+	task = app.updateStatus(token, task.ID, models.TaskPending, http.StatusOK)
+	if task.CompletedAt != nil {
+		t.Fatalf("expected a reopened task not to be completed, got %+v", task)
+	}
+}
+
+func TestWorkspaceWorkflow(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	editor, editorToken := app.newUser()
+	workspace := app.createWorkspace(token, "Release")
+	app.joinWorkspace(token, workspace, editor, editorToken, models.RoleEditor)
+	path := "/workspaces/update/id/" + workspace.ID + "/workflow"
+
+	expectStatus(t, app.request(http.MethodPut, path, reviewWorkflow, editorToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPut, path, reviewWorkflow, token), http.StatusOK)
+
+	task := app.createTask(editorToken, map[string]interface{}{"title": "Ship the feature", "workspace_id": workspace.ID})
+	if task.Status != "todo" {
+		t.Fatalf("expected the task to start in the initial status, got %+v", task)
+	}
+	rec := app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Invalid", "workspace_id": workspace.ID, "status": models.TaskPending}, editorToken)
+	expectStatus(t, rec, http.StatusBadRequest)
+
+	rec = app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d/transitions", task.ID), nil, editorToken)
+	expectStatus(t, rec, http.StatusOK)
+	var next []models.WorkflowStatus
+	decode(t, rec, &next)
+	if len(next) != 1 || next[0].Name != "review" {
+		t.Fatalf("expected the task to only move to review, got %+v", next)
+	}
+
+	app.updateStatus(editorToken, task.ID, "shipped", http.StatusConflict)
+	app.updateStatus(editorToken, task.ID, "review", http.StatusOK)
+	if task = app.updateStatus(editorToken, task.ID, "shipped", http.StatusOK); task.CompletedAt == nil {
+		t.Fatalf("expected a shipped task to be completed, got %+v", task)
+	}
+
+	// Resetting the workflow lets the task leave a status the default workflow doesn't have
+	rec = app.request(http.MethodDelete, "/workspaces/delete/id/"+workspace.ID+"/workflow", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var workflow models.Workflow
+	decode(t, rec, &workflow)
+	if workflow.Initial != models.TaskPending {
+		t.Fatalf("expected the default workflow, got %+v", workflow)
+	}
+	if task = app.updateStatus(editorToken, task.ID, models.TaskInProgress, http.StatusOK); task.CompletedAt != nil {
+		t.Fatalf("expected the task to be reopened, got %+v", task)
+	}
+}
+
+func TestInvalidWorkflow(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	workspace := app.createWorkspace(token, "Release")
+	path := "/workspaces/update/id/" + workspace.ID + "/workflow"
+
+	tests := []struct {
+		name     string
+		workflow map[string]interface{}
+	}{
+		{"no statuses", map[string]interface{}{"initial": "todo", "statuses": []map[string]interface{}{}}},
+		{"repeated status", map[string]interface{}{"initial": "todo", "statuses": []map[string]interface{}{{"name": "todo"}, {"name": "todo"}, {"name": "done", "terminal": true}}}},
+		{"no terminal status", map[string]interface{}{"initial": "todo", "statuses": []map[string]interface{}{{"name": "todo"}}}},
+		{"unknown initial status", map[string]interface{}{"initial": "backlog", "statuses": []map[string]interface{}{{"name": "todo"}, {"name": "done", "terminal": true}}}},
+		{"terminal initial status", map[string]interface{}{"initial": "done", "statuses": []map[string]interface{}{{"name": "todo"}, {"name": "done", "terminal": true}}}},
+		{"unknown transition", map[string]interface{}{
+			"initial":     "todo",
+			"statuses":    []map[string]interface{}{{"name": "todo"}, {"name": "done", "terminal": true}},
+			"transitions": []map[string]interface{}{{"from": "todo", "to": "review"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, app.request(http.MethodPut, path, tt.workflow, token), http.StatusBadRequest)
+		})
+	}
+}
+
+func TestCancelledDependencyUnblocks(t *testing.T) {
+	app := newTestApp(t)
+	_, token := app.newUser()
+	buy := app.createTask(token, map[string]interface{}{"title": "Buy paint"})
+	paint := app.createTask(token, map[string]interface{}{"title": "Paint the wall"})
+	app.addDependency(token, paint.ID, buy.ID, http.StatusCreated)
+
+	app.updateStatus(token, buy.ID, models.TaskCancelled, http.StatusOK)
+	if task := app.getTask(token, paint.ID); task.Blocked {
+		t.Fatalf("expected the painting to be unblocked once buying the paint is cancelled, got %+v", task)
+	}
+	app.updateStatus(token, paint.ID, models.TaskDone, http.StatusOK)
+}
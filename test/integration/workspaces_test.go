@@ -0,0 +1,261 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/devgugga/NullTask/internal/models"
+	"github.com/devgugga/NullTask/internal/repository"
+	"github.com/devgugga/NullTask/internal/storage"
+)
+
+// createWorkspace creates a workspace owned by the user the token belongs to and returns it.
+func (a *testApp) createWorkspace(token, name string) models.Workspace {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/workspaces/create", map[string]interface{}{"name": name}, token)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	var workspace models.Workspace
+	decode(a.t, rec, &workspace)
+	return workspace
+}
+
+// joinWorkspace invites the user to the workspace with the given role and accepts the invitation on their behalf.
+func (a *testApp) joinWorkspace(ownerToken string, workspace models.Workspace, user models.User, token string, role models.WorkspaceRole) {
+	a.t.Helper()
+
+	rec := a.request(http.MethodPost, "/workspaces/invite/id/"+workspace.ID, map[string]interface{}{"email": user.Email, "role": role}, ownerToken)
+	expectStatus(a.t, rec, http.StatusCreated)
+
+	expectStatus(a.t, a.request(http.MethodPost, "/workspaces/accept/"+a.invitationToken(user.Email), nil, token), http.StatusOK)
+}
+
+// invitationTokenPattern finds the token in the invitation e-mails.
+var invitationTokenPattern = regexp.MustCompile(`Token: (\S+)`)
+
+// invitationToken returns the token of the last invitation e-mailed to the address.
+func (a *testApp) invitationToken(email string) string {
+	a.t.Helper()
+
+	for i := len(a.mails) - 1; i >= 0; i-- {
+		if a.mails[i].To != email {
+			continue
+		}
+		match := invitationTokenPattern.FindStringSubmatch(a.mails[i].Body)
+		if match == nil {
+			a.t.Fatalf("expected a token in the invitation e-mail, got %q", a.mails[i].Body)
+		}
+		return match[1]
+	}
+	a.t.Fatalf("expected an invitation e-mailed to %s", email)
+	return ""
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	app := newTestApp(t)
+	user, token := app.newUser()
+
+	workspace := app.createWorkspace(token, "Family")
+	if workspace.ID == "" || workspace.OwnerID != user.ID {
+		t.Fatalf("unexpected created workspace: %+v", workspace)
+	}
+	if len(workspace.Members) != 1 || workspace.Members[0].Role != models.RoleOwner {
+		t.Fatalf("expected the owner to be the only member, got %+v", workspace.Members)
+	}
+
+	rec := app.request(http.MethodGet, "/workspaces/get/all/", nil, token)
+	expectStatus(t, rec, http.StatusOK)
+	var workspaces []models.Workspace
+	decode(t, rec, &workspaces)
+	if len(workspaces) != 1 || workspaces[0].ID != workspace.ID {
+		t.Fatalf("expected only workspace %s, got %+v", workspace.ID, workspaces)
+	}
+
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/create", map[string]interface{}{}, token), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/create", map[string]interface{}{"name": "Family"}, ""), http.StatusUnauthorized)
+}
+
+func TestWorkspaceInvitation(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	invited, invitedToken := app.newUser()
+	_, strangerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+
+	expectStatus(t, app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID, nil, invitedToken), http.StatusNotFound)
+
+	rec := app.request(http.MethodPost, "/workspaces/invite/id/"+workspace.ID, map[string]interface{}{"email": invited.Email, "role": "editor"}, ownerToken)
+	expectStatus(t, rec, http.StatusCreated)
+	var invitation models.WorkspaceInvitation
+	decode(t, rec, &invitation)
+	if invitation.Role != models.RoleEditor {
+		t.Fatalf("unexpected invitation: %+v", invitation)
+	}
+	if strings.Contains(rec.Body.String(), "token") {
+		t.Fatalf("expected the token to be e-mailed only, got %s", rec.Body.String())
+	}
+	token := app.invitationToken(invited.Email)
+	if mail := app.mails[len(app.mails)-1]; !strings.Contains(mail.Subject, "Family") {
+		t.Fatalf("expected the invitation e-mail to name the workspace, got %+v", mail)
+	}
+
+	rec = app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID+"/invitations", nil, ownerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var pending []models.WorkspaceInvitation
+	decode(t, rec, &pending)
+	if len(pending) != 1 || pending[0].ID != invitation.ID {
+		t.Fatalf("expected invitation %d to be pending, got %+v", invitation.ID, pending)
+	}
+
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/accept/"+token, nil, strangerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/accept/not-a-token", nil, invitedToken), http.StatusNotFound)
+
+	rec = app.request(http.MethodPost, "/workspaces/accept/"+token, nil, invitedToken)
+	expectStatus(t, rec, http.StatusOK)
+	var member models.WorkspaceMember
+	decode(t, rec, &member)
+	if member.UserID != invited.ID || member.Role != models.RoleEditor {
+		t.Fatalf("unexpected membership: %+v", member)
+	}
+
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/accept/"+token, nil, invitedToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID, nil, invitedToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/invite/id/"+workspace.ID, map[string]interface{}{"email": "x@nulltask.test", "role": "viewer"}, invitedToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/invite/id/"+workspace.ID, map[string]interface{}{"email": "x@nulltask.test", "role": "owner"}, ownerToken), http.StatusBadRequest)
+
+	rec = app.request(http.MethodPost, "/workspaces/invite/id/"+workspace.ID, map[string]interface{}{"email": invited.Email, "role": "viewer"}, ownerToken)
+	expectStatus(t, rec, http.StatusCreated)
+	decode(t, rec, &invitation)
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/accept/"+app.invitationToken(invited.Email), nil, invitedToken), http.StatusConflict)
+
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/workspaces/delete/id/%s/invitations/%d", workspace.ID, invitation.ID), nil, ownerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/workspaces/delete/id/%s/invitations/%d", workspace.ID, invitation.ID), nil, ownerToken), http.StatusNotFound)
+
+	// An invitation that couldn't be e-mailed doesn't stay pending
+	app.mailErr = errors.New("mail server unreachable")
+	expectStatus(t, app.request(http.MethodPost, "/workspaces/invite/id/"+workspace.ID, map[string]interface{}{"email": "x@nulltask.test", "role": "viewer"}, ownerToken), http.StatusBadGateway)
+	rec = app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID+"/invitations", nil, ownerToken)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &pending)
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending invitation, got %+v", pending)
+	}
+}
+
+func TestWorkspaceRoles(t *testing.T) {
+	app := newTestApp(t)
+	owner, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	_, strangerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+
+	task := app.createTask(editorToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+	if task.WorkspaceID == nil || *task.WorkspaceID != workspace.ID {
+		t.Fatalf("expected the task to belong to workspace %s, got %+v", workspace.ID, task.WorkspaceID)
+	}
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Nope", "workspace_id": workspace.ID}, viewerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPost, "/tasks/create", map[string]interface{}{"title": "Nope", "workspace_id": workspace.ID}, strangerToken), http.StatusNotFound)
+
+	taskPath := fmt.Sprintf("/tasks/get/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodGet, taskPath, nil, ownerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodGet, taskPath, nil, viewerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodGet, taskPath, nil, strangerToken), http.StatusNotFound)
+
+	updatePath := fmt.Sprintf("/tasks/update/id/%d", task.ID)
+	expectStatus(t, app.request(http.MethodPut, updatePath, map[string]interface{}{"title": "Changed"}, viewerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPut, updatePath, map[string]interface{}{"title": "Changed", "workspace_id": nil}, ownerToken), http.StatusOK)
+
+	rec := app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID+"/tasks", nil, viewerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var tasks []models.Tasks
+	decode(t, rec, &tasks)
+	if len(tasks) != 1 || tasks[0].Title != "Changed" || tasks[0].WorkspaceID == nil {
+		t.Fatalf("expected the updated workspace task, got %+v", tasks)
+	}
+
+	rec = app.request(http.MethodGet, "/tasks/get/user/"+editor.ID, nil, editorToken)
+	expectStatus(t, rec, http.StatusOK)
+	decode(t, rec, &tasks)
+	if len(tasks) != 0 {
+		t.Fatalf("expected workspace tasks to be left out of the personal list, got %+v", tasks)
+	}
+
+	memberPath := "/workspaces/update/id/" + workspace.ID + "/members/" + viewer.ID
+	expectStatus(t, app.request(http.MethodPut, memberPath, map[string]interface{}{"role": "editor"}, editorToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPut, memberPath, map[string]interface{}{"role": "owner"}, ownerToken), http.StatusBadRequest)
+	expectStatus(t, app.request(http.MethodPut, memberPath, map[string]interface{}{"role": "editor"}, ownerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodPut, "/workspaces/update/id/"+workspace.ID+"/members/"+owner.ID, map[string]interface{}{"role": "viewer"}, ownerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodPut, updatePath, map[string]interface{}{"title": "By the former viewer"}, viewerToken), http.StatusOK)
+
+	expectStatus(t, app.request(http.MethodPut, "/workspaces/update/id/"+workspace.ID, map[string]interface{}{"name": "Home"}, editorToken), http.StatusForbidden)
+	rec = app.request(http.MethodPut, "/workspaces/update/id/"+workspace.ID, map[string]interface{}{"name": "Home", "created_at": "2000-01-01T00:00:00Z"}, ownerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var renamed models.Workspace
+	decode(t, rec, &renamed)
+	if renamed.Name != "Home" || !renamed.CreatedAt.Equal(workspace.CreatedAt) {
+		t.Fatalf("expected the workspace to be renamed and keep its creation date, got %+v", renamed)
+	}
+}
+
+func TestRemoveWorkspaceMember(t *testing.T) {
+	app := newTestApp(t)
+	owner, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	viewer, viewerToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	app.joinWorkspace(ownerToken, workspace, viewer, viewerToken, models.RoleViewer)
+
+	membersPath := "/workspaces/delete/id/" + workspace.ID + "/members/"
+	expectStatus(t, app.request(http.MethodDelete, membersPath+viewer.ID, nil, editorToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodDelete, membersPath+viewer.ID, nil, viewerToken), http.StatusOK)
+	expectStatus(t, app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID, nil, viewerToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodDelete, membersPath+owner.ID, nil, ownerToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodDelete, membersPath+editor.ID, nil, ownerToken), http.StatusOK)
+
+	rec := app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID+"/members", nil, ownerToken)
+	expectStatus(t, rec, http.StatusOK)
+	var members []models.WorkspaceMember
+	decode(t, rec, &members)
+	if len(members) != 1 || members[0].UserID != owner.ID {
+		t.Fatalf("expected only the owner to be left, got %+v", members)
+	}
+}
+
+func TestDeleteWorkspace(t *testing.T) {
+	app := newTestApp(t)
+	_, ownerToken := app.newUser()
+	editor, editorToken := app.newUser()
+	workspace := app.createWorkspace(ownerToken, "Family")
+	app.joinWorkspace(ownerToken, workspace, editor, editorToken, models.RoleEditor)
+	task := app.createTask(editorToken, map[string]interface{}{"title": "Buy groceries", "workspace_id": workspace.ID})
+	trashed := app.createTask(editorToken, map[string]interface{}{"title": "Old list", "workspace_id": workspace.ID})
+	expectStatus(t, app.upload(editorToken, task.ID, "list.txt", []byte("milk")), http.StatusCreated)
+	expectStatus(t, app.upload(editorToken, trashed.ID, "old.txt", []byte("eggs")), http.StatusCreated)
+	expectStatus(t, app.request(http.MethodDelete, fmt.Sprintf("/tasks/delete/id/%d", trashed.ID), nil, editorToken), http.StatusOK)
+	attachments, err := repository.NewGormAttachmentRepository(app.db).FindByWorkspace(context.Background(), workspace.ID)
+	if err != nil || len(attachments) != 2 {
+		t.Fatalf("expected the attachments of both tasks, got %+v and %v", attachments, err)
+	}
+
+	expectStatus(t, app.request(http.MethodDelete, "/workspaces/delete/id/"+workspace.ID, nil, editorToken), http.StatusForbidden)
+	expectStatus(t, app.request(http.MethodDelete, "/workspaces/delete/id/"+workspace.ID, nil, ownerToken), http.StatusOK)
+
+	for _, attachment := range attachments {
+		if _, err := app.blobs.Open(context.Background(), attachment.StorageKey); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("expected the contents of %s to be removed, got %v", attachment.FileName, err)
+		}
+	}
+
+	expectStatus(t, app.request(http.MethodGet, "/workspaces/get/id/"+workspace.ID, nil, ownerToken), http.StatusNotFound)
+	expectStatus(t, app.request(http.MethodGet, fmt.Sprintf("/tasks/get/id/%d", task.ID), nil, editorToken), http.StatusNotFound)
+}